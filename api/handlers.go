@@ -0,0 +1,263 @@
+package api
+
+import (
+	"ci6ndex/ci6ndex"
+	"ci6ndex/ci6ndex/generated"
+	"database/sql"
+	"errors"
+	"net/http"
+	"slices"
+	"strconv"
+)
+
+func (s *Server) leaders(w http.ResponseWriter, r *http.Request, guildID uint64) error {
+	leaders, err := s.c.GetLeaders(guildID)
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, newLeaders(leaders))
+	return nil
+}
+
+func (s *Server) leader(w http.ResponseWriter, r *http.Request, guildID uint64) error {
+	leader, err := s.getLeader(r, guildID)
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, newLeader(leader))
+	return nil
+}
+
+func (s *Server) getLeader(r *http.Request, guildID uint64) (generated.Leader, error) {
+	leaderID, err := pathID(r, "leaderId")
+	if err != nil {
+		return generated.Leader{}, err
+	}
+	leader, err := s.c.GetLeaderById(guildID, uint64(leaderID))
+	if errors.Is(err, ci6ndex.ErrLeaderNotFound) {
+		return generated.Leader{}, notFound("leader %d not found", leaderID)
+	}
+	return leader, err
+}
+
+func (s *Server) leaderRanks(w http.ResponseWriter, r *http.Request, guildID uint64) error {
+	leader, err := s.getLeader(r, guildID)
+	if err != nil {
+		return err
+	}
+	ranks, err := s.c.GetRanksForLeader(guildID, leader.ID)
+	if err != nil {
+		return err
+	}
+	out := make([]LeaderRank, len(ranks))
+	for i, rank := range ranks {
+		out[i] = LeaderRank{PlayerID: rank.PlayerID, Username: rank.Username, Tier: ci6ndex.TierLetter(rank.Tier),
+			TierValue: rank.Tier, BBG: rank.BBG}
+	}
+	writeJSON(w, http.StatusOK, out)
+	return nil
+}
+
+// tiers takes the bbg and player_id query parameters of ci6ndex.TierListOptions.
+func (s *Server) tiers(w http.ResponseWriter, r *http.Request, guildID uint64) error {
+	bbg, err := queryBBG(r)
+	if err != nil {
+		return err
+	}
+	opts := ci6ndex.TierListOptions{BBG: bbg}
+	if player := r.URL.Query().Get("player_id"); player != "" {
+		v, err := strconv.ParseInt(player, 10, 64)
+		if err != nil {
+			return badRequest("player_id must be an integer")
+		}
+		opts.PlayerID = v
+	}
+	rows, err := s.c.GetTierList(guildID, opts)
+	if err != nil {
+		return err
+	}
+	out := make([]TierRow, len(rows))
+	for i, row := range rows {
+		out[i] = TierRow{Tier: ci6ndex.TierLetter(row.Tier.Value()), Leaders: newLeaders(row.Leaders)}
+	}
+	writeJSON(w, http.StatusOK, out)
+	return nil
+}
+
+// queryBBG is the bbg query parameter, which picks the BBG ruleset over the default vanilla one.
+func queryBBG(r *http.Request) (bool, error) {
+	bbg := r.URL.Query().Get("bbg")
+	if bbg == "" {
+		return false, nil
+	}
+	v, err := strconv.ParseBool(bbg)
+	if err != nil {
+		return false, badRequest("bbg must be true or false")
+	}
+	return v, nil
+}
+
+func (s *Server) players(w http.ResponseWriter, r *http.Request, guildID uint64) error {
+	players, err := s.c.GetPlayers(r.Context(), guildID)
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, newPlayers(players))
+	return nil
+}
+
+func (s *Server) playerRanks(w http.ResponseWriter, r *http.Request, guildID uint64) error {
+	playerID, err := pathID(r, "playerId")
+	if err != nil {
+		return err
+	}
+	bbg, err := queryBBG(r)
+	if err != nil {
+		return err
+	}
+	ranks, err := s.c.GetPlayerRanks(guildID, playerID, bbg)
+	if err != nil {
+		return err
+	}
+	out := make([]PlayerRank, len(ranks))
+	for i, rank := range ranks {
+		out[i] = PlayerRank{LeaderID: rank.Leader.ID, Tier: ci6ndex.TierLetter(rank.Tier), TierValue: rank.Tier,
+			Deviation: rank.Deviation}
+	}
+	writeJSON(w, http.StatusOK, out)
+	return nil
+}
+
+func (s *Server) drafts(w http.ResponseWriter, r *http.Request, guildID uint64) error {
+	drafts, err := s.c.GetDrafts(guildID)
+	if err != nil {
+		return err
+	}
+	out := make([]Draft, len(drafts))
+	for i, d := range drafts {
+		out[i] = newDraft(d)
+	}
+	writeJSON(w, http.StatusOK, out)
+	return nil
+}
+
+func (s *Server) getDraft(r *http.Request, guildID uint64) (ci6ndex.DraftRecord, error) {
+	draftID, err := pathID(r, "draftId")
+	if err != nil {
+		return ci6ndex.DraftRecord{}, err
+	}
+	record, err := s.c.GetDraft(guildID, draftID)
+	if errors.Is(err, ci6ndex.ErrDraftNotFound) {
+		return ci6ndex.DraftRecord{}, notFound("draft %d not found", draftID)
+	}
+	return record, err
+}
+
+func (s *Server) draft(w http.ResponseWriter, r *http.Request, guildID uint64) error {
+	record, err := s.getDraft(r, guildID)
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, DraftDetails{
+		Draft:     newDraft(record.DraftSummary),
+		Players:   newPlayers(record.Players),
+		Offerings: newOfferings(record.Offerings),
+		Picks:     newPicks(record.Picks),
+	})
+	return nil
+}
+
+func (s *Server) offerings(w http.ResponseWriter, r *http.Request, guildID uint64) error {
+	record, err := s.getDraft(r, guildID)
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, newOfferings(record.Offerings))
+	return nil
+}
+
+func (s *Server) picks(w http.ResponseWriter, r *http.Request, guildID uint64) error {
+	record, err := s.getDraft(r, guildID)
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, newPicks(record.Picks))
+	return nil
+}
+
+func (s *Server) results(w http.ResponseWriter, r *http.Request, guildID uint64) error {
+	results, err := s.c.GetDraftResults(guildID)
+	if err != nil {
+		return err
+	}
+	out := make([]*Result, len(results))
+	for i := range results {
+		out[i] = newResult(&results[i])
+	}
+	writeJSON(w, http.StatusOK, out)
+	return nil
+}
+
+// submitRank records a player's rank of a leader like the leader screen does, and answers with the leader's
+// recalculated tier.
+func (s *Server) submitRank(w http.ResponseWriter, r *http.Request, guildID uint64) error {
+	var body SubmitRank
+	if err := decode(r, &body); err != nil {
+		return err
+	}
+	if _, err := ci6ndex.GetTierByName(body.Tier); err != nil {
+		return badRequest("tier must be one of S, A, B, C or F")
+	}
+	if _, err := s.c.GetPlayer(r.Context(), guildID, body.PlayerID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return badRequest("player %d not found", body.PlayerID)
+		}
+		return err
+	}
+	if _, err := s.c.GetLeaderById(guildID, uint64(body.LeaderID)); err != nil {
+		if errors.Is(err, ci6ndex.ErrLeaderNotFound) {
+			return badRequest("leader %d not found", body.LeaderID)
+		}
+		return err
+	}
+
+	if err := s.c.SubmitRankForPlayer(guildID, body.Tier, body.PlayerID, body.LeaderID, body.BBG); err != nil {
+		return err
+	}
+	if err := s.c.CalculateTierForLeader(guildID, body.LeaderID); err != nil {
+		return err
+	}
+	leader, err := s.c.GetLeaderById(guildID, uint64(body.LeaderID))
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, newLeader(leader))
+	return nil
+}
+
+// recordResult records the winner of a draft, replacing any earlier result. The winner has to be one of the draft's
+// players.
+func (s *Server) recordResult(w http.ResponseWriter, r *http.Request, guildID uint64) error {
+	record, err := s.getDraft(r, guildID)
+	if err != nil {
+		return err
+	}
+	var body RecordResult
+	if err := decode(r, &body); err != nil {
+		return err
+	}
+	inDraft := slices.ContainsFunc(record.Players, func(p generated.Player) bool { return p.ID == body.WinnerID })
+	if !inDraft {
+		return badRequest("player %d isn't registered for draft %d", body.WinnerID, record.Draft.ID)
+	}
+
+	if err := s.c.SetDraftWinner(guildID, record.Draft.ID, body.WinnerID); err != nil {
+		return err
+	}
+	record, err = s.c.GetDraft(guildID, record.Draft.ID)
+	if err != nil {
+		return err
+	}
+	writeJSON(w, http.StatusOK, newResult(record.Result))
+	return nil
+}
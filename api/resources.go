@@ -0,0 +1,169 @@
+package api
+
+import (
+	"ci6ndex/ci6ndex"
+	"ci6ndex/ci6ndex/generated"
+	"strconv"
+	"time"
+)
+
+// The resources below are the JSON shapes of openapi.yaml, keep the two in sync.
+
+type Leader struct {
+	ID           int64   `json:"id"`
+	CivName      string  `json:"civ_name"`
+	LeaderName   string  `json:"leader_name"`
+	FriendlyName string  `json:"friendly_name,omitempty"`
+	Tier         string  `json:"tier"`
+	TierValue    float64 `json:"tier_value"`
+	Banned       bool    `json:"banned"`
+	Unranked     bool    `json:"unranked"`
+	BBGExpanded  bool    `json:"bbg_expanded"`
+}
+
+type TierRow struct {
+	Tier    string   `json:"tier"`
+	Leaders []Leader `json:"leaders"`
+}
+
+type Player struct {
+	ID         int64  `json:"id"`
+	Username   string `json:"username"`
+	GlobalName string `json:"global_name,omitempty"`
+}
+
+type LeaderRank struct {
+	PlayerID  int64   `json:"player_id"`
+	Username  string  `json:"username"`
+	Tier      string  `json:"tier"`
+	TierValue float64 `json:"tier_value"`
+	// BBG is set for ranks submitted under the BBG ruleset
+	BBG bool `json:"bbg"`
+}
+
+type PlayerRank struct {
+	LeaderID  int64   `json:"leader_id"`
+	Tier      string  `json:"tier"`
+	TierValue float64 `json:"tier_value"`
+	Deviation float64 `json:"deviation"`
+}
+
+type Draft struct {
+	ID     int64   `json:"id"`
+	Active bool    `json:"active"`
+	Mode   string  `json:"mode"`
+	Result *Result `json:"result"`
+}
+
+type DraftDetails struct {
+	Draft
+	Players   []Player   `json:"players"`
+	Offerings []Offering `json:"offerings"`
+	Picks     []Pick     `json:"picks"`
+}
+
+type Offering struct {
+	PlayerID  int64   `json:"player_id"`
+	LeaderIDs []int64 `json:"leader_ids"`
+}
+
+type Pick struct {
+	PlayerID int64 `json:"player_id"`
+	LeaderID int64 `json:"leader_id"`
+	Auto     bool  `json:"auto"`
+}
+
+type Result struct {
+	DraftID    int64     `json:"draft_id"`
+	WinnerID   int64     `json:"winner_id"`
+	RecordedAt time.Time `json:"recorded_at"`
+}
+
+// SubmitRank is the body of POST /guilds/{guildId}/ranks.
+type SubmitRank struct {
+	PlayerID int64 `json:"player_id"`
+	LeaderID int64 `json:"leader_id"`
+	// Tier is one of S, A, B, C or F
+	Tier string `json:"tier"`
+	// BBG ranks the leader under the BBG ruleset instead of vanilla
+	BBG bool `json:"bbg"`
+}
+
+// RecordResult is the body of PUT /guilds/{guildId}/drafts/{draftId}/result.
+type RecordResult struct {
+	WinnerID int64 `json:"winner_id"`
+}
+
+// Error is the body of every response that isn't a 2xx.
+type Error struct {
+	Error string `json:"error"`
+}
+
+func newLeader(l generated.Leader) Leader {
+	return Leader{
+		ID:           l.ID,
+		CivName:      l.CivName,
+		LeaderName:   l.LeaderName,
+		FriendlyName: l.FriendlyName.String,
+		Tier:         ci6ndex.LeaderTierLetter(l),
+		TierValue:    l.Tier,
+		Banned:       l.Banned,
+		Unranked:     l.Unranked,
+		BBGExpanded:  l.BbgExpanded,
+	}
+}
+
+func newLeaders(leaders []generated.Leader) []Leader {
+	out := make([]Leader, len(leaders))
+	for i, l := range leaders {
+		out[i] = newLeader(l)
+	}
+	return out
+}
+
+func newPlayers(players []generated.Player) []Player {
+	out := make([]Player, len(players))
+	for i, p := range players {
+		out[i] = Player{ID: p.ID, Username: p.Username, GlobalName: p.GlobalName.String}
+	}
+	return out
+}
+
+func newDraft(s ci6ndex.DraftSummary) Draft {
+	return Draft{ID: s.Draft.ID, Active: s.Draft.Active, Mode: s.Draft.Mode, Result: newResult(s.Result)}
+}
+
+func newResult(r *generated.DraftResult) *Result {
+	if r == nil {
+		return nil
+	}
+	// winner is the player ID as text, see 015_draft_results.sql
+	winner, _ := strconv.ParseInt(r.Winner, 10, 64)
+	return &Result{DraftID: r.DraftID, WinnerID: winner, RecordedAt: r.RecordedAt}
+}
+
+// newOfferings groups the pool of a draft by player, in the order the players first appear.
+func newOfferings(pool []generated.Pool) []Offering {
+	out := make([]Offering, 0)
+	index := make(map[int64]int)
+	for _, p := range pool {
+		i, ok := index[p.PlayerID]
+		if !ok {
+			i = len(out)
+			index[p.PlayerID] = i
+			out = append(out, Offering{PlayerID: p.PlayerID, LeaderIDs: make([]int64, 0)})
+		}
+		out[i].LeaderIDs = append(out[i].LeaderIDs, p.Leader)
+	}
+	return out
+}
+
+func newPicks(picks []generated.Pick) []Pick {
+	out := make([]Pick, len(picks))
+	for i, p := range picks {
+		// player is the player ID as text, like the winner of a draft
+		player, _ := strconv.ParseInt(p.Player, 10, 64)
+		out[i] = Pick{PlayerID: player, LeaderID: p.Pick, Auto: p.Auto}
+	}
+	return out
+}
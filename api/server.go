@@ -0,0 +1,154 @@
+// Package api serves the ci6ndex data of the bot's guilds as a REST/JSON API, described by openapi.yaml.
+package api
+
+import (
+	"ci6ndex/ci6ndex"
+	"crypto/subtle"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+//go:embed openapi.yaml
+var openAPISpec []byte
+
+// Server answers the API requests with the data of the guilds it was given, other guilds are not found.
+type Server struct {
+	c      *ci6ndex.Ci6ndex
+	guilds []uint64
+	// token authenticates the write endpoints, they are disabled when it's empty
+	token string
+}
+
+func New(c *ci6ndex.Ci6ndex, guilds []uint64, token string) *Server {
+	return &Server{c: c, guilds: guilds, token: token}
+}
+
+// NewHTTPServer serves the API on addr.
+func NewHTTPServer(addr string, s *Server) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+}
+
+// Handler routes the API endpoints, every one of them is in openapi.yaml.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /openapi.yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/yaml")
+		_, _ = w.Write(openAPISpec)
+	})
+
+	mux.HandleFunc("GET /v1/guilds/{guildId}/leaders", s.guild(s.leaders))
+	mux.HandleFunc("GET /v1/guilds/{guildId}/leaders/{leaderId}", s.guild(s.leader))
+	mux.HandleFunc("GET /v1/guilds/{guildId}/leaders/{leaderId}/ranks", s.guild(s.leaderRanks))
+	mux.HandleFunc("GET /v1/guilds/{guildId}/tiers", s.guild(s.tiers))
+	mux.HandleFunc("GET /v1/guilds/{guildId}/players", s.guild(s.players))
+	mux.HandleFunc("GET /v1/guilds/{guildId}/players/{playerId}/ranks", s.guild(s.playerRanks))
+	mux.HandleFunc("GET /v1/guilds/{guildId}/drafts", s.guild(s.drafts))
+	mux.HandleFunc("GET /v1/guilds/{guildId}/drafts/{draftId}", s.guild(s.draft))
+	mux.HandleFunc("GET /v1/guilds/{guildId}/drafts/{draftId}/offerings", s.guild(s.offerings))
+	mux.HandleFunc("GET /v1/guilds/{guildId}/drafts/{draftId}/picks", s.guild(s.picks))
+	mux.HandleFunc("GET /v1/guilds/{guildId}/results", s.guild(s.results))
+
+	mux.HandleFunc("POST /v1/guilds/{guildId}/ranks", s.authenticated(s.guild(s.submitRank)))
+	mux.HandleFunc("PUT /v1/guilds/{guildId}/drafts/{draftId}/result", s.authenticated(s.guild(s.recordResult)))
+	return mux
+}
+
+// guildHandler handles a request for a guild the server serves, errors are written by guild.
+type guildHandler func(w http.ResponseWriter, r *http.Request, guildID uint64) error
+
+// httpError is an error with the status it's answered with, errors without one are internal server errors.
+type httpError struct {
+	status int
+	msg    string
+}
+
+func (e httpError) Error() string {
+	return e.msg
+}
+
+func badRequest(format string, args ...any) error {
+	return httpError{status: http.StatusBadRequest, msg: fmt.Sprintf(format, args...)}
+}
+
+func notFound(format string, args ...any) error {
+	return httpError{status: http.StatusNotFound, msg: fmt.Sprintf(format, args...)}
+}
+
+// guild resolves the guildId of the path and writes the error fn returns, if any.
+func (s *Server) guild(fn guildHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		guildID, err := strconv.ParseUint(r.PathValue("guildId"), 10, 64)
+		if err != nil || !slices.Contains(s.guilds, guildID) {
+			writeError(w, r, notFound("guild %s not found", r.PathValue("guildId")))
+			return
+		}
+		if err := fn(w, r, guildID); err != nil {
+			writeError(w, r, err)
+		}
+	}
+}
+
+// authenticated only lets requests with the server's token through, as an Authorization: Bearer header.
+func (s *Server) authenticated(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if s.token == "" {
+			writeError(w, r, httpError{status: http.StatusForbidden, msg: "writes are disabled, set API_TOKEN"})
+			return
+		}
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(token), []byte(s.token)) != 1 {
+			w.Header().Set("WWW-Authenticate", "Bearer")
+			writeError(w, r, httpError{status: http.StatusUnauthorized, msg: "missing or invalid bearer token"})
+			return
+		}
+		next(w, r)
+	}
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("failed to write response", "error", err)
+	}
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var httpErr httpError
+	if !errors.As(err, &httpErr) {
+		slog.Error("api request failed", "method", r.Method, "path", r.URL.Path, "error", err)
+		httpErr = httpError{status: http.StatusInternalServerError, msg: "internal server error"}
+	}
+	writeJSON(w, httpErr.status, Error{Error: httpErr.msg})
+}
+
+// pathID parses the int64 ID of the named path segment.
+func pathID(r *http.Request, name string) (int64, error) {
+	id, err := strconv.ParseInt(r.PathValue(name), 10, 64)
+	if err != nil {
+		return 0, badRequest("%s must be an integer", name)
+	}
+	return id, nil
+}
+
+// decode reads the JSON body of r into v, rejecting unknown fields.
+func decode(r *http.Request, v any) error {
+	dec := json.NewDecoder(http.MaxBytesReader(nil, r.Body, 1<<20))
+	dec.DisallowUnknownFields()
+	if err := dec.Decode(v); err != nil {
+		return badRequest("invalid request body: %v", err)
+	}
+	return nil
+}
@@ -0,0 +1,231 @@
+package api
+
+import (
+	"bytes"
+	"ci6ndex/ci6ndex"
+	"ci6ndex/ci6ndex/generated"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	goose "github.com/pressly/goose/v3"
+)
+
+const (
+	testGuildID = 999999
+	testToken   = "secret"
+	guildPath   = "/v1/guilds/999999"
+)
+
+// newTestServer serves a guild with a database in a temp dir and two players registered for its active draft.
+func newTestServer(t *testing.T, token string) (*httptest.Server, *ci6ndex.Ci6ndex, generated.Draft) {
+	t.Helper()
+	goose.SetBaseFS(os.DirFS(".."))
+	goose.SetLogger(goose.NopLogger())
+	if err := goose.SetDialect("sqlite3"); err != nil {
+		t.Fatal(err)
+	}
+	c := &ci6ndex.Ci6ndex{Connections: make(map[uint64]*ci6ndex.DB), Path: t.TempDir() + "/"}
+	t.Cleanup(c.Close)
+
+	draft, err := c.GetOrCreateActiveDraft(testGuildID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	players := []generated.AddPlayerParams{{ID: 1, Username: "host"}, {ID: 2, Username: "player"}}
+	for _, p := range players {
+		if err := c.AddPlayer(context.Background(), testGuildID, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if errs := c.SetPlayersForDraft(testGuildID, draft.ID, players); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	srv := httptest.NewServer(New(c, []uint64{testGuildID}, token).Handler())
+	t.Cleanup(srv.Close)
+	return srv, c, draft
+}
+
+// do sends a request with body encoded as JSON and decodes the response into out, returning the status.
+func do(t *testing.T, srv *httptest.Server, method, path, token string, body, out any) int {
+	t.Helper()
+	var data []byte
+	if body != nil {
+		var err error
+		if data, err = json.Marshal(body); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req, err := http.NewRequest(method, srv.URL+path, bytes.NewReader(data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	res, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if out != nil {
+		if err := json.NewDecoder(res.Body).Decode(out); err != nil {
+			t.Fatalf("failed to decode %s %s: %v", method, path, err)
+		}
+	}
+	return res.StatusCode
+}
+
+func TestLeaders(t *testing.T) {
+	srv, _, _ := newTestServer(t, "")
+
+	var leaders []Leader
+	if status := do(t, srv, "GET", guildPath+"/leaders", "", nil, &leaders); status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	if len(leaders) == 0 {
+		t.Fatal("expected the seeded leaders")
+	}
+
+	var leader Leader
+	path := guildPath + "/leaders/" + strconv.FormatInt(leaders[0].ID, 10)
+	if status := do(t, srv, "GET", path, "", nil, &leader); status != http.StatusOK || leader != leaders[0] {
+		t.Errorf("expected leader %v, got %d %v", leaders[0], status, leader)
+	}
+
+	var tiers []TierRow
+	if status := do(t, srv, "GET", guildPath+"/tiers?bbg=true", "", nil, &tiers); status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	if len(tiers) != 6 || tiers[0].Tier != "S" || tiers[5].Tier != "Unranked" {
+		t.Errorf("expected every tier best first, got %v", tiers)
+	}
+
+	var apiErr Error
+	for _, path := range []string{"/v1/guilds/1/leaders", guildPath + "/leaders/100000"} {
+		if status := do(t, srv, "GET", path, "", nil, &apiErr); status != http.StatusNotFound {
+			t.Errorf("expected %s to be not found, got %d", path, status)
+		}
+	}
+	if status := do(t, srv, "GET", guildPath+"/tiers?bbg=maybe", "", nil, &apiErr); status != http.StatusBadRequest {
+		t.Errorf("expected an invalid query parameter to be a bad request, got %d", status)
+	}
+}
+
+func TestSubmitRank(t *testing.T) {
+	srv, _, _ := newTestServer(t, testToken)
+	var leaders []Leader
+	do(t, srv, "GET", guildPath+"/leaders", "", nil, &leaders)
+	rank := SubmitRank{PlayerID: 1, LeaderID: leaders[0].ID, Tier: "S"}
+
+	var apiErr Error
+	if status := do(t, srv, "POST", guildPath+"/ranks", "", rank, &apiErr); status != http.StatusUnauthorized {
+		t.Errorf("expected a missing token to be unauthorized, got %d", status)
+	}
+	if status := do(t, srv, "POST", guildPath+"/ranks", "wrong", rank, &apiErr); status != http.StatusUnauthorized {
+		t.Errorf("expected a wrong token to be unauthorized, got %d", status)
+	}
+	bad := SubmitRank{PlayerID: 1, LeaderID: leaders[0].ID, Tier: "Z"}
+	if status := do(t, srv, "POST", guildPath+"/ranks", testToken, bad, &apiErr); status != http.StatusBadRequest {
+		t.Errorf("expected an unknown tier to be a bad request, got %d", status)
+	}
+
+	var leader Leader
+	if status := do(t, srv, "POST", guildPath+"/ranks", testToken, rank, &leader); status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	if leader.ID != rank.LeaderID || leader.Tier != "S" {
+		t.Errorf("expected the leader's tier to be recalculated to S, got %v", leader)
+	}
+
+	var ranks []PlayerRank
+	do(t, srv, "GET", guildPath+"/players/1/ranks", "", nil, &ranks)
+	if len(ranks) != 1 || ranks[0].LeaderID != rank.LeaderID || ranks[0].Tier != "S" {
+		t.Errorf("expected the submitted rank, got %v", ranks)
+	}
+}
+
+func TestDraftResult(t *testing.T) {
+	srv, c, draft := newTestServer(t, testToken)
+	draftPath := guildPath + "/drafts/" + strconv.FormatInt(draft.ID, 10)
+
+	var details DraftDetails
+	if status := do(t, srv, "GET", draftPath, "", nil, &details); status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	if !details.Active || len(details.Players) != 2 || details.Result != nil {
+		t.Errorf("expected the active draft with two players and no result, got %+v", details)
+	}
+
+	resultPath := draftPath + "/result"
+	var apiErr Error
+	status := do(t, srv, "PUT", resultPath, testToken, RecordResult{WinnerID: 3}, &apiErr)
+	if status != http.StatusBadRequest {
+		t.Errorf("expected a winner outside the draft to be a bad request, got %d", status)
+	}
+	var result Result
+	if status := do(t, srv, "PUT", resultPath, testToken, RecordResult{WinnerID: 2}, &result); status != http.StatusOK {
+		t.Fatalf("expected 200, got %d", status)
+	}
+	if result.DraftID != draft.ID || result.WinnerID != 2 {
+		t.Errorf("expected player 2 to win, got %v", result)
+	}
+
+	var drafts []Draft
+	do(t, srv, "GET", guildPath+"/drafts", "", nil, &drafts)
+	if len(drafts) != 1 || drafts[0].Result == nil || drafts[0].Result.WinnerID != 2 {
+		t.Errorf("expected the draft with its result, got %v", drafts)
+	}
+	var results []Result
+	do(t, srv, "GET", guildPath+"/results", "", nil, &results)
+	if len(results) != 1 || results[0].WinnerID != 2 {
+		t.Errorf("expected the recorded result, got %v", results)
+	}
+	if winner, _ := c.GetDraft(testGuildID, draft.ID); winner.Result == nil || winner.Result.Winner != "2" {
+		t.Errorf("expected the winner to be stored like the bot stores it, got %v", winner.Result)
+	}
+
+	if status := do(t, srv, "GET", guildPath+"/drafts/12345", "", nil, &apiErr); status != http.StatusNotFound {
+		t.Errorf("expected an unknown draft to be not found, got %d", status)
+	}
+}
+
+func TestWritesDisabled(t *testing.T) {
+	srv, _, draft := newTestServer(t, "")
+	var apiErr Error
+	path := guildPath + "/drafts/" + strconv.FormatInt(draft.ID, 10) + "/result"
+	if status := do(t, srv, "PUT", path, "", RecordResult{WinnerID: 1}, &apiErr); status != http.StatusForbidden {
+		t.Errorf("expected writes to be forbidden without a token, got %d", status)
+	}
+	if !strings.Contains(apiErr.Error, "API_TOKEN") {
+		t.Errorf("expected the error to mention API_TOKEN, got %q", apiErr.Error)
+	}
+}
+
+func TestOpenAPISpec(t *testing.T) {
+	srv, _, _ := newTestServer(t, "")
+	res, err := http.Get(srv.URL + "/openapi.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	if res.StatusCode != http.StatusOK || !strings.HasPrefix(res.Header.Get("Content-Type"), "application/yaml") {
+		t.Errorf("expected the spec, got %d %s", res.StatusCode, res.Header.Get("Content-Type"))
+	}
+}
+
+func TestNewOfferings(t *testing.T) {
+	offerings := newOfferings([]generated.Pool{
+		{PlayerID: 2, Leader: 10}, {PlayerID: 1, Leader: 11}, {PlayerID: 2, Leader: 12},
+	})
+	if len(offerings) != 2 || offerings[0].PlayerID != 2 || len(offerings[0].LeaderIDs) != 2 ||
+		offerings[1].LeaderIDs[0] != 11 {
+		t.Errorf("expected the pool grouped by player in order, got %v", offerings)
+	}
+}
@@ -0,0 +1,363 @@
+package bot
+
+import (
+	"bytes"
+	"ci6ndex/ci6ndex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	snowflake "github.com/disgoorg/snowflake/v2"
+	md "github.com/nao1215/markdown"
+)
+
+const blindPickInput = "leader"
+
+func blindRoute(draftId int64, action string) string {
+	route := "/blind/" + strconv.FormatInt(draftId, 10)
+	if action != "" {
+		route += "/" + action
+	}
+	return route
+}
+
+// blindConflictOptions offers the ways to settle clashing blind picks when starting a blind pick draft.
+func blindConflictOptions() []discord.StringSelectMenuOption {
+	return []discord.StringSelectMenuOption{
+		discord.NewStringSelectMenuOption("Blind Pick: duplicates re-pick", string(ci6ndex.BlindRepick)).
+			WithDescription("Everyone who picked the same leader picks again"),
+		discord.NewStringSelectMenuOption("Blind Pick: random winner", string(ci6ndex.BlindRandomWinner)).
+			WithDescription("One of them keeps the leader, the others pick again"),
+		discord.NewStringSelectMenuOption("Blind Pick: allow duplicates", string(ci6ndex.BlindAllowDuplicates)).
+			WithDescription("Everyone keeps their pick"),
+	}
+}
+
+func (b *Bot) handleStartBlindDraftMenuSelectCommand() handler.SelectMenuComponentHandler {
+	return func(data discord.SelectMenuInteractionData, e *handler.ComponentEvent) error {
+		guildID, err := parseGuildId(e.GuildID().String())
+		if err != nil {
+			return err
+		}
+		selectData, ok := data.(discord.StringSelectMenuInteractionData)
+		if !ok || len(selectData.Values) == 0 {
+			return fmt.Errorf("unexpected select menu data %T", data)
+		}
+		if err := e.DeferCreateMessage(false); err != nil {
+			return err
+		}
+
+		draft, err := b.Ci6ndex.StartBlindDraft(guildID, ci6ndex.BlindConflictRule(selectData.Values[0]))
+		if errors.Is(err, ci6ndex.ErrNotEnoughPlayers) {
+			_, err = e.CreateFollowupMessage(discord.NewMessageCreate().
+				WithEphemeral(true).
+				WithContent("Select at least two players before starting a blind pick draft."))
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		components, err := blindDraftScreen(draft)
+		if err != nil {
+			return err
+		}
+		msg, err := e.CreateFollowupMessage(discord.MessageCreate{
+			Flags:      discord.MessageFlagIsComponentsV2,
+			Components: components,
+		})
+		if err != nil {
+			return errors.Join(err, errors.New("failed to create blind pick screen"))
+		}
+		b.scheduleBlindReveal(guildID, msg.ChannelID, msg.ID, draft)
+		return nil
+	}
+}
+
+func (b *Bot) handleBlindRefreshButtonCommand() handler.ButtonComponentHandler {
+	return func(bid discord.ButtonInteractionData, e *handler.ComponentEvent) error {
+		guildID, err := parseGuildId(e.GuildID().String())
+		if err != nil {
+			return err
+		}
+		draft, err := b.Ci6ndex.GetBlindDraft(guildID)
+		if err != nil {
+			return err
+		}
+		components, err := blindDraftScreen(draft)
+		if err != nil {
+			return err
+		}
+		if err := e.UpdateMessage(discord.MessageUpdate{
+			Components: &components,
+		}); err != nil {
+			return errors.Join(err, errors.New("failed to update blind pick screen"))
+		}
+		return nil
+	}
+}
+
+func (b *Bot) handleBlindPickButtonCommand() handler.ButtonComponentHandler {
+	return func(bid discord.ButtonInteractionData, e *handler.ComponentEvent) error {
+		return e.Modal(discord.ModalCreate{
+			CustomID: bid.CustomID(),
+			Title:    "Pick a leader secretly",
+			Components: []discord.LayoutComponent{
+				discord.NewLabel("Leader name", discord.TextInputComponent{
+					CustomID:    blindPickInput,
+					Style:       discord.TextInputStyleShort,
+					Required:    true,
+					MaxLength:   50,
+					Placeholder: "e.g. Gorgo, nobody sees it until the reveal",
+				}),
+			},
+		})
+	}
+}
+
+func (b *Bot) handleBlindPickModal() handler.ModalHandler {
+	return func(e *handler.ModalEvent) error {
+		guildID, err := parseGuildId(e.GuildID().String())
+		if err != nil {
+			return err
+		}
+		draftID, err := strconv.ParseInt(e.Vars["draftId"], 10, 64)
+		if err != nil {
+			return errors.Join(err, errors.New("failed to parse draft id"))
+		}
+		reply := func(content string) error {
+			return e.CreateMessage(discord.NewMessageCreate().WithEphemeral(true).WithContent(content))
+		}
+
+		draft, err := b.Ci6ndex.GetBlindDraft(guildID)
+		if err != nil {
+			return err
+		}
+		if draft.DraftID != draftID {
+			return reply("This draft is over, start a new one from /draft.")
+		}
+		query := strings.TrimSpace(e.Data.Text(blindPickInput))
+		matches := ci6ndex.SearchLeaders(draft.Available, query, ci6ndex.SearchLeaderName)
+		if len(matches) == 0 {
+			return reply(fmt.Sprintf("No available leader matches %q, try another search", query))
+		}
+		leader := matches[0].Leader
+
+		draft, err = b.Ci6ndex.SubmitBlindPick(guildID, int64(e.User().ID), leader.ID)
+		switch {
+		case errors.Is(err, ci6ndex.ErrNotInDraft):
+			return reply("You aren't playing in this draft.")
+		case errors.Is(err, ci6ndex.ErrAlreadyPicked):
+			return reply("Your pick has already been revealed.")
+		case err != nil:
+			return err
+		}
+
+		components, err := blindDraftScreen(draft)
+		if err != nil {
+			return err
+		}
+		if err := e.UpdateMessage(discord.MessageUpdate{Components: &components}); err != nil {
+			return errors.Join(err, errors.New("failed to update blind pick screen"))
+		}
+		_, err = e.CreateFollowupMessage(discord.NewMessageCreate().
+			WithEphemeral(true).
+			WithContent(fmt.Sprintf("You secretly picked %s %s. Pick again to change it before the reveal.",
+				leader.DiscordEmojiString.String, ci6ndex.LeaderName(leader))))
+		if err != nil {
+			return err
+		}
+
+		if e.Message == nil {
+			return nil
+		}
+		reveal, resolved, err := b.Ci6ndex.ResolveBlindRound(guildID, draft.DraftID, draft.Round, false)
+		if err != nil || !resolved {
+			return err
+		}
+		b.revealBlindRound(guildID, e.Message.ChannelID, e.Message.ID, reveal)
+		return nil
+	}
+}
+
+// blindRevealJob is the payload of the job that reveals a blind pick round at its deadline.
+type blindRevealJob struct {
+	ChannelID snowflake.ID
+	MessageID snowflake.ID
+	DraftID   int64
+	Round     int
+}
+
+// scheduleBlindReveal reveals the current round at its deadline, whether or not everybody has picked. Rounds that
+// were revealed early leave the job with nothing to do.
+func (b *Bot) scheduleBlindReveal(guildID uint64, channelID, messageID snowflake.ID, draft ci6ndex.BlindDraft) {
+	if draft.Done() {
+		return
+	}
+	err := b.Scheduler.Schedule(guildID, jobKindBlindReveal, fmt.Sprintf("blind:%d", draft.DraftID),
+		draft.Deadline(), blindRevealJob{
+			ChannelID: channelID,
+			MessageID: messageID,
+			DraftID:   draft.DraftID,
+			Round:     draft.Round,
+		})
+	if err != nil {
+		slog.Error("failed to schedule blind pick reveal", "draft", draft.DraftID, "round", draft.Round, "error", err)
+	}
+}
+
+func (b *Bot) runBlindRevealJob(guildID uint64, job ci6ndex.Job) error {
+	var payload blindRevealJob
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+	reveal, resolved, err := b.Ci6ndex.ResolveBlindRound(guildID, payload.DraftID, payload.Round, true)
+	if err != nil {
+		return errors.Join(err, fmt.Errorf("failed to reveal blind pick round %d", payload.Round))
+	}
+	if resolved {
+		b.revealBlindRound(guildID, payload.ChannelID, payload.MessageID, reveal)
+	}
+	return nil
+}
+
+// revealBlindRound posts the reveal to the channel, refreshes the blind pick screen and times the next round if
+// anybody has to pick again.
+func (b *Bot) revealBlindRound(guildID uint64, channelID, messageID snowflake.ID, reveal ci6ndex.BlindReveal) {
+	var buf bytes.Buffer
+	if err := renderBlindReveal(&buf, reveal); err != nil {
+		slog.Error("failed to render blind pick reveal", "error", err)
+		return
+	}
+	_, err := b.Client.Rest.CreateMessage(channelID, discord.MessageCreate{
+		Flags: discord.MessageFlagIsComponentsV2,
+		Components: []discord.LayoutComponent{
+			discord.NewContainer(discord.NewTextDisplay(buf.String())).WithAccentColor(colorSuccess),
+		},
+	})
+	if err != nil {
+		logError("failed to post blind pick reveal", err)
+	}
+
+	components, err := blindDraftScreen(reveal.Draft)
+	if err != nil {
+		slog.Error("failed to render blind pick screen", "error", err)
+		return
+	}
+	_, err = b.Client.Rest.UpdateMessage(channelID, messageID, discord.MessageUpdate{
+		Components: &components,
+	})
+	if err != nil {
+		logError("failed to update blind pick screen", err)
+	}
+	b.scheduleBlindReveal(guildID, channelID, messageID, reveal.Draft)
+}
+
+func blindDraftScreen(draft ci6ndex.BlindDraft) ([]discord.LayoutComponent, error) {
+	var header bytes.Buffer
+	if err := renderBlindDraft(&header, draft); err != nil {
+		return nil, errors.Join(err, errors.New("failed to render blind pick draft"))
+	}
+	if draft.Done() {
+		return []discord.LayoutComponent{
+			discord.NewContainer(discord.NewTextDisplay(header.String())).WithAccentColor(colorSuccess),
+		}, nil
+	}
+	return []discord.LayoutComponent{
+		discord.NewContainer(
+			discord.NewTextDisplay(header.String()),
+			discord.NewLargeSeparator(),
+			discord.NewActionRow(
+				discord.NewPrimaryButton("Pick secretly", blindRoute(draft.DraftID, "pick")).
+					WithEmoji(discord.ComponentEmoji{Name: crossedSwords}),
+				discord.NewSecondaryButton("Refresh", blindRoute(draft.DraftID, "")).
+					WithEmoji(discord.ComponentEmoji{Name: magnifyingGlass}),
+			),
+		).WithAccentColor(colorSuccess),
+	}, nil
+}
+
+func blindConflictDescription(rule ci6ndex.BlindConflictRule) string {
+	switch rule {
+	case ci6ndex.BlindRandomWinner:
+		return "a random player keeps the leader, the others pick again"
+	case ci6ndex.BlindAllowDuplicates:
+		return "everyone keeps their pick"
+	default:
+		return "everyone who picked the same leader picks again"
+	}
+}
+
+func renderBlindDraft(buffer io.Writer, draft ci6ndex.BlindDraft) error {
+	mdBuilder := md.NewMarkdown(buffer).H2f("Blind Pick (Round %d)", draft.Round+1).
+		PlainText("Every player secretly picks any eligible leader, all picks are revealed at once.").
+		PlainTextf("- **Duplicates**: %s", blindConflictDescription(draft.Rule))
+
+	for _, p := range draft.Players {
+		switch {
+		case p.Pick != nil && p.Auto:
+			mdBuilder.PlainTextf("- <@%d>: %s %s *(auto-picked)*", p.Player.ID, p.Pick.DiscordEmojiString.String,
+				ci6ndex.LeaderName(*p.Pick))
+		case p.Pick != nil:
+			mdBuilder.PlainTextf("- <@%d>: %s %s", p.Player.ID, p.Pick.DiscordEmojiString.String,
+				ci6ndex.LeaderName(*p.Pick))
+		case p.Submitted:
+			mdBuilder.PlainTextf("- <@%d>: picked, hidden until the reveal", p.Player.ID)
+		default:
+			mdBuilder.PlainTextf("- <@%d>: **picking...**", p.Player.ID)
+		}
+	}
+
+	if draft.Done() {
+		mdBuilder.PlainTextf("\n%s Everybody has picked, good luck!", partyEmoji)
+	} else {
+		mdBuilder.PlainTextf("\n%s Picks are revealed once everyone is in, or <t:%d:R> with the highest rated "+
+			"leader picked for anyone still picking.", crossedSwords, draft.Deadline().Unix())
+	}
+	return mdBuilder.Build()
+}
+
+func renderBlindReveal(buffer io.Writer, reveal ci6ndex.BlindReveal) error {
+	mdBuilder := md.NewMarkdown(buffer).H2f("%s Blind Pick Reveal (Round %d)", partyEmoji, reveal.Round+1)
+	for _, p := range reveal.Picks {
+		line := fmt.Sprintf("- <@%d>: %s %s", p.Player.ID, p.Leader.DiscordEmojiString.String,
+			ci6ndex.LeaderName(p.Leader))
+		if p.Auto {
+			line += " *(auto-picked)*"
+		}
+		if !p.Kept {
+			line = "- ~~" + strings.TrimPrefix(line, "- ") + "~~ picks again"
+		}
+		mdBuilder.PlainText(line)
+	}
+
+	if len(reveal.Clashes) > 0 {
+		mdBuilder.H3("Clashes")
+	}
+	for _, clash := range reveal.Clashes {
+		mentions := make([]string, len(clash.Players))
+		for i, p := range clash.Players {
+			mentions[i] = fmt.Sprintf("<@%d>", p.ID)
+		}
+		outcome := "everyone picks again"
+		switch {
+		case clash.Winner != nil:
+			outcome = fmt.Sprintf("<@%d> won the draw", clash.Winner.ID)
+		case reveal.Rule == ci6ndex.BlindAllowDuplicates:
+			outcome = "everyone keeps it"
+		}
+		mdBuilder.PlainTextf("- %s %s: %s, %s", clash.Leader.DiscordEmojiString.String,
+			ci6ndex.LeaderName(clash.Leader), strings.Join(mentions, ", "), outcome)
+	}
+
+	if !reveal.Draft.Done() {
+		mdBuilder.PlainTextf("\nRound %d is open, picks are revealed <t:%d:R> at the latest.",
+			reveal.Draft.Round+1, reveal.Draft.Deadline().Unix())
+	}
+	return mdBuilder.Build()
+}
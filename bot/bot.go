@@ -8,7 +8,7 @@ import (
 	"log/slog"
 	"strconv"
 	"strings"
-	"sync"
+	"time"
 
 	"github.com/disgoorg/disgo"
 	"github.com/disgoorg/disgo/bot"
@@ -29,70 +29,137 @@ type Bot struct {
 	guildIDs        string
 	listenToGuildID snowflake.ID
 	leadersCache    map[uint64][]generated.Leader
-	wg              sync.WaitGroup
+	tasks           *tasks
+	// Scheduler runs the bot's timed actions, e.g. auto-picking when a snake draft turn times out
+	Scheduler *Scheduler
+	// ShutdownTimeout is how long GracefulShutdown waits for in-flight interactions and background tasks
+	ShutdownTimeout time.Duration
 }
 
 func New(c *ci6ndex.Ci6ndex, discordToken, guildIDs string, listenToGuildID string) *Bot {
+	listenTo := snowflake.MustParse(listenToGuildID)
 	return &Bot{
 		Ci6ndex:         c,
 		discordToken:    discordToken,
 		guildIDs:        guildIDs,
-		listenToGuildID: snowflake.MustParse(listenToGuildID),
+		listenToGuildID: listenTo,
 		leadersCache:    make(map[uint64][]generated.Leader),
-		wg:              sync.WaitGroup{},
+		tasks:           newTasks(),
+		Scheduler:       NewScheduler(c, uint64(listenTo)),
+		ShutdownTimeout: DefaultShutdownTimeout,
 	}
 }
 
 func (b *Bot) Configure() error {
 	slog.Info("configuring Discord Bot...")
+	b.Scheduler.Handle(jobKindSnakeAutoPick, b.runSnakeAutoPickJob)
+	b.Scheduler.Handle(jobKindBlindReveal, b.runBlindRevealJob)
+	b.Scheduler.Handle(jobKindGameNightReminder, b.runGameNightReminderJob)
+
+	var err error
+	b.Client, err = disgo.New(b.discordToken,
+		bot.WithGatewayConfigOpts(
+			gateway.WithIntents(
+				gateway.IntentGuildMessages,
+				gateway.IntentMessageContent,
+				gateway.IntentGuilds,
+				gateway.IntentDirectMessages,
+			),
+			gateway.WithPresenceOpts(
+				gateway.WithPlayingActivity("loading..."),
+			),
+		),
+		bot.WithEventListenerFunc(b.onReady),
+		bot.WithEventListeners(b.Router()),
+	)
+	if err != nil {
+		return errors.Wrap(err, "failed to create discord client")
+	}
+	return nil
+}
+
+// Router routes every interaction the bot handles, behind the middlewares every interaction goes through.
+func (b *Bot) Router() *handler.Mux {
 	r := handler.New()
 	r.SlashCommand("/ping", HandlePing)
 	// r.SlashCommand("/leader", b.handleGetLeaderSlashCommand())
 
+	// middlewares run in the order they're added, the first one wraps all the others
 	r.Use(FilterGuildMiddleware(b.listenToGuildID))
+	r.Use(logInteractions)
+	r.Use(b.trackInteractions)
+	r.Use(b.instrumentInteractions)
+	r.Use(recoverPanics)
 
 	r.Group(func(r handler.Router) {
 		r.SlashCommand("/draft", b.handleManageDraft())
+		// routes match by prefix, so the settings routes have to come before /draft
+		r.ButtonComponent("/draft/settings", b.handleRollSettingsButtonCommand())
+		r.SelectMenuComponent("/draft/settings/{field}", b.handleRollSettingsMenuSelectCommand())
+		r.SelectMenuComponent("/draft/game-settings/{category}/{status}", b.handleGameSettingsListSelectCommand())
+		r.SelectMenuComponent("/draft/game-settings", b.handleGameSettingsCategorySelectCommand())
+		r.ButtonComponent("/draft/game-settings", b.handleGameSettingsButtonCommand())
 		r.ButtonComponent("/draft", b.handleManageDraftButton())
 		r.ButtonComponent("/create-draft", b.handleCreateDraft())
+		r.SelectMenuComponent("/select-player", b.handlePlayerSelect())
 	})
 	r.Group(func(r handler.Router) {
 		// r.ButtonComponent("/confirm-roll", b.handleConfirmRoll())
 		r.ButtonComponent("/confirm-roll-draft", b.handleConfirmRollDraft())
 	})
+	r.Route("/snake", func(r handler.Router) {
+		// routes match by prefix, so the pick routes have to come before /{draftId}
+		r.ButtonComponent("/start/{order}", b.handleStartSnakeDraftButtonCommand())
+		r.ButtonComponent("/{draftId}/search", b.handleSnakeSearchButtonCommand())
+		r.Modal("/{draftId}/search", b.handleSnakeSearchModal())
+		r.SelectMenuComponent("/{draftId}/pick", b.handleSnakePickMenuSelectCommand())
+		r.ButtonComponent("/{draftId}", b.handleSnakeRefreshButtonCommand())
+	})
+	r.Route("/blind", func(r handler.Router) {
+		// routes match by prefix, so the pick routes have to come before /{draftId}
+		r.SelectMenuComponent("/start", b.handleStartBlindDraftMenuSelectCommand())
+		r.ButtonComponent("/{draftId}/pick", b.handleBlindPickButtonCommand())
+		r.Modal("/{draftId}/pick", b.handleBlindPickModal())
+		r.ButtonComponent("/{draftId}", b.handleBlindRefreshButtonCommand())
+	})
 	r.SlashCommand("/leader", b.handleSearchLeaderSlashCommand())
+	r.Autocomplete("/leader", b.handleLeaderAutocomplete())
+	r.SlashCommand("/tierlist", b.handleTierListSlashCommand())
+	r.SlashCommand("/compare", b.handleCompareSlashCommand())
+	r.SlashCommand("/guides", b.handlePendingGuidesSlashCommand())
+	r.SlashCommand("/teams", b.handleRandomizeTeamsSlashCommand())
+	r.Route("/schedule", func(r handler.Router) {
+		r.SlashCommand("/", b.handleScheduleSlashCommand())
+		r.ButtonComponent("/{gameNightId}/rsvp/{response}", b.handleRSVPButtonCommand())
+		r.ButtonComponent("/{gameNightId}/start", b.handleStartDraftFromRSVPsButtonCommand())
+	})
+	r.Route("/mytiers", func(r handler.Router) {
+		r.SlashCommand("/", b.handleMyTiersSlashCommand())
+		r.ButtonComponent("/{ruleset}/{view}", b.handleMyTiersButtonCommand())
+	})
 	r.Route("/leaders", func(r handler.Router) {
 		// r.Use(middleware.Logger)
 		r.SlashCommand("/", b.handleManageLeadersSlashCommand())
 		r.ButtonComponent("/", b.handleManageLeadersButtonCommand())
 		r.ButtonComponent("/page/{page}", b.handleManageLeadersButtonCommand())
+		r.ButtonComponent("/browse/{filter}/{page}", b.handleLeaderBrowseButtonCommand())
+		r.SelectMenuComponent("/filter/{filter}/{field}", b.handleLeaderFilterMenuSelectCommand())
+		r.ButtonComponent("/civ/{filter}", b.handleLeaderCivFilterButtonCommand())
+		r.Modal("/civ/{filter}", b.handleLeaderCivFilterModal())
+		// routes match by prefix, so the guide routes have to come before /{leaderId}
+		r.ButtonComponent("/{leaderId}/docs/{docId}/vote", b.handleVoteGuideButtonCommand())
+		r.ButtonComponent("/{leaderId}/docs/{docId}/approve", b.handleApproveGuideButtonCommand())
+		r.ButtonComponent("/{leaderId}/docs/new", b.handleSubmitGuideButtonCommand())
+		r.Modal("/{leaderId}/docs/new", b.handleSubmitGuideModal())
+		r.ButtonComponent("/{leaderId}/docs", b.handleGuidesButtonCommand())
+		r.SelectMenuComponent("/{leaderId}/docs/remove", b.handleRemoveGuidesMenuSelectCommand())
 		r.ButtonComponent("/{leaderId}", b.handleLeaderDetailsButtonCommand())
 		r.SelectMenuComponent("/{leaderId}/rating", b.handleRateLeaderMenuSelectCommand())
+		r.SelectMenuComponent("/{leaderId}/rating/{ruleset}", b.handleRateLeaderMenuSelectCommand())
 	})
 
-	// r.SelectMenuComponent("/select-player", b.handlePlayerSelect())
 	//r.ButtonComponent("/game/latest", HandleViewLatestCompletedGame(b))
-
-	var err error
-	b.Client, err = disgo.New(b.discordToken,
-		bot.WithGatewayConfigOpts(
-			gateway.WithIntents(
-				gateway.IntentGuildMessages,
-				gateway.IntentMessageContent,
-				gateway.IntentGuilds,
-				gateway.IntentDirectMessages,
-			),
-			gateway.WithPresenceOpts(
-				gateway.WithPlayingActivity("loading..."),
-			),
-		),
-		bot.WithEventListenerFunc(b.onReady),
-		bot.WithEventListeners(r),
-	)
-	if err != nil {
-		return errors.Wrap(err, "failed to create discord client")
-	}
-	return nil
+	return r
 }
 
 func Start(b *Bot) error {
@@ -106,13 +173,27 @@ func Start(b *Bot) error {
 		return err
 	}
 	slog.Info("Successfully connected to Discord gateway")
+	b.Scheduler.Start()
 	return nil
 }
 
+// GracefulShutdown stops accepting interactions, waits up to ShutdownTimeout for the work in flight, then flushes the
+// databases and closes the Discord client and the databases.
 func GracefulShutdown(b *Bot) {
 	slog.Info("Shutting down Bot...")
-	b.Client.Close(context.Background())
-	b.Ci6ndex.Close()
+	b.shutdown(b.ShutdownTimeout, shutdownHooks{
+		closeGateway: func(ctx context.Context) {
+			if b.Client.HasGateway() {
+				b.Client.Gateway.Close(ctx)
+			}
+		},
+		flush: b.Ci6ndex.Flush,
+		close: func() {
+			b.Client.Close(context.Background())
+			b.Ci6ndex.Close()
+		},
+	})
+	slog.Info("Bot shut down")
 }
 
 func (b *Bot) onReady(_ *events.Ready) {
@@ -136,7 +217,7 @@ func (b *Bot) SyncCommands() error {
 
 	err := handler.SyncCommands(b.Client, Commands, guildIds)
 	if err != nil {
-		var restErr rest.Error
+		var restErr *rest.Error
 		if errors.As(err, &restErr) {
 			if err != nil {
 				slog.Error("failed to sync commands", "error", err)
@@ -160,11 +241,26 @@ func parseGuildId(guild string) (uint64, error) {
 	return id, nil
 }
 
+// isAdmin reports whether the member may moderate content, such as approving guides.
+func isAdmin(member *discord.ResolvedMember) bool {
+	return member != nil && member.Permissions.Has(discord.PermissionManageMessages)
+}
+
+// logError logs an error that can't be returned to logInteractions, e.g. in a scheduled job, along with the details
+// Discord sent back about it.
+func logError(msg string, err error) {
+	attrs := []any{"error", err}
+	if desc, ok := errorDescription(err); ok && desc != err.Error() {
+		attrs = append(attrs, "details", desc)
+	}
+	slog.Error(msg, attrs...)
+}
+
 func errorDescription(err error) (string, bool) {
 	if err == nil {
 		return "", false
 	}
-	var restErr rest.Error
+	var restErr *rest.Error
 	if errors.As(err, &restErr) {
 		if len(restErr.Errors) == 0 {
 			return string(restErr.RsBody), true
@@ -198,9 +294,10 @@ func FilterGuildMiddleware(guildID snowflake.ID) handler.Middleware {
 	}
 }
 
-// background is a convenience function to use a singular waitgroup for bot operations.
-// This is helpful when we want to gracefully shut down, as we can wg.wait() with a timeout and ensure
-// background tasks are attempted to be finished up
-func (b *Bot) background(fn func()) {
-	b.wg.Go(fn)
+// background runs fn in its own goroutine, tracked so GracefulShutdown waits for it to finish. Tasks started while the
+// bot is shutting down are dropped.
+func (b *Bot) background(name string, fn func()) {
+	if !b.tasks.Go(name, fn) {
+		slog.Info("DROP background task", "reason", "shutting down", "task", name)
+	}
 }
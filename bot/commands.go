@@ -7,6 +7,12 @@ var Commands = []discord.ApplicationCommandCreate{
 	checkLeaders,
 	startDraft,
 	getLeader,
+	tierList,
+	myTiers,
+	compare,
+	pendingGuides,
+	randomTeamsCommand,
+	scheduleCommand,
 }
 
 var startDraft = discord.SlashCommandCreate{
@@ -24,18 +30,95 @@ var getLeader = discord.SlashCommandCreate{
 	Description: "Get details about a specific leader",
 	Options: []discord.ApplicationCommandOption{
 		discord.ApplicationCommandOptionString{
-			Name:        "leader-name",
-			Description: "name of leader to search for",
+			Name:         "leader-name",
+			Description:  "name of leader to search for",
+			Required:     false,
+			Autocomplete: true,
+		},
+		discord.ApplicationCommandOptionString{
+			Name:         "civ-name",
+			Description:  "name of civ to search for",
+			Required:     false,
+			Autocomplete: true,
+		},
+		discord.ApplicationCommandOptionString{
+			Name:         "keyword",
+			Description:  "play style, ability or unique to search for, e.g. naval or Hoplite",
+			Required:     false,
+			Autocomplete: true,
+		},
+	},
+}
+
+// rulesetChoices picks whether a command uses the ranks submitted under BBG or under vanilla
+var rulesetChoices = []discord.ApplicationCommandOptionChoiceString{
+	{Name: "BBG", Value: "bbg"},
+	{Name: "Vanilla", Value: "vanilla"},
+}
+
+var tierList = discord.SlashCommandCreate{
+	Name:        "tierlist",
+	Description: "Share a tier list image of all leaders",
+	Options: []discord.ApplicationCommandOption{
+		discord.ApplicationCommandOptionString{
+			Name:        "ruleset",
+			Description: "which ruleset's ranks and leaders to use (default vanilla, unless you only ranked BBG)",
 			Required:    false,
+			Choices:     rulesetChoices,
 		},
 		discord.ApplicationCommandOptionString{
-			Name:        "civ-name",
-			Description: "name of civ to search for",
+			Name:        "ranks",
+			Description: "whose ranks to use (default community)",
 			Required:    false,
+			Choices: []discord.ApplicationCommandOptionChoiceString{
+				{Name: "Community", Value: "community"},
+				{Name: "Mine", Value: "mine"},
+			},
 		},
 	},
 }
 
+var myTiers = discord.SlashCommandCreate{
+	Name:        "mytiers",
+	Description: "View your personal tier list, unrated leaders and hot takes",
+	Options: []discord.ApplicationCommandOption{
+		discord.ApplicationCommandOptionString{
+			Name:        "ruleset",
+			Description: "which ruleset's ranks to show (default vanilla, unless you only ranked BBG)",
+			Required:    false,
+			Choices:     rulesetChoices,
+		},
+	},
+}
+
+var compare = discord.SlashCommandCreate{
+	Name:        "compare",
+	Description: "Compare how alike two players rate leaders",
+	Options: []discord.ApplicationCommandOption{
+		discord.ApplicationCommandOptionUser{
+			Name:        "player",
+			Description: "player to compare",
+			Required:    true,
+		},
+		discord.ApplicationCommandOptionUser{
+			Name:        "other",
+			Description: "player to compare against (default you)",
+			Required:    false,
+		},
+		discord.ApplicationCommandOptionString{
+			Name:        "ruleset",
+			Description: "which ruleset's ranks to compare (default vanilla)",
+			Required:    false,
+			Choices:     rulesetChoices,
+		},
+	},
+}
+
+var pendingGuides = discord.SlashCommandCreate{
+	Name:        "guides",
+	Description: "Review guides waiting for approval (admins only)",
+}
+
 var pingCommand = discord.SlashCommandCreate{
 	Name:        "ping",
 	Description: "Replies with pong",
@@ -0,0 +1,101 @@
+package bot
+
+import (
+	"bytes"
+	"ci6ndex/ci6ndex"
+	"errors"
+	"io"
+	"log/slog"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	md "github.com/nao1215/markdown"
+)
+
+// how many disagreements to list when comparing two players
+const compareDisagreementLimit = 5
+
+func (b *Bot) handleCompareSlashCommand() handler.SlashCommandHandler {
+	return func(data discord.SlashCommandInteractionData, e *handler.CommandEvent) error {
+		guildID, err := parseGuildId(e.GuildID().String())
+		if err != nil {
+			return err
+		}
+		playerA := data.User("player")
+		playerB, ok := data.OptUser("other")
+		if !ok {
+			playerB = e.User()
+		}
+		bbg := data.String("ruleset") == "bbg"
+		slog.Info("handleCompare", "playerA", playerA.ID, "playerB", playerB.ID, "bbg", bbg)
+
+		similarity, err := b.Ci6ndex.ComparePlayers(guildID, int64(playerA.ID), int64(playerB.ID), bbg)
+		if err != nil {
+			return errors.Join(err, errors.New("failed to compare players"))
+		}
+
+		var body bytes.Buffer
+		err = renderComparison(&body, similarity, bbg)
+		if err != nil {
+			return errors.Join(err, errors.New("failed to render comparison"))
+		}
+
+		err = e.CreateMessage(discord.NewMessageCreateV2().
+			WithComponents(
+				discord.NewContainer(
+					discord.NewTextDisplay(body.String()),
+				).WithAccentColor(colorSuccess),
+			).
+			WithAllowedMentions(&discord.AllowedMentions{}))
+		if err != nil {
+			return errors.Join(err, errors.New("failed to create comparison"))
+		}
+		return nil
+	}
+}
+
+// renderComparison explains a comparison of the players' BBG ranks when bbg is set, of their vanilla ranks otherwise.
+func renderComparison(output io.Writer, s ci6ndex.Similarity, bbg bool) error {
+	title := "Taste Comparison (Vanilla)"
+	if bbg {
+		title = "Taste Comparison (BBG)"
+	}
+	mdBuilder := md.NewMarkdown(output).H1(title).
+		PlainTextf("<@%d> vs <@%d>", s.PlayerA, s.PlayerB)
+
+	if !s.HasScore() {
+		mdBuilder.PlainTextf("Not enough leaders rated by both players to compare, only %d in common.", s.Common)
+		return mdBuilder.Build()
+	}
+	if s.Flat {
+		mdBuilder.PlainTextf("Not enough variation to compare, one of you rates all %d common leaders the same.",
+			s.Common)
+		return mdBuilder.Build()
+	}
+
+	mdBuilder.H2f("%.0f%% agreement", s.Agreement()).
+		PlainTextf("Spearman ρ = %.2f over %d commonly rated leaders.", s.Score, s.Common)
+
+	mdBuilder.H3("Biggest Disagreements")
+	shown := 0
+	for _, d := range s.Disagreements {
+		if d.Difference() == 0 || shown == compareDisagreementLimit {
+			break
+		}
+		tierA, err := ci6ndex.GetTierByValue(d.TierA)
+		if err != nil {
+			return err
+		}
+		tierB, err := ci6ndex.GetTierByValue(d.TierB)
+		if err != nil {
+			return err
+		}
+		mdBuilder.PlainTextf("- **%s**: <@%d> %s, <@%d> %s",
+			ci6ndex.LeaderName(d.Leader), s.PlayerA, tierA.Name(), s.PlayerB, tierB.Name())
+		shown++
+	}
+	if shown == 0 {
+		mdBuilder.PlainText("None, you rate every common leader the same!")
+	}
+	return mdBuilder.Build()
+}
@@ -2,7 +2,10 @@ package bot
 
 import (
 	"bytes"
+	"ci6ndex/ci6ndex"
+	"ci6ndex/ci6ndex/generated"
 	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 
@@ -39,6 +42,12 @@ func (b *Bot) draftScreen() ([]discord.LayoutComponent, error) {
 			discord.NewPrimaryButton("Leaders", "/leaders").WithEmoji(discord.ComponentEmoji{
 				Name: notebook,
 			}),
+			discord.NewSecondaryButton("Roll Settings", "/draft/settings").WithEmoji(discord.ComponentEmoji{
+				Name: gear,
+			}),
+			discord.NewSecondaryButton("Game Settings", "/draft/game-settings").WithEmoji(discord.ComponentEmoji{
+				Name: worldMap,
+			}),
 		),
 	).WithAccentColor(0x5c5fea),
 	}, nil
@@ -62,11 +71,7 @@ func (b *Bot) handleManageDraft() handler.SlashCommandHandler {
 			Flags:      flags,
 			Components: draft,
 		}); err != nil {
-			slog.Error("failed to create draft screen", "error", err)
-			desc, ok := errorDescription(err)
-			if ok {
-				slog.Error(desc)
-			}
+			return errors.Join(err, errors.New("failed to create draft screen"))
 		}
 
 		return nil
@@ -90,11 +95,7 @@ func (b *Bot) handleManageDraftButton() handler.ButtonComponentHandler {
 		if err := e.UpdateMessage(discord.MessageUpdate{
 			Components: &draft,
 		}); err != nil {
-			slog.Error("Failed to create test message", "error", err)
-			desc, ok := errorDescription(err)
-			if ok {
-				slog.Error(desc)
-			}
+			return errors.Join(err, errors.New("failed to create test message"))
 		}
 		return nil
 	}
@@ -122,21 +123,59 @@ func (b *Bot) handleCreateDraft() handler.ButtonComponentHandler {
 								Name: crossedSwords,
 							}),
 						),
+						discord.NewActionRow().WithComponents(
+							discord.NewSecondaryButton("Snake Draft",
+								"/snake/start/"+string(ci6ndex.TurnOrderRandom)),
+							discord.NewSecondaryButton("Snake Draft (by rating)",
+								"/snake/start/"+string(ci6ndex.TurnOrderByRating)),
+						),
+						discord.NewActionRow().WithComponents(
+							discord.NewStringSelectMenu("/blind/start", "Blind Pick...", blindConflictOptions()...),
+						),
 					).WithAccentColor(0x5c5fea),
 				},
 			},
 		)
 		if err != nil {
-			slog.Error("Failed to create test message", "error", err)
-			desc, ok := errorDescription(err)
-			if ok {
-				slog.Error(desc)
-			}
+			return errors.Join(err, errors.New("failed to create test message"))
 		}
 		return nil
 	}
 }
 
+// handlePlayerSelect registers the selected users as the players of the active draft.
+func (b *Bot) handlePlayerSelect() handler.SelectMenuComponentHandler {
+	return func(data discord.SelectMenuInteractionData, e *handler.ComponentEvent) error {
+		guildID, err := parseGuildId(e.GuildID().String())
+		if err != nil {
+			return err
+		}
+		userData, ok := data.(discord.UserSelectMenuInteractionData)
+		if !ok {
+			return fmt.Errorf("unexpected select menu data %T", data)
+		}
+
+		users := userData.Users()
+		players := make([]generated.AddPlayerParams, 0, len(users))
+		for _, user := range users {
+			id, err := b.addPlayer(guildID, user)
+			if err != nil {
+				return err
+			}
+			players = append(players, generated.AddPlayerParams{ID: id, Username: user.Username})
+		}
+
+		draft, err := b.Ci6ndex.GetOrCreateActiveDraft(guildID)
+		if err != nil {
+			return err
+		}
+		if errs := b.Ci6ndex.SetPlayersForDraft(guildID, draft.ID, players); len(errs) > 0 {
+			return errors.Join(errs...)
+		}
+		return e.DeferUpdateMessage()
+	}
+}
+
 func renderDraftMainScreen(header, previousGame io.Writer) error {
 	err := renderDraftHeader(header)
 	if err != nil {
@@ -0,0 +1,245 @@
+package bot
+
+import (
+	"bytes"
+	"ci6ndex/ci6ndex"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	md "github.com/nao1215/markdown"
+)
+
+func (b *Bot) handleGameSettingsButtonCommand() handler.ButtonComponentHandler {
+	return func(bid discord.ButtonInteractionData, e *handler.ComponentEvent) error {
+		guildID, err := parseGuildId(e.GuildID().String())
+		if err != nil {
+			return err
+		}
+		options, err := b.Ci6ndex.GetGameSettingOptions(guildID)
+		if err != nil {
+			return err
+		}
+		components, err := gameSettingsScreen(options)
+		if err != nil {
+			return err
+		}
+		return b.updateGameSettingsScreen(e, components)
+	}
+}
+
+// handleGameSettingsCategorySelectCommand opens the allowed and blocked lists of the selected category.
+func (b *Bot) handleGameSettingsCategorySelectCommand() handler.SelectMenuComponentHandler {
+	return func(data discord.SelectMenuInteractionData, e *handler.ComponentEvent) error {
+		guildID, err := parseGuildId(e.GuildID().String())
+		if err != nil {
+			return err
+		}
+		selectData, ok := data.(discord.StringSelectMenuInteractionData)
+		if !ok || len(selectData.Values) == 0 {
+			return fmt.Errorf("unexpected select menu data %T", data)
+		}
+		options, err := b.Ci6ndex.GetGameSettingOptions(guildID)
+		if err != nil {
+			return err
+		}
+		components, err := gameSettingCategoryScreen(ci6ndex.GameSettingCategory(selectData.Values[0]), options, "")
+		if err != nil {
+			return err
+		}
+		return b.updateGameSettingsScreen(e, components)
+	}
+}
+
+// handleGameSettingsListSelectCommand replaces the allowed or blocked list of a category with the selected values.
+func (b *Bot) handleGameSettingsListSelectCommand() handler.SelectMenuComponentHandler {
+	return func(data discord.SelectMenuInteractionData, e *handler.ComponentEvent) error {
+		guildID, err := parseGuildId(e.GuildID().String())
+		if err != nil {
+			return err
+		}
+		if !isAdmin(e.Member()) {
+			return e.CreateMessage(discord.NewMessageCreate().
+				WithEphemeral(true).
+				WithContent("Only admins can change the game settings."))
+		}
+		selectData, ok := data.(discord.StringSelectMenuInteractionData)
+		if !ok {
+			return fmt.Errorf("unexpected select menu data %T", data)
+		}
+
+		category := ci6ndex.GameSettingCategory(e.Vars["category"])
+		status := ci6ndex.GameSettingStatus(e.Vars["status"])
+		err = b.Ci6ndex.SetGameSettingStatuses(guildID, category, status, selectData.Values)
+		if err != nil {
+			return errors.Join(err, fmt.Errorf("failed to update %s list", status))
+		}
+
+		options, err := b.Ci6ndex.GetGameSettingOptions(guildID)
+		if err != nil {
+			return err
+		}
+		components, err := gameSettingCategoryScreen(category, options,
+			"Saved! The next draft will be rolled with these settings.")
+		if err != nil {
+			return err
+		}
+		return b.updateGameSettingsScreen(e, components)
+	}
+}
+
+func (b *Bot) updateGameSettingsScreen(e *handler.ComponentEvent, components []discord.LayoutComponent) error {
+	if err := e.UpdateMessage(discord.MessageUpdate{
+		Components: &components,
+	}); err != nil {
+		return errors.Join(err, errors.New("failed to update game settings screen"))
+	}
+	return nil
+}
+
+func gameSettingsScreen(options []ci6ndex.GameSettingOption) ([]discord.LayoutComponent, error) {
+	var header bytes.Buffer
+	if err := renderGameSettings(&header, options); err != nil {
+		return nil, errors.Join(err, errors.New("failed to render game settings"))
+	}
+
+	categoryOpts := make([]discord.StringSelectMenuOption, len(ci6ndex.GameSettingCategories))
+	for i, category := range ci6ndex.GameSettingCategories {
+		categoryOpts[i] = discord.NewStringSelectMenuOption(category.Name(), string(category))
+	}
+
+	return []discord.LayoutComponent{
+		discord.NewContainer(
+			discord.NewTextDisplay(header.String()),
+			discord.NewSmallSeparator(),
+			discord.NewActionRow(discord.NewStringSelectMenu("/draft/game-settings", "Edit a setting",
+				categoryOpts...)),
+			discord.NewLargeSeparator(),
+			discord.NewActionRow(
+				discord.NewPrimaryButton("Back", "/draft").WithEmoji(discord.ComponentEmoji{
+					Name: backArrow,
+				}),
+			),
+		).WithAccentColor(colorSuccess),
+	}, nil
+}
+
+func gameSettingCategoryScreen(
+	category ci6ndex.GameSettingCategory,
+	options []ci6ndex.GameSettingOption,
+	notice string,
+) ([]discord.LayoutComponent, error) {
+	options = slices.DeleteFunc(slices.Clone(options), func(o ci6ndex.GameSettingOption) bool {
+		return o.Category != category
+	})
+	if len(options) == 0 {
+		return nil, fmt.Errorf("unknown game setting %q", category)
+	}
+
+	var header bytes.Buffer
+	if err := renderGameSettingCategory(&header, category, options, notice); err != nil {
+		return nil, errors.Join(err, errors.New("failed to render game setting"))
+	}
+
+	list := func(status ci6ndex.GameSettingStatus, placeholder string) discord.StringSelectMenuComponent {
+		opts := make([]discord.StringSelectMenuOption, len(options))
+		for i, o := range options {
+			opts[i] = discord.NewStringSelectMenuOption(gameSettingValue(o.Category, o.Value), o.Value).
+				WithDefault(o.Status == status)
+		}
+		return discord.NewStringSelectMenu(fmt.Sprintf("/draft/game-settings/%s/%s", category, status),
+			placeholder, opts...).WithMinValues(0).WithMaxValues(len(opts))
+	}
+
+	return []discord.LayoutComponent{
+		discord.NewContainer(
+			discord.NewTextDisplay(header.String()),
+			discord.NewSmallSeparator(),
+			discord.NewActionRow(list(ci6ndex.GameSettingAllowed, "Allowed (only roll these)")),
+			discord.NewActionRow(list(ci6ndex.GameSettingBlocked, "Blocked (never roll these)")),
+			discord.NewLargeSeparator(),
+			discord.NewActionRow(
+				discord.NewPrimaryButton("Back", "/draft/game-settings").WithEmoji(discord.ComponentEmoji{
+					Name: backArrow,
+				}),
+			),
+		).WithAccentColor(colorSuccess),
+	}, nil
+}
+
+// gameSettingValue shows a value the way the game's lobby does.
+func gameSettingValue(category ci6ndex.GameSettingCategory, value string) string {
+	if category == ci6ndex.GameSettingDisasters {
+		return "Intensity " + value
+	}
+	return value
+}
+
+func renderGameSettings(buffer io.Writer, options []ci6ndex.GameSettingOption) error {
+	mdBuilder := md.NewMarkdown(buffer).H2f("%s Game Settings", worldMap).
+		PlainText("Every draft rolls one value of each setting, more likely the higher its weight. " +
+			"Allowing values limits the setting to them, blocked values are never rolled. Only admins can change them.")
+
+	for _, category := range ci6ndex.GameSettingCategories {
+		var allowed, blocked []string
+		for _, o := range options {
+			if o.Category != category {
+				continue
+			}
+			switch o.Status {
+			case ci6ndex.GameSettingAllowed:
+				allowed = append(allowed, gameSettingValue(o.Category, o.Value))
+			case ci6ndex.GameSettingBlocked:
+				blocked = append(blocked, gameSettingValue(o.Category, o.Value))
+			}
+		}
+		switch {
+		case len(allowed) > 0:
+			mdBuilder.PlainTextf("- **%s**: only %s", category.Name(), strings.Join(allowed, ", "))
+		case len(blocked) > 0:
+			mdBuilder.PlainTextf("- **%s**: anything but %s", category.Name(), strings.Join(blocked, ", "))
+		default:
+			mdBuilder.PlainTextf("- **%s**: anything", category.Name())
+		}
+	}
+	return mdBuilder.Build()
+}
+
+func renderGameSettingCategory(
+	buffer io.Writer,
+	category ci6ndex.GameSettingCategory,
+	options []ci6ndex.GameSettingOption,
+	notice string,
+) error {
+	mdBuilder := md.NewMarkdown(buffer).H2f("%s %s", worldMap, category.Name())
+	for _, o := range options {
+		line := fmt.Sprintf("- %s (weight %d)", gameSettingValue(o.Category, o.Value), o.Weight)
+		switch o.Status {
+		case ci6ndex.GameSettingAllowed:
+			line += " **allowed**"
+		case ci6ndex.GameSettingBlocked:
+			line = fmt.Sprintf("- ~~%s~~ (blocked)", gameSettingValue(o.Category, o.Value))
+		}
+		mdBuilder.PlainText(line)
+	}
+	if notice != "" {
+		mdBuilder.PlainTextf("\n*%s*", notice)
+	}
+	return mdBuilder.Build()
+}
+
+// gameSettingsRows lists the game settings rolled for a draft, shown below the offerings.
+func gameSettingsRows(settings []ci6ndex.GameSetting) []discord.ContainerSubComponent {
+	lines := make([]string, len(settings))
+	for i, s := range settings {
+		lines[i] = fmt.Sprintf("- **%s**: %s", s.Category.Name(), gameSettingValue(s.Category, s.Value))
+	}
+	return []discord.ContainerSubComponent{
+		discord.NewSmallSeparator(),
+		discord.NewTextDisplayf("### %s Game Settings\n%s", worldMap, strings.Join(lines, "\n")),
+	}
+}
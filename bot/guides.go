@@ -0,0 +1,417 @@
+package bot
+
+import (
+	"bytes"
+	"ci6ndex/ci6ndex"
+	"ci6ndex/ci6ndex/generated"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	md "github.com/nao1215/markdown"
+)
+
+const (
+	// each guide takes 3 components and its text, keep well under both of discord's limits
+	maxGuidesShown = 6
+	// how much of a note is shown in the guide list
+	maxNotePreviewLength = 300
+	// how many pending guides /guides lists
+	maxPendingGuidesShown = 10
+
+	guideNameInput = "name"
+	guideLinkInput = "link"
+	guideNoteInput = "note"
+)
+
+func (b *Bot) handleGuidesButtonCommand() handler.ButtonComponentHandler {
+	return func(bid discord.ButtonInteractionData, e *handler.ComponentEvent) error {
+		guildID, err := parseGuildId(e.GuildID().String())
+		if err != nil {
+			return err
+		}
+		leader, err := b.leaderFromVars(guildID, e.Vars)
+		if err != nil {
+			return err
+		}
+		return b.updateGuidesScreen(e, guildID, leader, isAdmin(e.Member()), "")
+	}
+}
+
+func (b *Bot) handleSubmitGuideButtonCommand() handler.ButtonComponentHandler {
+	return func(bid discord.ButtonInteractionData, e *handler.ComponentEvent) error {
+		return e.Modal(discord.ModalCreate{
+			CustomID: bid.CustomID(),
+			Title:    "Submit a guide",
+			Components: []discord.LayoutComponent{
+				discord.NewLabel("Name", discord.TextInputComponent{
+					CustomID:    guideNameInput,
+					Style:       discord.TextInputStyleShort,
+					Required:    true,
+					MaxLength:   ci6ndex.MaxDocumentNameLength,
+					Placeholder: "e.g. Domination opener",
+				}),
+				discord.NewLabel("Link", discord.TextInputComponent{
+					CustomID:    guideLinkInput,
+					Style:       discord.TextInputStyleShort,
+					MaxLength:   512,
+					Placeholder: "https://... leave empty to write a note instead",
+				}),
+				discord.NewLabel("Note", discord.TextInputComponent{
+					CustomID:    guideNoteInput,
+					Style:       discord.TextInputStyleParagraph,
+					MaxLength:   ci6ndex.MaxDocumentNoteLength,
+					Placeholder: "markdown is supported, leave empty when submitting a link",
+				}),
+			},
+		})
+	}
+}
+
+func (b *Bot) handleSubmitGuideModal() handler.ModalHandler {
+	return func(e *handler.ModalEvent) error {
+		guildID, err := parseGuildId(e.GuildID().String())
+		if err != nil {
+			return err
+		}
+		leader, err := b.leaderFromVars(guildID, e.Vars)
+		if err != nil {
+			return err
+		}
+		playerID, err := b.addPlayer(guildID, e.User())
+		if err != nil {
+			return err
+		}
+
+		admin := isAdmin(e.Member())
+		_, err = b.Ci6ndex.SubmitDocument(guildID, ci6ndex.DocumentSubmission{
+			LeaderID: leader.ID,
+			PlayerID: playerID,
+			Name:     e.Data.Text(guideNameInput),
+			Link:     e.Data.Text(guideLinkInput),
+			Note:     e.Data.Text(guideNoteInput),
+			Approved: admin,
+		})
+		var validationErr ci6ndex.DocumentValidationError
+		if errors.As(err, &validationErr) {
+			return e.CreateMessage(discord.NewMessageCreate().
+				WithEphemeral(true).
+				WithContent(fmt.Sprintf("Your guide wasn't submitted: %s.", validationErr.Reason)))
+		}
+		if err != nil {
+			return err
+		}
+
+		notice := "Thanks! Your guide will show up once an admin approves it."
+		if admin {
+			notice = "Your guide was added."
+		}
+		components, err := b.guidesScreen(guildID, leader, admin, notice)
+		if err != nil {
+			return err
+		}
+		if err := e.UpdateMessage(discord.MessageUpdate{
+			Components: &components,
+		}); err != nil {
+			return errors.Join(err, errors.New("failed to update guides screen"))
+		}
+		return nil
+	}
+}
+
+func (b *Bot) handleVoteGuideButtonCommand() handler.ButtonComponentHandler {
+	return func(bid discord.ButtonInteractionData, e *handler.ComponentEvent) error {
+		guildID, err := parseGuildId(e.GuildID().String())
+		if err != nil {
+			return err
+		}
+		leader, err := b.leaderFromVars(guildID, e.Vars)
+		if err != nil {
+			return err
+		}
+		docID, err := strconv.ParseInt(e.Vars["docId"], 10, 64)
+		if err != nil {
+			return err
+		}
+		playerID, err := b.addPlayer(guildID, e.User())
+		if err != nil {
+			return err
+		}
+
+		voted, err := b.Ci6ndex.ToggleDocumentVote(guildID, docID, playerID)
+		if err != nil {
+			return err
+		}
+		notice := "Vote removed."
+		if voted {
+			notice = "Thanks for voting!"
+		}
+		return b.updateGuidesScreen(e, guildID, leader, isAdmin(e.Member()), notice)
+	}
+}
+
+func (b *Bot) handleApproveGuideButtonCommand() handler.ButtonComponentHandler {
+	return func(bid discord.ButtonInteractionData, e *handler.ComponentEvent) error {
+		guildID, err := parseGuildId(e.GuildID().String())
+		if err != nil {
+			return err
+		}
+		if !isAdmin(e.Member()) {
+			return e.CreateMessage(discord.NewMessageCreate().
+				WithEphemeral(true).
+				WithContent("Only admins can approve guides."))
+		}
+		leader, err := b.leaderFromVars(guildID, e.Vars)
+		if err != nil {
+			return err
+		}
+		docID, err := strconv.ParseInt(e.Vars["docId"], 10, 64)
+		if err != nil {
+			return err
+		}
+
+		if err := b.Ci6ndex.ApproveDocument(guildID, docID); err != nil {
+			return err
+		}
+		return b.updateGuidesScreen(e, guildID, leader, true, "Guide approved.")
+	}
+}
+
+func (b *Bot) handleRemoveGuidesMenuSelectCommand() handler.SelectMenuComponentHandler {
+	return func(data discord.SelectMenuInteractionData, e *handler.ComponentEvent) error {
+		guildID, err := parseGuildId(e.GuildID().String())
+		if err != nil {
+			return err
+		}
+		if !isAdmin(e.Member()) {
+			return e.CreateMessage(discord.NewMessageCreate().
+				WithEphemeral(true).
+				WithContent("Only admins can remove guides."))
+		}
+		leader, err := b.leaderFromVars(guildID, e.Vars)
+		if err != nil {
+			return err
+		}
+		selectData, ok := data.(discord.StringSelectMenuInteractionData)
+		if !ok {
+			return fmt.Errorf("unexpected select menu type %T", data)
+		}
+
+		for _, v := range selectData.Values {
+			docID, err := strconv.ParseInt(v, 10, 64)
+			if err != nil {
+				return err
+			}
+			if err := b.Ci6ndex.RemoveDocument(guildID, docID); err != nil {
+				return err
+			}
+		}
+		notice := fmt.Sprintf("Removed %d guide(s).", len(selectData.Values))
+		return b.updateGuidesScreen(e, guildID, leader, true, notice)
+	}
+}
+
+func (b *Bot) handlePendingGuidesSlashCommand() handler.SlashCommandHandler {
+	return func(data discord.SlashCommandInteractionData, e *handler.CommandEvent) error {
+		guildID, err := parseGuildId(e.GuildID().String())
+		if err != nil {
+			return err
+		}
+		if !isAdmin(e.Member()) {
+			return e.CreateMessage(discord.NewMessageCreate().
+				WithEphemeral(true).
+				WithContent("Only admins can review guides."))
+		}
+
+		pending, err := b.Ci6ndex.GetPendingDocuments(guildID)
+		if err != nil {
+			return err
+		}
+		leaders, err := b.Leaders(guildID)
+		if err != nil {
+			return err
+		}
+		leadersByID := make(map[int64]generated.Leader, len(leaders))
+		for _, l := range leaders {
+			leadersByID[l.ID] = l
+		}
+
+		var header bytes.Buffer
+		err = md.NewMarkdown(&header).H1("Guides Awaiting Review").
+			PlainTextf("%d guide(s) are waiting for approval.", len(pending)).
+			Build()
+		if err != nil {
+			return errors.Join(err, errors.New("failed to render pending guides header"))
+		}
+
+		rows := make([]discord.ContainerSubComponent, 0, maxPendingGuidesShown)
+		for _, d := range pending {
+			if len(rows) == maxPendingGuidesShown {
+				break
+			}
+			leader, ok := leadersByID[d.LeaderID]
+			if !ok {
+				continue
+			}
+			text := fmt.Sprintf("**%s** for %s %s", d.DocName, leader.DiscordEmojiString.String,
+				ci6ndex.LeaderName(leader))
+			if d.SubmittedBy.Valid {
+				text += fmt.Sprintf(" by <@%d>", d.SubmittedBy.Int64)
+			}
+			rows = append(rows, discord.NewSection(discord.NewTextDisplay(text)).
+				WithAccessory(discord.NewSecondaryButton("Review", fmt.Sprintf("/leaders/%d/docs", leader.ID))))
+		}
+
+		flags := discord.MessageFlagIsComponentsV2
+		flags = flags.Add(discord.MessageFlagEphemeral)
+		if err := e.CreateMessage(discord.MessageCreate{
+			Flags: flags,
+			Components: []discord.LayoutComponent{
+				discord.NewContainer(discord.NewTextDisplay(header.String())).
+					AddComponents(rows...).
+					WithAccentColor(colorSuccess),
+			},
+		}); err != nil {
+			return errors.Join(err, errors.New("failed to create pending guides screen"))
+		}
+		return nil
+	}
+}
+
+func (b *Bot) leaderFromVars(guildID uint64, vars map[string]string) (generated.Leader, error) {
+	leaderID, err := strconv.ParseUint(vars["leaderId"], 10, 64)
+	if err != nil {
+		return generated.Leader{}, errors.Join(err, fmt.Errorf("invalid leader ID %q", vars["leaderId"]))
+	}
+	return b.Ci6ndex.GetLeaderById(guildID, leaderID)
+}
+
+func (b *Bot) updateGuidesScreen(e *handler.ComponentEvent, guildID uint64, leader generated.Leader, admin bool,
+	notice string) error {
+	components, err := b.guidesScreen(guildID, leader, admin, notice)
+	if err != nil {
+		return err
+	}
+	if err := e.UpdateMessage(discord.MessageUpdate{
+		Components: &components,
+	}); err != nil {
+		return errors.Join(err, errors.New("failed to update guides screen"))
+	}
+	return nil
+}
+
+// guidesScreen lists the leader's guides with a vote button each. Admins also see pending guides, which they can
+// approve, and a menu to remove guides.
+func (b *Bot) guidesScreen(guildID uint64, leader generated.Leader, admin bool,
+	notice string) ([]discord.LayoutComponent, error) {
+	docs, err := b.Ci6ndex.GetDocumentsForLeader(guildID, leader.ID)
+	if err != nil {
+		return nil, errors.Join(err, errors.New("failed to fetch guides"))
+	}
+
+	visible := make([]ci6ndex.LeaderDocument, 0, maxGuidesShown)
+	pending := 0
+	for _, d := range docs {
+		if !d.Document.Approved {
+			pending++
+			if !admin {
+				continue
+			}
+		}
+		if len(visible) < maxGuidesShown {
+			visible = append(visible, d)
+		}
+	}
+
+	var header bytes.Buffer
+	err = renderGuidesHeader(&header, leader, len(docs)-pending, pending, admin, notice)
+	if err != nil {
+		return nil, errors.Join(err, errors.New("failed to render guides header"))
+	}
+
+	rows := make([]discord.ContainerSubComponent, 0, len(visible))
+	removeOpts := make([]discord.StringSelectMenuOption, 0, len(visible))
+	for _, d := range visible {
+		var text bytes.Buffer
+		if err := renderGuide(&text, d); err != nil {
+			return nil, errors.Join(err, fmt.Errorf("failed to render guide %d", d.Document.ID))
+		}
+
+		var accessory discord.SectionAccessoryComponent
+		if d.Document.Approved {
+			accessory = discord.NewSecondaryButton(fmt.Sprintf("%d", d.Votes),
+				fmt.Sprintf("/leaders/%d/docs/%d/vote", leader.ID, d.Document.ID)).
+				WithEmoji(discord.ComponentEmoji{Name: "👍"})
+		} else {
+			accessory = discord.NewSuccessButton("Approve",
+				fmt.Sprintf("/leaders/%d/docs/%d/approve", leader.ID, d.Document.ID))
+		}
+		rows = append(rows, discord.NewSection(discord.NewTextDisplay(text.String())).WithAccessory(accessory))
+		removeOpts = append(removeOpts, discord.NewStringSelectMenuOption(d.Document.DocName,
+			strconv.FormatInt(d.Document.ID, 10)))
+	}
+
+	container := discord.NewContainer(discord.NewTextDisplay(header.String()), discord.NewLargeSeparator()).
+		AddComponents(rows...)
+	if admin && len(removeOpts) > 0 {
+		container = container.AddComponents(
+			discord.NewLargeSeparator(),
+			discord.NewActionRow(
+				discord.NewStringSelectMenu(fmt.Sprintf("/leaders/%d/docs/remove", leader.ID), "Remove guides...",
+					removeOpts...).WithMaxValues(len(removeOpts)),
+			),
+		)
+	}
+	container = container.AddComponents(
+		discord.NewLargeSeparator(),
+		discord.NewActionRow(
+			discord.NewPrimaryButton("Back", fmt.Sprintf("/leaders/%d", leader.ID)),
+			discord.NewSecondaryButton("Submit a Guide", fmt.Sprintf("/leaders/%d/docs/new", leader.ID)),
+		),
+	).WithAccentColor(colorSuccess)
+
+	return []discord.LayoutComponent{container}, nil
+}
+
+func renderGuidesHeader(output io.Writer, leader generated.Leader, approved, pending int, admin bool,
+	notice string) error {
+	mdBuilder := md.NewMarkdown(output).
+		H1(fmt.Sprintf("%s Guides for %s", leader.DiscordEmojiString.String, ci6ndex.LeaderName(leader)))
+	if notice != "" {
+		mdBuilder.PlainText("**" + notice + "**")
+	}
+	switch {
+	case approved == 0:
+		mdBuilder.PlainText("No guides yet. Be the first to submit one!")
+	case approved > maxGuidesShown:
+		mdBuilder.PlainTextf("Showing the %d most voted of %d guides. Vote for the ones you found useful.",
+			maxGuidesShown, approved)
+	default:
+		mdBuilder.PlainText("Vote for the guides you found useful.")
+	}
+	if admin && pending > 0 {
+		mdBuilder.PlainTextf("⏳ %d guide(s) awaiting approval.", pending)
+	}
+	return mdBuilder.Build()
+}
+
+func renderGuide(output io.Writer, d ci6ndex.LeaderDocument) error {
+	mdBuilder := md.NewMarkdown(output)
+	title := "**" + d.Document.DocName + "**"
+	if !d.Document.Approved {
+		title = "⏳ " + title + " (pending)"
+	}
+	if d.IsNote() {
+		mdBuilder.PlainText(title)
+		mdBuilder.PlainText(truncateText(d.Document.Note.String, maxNotePreviewLength))
+	} else {
+		mdBuilder.PlainText(title + " — " + d.Document.Link)
+	}
+	if d.Document.SubmittedBy.Valid {
+		mdBuilder.PlainTextf("-# submitted by <@%d>", d.Document.SubmittedBy.Int64)
+	}
+	return mdBuilder.Build()
+}
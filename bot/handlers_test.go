@@ -0,0 +1,214 @@
+package bot
+
+import (
+	"ci6ndex/ci6ndex"
+	"ci6ndex/ci6ndex/generated"
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/disgoorg/disgo/discord"
+)
+
+// registerPlayers registers n players for the active draft, like picking them in the draft's user select menu.
+func registerPlayers(t *testing.T, h *harness, n int) {
+	t.Helper()
+	draft, err := h.bot.Ci6ndex.GetOrCreateActiveDraft(h.guildID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	players := make([]generated.AddPlayerParams, n)
+	for i := range players {
+		players[i] = generated.AddPlayerParams{ID: int64(2000 + i), Username: fmt.Sprintf("player%d", i)}
+		if err := h.bot.Ci6ndex.AddPlayer(context.Background(), h.guildID(), players[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if errs := h.bot.Ci6ndex.SetPlayersForDraft(h.guildID(), draft.ID, players); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+}
+
+func TestDraftScreen(t *testing.T) {
+	h := newHarness(t)
+
+	res := h.SlashCommand(host, "draft", nil)
+	res.AssertNoError()
+	if res.ResponseType() != discord.InteractionResponseTypeCreateMessage || !res.Ephemeral() {
+		t.Errorf("expected an ephemeral message, got type %d", res.ResponseType())
+	}
+	res.AssertCustomIDs("/create-draft", "/leaders", "/draft/settings", "/draft/game-settings")
+
+	res = h.Button(host, "/create-draft")
+	res.AssertNoError()
+	res.AssertCustomIDs("/select-player", "/confirm-roll-draft")
+}
+
+func TestSearchLeader(t *testing.T) {
+	h := newHarness(t)
+
+	res := h.SlashCommand(player, "leader", map[string]any{"leader-name": "gandhi"})
+	res.AssertNoError()
+	res.AssertContains("Gandhi")
+
+	res = h.SlashCommand(player, "leader", map[string]any{"leader-name": "nobody like this"})
+	res.AssertContains("No leaders found")
+
+	res = h.SlashCommand(player, "leader", nil)
+	res.AssertContains("Invalid search")
+}
+
+func TestConfirmRollDraft(t *testing.T) {
+	h := newHarness(t)
+	registerPlayers(t, h, 4)
+
+	res := h.Button(host, "/confirm-roll-draft")
+	res.AssertNoError()
+	if res.ResponseType() != discord.InteractionResponseTypeDeferredCreateMessage {
+		t.Errorf("expected the roll to be deferred, got type %d", res.ResponseType())
+	}
+	res.AssertContains("<@2000>", "<@2003>", "Game Settings")
+
+	draft, err := h.bot.Ci6ndex.GetOrCreateActiveDraft(h.guildID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	offers, err := h.bot.Ci6ndex.Connections[h.guildID()].Queries.GetOffersByDraftId(context.Background(), draft.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	offered := make(map[int64]bool)
+	for _, o := range offers {
+		offered[o.PlayerID] = true
+	}
+	if len(offered) != 4 {
+		t.Errorf("expected every player's offering to be saved, got %+v", offers)
+	}
+}
+
+func TestConfirmRollDraft_NotEnoughLeaders(t *testing.T) {
+	h := newHarness(t)
+	// every player is offered leaders no one else is, there aren't enough for this many
+	registerPlayers(t, h, 40)
+	if err := h.bot.Ci6ndex.SetDraftMode(h.guildID(), ci6ndex.DraftModeSnake); err != nil {
+		t.Fatal(err)
+	}
+
+	res := h.Button(host, "/confirm-roll-draft")
+	res.AssertContains("Something went wrong", "enough leaders")
+
+	// a failed roll leaves the active draft as it was
+	draft, err := h.bot.Ci6ndex.GetOrCreateActiveDraft(h.guildID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ci6ndex.DraftMode(draft.Mode) != ci6ndex.DraftModeSnake {
+		t.Errorf("expected the draft to stay a snake draft, got %q", draft.Mode)
+	}
+	stored, err := h.bot.Ci6ndex.Connections[h.guildID()].Queries.GetDraftGameSettings(context.Background(), draft.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stored) != 0 {
+		t.Errorf("expected no game settings to be stored, got %+v", stored)
+	}
+}
+
+func TestConfirmRollDraft_EverySettingBlocked(t *testing.T) {
+	h := newHarness(t)
+	registerPlayers(t, h, 4)
+	options, err := h.bot.Ci6ndex.GetGameSettingOptions(h.guildID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var mapTypes []string
+	for _, o := range options {
+		if o.Category == ci6ndex.GameSettingMapType {
+			mapTypes = append(mapTypes, o.Value)
+		}
+	}
+	err = h.bot.Ci6ndex.SetGameSettingStatuses(h.guildID(), ci6ndex.GameSettingMapType, ci6ndex.GameSettingBlocked,
+		mapTypes)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res := h.Button(host, "/confirm-roll-draft")
+	res.AssertContains("Something went wrong", "Every Map Type is blocked")
+}
+
+func TestGameSettings_AdminOnly(t *testing.T) {
+	h := newHarness(t)
+
+	res := h.Button(player, "/draft/game-settings")
+	res.AssertNoError()
+	res.AssertContains("Game Settings")
+
+	res = h.SelectMenu(player, "/draft/game-settings/map_type/blocked", "Pangaea")
+	res.AssertContains("Only admins")
+	if !res.Ephemeral() {
+		t.Error("expected the refusal to be ephemeral")
+	}
+
+	res = h.SelectMenu(host, "/draft/game-settings/map_type/blocked", "Pangaea")
+	res.AssertNoError()
+	res.AssertContains("Saved!", "~~Pangaea~~")
+}
+
+func TestRollSettings_TeamTag(t *testing.T) {
+	h := newHarness(t)
+
+	res := h.SelectMenu(host, "/draft/settings/team-tag", "naval")
+	res.AssertNoError()
+	res.AssertContains("Saved!", "every team offered a naval leader")
+	settings, err := h.bot.Ci6ndex.GetRollSettings(h.guildID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.TeamTag != "naval" {
+		t.Errorf("expected the naval team tag to be saved, got %q", settings.TeamTag)
+	}
+
+	res = h.SelectMenu(host, "/draft/settings/team-tag", "none")
+	res.AssertContains("Saved!", "**Team guarantee**: none")
+}
+
+func TestRollSettings_PlayerTag(t *testing.T) {
+	h := newHarness(t)
+
+	res := h.SelectMenu(host, "/draft/settings/player-tag", "science")
+	res.AssertNoError()
+	res.AssertContains("Saved!", "at least one science leader")
+	settings, err := h.bot.Ci6ndex.GetRollSettings(h.guildID())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if settings.PlayerTag != "science" {
+		t.Errorf("expected the science player tag to be saved, got %q", settings.PlayerTag)
+	}
+
+	res = h.SelectMenu(host, "/draft/settings/player-tag", "none")
+	res.AssertContains("Saved!", "**Play-style guarantee**: none")
+}
+
+func TestMyTiers_Ruleset(t *testing.T) {
+	h := newHarness(t)
+	err := h.bot.Ci6ndex.SubmitRankForPlayer(h.guildID(), "S", int64(player.ID), 2, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a player who only ranked under BBG sees their BBG ranks by default
+	res := h.SlashCommand(player, "mytiers", nil)
+	res.AssertNoError()
+	res.AssertContains("Your Tiers (BBG)", "You have rated 1 of")
+	res.AssertCustomIDs("/mytiers/bbg/unrated", "/mytiers/bbg/hot-takes", "/mytiers/vanilla/tiers")
+
+	res = h.Button(player, "/mytiers/vanilla/tiers")
+	res.AssertNoError()
+	res.AssertContains("Your Tiers (Vanilla)", "You have rated 0 of")
+	res.AssertCustomIDs("/mytiers/vanilla/unrated", "/mytiers/bbg/tiers")
+
+	res = h.SlashCommand(player, "mytiers", map[string]any{"ruleset": "vanilla"})
+	res.AssertContains("Your Tiers (Vanilla)")
+}
@@ -0,0 +1,354 @@
+package bot
+
+import (
+	"ci6ndex/ci6ndex"
+	"context"
+	"fmt"
+	"maps"
+	"net/http"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+
+	"github.com/disgoorg/disgo"
+	"github.com/disgoorg/disgo/bot"
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/disgo/rest"
+	json "github.com/disgoorg/json/v2"
+	snowflake "github.com/disgoorg/snowflake/v2"
+	goose "github.com/pressly/goose/v3"
+)
+
+const (
+	harnessGuildID   snowflake.ID = 999999
+	harnessChannelID snowflake.ID = 4
+	// harnessToken is a made up token, the application id disgo reads from it is 123
+	harnessToken = "MTIz.harness.token"
+)
+
+var (
+	host   = harnessUser{ID: 1000, Name: "host", Admin: true}
+	player = harnessUser{ID: 1001, Name: "player"}
+)
+
+// harnessUser is who an interaction comes from.
+type harnessUser struct {
+	ID    snowflake.ID
+	Name  string
+	Admin bool
+}
+
+// harness runs interactions through the router Configure uses, with a database in a temp dir and a fake REST client
+// in place of Discord.
+type harness struct {
+	t      *testing.T
+	bot    *Bot
+	router *handler.Mux
+	rest   *fakeRest
+	nextID atomic.Uint64
+}
+
+func newHarness(t *testing.T) *harness {
+	t.Helper()
+	goose.SetBaseFS(os.DirFS(".."))
+	goose.SetLogger(goose.NopLogger())
+	if err := goose.SetDialect("sqlite3"); err != nil {
+		t.Fatal(err)
+	}
+	c := &ci6ndex.Ci6ndex{
+		Connections: make(map[uint64]*ci6ndex.DB),
+		Path:        t.TempDir() + "/",
+	}
+	t.Cleanup(c.Close)
+
+	fake := &fakeRest{}
+	client, err := disgo.New(harnessToken, bot.WithRestClient(fake))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b := New(c, harnessToken, harnessGuildID.String(), harnessGuildID.String())
+	b.Client = client
+	h := &harness{t: t, bot: b, router: b.Router(), rest: fake}
+	h.nextID.Store(100)
+	return h
+}
+
+// guildID is the guild the harness's interactions come from, as the domain layer knows it.
+func (h *harness) guildID() uint64 {
+	return uint64(harnessGuildID)
+}
+
+// SlashCommand runs /name with the given options, the values decide the option types.
+func (h *harness) SlashCommand(user harnessUser, name string, options map[string]any) *result {
+	h.t.Helper()
+	opts := make([]map[string]any, 0, len(options))
+	for _, optName := range slices.Sorted(maps.Keys(options)) {
+		opts = append(opts, map[string]any{"name": optName, "type": optionType(h.t, options[optName]),
+			"value": options[optName]})
+	}
+	return h.interact(user, discord.InteractionTypeApplicationCommand, map[string]any{
+		"id":      "50",
+		"name":    name,
+		"type":    discord.ApplicationCommandTypeSlash,
+		"options": opts,
+	})
+}
+
+// Button clicks the button with the given custom ID.
+func (h *harness) Button(user harnessUser, customID string) *result {
+	h.t.Helper()
+	return h.interact(user, discord.InteractionTypeComponent, map[string]any{
+		"custom_id":      customID,
+		"component_type": discord.ComponentTypeButton,
+	})
+}
+
+// SelectMenu picks the values of the string select menu with the given custom ID.
+func (h *harness) SelectMenu(user harnessUser, customID string, values ...string) *result {
+	h.t.Helper()
+	return h.interact(user, discord.InteractionTypeComponent, map[string]any{
+		"custom_id":      customID,
+		"component_type": discord.ComponentTypeStringSelectMenu,
+		"values":         values,
+	})
+}
+
+func (h *harness) interact(user harnessUser, kind discord.InteractionType, data map[string]any) *result {
+	h.t.Helper()
+	permissions := "0"
+	if user.Admin {
+		permissions = strconv.FormatInt(int64(discord.PermissionManageMessages), 10)
+	}
+	payload, err := json.Marshal(map[string]any{
+		"id":             fmt.Sprint(h.nextID.Add(1)),
+		"application_id": "123",
+		"type":           kind,
+		"token":          "interaction-token",
+		"version":        1,
+		"guild_id":       harnessGuildID.String(),
+		"channel_id":     harnessChannelID.String(),
+		"channel":        map[string]any{"id": harnessChannelID.String(), "type": discord.ChannelTypeGuildText},
+		"member": map[string]any{
+			"user":        map[string]any{"id": user.ID.String(), "username": user.Name},
+			"roles":       []string{},
+			"joined_at":   "2024-01-01T00:00:00Z",
+			"permissions": permissions,
+		},
+		"data": data,
+	})
+	if err != nil {
+		h.t.Fatal(err)
+	}
+	interaction, err := discord.UnmarshalInteraction(payload)
+	if err != nil {
+		h.t.Fatalf("failed to build interaction: %v", err)
+	}
+
+	start := h.rest.len()
+	h.router.OnEvent(&events.InteractionCreate{
+		GenericEvent: events.NewGenericEvent(h.bot.Client, 0, 0),
+		Interaction:  interaction,
+		Respond: func(t discord.InteractionResponseType, data discord.InteractionResponseData,
+			opts ...rest.RequestOpt) error {
+			return h.bot.Client.Rest.CreateInteractionResponse(interaction.ID(), interaction.Token(),
+				discord.InteractionResponse{Type: t, Data: data}, opts...)
+		},
+	})
+	return &result{t: h.t, requests: h.rest.since(start)}
+}
+
+func optionType(t *testing.T, v any) discord.ApplicationCommandOptionType {
+	switch v.(type) {
+	case string:
+		return discord.ApplicationCommandOptionTypeString
+	case int, int64:
+		return discord.ApplicationCommandOptionTypeInt
+	case bool:
+		return discord.ApplicationCommandOptionTypeBool
+	case float64:
+		return discord.ApplicationCommandOptionTypeFloat
+	}
+	t.Fatalf("unsupported option value %T", v)
+	return 0
+}
+
+// restRequest is a request the bot sent to Discord, with the body as the JSON Discord would have received.
+type restRequest struct {
+	Method string
+	Route  string
+	Body   map[string]any
+}
+
+// fakeRest records the requests of a rest.Client instead of sending them. Requests expecting a message back get an
+// empty message in the harness channel.
+type fakeRest struct {
+	mu       sync.Mutex
+	requests []restRequest
+}
+
+func (f *fakeRest) HTTPClient() *http.Client      { return http.DefaultClient }
+func (f *fakeRest) RateLimiter() rest.RateLimiter { return nil }
+func (f *fakeRest) Close(ctx context.Context)     {}
+
+func (f *fakeRest) Do(endpoint *rest.CompiledEndpoint, rqBody any, rsBody any, opts ...rest.RequestOpt) error {
+	req := restRequest{Method: endpoint.Endpoint.Method, Route: endpoint.Endpoint.Route}
+	if rqBody != nil {
+		if multipart, ok := rqBody.(*discord.MultipartBuffer); ok {
+			req.Body = map[string]any{"content_type": multipart.ContentType}
+		} else {
+			data, err := json.Marshal(rqBody)
+			if err != nil {
+				return err
+			}
+			if err := json.Unmarshal(data, &req.Body); err != nil {
+				return err
+			}
+		}
+	}
+	f.mu.Lock()
+	f.requests = append(f.requests, req)
+	id := len(f.requests)
+	f.mu.Unlock()
+
+	if rsBody == nil {
+		return nil
+	}
+	return json.Unmarshal([]byte(fmt.Sprintf(`{
+		"id": "%d",
+		"channel_id": "%s",
+		"type": 0,
+		"content": "",
+		"author": {"id": "123", "username": "ci6ndex"},
+		"timestamp": "2024-01-01T00:00:00Z"
+	}`, 10000+id, harnessChannelID)), rsBody)
+}
+
+func (f *fakeRest) len() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return len(f.requests)
+}
+
+func (f *fakeRest) since(start int) []restRequest {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return slices.Clone(f.requests[start:])
+}
+
+// result holds what the bot sent to Discord while handling one interaction.
+type result struct {
+	t        *testing.T
+	requests []restRequest
+}
+
+// Response is the interaction response, failing the test when the bot didn't respond.
+func (r *result) Response() restRequest {
+	r.t.Helper()
+	for _, req := range r.requests {
+		if strings.HasSuffix(req.Route, "/callback") {
+			return req
+		}
+	}
+	r.t.Fatalf("expected a response, the bot sent %v", r.routes())
+	return restRequest{}
+}
+
+// ResponseType is the type of the interaction response, e.g. discord.InteractionResponseTypeUpdateMessage.
+func (r *result) ResponseType() discord.InteractionResponseType {
+	r.t.Helper()
+	kind, _ := r.Response().Body["type"].(float64)
+	return discord.InteractionResponseType(kind)
+}
+
+// Ephemeral reports whether the message the interaction responded with is only shown to its user.
+func (r *result) Ephemeral() bool {
+	r.t.Helper()
+	data, _ := r.Response().Body["data"].(map[string]any)
+	flags, _ := data["flags"].(float64)
+	return discord.MessageFlags(flags).Has(discord.MessageFlagEphemeral)
+}
+
+// Text joins the content of every message and text display the bot sent.
+func (r *result) Text() string {
+	var texts []string
+	for _, req := range r.requests {
+		walk(req.Body, func(m map[string]any) {
+			if content, ok := m["content"].(string); ok && content != "" {
+				texts = append(texts, content)
+			}
+		})
+	}
+	return strings.Join(texts, "\n")
+}
+
+// CustomIDs lists the custom IDs of every button and select menu the bot sent, in order.
+func (r *result) CustomIDs() []string {
+	var ids []string
+	for _, req := range r.requests {
+		walk(req.Body, func(m map[string]any) {
+			if id, ok := m["custom_id"].(string); ok {
+				ids = append(ids, id)
+			}
+		})
+	}
+	return ids
+}
+
+// AssertContains fails the test unless every substring is in the text the bot sent.
+func (r *result) AssertContains(substrings ...string) {
+	r.t.Helper()
+	text := r.Text()
+	for _, s := range substrings {
+		if !strings.Contains(text, s) {
+			r.t.Errorf("expected %q in\n%s", s, text)
+		}
+	}
+}
+
+// AssertCustomIDs fails the test unless every custom ID is on a component the bot sent.
+func (r *result) AssertCustomIDs(ids ...string) {
+	r.t.Helper()
+	sent := r.CustomIDs()
+	for _, id := range ids {
+		if !slices.Contains(sent, id) {
+			r.t.Errorf("expected a component with custom id %q, got %v", id, sent)
+		}
+	}
+}
+
+// AssertNoError fails the test when the bot answered with the error card.
+func (r *result) AssertNoError() {
+	r.t.Helper()
+	if text := r.Text(); strings.Contains(text, "Something went wrong") {
+		r.t.Errorf("expected no error, got\n%s", text)
+	}
+}
+
+func (r *result) routes() []string {
+	routes := make([]string, len(r.requests))
+	for i, req := range r.requests {
+		routes[i] = req.Method + " " + req.Route
+	}
+	return routes
+}
+
+// walk calls fn with every object in a decoded JSON value.
+func walk(v any, fn func(map[string]any)) {
+	switch v := v.(type) {
+	case map[string]any:
+		fn(v)
+		for _, child := range v {
+			walk(child, fn)
+		}
+	case []any:
+		for _, child := range v {
+			walk(child, fn)
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package bot
+
+import (
+	"ci6ndex/metrics"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/disgoorg/disgo/gateway"
+)
+
+// NewHealthServer serves the probes and metrics of the bot on addr:
+//   - /healthz answers as long as the process is up
+//   - /readyz answers once ready returns nil, e.g. Bot.Ready
+//   - /metrics writes metrics.Default in the Prometheus text format
+func NewHealthServer(addr string, ready func() error) *http.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("GET /readyz", func(w http.ResponseWriter, r *http.Request) {
+		if err := ready(); err != nil {
+			http.Error(w, err.Error(), http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = fmt.Fprintln(w, "ok")
+	})
+	mux.HandleFunc("GET /metrics", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+		if err := metrics.Default.Write(w); err != nil {
+			slog.Error("failed to write metrics", "error", err)
+		}
+	})
+	return &http.Server{
+		Addr:              addr,
+		Handler:           mux,
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+}
+
+// Ready reports why the bot can't serve interactions, if it can't: the gateway has to be connected and every open
+// database has to answer pings.
+func (b *Bot) Ready() error {
+	if b.Client == nil || !b.Client.HasGateway() {
+		return errors.New("discord client isn't configured")
+	}
+	if status := b.Client.Gateway.Status(); status != gateway.StatusReady {
+		return fmt.Errorf("gateway isn't ready: %s", status)
+	}
+	if errs := b.Ci6ndex.Health(); len(errs) > 0 {
+		return errors.Join(append([]error{errors.New("database isn't healthy")}, errs...)...)
+	}
+	return nil
+}
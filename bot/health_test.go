@@ -0,0 +1,62 @@
+package bot
+
+import (
+	"ci6ndex/metrics"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHealthServer(t *testing.T) {
+	readyErr := errors.New("gateway isn't ready: StatusDisconnected")
+	srv := httptest.NewServer(NewHealthServer("", func() error { return readyErr }).Handler)
+	defer srv.Close()
+
+	get := func(path string) (int, string) {
+		t.Helper()
+		res, err := http.Get(srv.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+		body, err := io.ReadAll(res.Body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return res.StatusCode, string(body)
+	}
+
+	if status, _ := get("/healthz"); status != http.StatusOK {
+		t.Errorf("expected /healthz to be ok, got %d", status)
+	}
+	if status, body := get("/readyz"); status != http.StatusServiceUnavailable || !strings.Contains(body, "gateway") {
+		t.Errorf("expected /readyz to be unavailable with the reason, got %d %q", status, body)
+	}
+	readyErr = nil
+	if status, _ := get("/readyz"); status != http.StatusOK {
+		t.Errorf("expected /readyz to be ok once ready, got %d", status)
+	}
+
+	metrics.RollFailures.Inc("not_diverse_enough")
+	_, body := get("/metrics")
+	if !strings.Contains(body, `ci6ndex_roll_failures_total{error="not_diverse_enough"}`) {
+		t.Errorf("expected roll failures in /metrics, got\n%s", body)
+	}
+}
+
+func TestRouteLabel(t *testing.T) {
+	cases := map[string]string{
+		"/draft":                                "/draft",
+		"/leaders/12/docs/3/vote":               "/leaders/{id}/docs/{id}/vote",
+		"/leaders/browse/t13.by.u-.g-.v1/2":     "/leaders/browse/{value}/{id}",
+		"/draft/game-settings/map_type/allowed": "/draft/game-settings/map_type/allowed",
+	}
+	for path, want := range cases {
+		if got := routeLabel(path); got != want {
+			t.Errorf("routeLabel(%q) = %q, expected %q", path, got, want)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"ci6ndex/ci6ndex"
 	"ci6ndex/ci6ndex/generated"
+	"cmp"
 	"context"
 	"database/sql"
 	"errors"
@@ -22,8 +23,13 @@ import (
 var (
 	// 1 based page number. equivalent to offset+1
 	defaultPage uint64 = 1
-	// How many items to fetch per page
-	pageLimit uint64 = 10
+	// How many leaders to show per page. Each leader takes 3 of the 40 components a message may have and the
+	// filter menus and buttons of the browser take another 14
+	pageLimit uint64 = 6
+	// How many leaders a search shows, each one takes 3 of the 40 components a message may have
+	maxSearchResults = 10
+	// Discord allows at most 25 autocomplete choices
+	maxAutocompleteChoices = 25
 )
 
 // Leaders returns a cached, alphabetized, list of leaders for the guildID. since we store these in memory, don't rely on them for non static data
@@ -89,32 +95,19 @@ func (b *Bot) getPrevLeader(guildID uint64, leader *generated.Leader) (*generate
 	return &leaders[prevIndex], nil
 }
 
-func (b *Bot) handleManageLeaders(id, guildID string, page uint64) (*[]discord.LayoutComponent, error) {
-	slog.Info("handleManageLeaders", "path", id, "page", page)
-	gid, err := strconv.ParseUint(guildID, 10, 64)
-	if err != nil {
-		return nil, fmt.Errorf("failed to parse guildID")
-	}
-	offset := (page - 1) * pageLimit
-	r, err := b.leadersScreen(gid, offset, pageLimit)
-	if err != nil {
-		return nil, err
-	}
-	return &r, nil
-}
-
 func (b *Bot) handleSearchLeaderSlashCommand() handler.SlashCommandHandler {
 	return func(data discord.SlashCommandInteractionData, e *handler.CommandEvent) error {
 		searchLeader := data.String("leader-name")
 		searchCiv := data.String("civ-name")
-		slog.Info("handleLeaderDetails", "leaderSearch", searchLeader, "civSearch", searchCiv)
+		searchKeyword := data.String("keyword")
+		slog.Info("handleLeaderDetails", "leaderSearch", searchLeader, "civSearch", searchCiv, "keywordSearch", searchKeyword)
 
 		flags := discord.MessageFlagEphemeral
 
-		if searchLeader == "" && searchCiv == "" {
+		if searchLeader == "" && searchCiv == "" && searchKeyword == "" {
 			err := e.CreateMessage(discord.NewMessageCreateV2().
 				AddFlags(flags).
-				WithContent("Invalid search, must specify leader or civ"))
+				WithContent("Invalid search, must specify leader, civ or keyword"))
 			return err
 		}
 
@@ -128,33 +121,14 @@ func (b *Bot) handleSearchLeaderSlashCommand() handler.SlashCommandHandler {
 			return err
 		}
 
-		foundLeaders := make([]generated.Leader, 0)
-		seen := make(map[int64]bool)
-
-		if searchCiv != "" {
-			for _, l := range leaders {
-				if strings.Contains(l.CivName, strings.ToUpper(searchCiv)) && !seen[l.ID] {
-					seen[l.ID] = true
-					foundLeaders = append(foundLeaders, l)
-				}
-			}
-		}
-
-		if searchLeader != "" {
-			for _, l := range leaders {
-				if strings.Contains(l.LeaderName, strings.ToUpper(searchLeader)) && !seen[l.ID] {
-					seen[l.ID] = true
-					foundLeaders = append(foundLeaders, l)
-				}
-			}
+		foundLeaders := searchLeaders(leaders, searchLeader, searchCiv, searchKeyword)
+		if len(foundLeaders) == 0 {
+			err := e.CreateMessage(discord.NewMessageCreateV2().
+				AddFlags(flags).
+				WithContent("No leaders found, try another search"))
+			return err
 		}
 
-		slices.SortFunc(foundLeaders, func(l1, l2 generated.Leader) int {
-			if cmp := strings.Compare(l1.CivName, l2.CivName); cmp != 0 {
-				return cmp
-			}
-			return strings.Compare(l1.LeaderName, l2.LeaderName)
-		})
 		var header bytes.Buffer
 		err = renderLeadersMainScreen(&header)
 		if err != nil {
@@ -184,16 +158,133 @@ func (b *Bot) handleSearchLeaderSlashCommand() handler.SlashCommandHandler {
 			WithComponents(layout))
 
 		if err != nil {
-			slog.Error("Failed to create leader details screen", "error", err)
-			desc, ok := errorDescription(err)
-			if ok {
-				slog.Error(desc)
-			}
+			return errors.Join(err, errors.New("failed to create leader details screen"))
 		}
 		return nil
 	}
 }
 
+// searchLeaders returns the leaders matching any of the searches, best match first, capped at maxSearchResults
+func searchLeaders(leaders []generated.Leader, searchLeader, searchCiv, searchKeyword string) []generated.Leader {
+	var matches []ci6ndex.LeaderMatch
+	if searchKeyword != "" {
+		matches = append(matches, ci6ndex.SearchLeaders(leaders, searchKeyword, ci6ndex.SearchKeyword)...)
+	}
+	if searchCiv != "" {
+		matches = append(matches, ci6ndex.SearchLeaders(leaders, searchCiv, ci6ndex.SearchCivName)...)
+	}
+	if searchLeader != "" {
+		matches = append(matches, ci6ndex.SearchLeaders(leaders, searchLeader, ci6ndex.SearchLeaderName)...)
+	}
+	slices.SortStableFunc(matches, func(m1, m2 ci6ndex.LeaderMatch) int {
+		return cmp.Compare(m2.Score, m1.Score)
+	})
+
+	found := make([]generated.Leader, 0, maxSearchResults)
+	seen := make(map[int64]bool)
+	for _, m := range matches {
+		if len(found) == maxSearchResults {
+			break
+		}
+		if !seen[m.Leader.ID] {
+			seen[m.Leader.ID] = true
+			found = append(found, m.Leader)
+		}
+	}
+	return found
+}
+
+func (b *Bot) handleLeaderAutocomplete() handler.AutocompleteHandler {
+	return func(e *handler.AutocompleteEvent) error {
+		guildID, err := parseGuildId(e.GuildID().String())
+		if err != nil {
+			return err
+		}
+		leaders, err := b.Leaders(guildID)
+		if err != nil {
+			return err
+		}
+
+		focused := e.Data.Focused()
+		query := e.Data.String(focused.Name)
+		choices := make([]discord.AutocompleteChoice, 0, maxAutocompleteChoices)
+
+		switch focused.Name {
+		case "civ-name":
+			seen := make(map[string]bool)
+			for _, l := range autocompleteCandidates(leaders, query, ci6ndex.SearchCivName) {
+				if len(choices) == maxAutocompleteChoices {
+					break
+				}
+				if !seen[l.CivName] {
+					seen[l.CivName] = true
+					choices = append(choices, discord.AutocompleteChoiceString{Name: l.CivName, Value: l.CivName})
+				}
+			}
+		case "keyword":
+			for _, k := range keywordCandidates(leaders, query) {
+				if len(choices) == maxAutocompleteChoices {
+					break
+				}
+				choices = append(choices, discord.AutocompleteChoiceString{Name: k, Value: k})
+			}
+		default:
+			for _, l := range autocompleteCandidates(leaders, query, ci6ndex.SearchLeaderName) {
+				if len(choices) == maxAutocompleteChoices {
+					break
+				}
+				choices = append(choices, discord.AutocompleteChoiceString{
+					Name:  fmt.Sprintf("%s (%s)", ci6ndex.LeaderName(l), l.CivName),
+					Value: ci6ndex.LeaderName(l),
+				})
+			}
+		}
+		return e.AutocompleteResult(choices)
+	}
+}
+
+// autocompleteCandidates returns fuzzy matches for query, or every leader when nothing has been typed yet
+func autocompleteCandidates(leaders []generated.Leader, query string, field ci6ndex.SearchField) []generated.Leader {
+	if strings.TrimSpace(query) == "" {
+		return leaders
+	}
+	matches := ci6ndex.SearchLeaders(leaders, query, field)
+	candidates := make([]generated.Leader, len(matches))
+	for i, m := range matches {
+		candidates[i] = m.Leader
+	}
+	return candidates
+}
+
+// keywordCandidates suggests play-style tags while nothing has been typed, otherwise the tags, abilities and uniques
+// of the leaders matching query
+func keywordCandidates(leaders []generated.Leader, query string) []string {
+	if strings.TrimSpace(query) == "" {
+		return ci6ndex.PlayStyleTags
+	}
+	needle := strings.ToLower(strings.TrimSpace(query))
+	keywords := make([]string, 0)
+	seen := make(map[string]bool)
+	add := func(k string) {
+		if !seen[k] && strings.Contains(strings.ToLower(k), needle) {
+			seen[k] = true
+			keywords = append(keywords, k)
+		}
+	}
+	for _, t := range ci6ndex.PlayStyleTags {
+		add(t)
+	}
+	for _, m := range ci6ndex.SearchLeaders(leaders, query, ci6ndex.SearchKeyword) {
+		metadata, _ := ci6ndex.MetadataForLeader(m.Leader)
+		add(metadata.CivAbility.Name)
+		add(metadata.LeaderAbility.Name)
+		for _, u := range metadata.Uniques {
+			add(u.Name)
+		}
+	}
+	return keywords
+}
+
 func (b *Bot) handleLeaderDetailsButtonCommand() handler.ButtonComponentHandler {
 	return func(bid discord.ButtonInteractionData, e *handler.ComponentEvent) error {
 		lid, err := strconv.ParseInt(e.Vars["leaderId"], 10, 64)
@@ -225,25 +316,23 @@ func (b *Bot) handleLeaderDetailsButtonCommand() handler.ButtonComponentHandler
 		if err := e.UpdateMessage(discord.MessageUpdate{
 			Components: &components,
 		}); err != nil {
-			slog.Error("Failed to create leader details screen", "error", err)
-			desc, ok := errorDescription(err)
-			if ok {
-				slog.Error(desc)
-			}
+			return errors.Join(err, errors.New("failed to create leader details screen"))
 		}
 		return nil
 	}
 }
 
+// handleManageLeadersButtonCommand serves the unfiltered browser, from the details screen's Back button and
+// pagination buttons sent before filters existed.
 func (b *Bot) handleManageLeadersButtonCommand() handler.ButtonComponentHandler {
 	return func(bid discord.ButtonInteractionData, e *handler.ComponentEvent) error {
-		guildID := e.GuildID()
-		if guildID == nil {
-			return fmt.Errorf("event missing guild ID ")
+		guildID, err := parseGuildId(e.GuildID().String())
+		if err != nil {
+			return err
 		}
 		var currentPage uint64
 		if pageStr := e.Vars["page"]; pageStr != "" {
-			if parsedPage, errConv := strconv.ParseUint(pageStr, 10, 64); errConv == nil {
+			if parsedPage, errConv := strconv.ParseUint(pageStr, 10, 64); errConv == nil && parsedPage > 0 {
 				currentPage = parsedPage
 			} else {
 				slog.Debug("failed to parse page", "pageStr", pageStr, "error", errConv)
@@ -254,23 +343,7 @@ func (b *Bot) handleManageLeadersButtonCommand() handler.ButtonComponentHandler
 			currentPage = defaultPage
 		}
 
-		components, err := b.handleManageLeaders(bid.CustomID(), guildID.String(), currentPage)
-
-		if err != nil {
-			return err
-		}
-
-		if err := e.UpdateMessage(discord.MessageUpdate{
-			Components: components,
-		}); err != nil {
-			slog.Error("Failed to create leaders screen", "error", slog.Any("err", err))
-			desc, ok := errorDescription(err)
-			if ok {
-				slog.Error(desc)
-			}
-			return err
-		}
-		return nil
+		return b.updateLeadersScreen(e, guildID, defaultLeaderFilter(), currentPage)
 	}
 }
 
@@ -283,8 +356,9 @@ func (b *Bot) handleRateLeaderMenuSelectCommand() handler.SelectMenuComponentHan
 
 		selectData := data.(discord.StringSelectMenuInteractionData)
 
-		// /leaders/id/rating
+		// /leaders/id/rating/ruleset, menus sent before rulesets were rated separately rate vanilla
 		leader := strings.Split(selectData.CustomID(), "/")[2]
+		bbg := e.Vars["ruleset"] == "bbg"
 		// single select
 		rank := selectData.Values[0]
 
@@ -293,37 +367,17 @@ func (b *Bot) handleRateLeaderMenuSelectCommand() handler.SelectMenuComponentHan
 			return err
 		}
 
-		user := e.User()
-		playerID, err := strconv.ParseInt(user.ID.String(), 10, 64)
-		if err != nil {
-			return err
-		}
-
-		globalName := sql.NullString{}
-		if user.GlobalName != nil {
-			globalName = sql.NullString{String: *user.GlobalName, Valid: true}
-		}
-		avatar := sql.NullString{}
-		if user.Avatar != nil {
-			avatar = sql.NullString{String: *user.Avatar, Valid: true}
-		}
-
-		err = b.Ci6ndex.AddPlayer(context.Background(), guildID, generated.AddPlayerParams{
-			ID:            playerID,
-			Username:      user.Username,
-			GlobalName:    globalName,
-			DiscordAvatar: avatar,
-		})
+		playerID, err := b.addPlayer(guildID, e.User())
 		if err != nil {
 			return err
 		}
 
-		err = b.Ci6ndex.SubmitRankForPlayer(guildID, rank, playerID, leaderID)
+		err = b.Ci6ndex.SubmitRankForPlayer(guildID, rank, playerID, leaderID, bbg)
 		if err != nil {
 			return err
 		}
 
-		b.background(func() {
+		b.background(fmt.Sprintf("calculate tier of leader %d", leaderID), func() {
 			err := b.Ci6ndex.CalculateTierForLeader(guildID, leaderID)
 			if err != nil {
 				slog.Error("failed to calulate tier", "guild", guildID, "leader", leaderID, "err", err)
@@ -336,13 +390,11 @@ func (b *Bot) handleRateLeaderMenuSelectCommand() handler.SelectMenuComponentHan
 
 func (b *Bot) handleManageLeadersSlashCommand() handler.SlashCommandHandler {
 	return func(data discord.SlashCommandInteractionData, e *handler.CommandEvent) error {
-		guildID := e.GuildID()
-		if guildID == nil {
-			return fmt.Errorf("event missing guild ID")
+		guildID, err := parseGuildId(e.GuildID().String())
+		if err != nil {
+			return err
 		}
-		var currentPage uint64 = 1
-		components, err := b.handleManageLeaders("/leaders", guildID.String(), currentPage)
-
+		components, err := b.leadersScreen(guildID, defaultLeaderFilter(), defaultPage)
 		if err != nil {
 			return err
 		}
@@ -352,38 +404,39 @@ func (b *Bot) handleManageLeadersSlashCommand() handler.SlashCommandHandler {
 
 		if err := e.CreateMessage(discord.MessageCreate{
 			Flags:      flags,
-			Components: *components,
+			Components: components,
 		}); err != nil {
-			slog.Error("failed to create leaders screen", "error", slog.Any("err", err))
-			desc, ok := errorDescription(err)
-			if ok {
-				slog.Error(desc)
-			}
-			return err
+			return errors.Join(err, errors.New("failed to create leaders screen"))
 		}
 		return nil
 	}
 }
 
+// maxLinkButtons is how many link buttons fit the action row of the details screen
+const maxLinkButtons = 5
+
+// documentsForLeaderComponent returns link buttons for the leader's most voted approved links. Notes and pending
+// documents are only shown on the guides screen.
 func (b *Bot) documentsForLeaderComponent(guildID uint64, leaderID int64) ([]discord.InteractiveComponent, error) {
 	docs, err := b.Ci6ndex.GetDocumentsForLeader(guildID, leaderID)
 	if err != nil {
 		return nil, err
 	}
-	if len(docs) == 0 {
-		return nil, fmt.Errorf("no documents found for leader=%d", leaderID)
-	}
-	if len(docs) >= 5 {
-		return nil, fmt.Errorf("unable to build document action row, exceeds max buttons #: %d", len(docs))
-	}
-	buttons := make([]discord.InteractiveComponent, len(docs))
-	for i, d := range docs {
-		buttons[i] = discord.NewLinkButton(d.DocName, d.Link)
+	buttons := make([]discord.InteractiveComponent, 0, maxLinkButtons)
+	for _, d := range docs {
+		if len(buttons) == maxLinkButtons {
+			break
+		}
+		if !d.Document.Approved || d.IsNote() {
+			continue
+		}
+		buttons = append(buttons, discord.NewLinkButton(d.Document.DocName, d.Document.Link))
 	}
 	return buttons, nil
 }
 
-func (b *Bot) updateRatingForLeaderComponent(leaderID int64) discord.StringSelectMenuComponent {
+// updateRatingForLeaderComponent rates the leader under the BBG ruleset when bbg is set, the vanilla one otherwise.
+func (b *Bot) updateRatingForLeaderComponent(leaderID int64, bbg bool) discord.StringSelectMenuComponent {
 	opts := make([]discord.StringSelectMenuOption, 5)
 
 	opts[0] = discord.StringSelectMenuOption{
@@ -412,7 +465,12 @@ func (b *Bot) updateRatingForLeaderComponent(leaderID int64) discord.StringSelec
 		Description: "An F tier leader is never the strongest choice when offered. There is always a better choice",
 	}
 
-	return discord.NewStringSelectMenu(fmt.Sprintf("/leaders/%d/rating", leaderID), "Update rating...", opts...)
+	if bbg {
+		return discord.NewStringSelectMenu(fmt.Sprintf("/leaders/%d/rating/bbg", leaderID), "Update BBG rating...",
+			opts...)
+	}
+	return discord.NewStringSelectMenu(fmt.Sprintf("/leaders/%d/rating/vanilla", leaderID),
+		"Update vanilla rating...", opts...)
 }
 
 func (b *Bot) leaderDetailsScreen(leader generated.Leader, guildId uint64) ([]discord.LayoutComponent, error) {
@@ -425,6 +483,20 @@ func (b *Bot) leaderDetailsScreen(leader generated.Leader, guildId uint64) ([]di
 		return nil, errors.Join(err, errors.New("failed to render leader details"))
 	}
 
+	// Abilities, uniques and play style
+	var metadataBuf bytes.Buffer
+	metadata, ok := ci6ndex.MetadataForLeader(leader)
+	if ok {
+		err = renderLeaderMetadata(&metadataBuf, metadata)
+		if err != nil {
+			return nil, errors.Join(err, errors.New("failed to render leader metadata"))
+		}
+	}
+	metadataSection := make([]discord.ContainerSubComponent, 0, 2)
+	if metadataBuf.Len() > 0 {
+		metadataSection = append(metadataSection, discord.NewSmallSeparator(), discord.NewTextDisplay(metadataBuf.String()))
+	}
+
 	// Community rankings
 	var rankingsBuf bytes.Buffer
 	ranks, err := b.Ci6ndex.GetRanksForLeader(guildId, leader.ID)
@@ -436,6 +508,17 @@ func (b *Bot) leaderDetailsScreen(leader generated.Leader, guildId uint64) ([]di
 		return nil, errors.Join(err, errors.New("failed to render community rankings"))
 	}
 
+	// Tier history
+	var historyBuf bytes.Buffer
+	history, err := b.Ci6ndex.GetTierHistory(guildId, leader.ID)
+	if err != nil {
+		return nil, errors.Join(err, errors.New("failed to fetch tier history"))
+	}
+	err = renderTierHistory(&historyBuf, history)
+	if err != nil {
+		return nil, errors.Join(err, errors.New("failed to render tier history"))
+	}
+
 	// Middle section
 	documentButtons, err := b.documentsForLeaderComponent(guildId, leader.ID)
 	if err != nil {
@@ -460,24 +543,40 @@ func (b *Bot) leaderDetailsScreen(leader generated.Leader, guildId uint64) ([]di
 
 	refreshButton := discord.NewSecondaryButton("Refresh", fmt.Sprintf("/leaders/%d", leader.ID)).
 		WithEmoji(discord.ComponentEmoji{Name: "🔄"})
+	// Only approved links get a button, leaders without any skip straight to the guides row
+	linksRow := make([]discord.ContainerSubComponent, 0, 1)
+	if len(documentButtons) > 0 {
+		linksRow = append(linksRow, discord.NewActionRow(documentButtons...))
+	}
+	guidesButton := discord.NewSecondaryButton("Guides & Notes", fmt.Sprintf("/leaders/%d/docs", leader.ID)).
+		WithEmoji(discord.ComponentEmoji{Name: notebook})
+	submitButton := discord.NewSecondaryButton("Submit a Guide", fmt.Sprintf("/leaders/%d/docs/new", leader.ID))
+
 	layout := []discord.LayoutComponent{
 		discord.NewContainer().AddComponents(
 			discord.NewSection(
 				discord.NewTextDisplay(headerBuf.String()),
 			).WithAccessory(discord.NewThumbnail(me.EffectiveAvatarURL())),
-			discord.NewActionRow(b.updateRatingForLeaderComponent(leader.ID)),
-			discord.NewSmallSeparator(),
-			discord.NewTextDisplay(rankingsBuf.String()),
-			discord.NewSmallSeparator(),
-			discord.NewTextDisplay("### Relevant Links"),
-			discord.NewActionRow(documentButtons...),
-			discord.NewLargeSeparator(),
-			discord.NewActionRow().WithComponents(
-				discord.NewPrimaryButton("Back", "/leaders"),
-				prevButton,
-				nextButton,
-				refreshButton,
-			)).
+			discord.NewActionRow(b.updateRatingForLeaderComponent(leader.ID, true)),
+			discord.NewActionRow(b.updateRatingForLeaderComponent(leader.ID, false))).
+			AddComponents(metadataSection...).
+			AddComponents(
+				discord.NewSmallSeparator(),
+				discord.NewTextDisplay(rankingsBuf.String()),
+				discord.NewSmallSeparator(),
+				discord.NewTextDisplay(historyBuf.String()),
+				discord.NewSmallSeparator(),
+				discord.NewTextDisplay("### Relevant Links")).
+			AddComponents(linksRow...).
+			AddComponents(
+				discord.NewActionRow(guidesButton, submitButton),
+				discord.NewLargeSeparator(),
+				discord.NewActionRow().WithComponents(
+					discord.NewPrimaryButton("Back", "/leaders"),
+					prevButton,
+					nextButton,
+					refreshButton,
+				)).
 			WithAccentColor(colorSuccess),
 	}
 
@@ -494,7 +593,7 @@ func renderLeaderDetails(buffer io.Writer, leader generated.Leader) error {
 	}
 
 	// Create a more detailed leader profile
-	mdBuilder := md.H1(emoji+" "+leaderDisplayName(leader)+" of "+leader.CivName).
+	mdBuilder := md.H1(emoji+" "+ci6ndex.LeaderName(leader)+" of "+leader.CivName).
 		H2f("**Tier**: %s", tier.Name())
 	if leader.Banned {
 		mdBuilder.PlainText("\n**Status**: " + getBannedStatus(leader.Banned))
@@ -515,6 +614,29 @@ func getBannedStatus(banned bool) string {
 	return "✅ Available for draft"
 }
 
+func renderLeaderMetadata(buffer io.Writer, metadata ci6ndex.LeaderMetadata) error {
+	md := md.NewMarkdown(buffer)
+	mdBuilder := md.H3("Abilities & Uniques")
+
+	for _, a := range []ci6ndex.Ability{metadata.CivAbility, metadata.LeaderAbility} {
+		switch {
+		case a.Name == "":
+		case a.Description == "":
+			mdBuilder.PlainTextf("- **%s**", a.Name)
+		default:
+			mdBuilder.PlainTextf("- **%s**: %s", a.Name, a.Description)
+		}
+	}
+	for _, u := range metadata.Uniques {
+		mdBuilder.PlainTextf("- %s (%s)", u.Name, u.Kind)
+	}
+	if len(metadata.Tags) > 0 {
+		mdBuilder.PlainTextf("**Play style**: %s", strings.Join(metadata.Tags, ", "))
+	}
+
+	return mdBuilder.Build()
+}
+
 func renderCommunityRankings(buffer io.Writer, ranks []ci6ndex.LeaderRankWithPlayer) error {
 	md := md.NewMarkdown(buffer)
 	mdBuilder := md.H3("Community Rankings")
@@ -524,9 +646,21 @@ func renderCommunityRankings(buffer io.Writer, ranks []ci6ndex.LeaderRankWithPla
 		return mdBuilder.Build()
 	}
 
-	mdBuilder.PlainTextf("Rated by %d player(s)", len(ranks))
-
-	for _, r := range ranks {
+	// ranks come vanilla first, every ruleset gets its own list
+	for i, r := range ranks {
+		if i == 0 || r.BBG != ranks[i-1].BBG {
+			ruleset := "Vanilla"
+			if r.BBG {
+				ruleset = "BBG"
+			}
+			n := 0
+			for _, other := range ranks {
+				if other.BBG == r.BBG {
+					n++
+				}
+			}
+			mdBuilder.PlainTextf("**%s**: rated by %d player(s)", ruleset, n)
+		}
 		tier, err := ci6ndex.GetTierByValue(r.Tier)
 		if err != nil {
 			return err
@@ -537,11 +671,42 @@ func renderCommunityRankings(buffer io.Writer, ranks []ci6ndex.LeaderRankWithPla
 	return mdBuilder.Build()
 }
 
-func leaderDisplayName(leader generated.Leader) string {
-	if leader.FriendlyName.Valid && leader.FriendlyName.String != "" {
-		return leader.FriendlyName.String
+// tierHistoryLimit is how many of the most recent tier changes are shown on the details screen
+const tierHistoryLimit = 5
+
+func renderTierHistory(buffer io.Writer, history []ci6ndex.TierChange) error {
+	md := md.NewMarkdown(buffer)
+	mdBuilder := md.H3("Tier History")
+
+	if len(history) == 0 {
+		mdBuilder.PlainText("No tier changes recorded yet.")
+		return mdBuilder.Build()
+	}
+
+	if len(history) > tierHistoryLimit {
+		history = history[len(history)-tierHistoryLimit:]
+	}
+	// newest first
+	for i := len(history) - 1; i >= 0; i-- {
+		change := history[i]
+		tier, err := ci6ndex.GetTierByValue(change.Tier)
+		if err != nil {
+			return err
+		}
+		movedBy := "recalculated"
+		if change.PlayerID.Valid {
+			movedBy = fmt.Sprintf("moved by <@%d>", change.PlayerID.Int64)
+		}
+		if change.PreviousTier == 0 {
+			mdBuilder.PlainTextf("- <t:%d:d> %s (%.2f), %s",
+				change.ChangedAt.Unix(), tier.Name(), change.Tier, movedBy)
+			continue
+		}
+		mdBuilder.PlainTextf("- <t:%d:d> %.2f → %s (%.2f), %s",
+			change.ChangedAt.Unix(), change.PreviousTier, tier.Name(), change.Tier, movedBy)
 	}
-	return leader.LeaderName
+
+	return mdBuilder.Build()
 }
 
 func (b *Bot) leadersBody(guildID uint64, leads []generated.Leader) ([]discord.ContainerSubComponent, error) {
@@ -549,7 +714,7 @@ func (b *Bot) leadersBody(guildID uint64, leads []generated.Leader) ([]discord.C
 	for i, leader := range leads {
 		var leaderRow bytes.Buffer
 		err := md.NewMarkdown(&leaderRow).
-			H1(leader.DiscordEmojiString.String + fmt.Sprintf(" %s ", leaderDisplayName(leader))).
+			H1(leader.DiscordEmojiString.String + fmt.Sprintf(" %s ", ci6ndex.LeaderName(leader))).
 			Build()
 		if err != nil {
 			return nil, errors.Join(err, fmt.Errorf("failed to build leader: %v", leader))
@@ -569,21 +734,34 @@ func (b *Bot) leadersBody(guildID uint64, leads []generated.Leader) ([]discord.C
 
 	return leaderRows, nil
 }
-func (b *Bot) leadersScreen(guildId uint64, currentOffset uint64, limit uint64) ([]discord.LayoutComponent, error) {
+func (b *Bot) leadersScreen(guildId uint64, filter leaderFilter, page uint64) ([]discord.LayoutComponent, error) {
 	me, _ := b.Client.Caches.SelfUser()
 
+	civName := ""
+	if filter.civ != 0 {
+		civ, err := b.Ci6ndex.GetLeaderById(guildId, uint64(filter.civ))
+		if err != nil {
+			return nil, errors.Join(err, errors.New("failed to fetch civ filter"))
+		}
+		civName = civ.CivName
+	}
+
+	leads, total, err := b.Ci6ndex.QueryLeaders(guildId, filter.query(civName, page))
+	if err != nil {
+		return nil, errors.Join(err, fmt.Errorf("failed to fetch leaders for page %d", page))
+	}
+
 	var leadersHeader bytes.Buffer
-	err := renderLeadersMainScreen(&leadersHeader)
+	err = renderLeadersMainScreen(&leadersHeader)
 	if err != nil {
 		return nil, errors.Join(err, errors.New("failed to render leaders main screen"))
 	}
-
-	leads, err := b.Ci6ndex.GetLeadersInRange(guildId, currentOffset, limit)
+	err = renderLeadersFilterSummary(&leadersHeader, filter, civName, page, len(leads), total)
 	if err != nil {
-		return nil, errors.Join(err, fmt.Errorf("failed to fetch leaders with offset %d, limit %d", currentOffset, limit))
+		return nil, errors.Join(err, errors.New("failed to render leaders filter summary"))
 	}
 
-	leaderRows, err := b.leadersBody(guildId, leads)
+	leaderRows, err := b.leaderStatsBody(leads)
 	if err != nil {
 		return nil, err
 	}
@@ -595,16 +773,56 @@ func (b *Bot) leadersScreen(guildId uint64, currentOffset uint64, limit uint64)
 			).WithAccessory(discord.NewThumbnail(me.EffectiveAvatarURL())),
 			discord.NewLargeSeparator()).
 			AddComponents(leaderRows...).
-			AddComponents(
-				discord.NewLargeSeparator(),
-				b.createLeaderPaginationActionRow(currentOffset, limit, len(leads)),
-			).
+			AddComponents(discord.NewLargeSeparator()).
+			AddComponents(filter.filterMenus()...).
+			AddComponents(b.createLeaderPaginationActionRow(filter, page, total)).
 			WithAccentColor(colorSuccess),
 	}
 
 	return layout, nil
 }
 
+// leaderStatsBody renders a row per leader with the stats the browser can sort by.
+func (b *Bot) leaderStatsBody(leads []ci6ndex.LeaderStats) ([]discord.ContainerSubComponent, error) {
+	if len(leads) == 0 {
+		return []discord.ContainerSubComponent{
+			discord.NewTextDisplay("No leaders match these filters."),
+		}, nil
+	}
+
+	leaderRows := make([]discord.ContainerSubComponent, len(leads))
+	for i, s := range leads {
+		tier, err := ci6ndex.GetTierForLeader(s.Leader)
+		if err != nil {
+			return nil, err
+		}
+		stats := fmt.Sprintf("%s · %d rating(s)", tier.Name(), s.Ratings)
+		if s.Picks > 0 {
+			stats += fmt.Sprintf(" · %.0f%% win rate over %d pick(s)", s.WinRate()*100, s.Picks)
+		}
+		if s.Leader.Banned {
+			stats += " · banned"
+		}
+
+		var leaderRow bytes.Buffer
+		err = md.NewMarkdown(&leaderRow).
+			H1(s.Leader.DiscordEmojiString.String + fmt.Sprintf(" %s ", ci6ndex.LeaderName(s.Leader))).
+			PlainText(stats).
+			Build()
+		if err != nil {
+			return nil, errors.Join(err, fmt.Errorf("failed to build leader: %v", s.Leader))
+		}
+
+		leaderRows[i] = discord.NewSection(
+			discord.NewTextDisplay(leaderRow.String()),
+		).WithAccessory(discord.NewSecondaryButton(
+			"Details",
+			fmt.Sprintf("/leaders/%d", s.Leader.ID),
+		))
+	}
+	return leaderRows, nil
+}
+
 func renderLeadersMainScreen(header io.Writer) error {
 	err := renderLeadersHeader(header)
 	if err != nil {
@@ -619,25 +837,72 @@ func renderLeadersHeader(header io.Writer) error {
 		Build()
 }
 
-func (b *Bot) createLeaderPaginationActionRow(currentOffset, limit uint64, numLeadsFetched int) discord.ActionRowComponent {
-	prevPage := (currentOffset / limit)
-	prevButton := discord.NewSecondaryButton("Previous", fmt.Sprintf("/leaders/page/%d", prevPage)).
+func renderLeadersFilterSummary(header io.Writer, filter leaderFilter, civName string, page uint64,
+	shown, total int) error {
+	mdBuilder := md.NewMarkdown(header)
+	switch {
+	case total == 0:
+		mdBuilder.PlainText("No leaders found.")
+	case shown == 0:
+		mdBuilder.PlainTextf("No leaders on page %d, %d leaders match.", page, total)
+	default:
+		first := (page-1)*pageLimit + 1
+		mdBuilder.PlainTextf("Showing %d-%d of %d leaders.", first, first+uint64(shown)-1, total)
+	}
+	if civName != "" {
+		mdBuilder.PlainTextf("Civ: **%s**", civName)
+	}
+	if filter.isDefault() {
+		mdBuilder.PlainText("Use the menus below to filter and sort.")
+	}
+	return mdBuilder.Build()
+}
+
+func (b *Bot) createLeaderPaginationActionRow(filter leaderFilter, page uint64, total int) discord.ActionRowComponent {
+	prevButton := discord.NewSecondaryButton("Previous", filter.route(page-1)).
 		WithEmoji(discord.ComponentEmoji{Name: "⬅️"})
-	if currentOffset <= 1 {
+	if page <= 1 {
 		prevButton = prevButton.WithDisabled(true)
 	}
 
-	nextPage := (currentOffset / limit) + 2
-
-	nextButton := discord.NewSecondaryButton("Next", fmt.Sprintf("/leaders/page/%d", nextPage)).
+	nextButton := discord.NewSecondaryButton("Next", filter.route(page+1)).
 		WithEmoji(discord.ComponentEmoji{Name: "➡️"})
-	if numLeadsFetched < int(limit) {
+	if page*pageLimit >= uint64(total) {
 		nextButton = nextButton.WithDisabled(true)
 	}
 	// Back Button (to draft menu)
 	backToDraftButton := discord.NewPrimaryButton("Drafts", "/draft").WithEmoji(discord.ComponentEmoji{
 		Name: crossedSwords,
 	})
+	civButton := discord.NewSecondaryButton("Civ", "/leaders/civ/"+filter.encode()).
+		WithEmoji(discord.ComponentEmoji{Name: magnifyingGlass})
+	resetButton := discord.NewSecondaryButton("Reset", defaultLeaderFilter().route(defaultPage)).
+		WithDisabled(filter.isDefault())
+
+	return discord.NewActionRow().WithComponents(backToDraftButton, prevButton, nextButton, civButton, resetButton)
+}
+
+// addPlayer registers or refreshes the discord user as a player of the guild and returns their player ID.
+func (b *Bot) addPlayer(guildID uint64, user discord.User) (int64, error) {
+	playerID := int64(user.ID)
 
-	return discord.NewActionRow().WithComponents(backToDraftButton, prevButton, nextButton)
+	globalName := sql.NullString{}
+	if user.GlobalName != nil {
+		globalName = sql.NullString{String: *user.GlobalName, Valid: true}
+	}
+	avatar := sql.NullString{}
+	if user.Avatar != nil {
+		avatar = sql.NullString{String: *user.Avatar, Valid: true}
+	}
+
+	err := b.Ci6ndex.AddPlayer(context.Background(), guildID, generated.AddPlayerParams{
+		ID:            playerID,
+		Username:      user.Username,
+		GlobalName:    globalName,
+		DiscordAvatar: avatar,
+	})
+	if err != nil {
+		return 0, err
+	}
+	return playerID, nil
 }
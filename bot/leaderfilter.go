@@ -0,0 +1,331 @@
+package bot
+
+import (
+	"ci6ndex/ci6ndex"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+)
+
+const (
+	// option values of the status select menu
+	statusBanned   = "banned"
+	statusAllowed  = "allowed"
+	statusUnranked = "unranked"
+	statusRanked   = "ranked"
+	statusGuide    = "guide"
+	statusNoGuide  = "no-guide"
+	civFilterInput = "civ"
+)
+
+// leaderFilter is the state of the /leaders browser. It is encoded into the custom ID of every component on the
+// screen, so paging and changing one filter keeps the others.
+type leaderFilter struct {
+	// minTier and maxTier are tier values 1 (S) to 5 (F), 0 leaves the bound open
+	minTier int
+	maxTier int
+	// banned, unranked and guide are 'y', 'n' or '-' for either
+	banned   byte
+	unranked byte
+	guide    byte
+	version  ci6ndex.LeaderVersion
+	sort     ci6ndex.LeaderSort
+	// civ is the ID of a leader whose civ is filtered on, 0 for any civ
+	civ int64
+}
+
+func defaultLeaderFilter() leaderFilter {
+	return leaderFilter{banned: '-', unranked: '-', guide: '-'}
+}
+
+// encode returns a compact, "/" free, form of f that fits in a custom ID, e.g. t13.by.u-.g-.v1.s2.c0
+func (f leaderFilter) encode() string {
+	return fmt.Sprintf("t%d%d.b%c.u%c.g%c.v%d.s%d.c%d",
+		f.minTier, f.maxTier, f.banned, f.unranked, f.guide, f.version, f.sort, f.civ)
+}
+
+// decodeLeaderFilter parses the output of encode. Unknown or malformed parts fall back to their default so an old
+// message never breaks the browser.
+func decodeLeaderFilter(s string) leaderFilter {
+	f := defaultLeaderFilter()
+	for _, part := range strings.Split(s, ".") {
+		if len(part) < 2 {
+			continue
+		}
+		value := part[1:]
+		switch part[0] {
+		case 't':
+			if len(value) == 2 && isTierDigit(value[0]) && isTierDigit(value[1]) {
+				f.minTier, f.maxTier = int(value[0]-'0'), int(value[1]-'0')
+			}
+		case 'b':
+			f.banned = triState(value)
+		case 'u':
+			f.unranked = triState(value)
+		case 'g':
+			f.guide = triState(value)
+		case 'v':
+			if v, err := strconv.Atoi(value); err == nil && v >= 0 && v <= int(ci6ndex.VersionBBGExpanded) {
+				f.version = ci6ndex.LeaderVersion(v)
+			}
+		case 's':
+			if v, err := strconv.Atoi(value); err == nil && v >= 0 && v <= int(ci6ndex.SortByWinRate) {
+				f.sort = ci6ndex.LeaderSort(v)
+			}
+		case 'c':
+			if v, err := strconv.ParseInt(value, 10, 64); err == nil && v >= 0 {
+				f.civ = v
+			}
+		}
+	}
+	return f
+}
+
+func isTierDigit(c byte) bool {
+	return c >= '0' && c <= '5'
+}
+
+func triState(value string) byte {
+	if value == "y" || value == "n" {
+		return value[0]
+	}
+	return '-'
+}
+
+func triStateBool(state byte) sql.NullBool {
+	if state == '-' {
+		return sql.NullBool{}
+	}
+	return sql.NullBool{Bool: state == 'y', Valid: true}
+}
+
+// query converts f into a domain query for the given 1 based page.
+func (f leaderFilter) query(civName string, page uint64) ci6ndex.LeaderQuery {
+	return ci6ndex.LeaderQuery{
+		MinTier:  float64(f.minTier),
+		MaxTier:  float64(f.maxTier),
+		Banned:   triStateBool(f.banned),
+		Unranked: triStateBool(f.unranked),
+		HasGuide: triStateBool(f.guide),
+		CivName:  civName,
+		Version:  f.version,
+		Sort:     f.sort,
+		Offset:   (page - 1) * pageLimit,
+		Limit:    pageLimit,
+	}
+}
+
+func (f leaderFilter) isDefault() bool {
+	return f == defaultLeaderFilter()
+}
+
+// route returns the custom ID of the browser page for f.
+func (f leaderFilter) route(page uint64) string {
+	return fmt.Sprintf("/leaders/browse/%s/%d", f.encode(), page)
+}
+
+// filterMenus returns the select menus changing each part of f. Every menu takes 2 of the 40 components a message
+// may have.
+func (f leaderFilter) filterMenus() []discord.ContainerSubComponent {
+	tierOpts := make([]discord.StringSelectMenuOption, 0, 5)
+	for _, t := range []ci6ndex.Tier{ci6ndex.S, ci6ndex.A, ci6ndex.B, ci6ndex.C, ci6ndex.F} {
+		v := int(t.Value())
+		inRange := f.minTier > 0 && v >= f.minTier && v <= f.maxTier
+		tierOpts = append(tierOpts, discord.NewStringSelectMenuOption(t.Name(), strconv.Itoa(v)).WithDefault(inRange))
+	}
+	tierMenu := discord.NewStringSelectMenu(f.filterRoute("tier"), "Tier range: any", tierOpts...).
+		WithMinValues(0).
+		WithMaxValues(len(tierOpts))
+
+	statusOpt := func(label, value string, selected bool) discord.StringSelectMenuOption {
+		return discord.NewStringSelectMenuOption(label, value).WithDefault(selected)
+	}
+	statusMenu := discord.NewStringSelectMenu(f.filterRoute("status"), "Status: any",
+		statusOpt("Banned", statusBanned, f.banned == 'y'),
+		statusOpt("Not banned", statusAllowed, f.banned == 'n'),
+		statusOpt("Unranked", statusUnranked, f.unranked == 'y'),
+		statusOpt("Ranked", statusRanked, f.unranked == 'n'),
+		statusOpt("Has a guide", statusGuide, f.guide == 'y'),
+		statusOpt("No guide", statusNoGuide, f.guide == 'n'),
+	).WithMinValues(0).WithMaxValues(6)
+
+	versionMenu := discord.NewStringSelectMenu(f.filterRoute("version"), "Game version",
+		discord.NewStringSelectMenuOption("All leaders", strconv.Itoa(int(ci6ndex.VersionAll))).
+			WithDefault(f.version == ci6ndex.VersionAll),
+		discord.NewStringSelectMenuOption("Vanilla only", strconv.Itoa(int(ci6ndex.VersionVanilla))).
+			WithDefault(f.version == ci6ndex.VersionVanilla),
+		discord.NewStringSelectMenuOption("BBG Expanded only", strconv.Itoa(int(ci6ndex.VersionBBGExpanded))).
+			WithDefault(f.version == ci6ndex.VersionBBGExpanded),
+	)
+
+	sortMenu := discord.NewStringSelectMenu(f.filterRoute("sort"), "Sort by",
+		discord.NewStringSelectMenuOption("Sort by name", strconv.Itoa(int(ci6ndex.SortByName))).
+			WithDefault(f.sort == ci6ndex.SortByName),
+		discord.NewStringSelectMenuOption("Sort by tier", strconv.Itoa(int(ci6ndex.SortByTier))).
+			WithDefault(f.sort == ci6ndex.SortByTier),
+		discord.NewStringSelectMenuOption("Sort by number of ratings", strconv.Itoa(int(ci6ndex.SortByRatings))).
+			WithDefault(f.sort == ci6ndex.SortByRatings),
+		discord.NewStringSelectMenuOption("Sort by win rate", strconv.Itoa(int(ci6ndex.SortByWinRate))).
+			WithDefault(f.sort == ci6ndex.SortByWinRate),
+	)
+
+	return []discord.ContainerSubComponent{
+		discord.NewActionRow(tierMenu),
+		discord.NewActionRow(statusMenu),
+		discord.NewActionRow(versionMenu),
+		discord.NewActionRow(sortMenu),
+	}
+}
+
+func (f leaderFilter) filterRoute(field string) string {
+	return fmt.Sprintf("/leaders/filter/%s/%s", f.encode(), field)
+}
+
+// withSelection returns f with field changed to the values picked in its select menu.
+func (f leaderFilter) withSelection(field string, values []string) leaderFilter {
+	switch field {
+	case "tier":
+		f.minTier, f.maxTier = 0, 0
+		for _, v := range values {
+			t, err := strconv.Atoi(v)
+			if err != nil || t < 1 || t > 5 {
+				continue
+			}
+			if f.minTier == 0 || t < f.minTier {
+				f.minTier = t
+			}
+			f.maxTier = max(f.maxTier, t)
+		}
+	case "status":
+		pick := func(yes, no string) byte {
+			hasYes, hasNo := slices.Contains(values, yes), slices.Contains(values, no)
+			switch {
+			case hasYes && !hasNo:
+				return 'y'
+			case hasNo && !hasYes:
+				return 'n'
+			default:
+				return '-'
+			}
+		}
+		f.banned = pick(statusBanned, statusAllowed)
+		f.unranked = pick(statusUnranked, statusRanked)
+		f.guide = pick(statusGuide, statusNoGuide)
+	case "version":
+		if len(values) > 0 {
+			if v, err := strconv.Atoi(values[0]); err == nil && v >= 0 && v <= int(ci6ndex.VersionBBGExpanded) {
+				f.version = ci6ndex.LeaderVersion(v)
+			}
+		}
+	case "sort":
+		if len(values) > 0 {
+			if v, err := strconv.Atoi(values[0]); err == nil && v >= 0 && v <= int(ci6ndex.SortByWinRate) {
+				f.sort = ci6ndex.LeaderSort(v)
+			}
+		}
+	}
+	return f
+}
+
+func (b *Bot) handleLeaderFilterMenuSelectCommand() handler.SelectMenuComponentHandler {
+	return func(data discord.SelectMenuInteractionData, e *handler.ComponentEvent) error {
+		guildID, err := parseGuildId(e.GuildID().String())
+		if err != nil {
+			return err
+		}
+		selectData, ok := data.(discord.StringSelectMenuInteractionData)
+		if !ok {
+			return fmt.Errorf("unexpected select menu type %T", data)
+		}
+		filter := decodeLeaderFilter(e.Vars["filter"]).withSelection(e.Vars["field"], selectData.Values)
+		return b.updateLeadersScreen(e, guildID, filter, defaultPage)
+	}
+}
+
+func (b *Bot) handleLeaderBrowseButtonCommand() handler.ButtonComponentHandler {
+	return func(bid discord.ButtonInteractionData, e *handler.ComponentEvent) error {
+		guildID, err := parseGuildId(e.GuildID().String())
+		if err != nil {
+			return err
+		}
+		page, err := strconv.ParseUint(e.Vars["page"], 10, 64)
+		if err != nil || page == 0 {
+			slog.Debug("failed to parse page", "page", e.Vars["page"], "error", err)
+			page = defaultPage
+		}
+		return b.updateLeadersScreen(e, guildID, decodeLeaderFilter(e.Vars["filter"]), page)
+	}
+}
+
+func (b *Bot) handleLeaderCivFilterButtonCommand() handler.ButtonComponentHandler {
+	return func(bid discord.ButtonInteractionData, e *handler.ComponentEvent) error {
+		return e.Modal(discord.ModalCreate{
+			CustomID: bid.CustomID(),
+			Title:    "Filter by civ",
+			Components: []discord.LayoutComponent{
+				discord.NewLabel("Civ name", discord.TextInputComponent{
+					CustomID:    civFilterInput,
+					Style:       discord.TextInputStyleShort,
+					MaxLength:   50,
+					Placeholder: "e.g. Byzantium, leave empty for every civ",
+				}),
+			},
+		})
+	}
+}
+
+func (b *Bot) handleLeaderCivFilterModal() handler.ModalHandler {
+	return func(e *handler.ModalEvent) error {
+		guildID, err := parseGuildId(e.GuildID().String())
+		if err != nil {
+			return err
+		}
+		filter := decodeLeaderFilter(e.Vars["filter"])
+		filter.civ = 0
+
+		if civ := strings.TrimSpace(e.Data.Text(civFilterInput)); civ != "" {
+			leaders, err := b.Leaders(guildID)
+			if err != nil {
+				return err
+			}
+			matches := ci6ndex.SearchLeaders(leaders, civ, ci6ndex.SearchCivName)
+			if len(matches) == 0 {
+				return e.CreateMessage(discord.NewMessageCreate().
+					WithEphemeral(true).
+					WithContent(fmt.Sprintf("No civ matches %q, try another search", civ)))
+			}
+			filter.civ = matches[0].Leader.ID
+		}
+
+		components, err := b.leadersScreen(guildID, filter, defaultPage)
+		if err != nil {
+			return err
+		}
+		if err := e.UpdateMessage(discord.MessageUpdate{
+			Components: &components,
+		}); err != nil {
+			return errors.Join(err, errors.New("failed to update leaders screen"))
+		}
+		return nil
+	}
+}
+
+func (b *Bot) updateLeadersScreen(e *handler.ComponentEvent, guildID uint64, filter leaderFilter, page uint64) error {
+	components, err := b.leadersScreen(guildID, filter, page)
+	if err != nil {
+		return err
+	}
+	if err := e.UpdateMessage(discord.MessageUpdate{
+		Components: &components,
+	}); err != nil {
+		return errors.Join(err, errors.New("failed to update leaders screen"))
+	}
+	return nil
+}
@@ -0,0 +1,80 @@
+package bot
+
+import (
+	"ci6ndex/ci6ndex"
+	"ci6ndex/metrics"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+)
+
+// routeWord matches the custom ID segments that are part of a route rather than a value filled into it.
+var routeWord = regexp.MustCompile(`^[a-z][a-z_-]*$`)
+
+// instrumentInteractions counts the interactions and handler errors per route.
+func (b *Bot) instrumentInteractions(next handler.Handler) handler.Handler {
+	return func(e *handler.InteractionEvent) error {
+		kind, path := interactionRoute(e)
+		route := routeLabel(path)
+		metrics.Interactions.Inc(kind, route)
+		err := next(e)
+		if err != nil {
+			metrics.HandlerErrors.Inc(kind, route)
+		}
+		return err
+	}
+}
+
+// interactionRoute returns the type of an interaction and the path it's routed by, the same way handler.Mux does.
+func interactionRoute(e *handler.InteractionEvent) (string, string) {
+	switch i := e.Interaction.(type) {
+	case discord.ApplicationCommandInteraction:
+		return "command", "/" + i.Data.CommandName()
+	case discord.AutocompleteInteraction:
+		return "autocomplete", "/" + i.Data.CommandName
+	case discord.ComponentInteraction:
+		return "component", i.Data.CustomID()
+	case discord.ModalSubmitInteraction:
+		return "modal", i.Data.CustomID
+	}
+	return fmt.Sprintf("interaction_%d", e.Type()), ""
+}
+
+// routeLabel replaces the ids and encoded values in a custom ID with placeholders, so every message built from the
+// same route is counted together, e.g. /leaders/12/docs becomes /leaders/{id}/docs.
+func routeLabel(path string) string {
+	segments := strings.Split(path, "/")
+	for i, s := range segments {
+		switch {
+		case s == "" || routeWord.MatchString(s):
+		case strings.Trim(s, "0123456789") == "":
+			segments[i] = "{id}"
+		default:
+			segments[i] = "{value}"
+		}
+	}
+	return strings.Join(segments, "/")
+}
+
+// rollFailureType names the reason a draft roll failed for metrics.RollFailures.
+func rollFailureType(err error) string {
+	var notDiverse ci6ndex.NotDiverseEnoughError
+	var teamRule ci6ndex.TeamRuleError
+	var outOfChoices ci6ndex.RanOutOfChoicesError
+	var noOptions ci6ndex.NoGameSettingOptionsError
+	switch {
+	case errors.As(err, &notDiverse):
+		return "not_diverse_enough"
+	case errors.As(err, &teamRule):
+		return "team_rule"
+	case errors.As(err, &outOfChoices):
+		return "ran_out_of_choices"
+	case errors.As(err, &noOptions):
+		return "no_game_setting_options"
+	}
+	return "other"
+}
@@ -0,0 +1,184 @@
+package bot
+
+import (
+	"ci6ndex/ci6ndex"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/disgo/rest"
+)
+
+type requestIDKey struct{}
+
+// requestID returns the ID logInteractions assigned to the interaction ctx belongs to.
+func requestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+func newRequestID() string {
+	b := make([]byte, 4)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// logInteractions assigns every interaction a request ID and logs its route, user, guild and latency. Errors returned
+// by the handler are logged and shown to the user as an ephemeral error card with the request ID, so they can be
+// found in the logs.
+func logInteractions(next handler.Handler) handler.Handler {
+	return func(e *handler.InteractionEvent) error {
+		id := newRequestID()
+		e.Ctx = context.WithValue(e.Ctx, requestIDKey{}, id)
+
+		// the error card is a followup once the handler has responded, e.g. after deferring
+		var responded atomic.Bool
+		respond := e.Respond
+		e.Respond = func(t discord.InteractionResponseType, data discord.InteractionResponseData,
+			opts ...rest.RequestOpt) error {
+			err := respond(t, data, opts...)
+			if err == nil {
+				responded.Store(true)
+			}
+			return err
+		}
+
+		kind, path := interactionRoute(e)
+		start := time.Now()
+		err := next(e)
+		attrs := []any{
+			"request", id,
+			"type", kind,
+			"route", path,
+			"user", e.User().ID,
+			"guild", e.GuildID(),
+			"latency", time.Since(start),
+		}
+		if err == nil {
+			slog.Info("handled interaction", attrs...)
+			return nil
+		}
+
+		attrs = append(attrs, "error", err)
+		if desc, ok := errorDescription(err); ok && desc != err.Error() {
+			attrs = append(attrs, "details", desc)
+		}
+		slog.Error("interaction failed", attrs...)
+		// autocomplete can only answer with choices
+		if kind == "autocomplete" {
+			return nil
+		}
+		if err := reportError(e, id, err, responded.Load()); err != nil {
+			slog.Error("failed to report interaction error", "request", id, "error", err)
+		}
+		return nil
+	}
+}
+
+// recoverPanics turns a panicking handler into an error, so it's reported like any other.
+func recoverPanics(next handler.Handler) handler.Handler {
+	return func(e *handler.InteractionEvent) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				slog.Error("handler panicked", "request", requestID(e.Ctx), "panic", r, "stack", string(debug.Stack()))
+				err = fmt.Errorf("handler panicked: %v", r)
+			}
+		}()
+		return next(e)
+	}
+}
+
+func reportError(e *handler.InteractionEvent, id string, err error, responded bool) error {
+	msg := discord.MessageCreate{
+		Flags:      discord.MessageFlagIsComponentsV2 | discord.MessageFlagEphemeral,
+		Components: errorCard(id, friendlyError(err)),
+	}
+	if responded {
+		_, err := e.CreateFollowupMessage(msg)
+		return err
+	}
+	return e.CreateMessage(msg)
+}
+
+func errorCard(id, message string) []discord.LayoutComponent {
+	return []discord.LayoutComponent{
+		discord.NewContainer(
+			discord.NewTextDisplayf("### %s Something went wrong\n%s\n-# Request ID `%s`", warning, message, id),
+		).WithAccentColor(colorError),
+	}
+}
+
+// friendlyError explains the domain errors players can run into, everything else gets a generic message.
+func friendlyError(err error) string {
+	var (
+		outOfChoices    ci6ndex.RanOutOfChoicesError
+		notDiverse      ci6ndex.NotDiverseEnoughError
+		teamRule        ci6ndex.TeamRuleError
+		noOptions       ci6ndex.NoGameSettingOptionsError
+		notYourTurn     ci6ndex.NotYourTurnError
+		unavailable     ci6ndex.LeaderUnavailableError
+		invalidTeams    ci6ndex.InvalidTeamCountError
+		invalidNight    ci6ndex.InvalidGameNightError
+		invalidSettings ci6ndex.RollSettingsValidationError
+		invalidDocument ci6ndex.DocumentValidationError
+	)
+	switch {
+	case errors.As(err, &outOfChoices):
+		return "There aren't enough leaders left to roll for everyone. Unban some leaders or lower the number of " +
+			"offerings in the roll settings."
+	case errors.As(err, &notDiverse):
+		return fmt.Sprintf("Couldn't roll offerings spanning %d categories for everyone, try lowering the "+
+			"diversity in the roll settings.", notDiverse.MinCategories)
+	case errors.As(err, &teamRule):
+		return fmt.Sprintf("Couldn't roll offerings with %s, try loosening the roll settings or splitting the "+
+			"teams again.", teamRule.Rule.Description())
+	case errors.As(err, &noOptions):
+		return fmt.Sprintf("Every %s is blocked, unblock one in the game settings.", noOptions.Category.Name())
+	case errors.As(err, &notYourTurn):
+		return fmt.Sprintf("It's %s's turn to pick.", notYourTurn.Current.Username)
+	case errors.As(err, &unavailable):
+		return "That leader was already picked or is banned, pick another one."
+	case errors.As(err, &invalidTeams):
+		return fmt.Sprintf("Can't split %d players into %d teams.", invalidTeams.Players, invalidTeams.Teams)
+	case errors.As(err, &invalidNight):
+		return sentence(invalidNight.Reason)
+	case errors.As(err, &invalidSettings):
+		return sentence(invalidSettings.Reason)
+	case errors.As(err, &invalidDocument):
+		return sentence(invalidDocument.Reason)
+	case errors.Is(err, ci6ndex.ErrNotInDraft):
+		return "You aren't registered for the draft."
+	case errors.Is(err, ci6ndex.ErrDraftComplete):
+		return "Every player has already picked."
+	case errors.Is(err, ci6ndex.ErrNotEnoughPlayers):
+		return "A draft needs at least two players, select them under New Draft first."
+	case errors.Is(err, ci6ndex.ErrDocumentPending):
+		return "That guide is still waiting for an admin to approve it."
+	case errors.Is(err, ci6ndex.ErrNoRSVPs):
+		return "Nobody has said yes yet."
+	case errors.Is(err, ci6ndex.ErrNotSnakeDraft), errors.Is(err, ci6ndex.ErrNotBlindDraft):
+		return "The draft has moved on, open it again from /draft."
+	}
+	return "That didn't work, try again in a bit."
+}
+
+// sentence capitalizes a reason and ends it with a period.
+func sentence(reason string) string {
+	if reason == "" {
+		return reason
+	}
+	reason = strings.ToUpper(reason[:1]) + reason[1:]
+	if !strings.HasSuffix(reason, ".") {
+		reason += "."
+	}
+	return reason
+}
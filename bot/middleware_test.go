@@ -0,0 +1,121 @@
+package bot
+
+import (
+	"ci6ndex/ci6ndex"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/events"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/disgo/rest"
+)
+
+// response is what a handler answered an interaction with.
+type response struct {
+	kind discord.InteractionResponseType
+	data discord.InteractionResponseData
+}
+
+// testInteraction builds a button click on customID, recording the responses to it.
+func testInteraction(t *testing.T, customID string) (*handler.InteractionEvent, *[]response) {
+	t.Helper()
+	interaction, err := discord.UnmarshalInteraction([]byte(fmt.Sprintf(`{
+		"id": "1",
+		"application_id": "2",
+		"type": 3,
+		"token": "token",
+		"version": 1,
+		"guild_id": "3",
+		"channel_id": "4",
+		"member": {"user": {"id": "5", "username": "player"}, "roles": [], "joined_at": "2024-01-01T00:00:00Z"},
+		"data": {"custom_id": %q, "component_type": 2}
+	}`, customID)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var responses []response
+	e := &handler.InteractionEvent{
+		InteractionCreate: &events.InteractionCreate{
+			GenericEvent: events.NewGenericEvent(nil, 0, 0),
+			Interaction:  interaction,
+			Respond: func(kind discord.InteractionResponseType, data discord.InteractionResponseData,
+				opts ...rest.RequestOpt) error {
+				responses = append(responses, response{kind: kind, data: data})
+				return nil
+			},
+		},
+		Ctx:  context.Background(),
+		Vars: map[string]string{},
+	}
+	return e, &responses
+}
+
+func errorCardText(t *testing.T, r response) string {
+	t.Helper()
+	msg, ok := r.data.(discord.MessageCreate)
+	if !ok || r.kind != discord.InteractionResponseTypeCreateMessage {
+		t.Fatalf("expected an error message, got %v %T", r.kind, r.data)
+	}
+	if !msg.Flags.Has(discord.MessageFlagEphemeral) {
+		t.Error("expected the error card to be ephemeral")
+	}
+	container := msg.Components[0].(discord.ContainerComponent)
+	return container.Components[0].(discord.TextDisplayComponent).Content
+}
+
+func TestLogInteractions_ReportsErrors(t *testing.T) {
+	e, responses := testInteraction(t, "/snake/1/pick")
+	var id string
+	h := logInteractions(recoverPanics(func(e *handler.InteractionEvent) error {
+		id = requestID(e.Ctx)
+		return errors.Join(ci6ndex.RanOutOfChoicesError{}, errors.New("failed to roll"))
+	}))
+	if err := h(e); err != nil {
+		t.Fatalf("expected the error to be handled, got %v", err)
+	}
+	if id == "" {
+		t.Fatal("expected a request id")
+	}
+	if len(*responses) != 1 {
+		t.Fatalf("expected 1 response, got %d", len(*responses))
+	}
+	text := errorCardText(t, (*responses)[0])
+	if !strings.Contains(text, id) || !strings.Contains(text, "enough leaders") {
+		t.Errorf("expected the friendly message and request id %s, got %q", id, text)
+	}
+}
+
+func TestLogInteractions_RecoversPanics(t *testing.T) {
+	e, responses := testInteraction(t, "/draft")
+	h := logInteractions(recoverPanics(func(e *handler.InteractionEvent) error {
+		var teams map[int]int
+		teams[0] = 1
+		return nil
+	}))
+	if err := h(e); err != nil {
+		t.Fatalf("expected the panic to be handled, got %v", err)
+	}
+	if len(*responses) != 1 {
+		t.Fatalf("expected 1 response, got %d", len(*responses))
+	}
+	if text := errorCardText(t, (*responses)[0]); !strings.Contains(text, "try again") {
+		t.Errorf("expected the generic message, got %q", text)
+	}
+}
+
+func TestLogInteractions_Success(t *testing.T) {
+	e, responses := testInteraction(t, "/draft")
+	h := logInteractions(func(e *handler.InteractionEvent) error {
+		return e.DeferUpdateMessage()
+	})
+	if err := h(e); err != nil {
+		t.Fatal(err)
+	}
+	if len(*responses) != 1 || (*responses)[0].kind != discord.InteractionResponseTypeDeferredUpdateMessage {
+		t.Errorf("expected only the handler's response, got %v", *responses)
+	}
+}
@@ -0,0 +1,249 @@
+package bot
+
+import (
+	"bytes"
+	"ci6ndex/ci6ndex"
+	"ci6ndex/ci6ndex/generated"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	md "github.com/nao1215/markdown"
+)
+
+const (
+	myTiersViewTiers    = "tiers"
+	myTiersViewUnrated  = "unrated"
+	myTiersViewHotTakes = "hot-takes"
+	// how many hot takes to show
+	hotTakesLimit = 10
+	// keep well under the 4000 character limit of a single message
+	maxTextDisplayLength = 3500
+)
+
+func (b *Bot) handleMyTiersSlashCommand() handler.SlashCommandHandler {
+	return func(data discord.SlashCommandInteractionData, e *handler.CommandEvent) error {
+		guildID, err := parseGuildId(e.GuildID().String())
+		if err != nil {
+			return err
+		}
+		bbg := data.String("ruleset") == "bbg"
+		if data.String("ruleset") == "" {
+			if bbg, err = b.Ci6ndex.DefaultsToBBG(guildID, int64(e.User().ID)); err != nil {
+				return err
+			}
+		}
+		components, err := b.myTiersScreen(guildID, e.User(), bbg, myTiersViewTiers)
+		if err != nil {
+			return err
+		}
+
+		flags := discord.MessageFlagIsComponentsV2
+		flags = flags.Add(discord.MessageFlagEphemeral)
+		if err := e.CreateMessage(discord.MessageCreate{
+			Flags:      flags,
+			Components: components,
+		}); err != nil {
+			return errors.Join(err, errors.New("failed to create my tiers screen"))
+		}
+		return nil
+	}
+}
+
+func (b *Bot) handleMyTiersButtonCommand() handler.ButtonComponentHandler {
+	return func(bid discord.ButtonInteractionData, e *handler.ComponentEvent) error {
+		guildID, err := parseGuildId(e.GuildID().String())
+		if err != nil {
+			return err
+		}
+		components, err := b.myTiersScreen(guildID, e.User(), e.Vars["ruleset"] == "bbg", e.Vars["view"])
+		if err != nil {
+			return err
+		}
+
+		if err := e.UpdateMessage(discord.MessageUpdate{
+			Components: &components,
+		}); err != nil {
+			return errors.Join(err, errors.New("failed to update my tiers screen"))
+		}
+		return nil
+	}
+}
+
+// myTiersScreen shows the player's ranks under the BBG ruleset when bbg is set, the vanilla ones otherwise.
+func (b *Bot) myTiersScreen(guildID uint64, user discord.User, bbg bool,
+	view string) ([]discord.LayoutComponent, error) {
+	playerID := int64(user.ID)
+	ranks, err := b.Ci6ndex.GetPlayerRanks(guildID, playerID, bbg)
+	if err != nil {
+		return nil, errors.Join(err, errors.New("failed to fetch player ranks"))
+	}
+	unrated, err := b.Ci6ndex.GetUnratedLeaders(guildID, playerID, bbg)
+	if err != nil {
+		return nil, errors.Join(err, errors.New("failed to fetch unrated leaders"))
+	}
+
+	ruleset, other, otherLabel := "vanilla", "bbg", "Show BBG"
+	if bbg {
+		ruleset, other, otherLabel = other, ruleset, "Show Vanilla"
+	}
+	var header, body bytes.Buffer
+	err = renderMyTiersHeader(&header, bbg, len(ranks), len(ranks)+len(unrated))
+	if err != nil {
+		return nil, errors.Join(err, errors.New("failed to render my tiers header"))
+	}
+
+	switch view {
+	case myTiersViewUnrated:
+		err = renderUnratedLeaders(&body, unrated)
+	case myTiersViewHotTakes:
+		takes, hotErr := b.Ci6ndex.GetHotTakes(guildID, playerID, bbg, hotTakesLimit)
+		if hotErr != nil {
+			return nil, errors.Join(hotErr, errors.New("failed to fetch hot takes"))
+		}
+		err = renderHotTakes(&body, takes)
+	default:
+		view = myTiersViewTiers
+		err = renderPersonalTierList(&body, ranks)
+	}
+	if err != nil {
+		return nil, errors.Join(err, fmt.Errorf("failed to render %s view", view))
+	}
+
+	viewButton := func(label, target string) discord.ButtonComponent {
+		button := discord.NewSecondaryButton(label, fmt.Sprintf("/mytiers/%s/%s", ruleset, target))
+		if target == view {
+			button = button.AsDisabled()
+		}
+		return button
+	}
+	actions := discord.NewActionRow(
+		viewButton("Tier List", myTiersViewTiers),
+		viewButton("Unrated", myTiersViewUnrated),
+		viewButton("Hot Takes", myTiersViewHotTakes),
+		discord.NewSecondaryButton(otherLabel, fmt.Sprintf("/mytiers/%s/%s", other, view)),
+	)
+	if len(unrated) > 0 {
+		actions = actions.AddComponents(
+			discord.NewPrimaryButton("Rate Next", fmt.Sprintf("/leaders/%d", unrated[0].ID)).
+				WithEmoji(discord.ComponentEmoji{Name: notebook}),
+		)
+	}
+
+	return []discord.LayoutComponent{
+		discord.NewContainer().AddComponents(
+			discord.NewSection(
+				discord.NewTextDisplay(header.String()),
+			).WithAccessory(discord.NewThumbnail(user.EffectiveAvatarURL())),
+			discord.NewLargeSeparator(),
+			discord.NewTextDisplay(truncateText(body.String(), maxTextDisplayLength)),
+			discord.NewLargeSeparator(),
+			actions,
+		).WithAccentColor(colorSuccess),
+	}, nil
+}
+
+func renderMyTiersHeader(header io.Writer, bbg bool, rated, total int) error {
+	completion := 0
+	if total > 0 {
+		completion = rated * 100 / total
+	}
+	title := "Your Tiers (Vanilla)"
+	if bbg {
+		title = "Your Tiers (BBG)"
+	}
+	return md.NewMarkdown(header).H1(title).
+		PlainTextf("You have rated %d of %d leaders (%d%%).", rated, total, completion).
+		Build()
+}
+
+func renderPersonalTierList(output io.Writer, ranks []ci6ndex.PlayerRank) error {
+	mdBuilder := md.NewMarkdown(output).H2("Personal Tier List")
+	if len(ranks) == 0 {
+		mdBuilder.PlainText("You haven't rated any leaders yet. Use **Rate Next** to get started.")
+		return mdBuilder.Build()
+	}
+
+	// ranks are sorted best tier first
+	var names []string
+	current, err := ci6ndex.GetTierByValue(ranks[0].Tier)
+	if err != nil {
+		return err
+	}
+	for _, r := range ranks {
+		tier, err := ci6ndex.GetTierByValue(r.Tier)
+		if err != nil {
+			return err
+		}
+		if tier.Value() != current.Value() {
+			mdBuilder.PlainTextf("**%s**: %s", current.Name(), strings.Join(names, ", "))
+			current, names = tier, nil
+		}
+		names = append(names, ci6ndex.LeaderName(r.Leader))
+	}
+	mdBuilder.PlainTextf("**%s**: %s", current.Name(), strings.Join(names, ", "))
+	return mdBuilder.Build()
+}
+
+func renderUnratedLeaders(output io.Writer, unrated []generated.Leader) error {
+	mdBuilder := md.NewMarkdown(output).H2("Not Rated Yet")
+	if len(unrated) == 0 {
+		mdBuilder.PlainText("You have rated every leader. Nice!")
+		return mdBuilder.Build()
+	}
+
+	names := make([]string, len(unrated))
+	for i, l := range unrated {
+		names[i] = ci6ndex.LeaderName(l)
+	}
+	mdBuilder.PlainTextf("%d leader(s) left: %s", len(unrated), strings.Join(names, ", "))
+	return mdBuilder.Build()
+}
+
+func renderHotTakes(output io.Writer, takes []ci6ndex.PlayerRank) error {
+	mdBuilder := md.NewMarkdown(output).H2("Hot Takes").
+		PlainText("Where your ranks disagree the most with the community.")
+	if len(takes) == 0 {
+		mdBuilder.PlainText("No disagreements yet, you agree with everyone!")
+		return mdBuilder.Build()
+	}
+
+	for _, t := range takes {
+		mine, err := ci6ndex.GetTierByValue(t.Tier)
+		if err != nil {
+			return err
+		}
+		community, err := ci6ndex.GetTierByValue(t.Community)
+		if err != nil {
+			return err
+		}
+		direction := "higher"
+		if t.Deviation > 0 {
+			direction = "lower"
+		}
+		mdBuilder.PlainTextf("- **%s**: you %s, community %s (%.2f) — %.1f tiers %s",
+			ci6ndex.LeaderName(t.Leader), mine.Name(), community.Name(), t.Community,
+			math.Abs(t.Deviation), direction)
+	}
+	return mdBuilder.Build()
+}
+
+// truncateText cuts text to at most limit bytes on a line boundary so discord doesn't reject the message.
+func truncateText(text string, limit int) string {
+	if len(text) <= limit {
+		return text
+	}
+	cut := strings.LastIndex(text[:limit], "\n")
+	if cut <= 0 {
+		cut = limit
+		for cut > 0 && !utf8.RuneStart(text[cut]) {
+			cut--
+		}
+	}
+	return text[:cut] + "\n…"
+}
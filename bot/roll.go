@@ -2,8 +2,12 @@ package bot
 
 import (
 	"ci6ndex/ci6ndex"
+	"ci6ndex/ci6ndex/generated"
+	"ci6ndex/metrics"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 
 	"github.com/disgoorg/disgo/discord"
 	"github.com/disgoorg/disgo/handler"
@@ -14,12 +18,7 @@ func (b *Bot) handleConfirmRollDraft() handler.ButtonComponentHandler {
 		slog.Info("handleConfirmRollDraft")
 		err := e.DeferCreateMessage(true)
 		if err != nil {
-			slog.Error("Failed to defer message", "error", err)
-			desc, ok := errorDescription(err)
-			if ok {
-				slog.Error(desc)
-			}
-			return err
+			return errors.Join(err, errors.New("failed to defer message"))
 		}
 
 		guild, err := parseGuildId(e.GuildID().String())
@@ -35,39 +34,66 @@ func (b *Bot) handleConfirmRollDraft() handler.ButtonComponentHandler {
 		for i, player := range players {
 			playerIds[i] = player.ID
 		}
-		var rules = make([]ci6ndex.Rule, 0)
-		rules = append(rules, &ci6ndex.MinTierRule{MinTier: 3})
-		rules = append(rules, &ci6ndex.NoOpRule{})
-		rules = append(rules, &ci6ndex.NoOpRule{})
-		rules = append(rules, &ci6ndex.NoOpRule{})
-		rules = append(rules, &ci6ndex.NoOpRule{})
-		offers, err := b.Ci6ndex.RollForPlayers(
-			guild,
-			playerIds,
-			rules,
-		)
+		settings, err := b.Ci6ndex.GetRollSettings(guild)
+		if err != nil {
+			return err
+		}
+		teams, err := b.Ci6ndex.GetTeamsForActiveDraft(guild)
 		if err != nil {
-			slog.Error("Failed to roll for players", "error", err)
-			desc, ok := errorDescription(err)
-			if ok {
-				slog.Error(desc)
-			}
 			return err
 		}
-		slog.Info("handleConfirmRollDraft", "offers", offers)
-		rows := make([]discord.ContainerSubComponent, len(offers))
-		for i, offer := range offers {
-			leaderStr := ""
-			for _, leader := range offer.Leaders {
-				leaderStr += fmt.Sprintf("%s %s,", leader.DiscordEmojiString.String, leaderDisplayName(leader))
+
+		var offers []ci6ndex.Offering
+		var summary string
+		if len(teams) > 0 {
+			grouped, ok := teamPlayerIds(teams, playerIds)
+			if !ok {
+				_, err = e.CreateFollowupMessage(discord.NewMessageCreate().
+					WithEphemeral(true).
+					WithContent("Some players aren't on a team yet, split the teams again with /teams."))
+				return err
 			}
-			// Strip final ,
-			leaderStr = leaderStr[:len(leaderStr)-1]
-			rows[i] = discord.NewTextDisplayf(
-				"<@%d>: %s",
-				offer.Player.ID, leaderStr,
+			offers, err = b.Ci6ndex.RollForTeams(
+				guild,
+				grouped,
+				settings.Rules(),
+				settings.TeamRules(),
+			)
+		} else {
+			var roll ci6ndex.BalancedRoll
+			roll, err = b.Ci6ndex.RollBalanced(
+				guild,
+				playerIds,
+				settings.Rules(),
+				settings.Balance(),
 			)
+			offers = roll.Offerings
+			if settings.BalanceMode != ci6ndex.BalanceOff {
+				summary = balanceSummary(settings, roll)
+			}
+		}
+		if err != nil {
+			metrics.RollFailures.Inc(rollFailureType(err))
+			return errors.Join(err, errors.New("failed to roll for players"))
 		}
+		// only a successful leader roll replaces the active draft's game settings, offerings and mode
+		gameSettings, err := b.Ci6ndex.RollGameSettings(guild)
+		if err != nil {
+			metrics.RollFailures.Inc(rollFailureType(err))
+			return errors.Join(err, errors.New("failed to roll game settings"))
+		}
+		if err := b.Ci6ndex.SaveOfferings(guild, offers); err != nil {
+			return errors.Join(err, errors.New("failed to save offerings"))
+		}
+		if err := b.Ci6ndex.SetDraftMode(guild, ci6ndex.DraftModeRandom); err != nil {
+			return err
+		}
+		slog.Info("handleConfirmRollDraft", "offers", offers, "teams", len(teams))
+		rows := offeringRows(offers, teams)
+		if summary != "" {
+			rows = append(rows, discord.NewSmallSeparator(), discord.NewTextDisplay(summary))
+		}
+		rows = append(rows, gameSettingsRows(gameSettings)...)
 
 		layout := []discord.LayoutComponent{
 			discord.NewContainer().AddComponents(rows...).WithAccentColor(colorSuccess),
@@ -79,13 +105,95 @@ func (b *Bot) handleConfirmRollDraft() handler.ButtonComponentHandler {
 			},
 		)
 		if err != nil {
-			slog.Error("Failed to create test message", "error", err)
-			desc, ok := errorDescription(err)
-			if ok {
-				slog.Error(desc)
-			}
-			return err
+			return errors.Join(err, errors.New("failed to create test message"))
 		}
 		return nil
 	}
 }
+
+// balanceSummary explains how even a balanced roll came out, and says so when it had to fall back to the most
+// balanced roll it found.
+func balanceSummary(settings ci6ndex.RollSettings, roll ci6ndex.BalancedRoll) string {
+	if roll.WithinTolerance {
+		return fmt.Sprintf("-# %s Balanced by %s strength: spread %g (tolerance %g)",
+			scales, balanceUnit(settings.BalanceMode), roll.Spread, settings.BalanceTolerance)
+	}
+	return fmt.Sprintf("-# %s No roll was within the tolerance of %g, this is the most balanced one found "+
+		"(spread %g by %s strength)", scales, settings.BalanceTolerance, roll.Spread, balanceUnit(settings.BalanceMode))
+}
+
+// teamPlayerIds groups the registered players by team. It fails when a player joined the draft after the teams were
+// made, since they'd have no team to be balanced against.
+func teamPlayerIds(teams []ci6ndex.Team, playerIds []int64) ([][]int64, bool) {
+	var grouped [][]int64
+	onTeam := 0
+	for _, team := range teams {
+		var ids []int64
+		for _, p := range team.Players {
+			if slices.Contains(playerIds, p.ID) {
+				ids = append(ids, p.ID)
+			}
+		}
+		// Teams whose players all left the draft don't need offerings
+		if len(ids) > 0 {
+			grouped = append(grouped, ids)
+			onTeam += len(ids)
+		}
+	}
+	return grouped, onTeam == len(playerIds)
+}
+
+// offeringRows lists every player's offering, grouped under their team when the draft has teams.
+func offeringRows(offers []ci6ndex.Offering, teams []ci6ndex.Team) []discord.ContainerSubComponent {
+	offerRow := func(offer ci6ndex.Offering) discord.ContainerSubComponent {
+		leaderStr := ""
+		for _, leader := range offer.Leaders {
+			leaderStr += fmt.Sprintf("%s %s,", leader.DiscordEmojiString.String, ci6ndex.LeaderName(leader))
+		}
+		// Strip final ,
+		leaderStr = leaderStr[:len(leaderStr)-1]
+		return discord.NewTextDisplayf(
+			"<@%d> (strength %g): %s",
+			offer.Player.ID, offer.Strength, leaderStr,
+		)
+	}
+
+	rows := make([]discord.ContainerSubComponent, 0, len(offers)+len(teams)+3)
+	if len(teams) == 0 {
+		for _, offer := range offers {
+			rows = append(rows, offerRow(offer))
+		}
+		return rows
+	}
+
+	grouped := make(map[int64]bool, len(offers))
+	for _, team := range teams {
+		strength := 0.0
+		for _, offer := range offers {
+			if slices.ContainsFunc(team.Players, func(p generated.Player) bool { return p.ID == offer.Player.ID }) {
+				strength += offer.Strength
+			}
+		}
+		rows = append(rows, discord.NewTextDisplayf("### Team %d (strength %g)", team.Number, strength))
+		for _, p := range team.Players {
+			for _, offer := range offers {
+				if offer.Player.ID == p.ID {
+					rows = append(rows, offerRow(offer))
+					grouped[p.ID] = true
+				}
+			}
+		}
+	}
+	// Players registered after the teams were made aren't on any team yet
+	var teamless []discord.ContainerSubComponent
+	for _, offer := range offers {
+		if !grouped[offer.Player.ID] {
+			teamless = append(teamless, offerRow(offer))
+		}
+	}
+	if len(teamless) > 0 {
+		rows = append(rows, discord.NewTextDisplay("### No Team"))
+		rows = append(rows, teamless...)
+	}
+	return rows
+}
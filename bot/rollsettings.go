@@ -0,0 +1,311 @@
+package bot
+
+import (
+	"bytes"
+	"ci6ndex/ci6ndex"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	md "github.com/nao1215/markdown"
+)
+
+const (
+	rollSettingPool      = "pool"
+	rollSettingTier      = "tier"
+	rollSettingMode      = "mode"
+	rollSettingDiversity = "diversity"
+	rollSettingBalance   = "balance"
+	rollSettingTolerance = "tolerance"
+	rollSettingTeamTag   = "team-tag"
+	rollSettingPlayerTag = "player-tag"
+	// tagOff is the tag menus' value for no tag, menu values can't be empty
+	tagOff = "none"
+)
+
+// balanceTolerances are the tolerances offered in the roll settings, in strength points.
+var balanceTolerances = []float64{0, 1, 2, 3, 5}
+
+func (b *Bot) handleRollSettingsButtonCommand() handler.ButtonComponentHandler {
+	return func(bid discord.ButtonInteractionData, e *handler.ComponentEvent) error {
+		guildID, err := parseGuildId(e.GuildID().String())
+		if err != nil {
+			return err
+		}
+		settings, err := b.Ci6ndex.GetRollSettings(guildID)
+		if err != nil {
+			return err
+		}
+		return b.updateRollSettingsScreen(e, settings, "")
+	}
+}
+
+func (b *Bot) handleRollSettingsMenuSelectCommand() handler.SelectMenuComponentHandler {
+	return func(data discord.SelectMenuInteractionData, e *handler.ComponentEvent) error {
+		guildID, err := parseGuildId(e.GuildID().String())
+		if err != nil {
+			return err
+		}
+		if !isAdmin(e.Member()) {
+			return e.CreateMessage(discord.NewMessageCreate().
+				WithEphemeral(true).
+				WithContent("Only admins can change the roll settings."))
+		}
+		selectData, ok := data.(discord.StringSelectMenuInteractionData)
+		if !ok || len(selectData.Values) == 0 {
+			return fmt.Errorf("unexpected select menu data %T", data)
+		}
+
+		settings, err := b.Ci6ndex.GetRollSettings(guildID)
+		if err != nil {
+			return err
+		}
+		settings, err = withRollSetting(settings, e.Vars["field"], selectData.Values[0])
+		if err != nil {
+			return err
+		}
+
+		notice := "Saved! The next draft will be rolled with these settings."
+		err = b.Ci6ndex.UpdateRollSettings(guildID, settings)
+		var validationErr ci6ndex.RollSettingsValidationError
+		if errors.As(err, &validationErr) {
+			notice = fmt.Sprintf("Not saved: %s.", validationErr.Reason)
+			settings, err = b.Ci6ndex.GetRollSettings(guildID)
+		}
+		if err != nil {
+			return err
+		}
+		return b.updateRollSettingsScreen(e, settings, notice)
+	}
+}
+
+// withRollSetting applies a selected menu value to settings. Switching the diversity mode clamps the diversity to
+// what the new mode can reach.
+func withRollSetting(settings ci6ndex.RollSettings, field, value string) (ci6ndex.RollSettings, error) {
+	switch field {
+	case rollSettingMode:
+		settings.DiversityMode = ci6ndex.DiversityMode(value)
+		settings.DiversityMin = min(settings.DiversityMin, ci6ndex.MaxDiversity(settings.DiversityMode))
+		return settings, nil
+	case rollSettingBalance:
+		settings.BalanceMode = ci6ndex.BalanceMode(value)
+		return settings, nil
+	case rollSettingTolerance:
+		tolerance, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			return settings, errors.Join(err, fmt.Errorf("failed to parse %s setting", field))
+		}
+		settings.BalanceTolerance = tolerance
+		return settings, nil
+	case rollSettingTeamTag:
+		if value == tagOff {
+			value = ""
+		}
+		settings.TeamTag = value
+		return settings, nil
+	case rollSettingPlayerTag:
+		if value == tagOff {
+			value = ""
+		}
+		settings.PlayerTag = value
+		return settings, nil
+	}
+
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return settings, errors.Join(err, fmt.Errorf("failed to parse %s setting", field))
+	}
+	switch field {
+	case rollSettingPool:
+		settings.PoolSize = n
+	case rollSettingTier:
+		settings.MinTier = float64(n)
+	case rollSettingDiversity:
+		settings.DiversityMin = n
+	default:
+		return settings, fmt.Errorf("unknown roll setting %q", field)
+	}
+	return settings, nil
+}
+
+func (b *Bot) updateRollSettingsScreen(e *handler.ComponentEvent, settings ci6ndex.RollSettings, notice string) error {
+	components, err := rollSettingsScreen(settings, notice)
+	if err != nil {
+		return err
+	}
+	if err := e.UpdateMessage(discord.MessageUpdate{
+		Components: &components,
+	}); err != nil {
+		return errors.Join(err, errors.New("failed to update roll settings screen"))
+	}
+	return nil
+}
+
+func rollSettingsScreen(settings ci6ndex.RollSettings, notice string) ([]discord.LayoutComponent, error) {
+	var header bytes.Buffer
+	if err := renderRollSettings(&header, settings, notice); err != nil {
+		return nil, errors.Join(err, errors.New("failed to render roll settings"))
+	}
+
+	route := func(field string) string {
+		return "/draft/settings/" + field
+	}
+
+	poolOpts := make([]discord.StringSelectMenuOption, 0, ci6ndex.MaxPoolSize)
+	for n := ci6ndex.MinPoolSize; n <= ci6ndex.MaxPoolSize; n++ {
+		poolOpts = append(poolOpts, discord.NewStringSelectMenuOption(fmt.Sprintf("%d leaders per player", n),
+			strconv.Itoa(n)).WithDefault(settings.PoolSize == n))
+	}
+
+	tierOpts := []discord.StringSelectMenuOption{
+		discord.NewStringSelectMenuOption("No tier guarantee", "0").WithDefault(settings.MinTier == 0),
+	}
+	for _, t := range []ci6ndex.Tier{ci6ndex.S, ci6ndex.A, ci6ndex.B, ci6ndex.C, ci6ndex.F} {
+		tierOpts = append(tierOpts, discord.NewStringSelectMenuOption(
+			fmt.Sprintf("At least one %s or better", t.Name()), strconv.Itoa(int(t.Value())),
+		).WithDefault(settings.MinTier == t.Value()))
+	}
+
+	modeMenu := discord.NewStringSelectMenu(route(rollSettingMode), "Diversity by",
+		discord.NewStringSelectMenuOption("Diversity by victory type", string(ci6ndex.DiversityByVictoryType)).
+			WithDescription(strings.Join(ci6ndex.VictoryTypes, ", ")).
+			WithDefault(settings.DiversityMode == ci6ndex.DiversityByVictoryType),
+		discord.NewStringSelectMenuOption("Diversity by play style", string(ci6ndex.DiversityByTag)).
+			WithDescription("Every play-style tag counts, e.g. naval or wonders").
+			WithDefault(settings.DiversityMode == ci6ndex.DiversityByTag),
+	)
+
+	diversityOpts := []discord.StringSelectMenuOption{
+		discord.NewStringSelectMenuOption("No diversity requirement", "0").WithDefault(settings.DiversityMin == 0),
+	}
+	for n := 2; n <= ci6ndex.MaxDiversity(settings.DiversityMode); n++ {
+		diversityOpts = append(diversityOpts, discord.NewStringSelectMenuOption(
+			fmt.Sprintf("Span at least %d %s", n, diversityUnit(settings.DiversityMode)), strconv.Itoa(n),
+		).WithDefault(settings.DiversityMin == n))
+	}
+
+	balanceMenu := discord.NewStringSelectMenu(route(rollSettingBalance), "Balance",
+		discord.NewStringSelectMenuOption("No balancing", string(ci6ndex.BalanceOff)).
+			WithDefault(settings.BalanceMode == ci6ndex.BalanceOff),
+		discord.NewStringSelectMenuOption("Balance total strength", string(ci6ndex.BalanceBySum)).
+			WithDescription("Every pool's tiers add up to about the same").
+			WithDefault(settings.BalanceMode == ci6ndex.BalanceBySum),
+		discord.NewStringSelectMenuOption("Balance best leader", string(ci6ndex.BalanceByBest)).
+			WithDescription("Every pool's strongest leader is about as strong").
+			WithDefault(settings.BalanceMode == ci6ndex.BalanceByBest),
+	)
+
+	toleranceOpts := make([]discord.StringSelectMenuOption, 0, len(balanceTolerances))
+	for _, t := range balanceTolerances {
+		toleranceOpts = append(toleranceOpts, discord.NewStringSelectMenuOption(
+			fmt.Sprintf("Allow a spread of %g", t), strconv.FormatFloat(t, 'g', -1, 64),
+		).WithDefault(settings.BalanceTolerance == t))
+	}
+
+	teamTagOpts := []discord.StringSelectMenuOption{
+		discord.NewStringSelectMenuOption("No team guarantee", tagOff).WithDefault(settings.TeamTag == ""),
+	}
+	for _, tag := range ci6ndex.PlayStyleTags {
+		teamTagOpts = append(teamTagOpts, discord.NewStringSelectMenuOption(
+			fmt.Sprintf("Every team gets a %s leader", tag), tag,
+		).WithDefault(settings.TeamTag == tag))
+	}
+
+	playerTagOpts := []discord.StringSelectMenuOption{
+		discord.NewStringSelectMenuOption("No play-style guarantee", tagOff).WithDefault(settings.PlayerTag == ""),
+	}
+	for _, tag := range ci6ndex.PlayStyleTags {
+		playerTagOpts = append(playerTagOpts, discord.NewStringSelectMenuOption(
+			fmt.Sprintf("Every player gets a %s leader", tag), tag,
+		).WithDefault(settings.PlayerTag == tag))
+	}
+
+	return []discord.LayoutComponent{
+		discord.NewContainer(
+			discord.NewTextDisplay(header.String()),
+			discord.NewSmallSeparator(),
+			discord.NewActionRow(discord.NewStringSelectMenu(route(rollSettingPool), "Pool size", poolOpts...)),
+			discord.NewActionRow(discord.NewStringSelectMenu(route(rollSettingTier), "Tier guarantee", tierOpts...)),
+			discord.NewActionRow(modeMenu),
+			discord.NewActionRow(discord.NewStringSelectMenu(route(rollSettingDiversity), "Diversity", diversityOpts...)),
+			discord.NewActionRow(discord.NewStringSelectMenu(route(rollSettingPlayerTag), "Play-style guarantee",
+				playerTagOpts...)),
+			discord.NewActionRow(balanceMenu),
+			discord.NewActionRow(discord.NewStringSelectMenu(route(rollSettingTolerance), "Balance tolerance",
+				toleranceOpts...)),
+			discord.NewActionRow(discord.NewStringSelectMenu(route(rollSettingTeamTag), "Team guarantee",
+				teamTagOpts...)),
+			discord.NewLargeSeparator(),
+			discord.NewActionRow(
+				discord.NewPrimaryButton("Back", "/draft").WithEmoji(discord.ComponentEmoji{
+					Name: backArrow,
+				}),
+			),
+		).WithAccentColor(colorSuccess),
+	}, nil
+}
+
+func diversityUnit(mode ci6ndex.DiversityMode) string {
+	if mode == ci6ndex.DiversityByTag {
+		return "play styles"
+	}
+	return "victory types"
+}
+
+func renderRollSettings(buffer io.Writer, settings ci6ndex.RollSettings, notice string) error {
+	mdBuilder := md.NewMarkdown(buffer).H2("Roll Settings").
+		PlainText("Every player is offered a pool of leaders built from these rules. Only admins can change them.").
+		PlainTextf("- **Pool size**: %d leaders", settings.PoolSize)
+
+	if settings.MinTier > 0 {
+		tier, err := ci6ndex.GetTierByValue(settings.MinTier)
+		if err != nil {
+			return err
+		}
+		mdBuilder.PlainTextf("- **Tier guarantee**: at least one %s or better", tier.Name())
+	} else {
+		mdBuilder.PlainText("- **Tier guarantee**: none")
+	}
+
+	if settings.PlayerTag != "" {
+		mdBuilder.PlainTextf("- **Play-style guarantee**: at least one %s leader", settings.PlayerTag)
+	} else {
+		mdBuilder.PlainText("- **Play-style guarantee**: none")
+	}
+
+	if settings.DiversityMin > 0 {
+		mdBuilder.PlainTextf("- **Diversity**: at least %d %s per player", settings.DiversityMin,
+			diversityUnit(settings.DiversityMode))
+	} else {
+		mdBuilder.PlainText("- **Diversity**: none")
+	}
+
+	if settings.BalanceMode != ci6ndex.BalanceOff {
+		mdBuilder.PlainTextf("- **Balance**: %s strength may differ by at most %g between players",
+			balanceUnit(settings.BalanceMode), settings.BalanceTolerance)
+	} else {
+		mdBuilder.PlainText("- **Balance**: none")
+	}
+
+	if settings.TeamTag != "" {
+		mdBuilder.PlainTextf("- **Team guarantee**: every team offered a %s leader", settings.TeamTag)
+	} else {
+		mdBuilder.PlainText("- **Team guarantee**: none")
+	}
+
+	if notice != "" {
+		mdBuilder.PlainTextf("\n*%s*", notice)
+	}
+	return mdBuilder.Build()
+}
+
+func balanceUnit(mode ci6ndex.BalanceMode) string {
+	if mode == ci6ndex.BalanceByBest {
+		return "best leader"
+	}
+	return "total"
+}
@@ -0,0 +1,250 @@
+package bot
+
+import (
+	"bytes"
+	"ci6ndex/ci6ndex"
+	"ci6ndex/ci6ndex/generated"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	snowflake "github.com/disgoorg/snowflake/v2"
+	md "github.com/nao1215/markdown"
+)
+
+var scheduleCommand = discord.SlashCommandCreate{
+	Name:        "schedule",
+	Description: "Schedule a game night players can RSVP to",
+	Options: []discord.ApplicationCommandOption{
+		discord.ApplicationCommandOptionString{
+			Name:        "date",
+			Description: "the day of the game, e.g. 2025-01-31",
+			Required:    true,
+		},
+		discord.ApplicationCommandOptionString{
+			Name:        "time",
+			Description: "when the game starts, e.g. 20:30",
+			Required:    true,
+		},
+		discord.ApplicationCommandOptionString{
+			Name:        "timezone",
+			Description: "the timezone of the date and time, e.g. Europe/Berlin (default UTC)",
+			Required:    false,
+		},
+	},
+}
+
+func (b *Bot) handleScheduleSlashCommand() handler.SlashCommandHandler {
+	return func(data discord.SlashCommandInteractionData, e *handler.CommandEvent) error {
+		guildID, err := parseGuildId(e.GuildID().String())
+		if err != nil {
+			return err
+		}
+		timezone := data.String("timezone")
+		if timezone == "" {
+			timezone = "UTC"
+		}
+
+		startsAt, err := ci6ndex.ParseGameNightTime(data.String("date"), data.String("time"), timezone, time.Now())
+		var invalid ci6ndex.InvalidGameNightError
+		if errors.As(err, &invalid) {
+			return e.CreateMessage(discord.NewMessageCreate().
+				WithEphemeral(true).
+				WithContent(invalid.Reason + "."))
+		}
+		if err != nil {
+			return err
+		}
+		if err := e.DeferCreateMessage(false); err != nil {
+			return err
+		}
+
+		night, err := b.Ci6ndex.ScheduleGameNight(guildID, startsAt, timezone, uint64(e.Channel().ID()),
+			int64(e.User().ID))
+		if err != nil {
+			return err
+		}
+		components, err := gameNightScreen(night)
+		if err != nil {
+			return err
+		}
+		msg, err := e.CreateFollowupMessage(discord.MessageCreate{
+			Flags:      discord.MessageFlagIsComponentsV2,
+			Components: components,
+		})
+		if err != nil {
+			return errors.Join(err, errors.New("failed to create game night"))
+		}
+		if err := b.Ci6ndex.SetGameNightMessage(guildID, night.ID, uint64(msg.ID)); err != nil {
+			return err
+		}
+		return b.scheduleGameNightReminder(guildID, night)
+	}
+}
+
+func (b *Bot) handleRSVPButtonCommand() handler.ButtonComponentHandler {
+	return func(bid discord.ButtonInteractionData, e *handler.ComponentEvent) error {
+		guildID, err := parseGuildId(e.GuildID().String())
+		if err != nil {
+			return err
+		}
+		gameNightID, err := strconv.ParseInt(e.Vars["gameNightId"], 10, 64)
+		if err != nil {
+			return errors.Join(err, errors.New("failed to parse game night id"))
+		}
+		playerID, err := b.addPlayer(guildID, e.User())
+		if err != nil {
+			return err
+		}
+
+		night, err := b.Ci6ndex.RespondToGameNight(guildID, gameNightID, playerID, ci6ndex.RSVP(e.Vars["response"]))
+		if err != nil {
+			return err
+		}
+		components, err := gameNightScreen(night)
+		if err != nil {
+			return err
+		}
+		if err := e.UpdateMessage(discord.MessageUpdate{
+			Components: &components,
+		}); err != nil {
+			return errors.Join(err, errors.New("failed to update game night"))
+		}
+		return nil
+	}
+}
+
+// handleStartDraftFromRSVPsButtonCommand registers everyone who said yes on the active draft, ready to be rolled
+// from /draft.
+func (b *Bot) handleStartDraftFromRSVPsButtonCommand() handler.ButtonComponentHandler {
+	return func(bid discord.ButtonInteractionData, e *handler.ComponentEvent) error {
+		guildID, err := parseGuildId(e.GuildID().String())
+		if err != nil {
+			return err
+		}
+		gameNightID, err := strconv.ParseInt(e.Vars["gameNightId"], 10, 64)
+		if err != nil {
+			return errors.Join(err, errors.New("failed to parse game night id"))
+		}
+		night, err := b.Ci6ndex.GetGameNight(guildID, gameNightID)
+		if err != nil {
+			return err
+		}
+		if night.CreatedBy != int64(e.User().ID) && !isAdmin(e.Member()) {
+			return e.CreateMessage(discord.NewMessageCreate().
+				WithEphemeral(true).
+				WithContent("Only the host or an admin can start the draft."))
+		}
+
+		players, err := b.Ci6ndex.StartDraftFromRSVPs(guildID, gameNightID)
+		if errors.Is(err, ci6ndex.ErrNoRSVPs) {
+			return e.CreateMessage(discord.NewMessageCreate().
+				WithEphemeral(true).
+				WithContent("Nobody has said yes yet."))
+		}
+		if err != nil {
+			return errors.Join(err, errors.New("failed to start draft from rsvps"))
+		}
+		return e.CreateMessage(discord.NewMessageCreate().
+			WithContent(fmt.Sprintf("%s Registered %s for the draft, roll from /draft when everyone's here.",
+				crossedSwords, mentions(players))))
+	}
+}
+
+// gameNightReminderJob is the payload of the job that reminds players of a game night.
+type gameNightReminderJob struct {
+	GameNightID int64
+}
+
+// scheduleGameNightReminder posts a reminder mentioning everyone who said yes or maybe, GameNightReminderLead
+// before the game night starts.
+func (b *Bot) scheduleGameNightReminder(guildID uint64, night ci6ndex.GameNight) error {
+	return b.Scheduler.Schedule(guildID, jobKindGameNightReminder, fmt.Sprintf("gamenight:%d", night.ID),
+		night.ReminderAt(), gameNightReminderJob{GameNightID: night.ID})
+}
+
+func (b *Bot) runGameNightReminderJob(guildID uint64, job ci6ndex.Job) error {
+	var payload gameNightReminderJob
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+	// A reminder is only posted once, even when the job is retried after posting it
+	night, ok, err := b.Ci6ndex.MarkReminded(guildID, payload.GameNightID)
+	if err != nil || !ok {
+		return err
+	}
+	players := append(night.Players(ci6ndex.RSVPYes), night.Players(ci6ndex.RSVPMaybe)...)
+	content := fmt.Sprintf("%s Game night starts <t:%d:R>!", calendar, night.StartsAt.Unix())
+	if len(players) > 0 {
+		content += " " + mentions(players)
+	}
+	_, err = b.Client.Rest.CreateMessage(snowflake.ID(night.ChannelID), discord.NewMessageCreate().
+		WithContent(content))
+	if err != nil {
+		logError("failed to post game night reminder", err)
+	}
+	return nil
+}
+
+func mentions(players []generated.Player) string {
+	m := make([]string, len(players))
+	for i, p := range players {
+		m[i] = fmt.Sprintf("<@%d>", p.ID)
+	}
+	return strings.Join(m, " ")
+}
+
+func gameNightScreen(night ci6ndex.GameNight) ([]discord.LayoutComponent, error) {
+	var body bytes.Buffer
+	if err := renderGameNight(&body, night); err != nil {
+		return nil, errors.Join(err, errors.New("failed to render game night"))
+	}
+
+	route := func(action string) string {
+		return fmt.Sprintf("/schedule/%d/%s", night.ID, action)
+	}
+	return []discord.LayoutComponent{
+		discord.NewContainer(
+			discord.NewTextDisplay(body.String()),
+			discord.NewLargeSeparator(),
+			discord.NewActionRow(
+				discord.NewSuccessButton("Yes", route("rsvp/"+string(ci6ndex.RSVPYes))),
+				discord.NewSecondaryButton("Maybe", route("rsvp/"+string(ci6ndex.RSVPMaybe))),
+				discord.NewDangerButton("No", route("rsvp/"+string(ci6ndex.RSVPNo))),
+				discord.NewPrimaryButton("Start Draft", route("start")).WithEmoji(discord.ComponentEmoji{
+					Name: crossedSwords,
+				}),
+			),
+		).WithAccentColor(colorSuccess),
+	}, nil
+}
+
+func renderGameNight(output io.Writer, night ci6ndex.GameNight) error {
+	mdBuilder := md.NewMarkdown(output).H2f("%s Game Night", calendar).
+		PlainTextf("<t:%d:F> (<t:%d:R>), scheduled for %s %s by <@%d>.", night.StartsAt.Unix(),
+			night.StartsAt.Unix(), night.Local().Format("15:04"), night.Timezone, night.CreatedBy)
+
+	for _, r := range []struct {
+		response ci6ndex.RSVP
+		label    string
+	}{
+		{ci6ndex.RSVPYes, "Yes"},
+		{ci6ndex.RSVPMaybe, "Maybe"},
+		{ci6ndex.RSVPNo, "No"},
+	} {
+		players := night.Players(r.response)
+		if len(players) == 0 {
+			mdBuilder.PlainTextf("- **%s** (0)", r.label)
+			continue
+		}
+		mdBuilder.PlainTextf("- **%s** (%d): %s", r.label, len(players), mentions(players))
+	}
+	mdBuilder.PlainTextf("\n-# Everyone who said yes or maybe is reminded %g minutes before the start.",
+		ci6ndex.GameNightReminderLead.Minutes())
+	return mdBuilder.Build()
+}
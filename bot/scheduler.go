@@ -0,0 +1,174 @@
+package bot
+
+import (
+	"ci6ndex/ci6ndex"
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+const (
+	jobKindSnakeAutoPick     = "snake.autopick"
+	jobKindBlindReveal       = "blind.reveal"
+	jobKindGameNightReminder = "gamenight.reminder"
+)
+
+const (
+	// schedulerInterval is how often the scheduler looks for due jobs when nothing wakes it up
+	schedulerInterval = time.Second
+	// schedulerBatch caps how many jobs of a guild are claimed at once
+	schedulerBatch = 10
+)
+
+// JobHandler runs a due job. Returning an error runs the job again later, see ci6ndex.FailJob, so handlers should
+// only return errors worth retrying and otherwise log them.
+type JobHandler func(guildID uint64, job ci6ndex.Job) error
+
+// Scheduler runs the jobs persisted in the guild databases once they're due. Jobs outlive restarts, so jobs that
+// came due while the bot was down run as soon as it starts.
+type Scheduler struct {
+	ci6ndex  *ci6ndex.Ci6ndex
+	guildIDs []uint64
+	mu       sync.RWMutex
+	handlers map[string]JobHandler
+	wake     chan struct{}
+	stop     chan struct{}
+	done     chan struct{}
+	started  bool
+	stopOnce sync.Once
+}
+
+func NewScheduler(c *ci6ndex.Ci6ndex, guildIDs ...uint64) *Scheduler {
+	return &Scheduler{
+		ci6ndex:  c,
+		guildIDs: guildIDs,
+		handlers: make(map[string]JobHandler),
+		wake:     make(chan struct{}, 1),
+		stop:     make(chan struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// Handle registers the handler run for every job of the given kind.
+func (s *Scheduler) Handle(kind string, h JobHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[kind] = h
+}
+
+// Schedule persists a job due at dueAt, replacing the pending jobs with the same tag.
+func (s *Scheduler) Schedule(guildID uint64, kind, tag string, dueAt time.Time, payload any) error {
+	if _, err := s.ci6ndex.ScheduleJob(guildID, kind, tag, dueAt, payload); err != nil {
+		return err
+	}
+	// Jobs due right away shouldn't wait for the next tick
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+// Cancel cancels the pending jobs with the given tag.
+func (s *Scheduler) Cancel(guildID uint64, tag string) error {
+	_, err := s.ci6ndex.CancelJobs(guildID, tag)
+	return err
+}
+
+// Start puts jobs interrupted by the last shutdown back in the queue and starts the worker loop.
+func (s *Scheduler) Start() {
+	for _, guildID := range s.guildIDs {
+		n, err := s.ci6ndex.ResetRunningJobs(guildID)
+		if err != nil {
+			slog.Error("failed to reset running jobs", "guild", guildID, "error", err)
+			continue
+		}
+		if n > 0 {
+			slog.Info("requeued interrupted jobs", "guild", guildID, "count", n)
+		}
+	}
+	s.started = true
+	go s.run()
+}
+
+// Stop stops the worker loop once the jobs it's running are done, or gives up when ctx is done first. Jobs that are
+// still running when it gives up are run again on the next start.
+func (s *Scheduler) Stop(ctx context.Context) error {
+	if !s.started {
+		return nil
+	}
+	s.stopOnce.Do(func() { close(s.stop) })
+	select {
+	case <-s.done:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("scheduler didn't stop in time: %w", ctx.Err())
+	}
+}
+
+func (s *Scheduler) run() {
+	defer close(s.done)
+	ticker := time.NewTicker(schedulerInterval)
+	defer ticker.Stop()
+	for {
+		s.runDue(time.Now())
+		select {
+		case <-s.stop:
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+// runDue runs every job due by now, one at a time.
+func (s *Scheduler) runDue(now time.Time) {
+	for _, guildID := range s.guildIDs {
+		jobs, err := s.ci6ndex.ClaimDueJobs(guildID, now, schedulerBatch)
+		if err != nil {
+			slog.Error("failed to claim due jobs", "guild", guildID, "error", err)
+			continue
+		}
+		for _, job := range jobs {
+			s.runJob(guildID, job)
+		}
+	}
+}
+
+func (s *Scheduler) runJob(guildID uint64, job ci6ndex.Job) {
+	s.mu.RLock()
+	h, ok := s.handlers[job.Kind]
+	s.mu.RUnlock()
+
+	var err error
+	if ok {
+		err = safeRun(guildID, job, h)
+	} else {
+		err = fmt.Errorf("no handler for %s jobs", job.Kind)
+	}
+	if err == nil {
+		if err := s.ci6ndex.CompleteJob(guildID, job.ID); err != nil {
+			slog.Error("failed to complete job", "job", job.ID, "kind", job.Kind, "error", err)
+		}
+		return
+	}
+
+	retry, failErr := s.ci6ndex.FailJob(guildID, job, err, time.Now())
+	if failErr != nil {
+		slog.Error("failed to record job failure", "job", job.ID, "kind", job.Kind, "error", failErr)
+	}
+	slog.Error("job failed", "job", job.ID, "kind", job.Kind, "attempt", job.Attempts, "retry", retry,
+		"error", err)
+}
+
+// safeRun runs a handler, turning a panic into an error so one bad job can't take the worker down.
+func safeRun(guildID uint64, job ci6ndex.Job, h JobHandler) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("%s job panicked: %v", job.Kind, r)
+		}
+	}()
+	return h(guildID, job)
+}
@@ -0,0 +1,154 @@
+package bot
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/disgo/handler"
+)
+
+const (
+	// DefaultShutdownTimeout is how long shutting down waits for in-flight work before abandoning it
+	DefaultShutdownTimeout = 10 * time.Second
+	// flushTimeout is how long flushing the databases may take once the in-flight work is done or abandoned
+	flushTimeout = 5 * time.Second
+)
+
+// tasks keeps track of the interactions and background tasks in flight, so shutting down can wait for them and name
+// the ones it had to abandon.
+type tasks struct {
+	mu      sync.Mutex
+	wg      sync.WaitGroup
+	closed  bool
+	next    int
+	running map[int]string
+}
+
+func newTasks() *tasks {
+	return &tasks{running: make(map[int]string)}
+}
+
+// start registers a task and returns the func to call once it's done. It returns false once the tasks are closed, the
+// task must not run then.
+func (t *tasks) start(name string) (func(), bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return nil, false
+	}
+	id := t.next
+	t.next++
+	t.running[id] = name
+	t.wg.Add(1)
+	return func() {
+		t.mu.Lock()
+		delete(t.running, id)
+		t.mu.Unlock()
+		t.wg.Done()
+	}, true
+}
+
+// Go runs fn in its own goroutine unless the tasks are closed, and reports whether it does.
+func (t *tasks) Go(name string, fn func()) bool {
+	done, ok := t.start(name)
+	if !ok {
+		return false
+	}
+	go func() {
+		defer done()
+		fn()
+	}()
+	return true
+}
+
+// Close stops accepting new tasks.
+func (t *tasks) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.closed = true
+}
+
+// Wait waits for the running tasks until ctx is done and returns the names of the ones still running by then.
+func (t *tasks) Wait(ctx context.Context) []string {
+	finished := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+		return nil
+	case <-ctx.Done():
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	abandoned := make([]string, 0, len(t.running))
+	for _, name := range t.running {
+		abandoned = append(abandoned, name)
+	}
+	slices.Sort(abandoned)
+	return abandoned
+}
+
+// trackInteractions counts every interaction as in flight until its handler returns, and drops the interactions that
+// arrive once the bot is shutting down.
+func (b *Bot) trackInteractions(next handler.Handler) handler.Handler {
+	return func(e *handler.InteractionEvent) error {
+		done, ok := b.tasks.start(interactionName(e))
+		if !ok {
+			slog.Info("DROP event", "reason", "shutting down", "interaction", interactionName(e))
+			return nil
+		}
+		defer done()
+		return next(e)
+	}
+}
+
+// interactionName names an interaction after its type and the route it's handled by.
+func interactionName(e *handler.InteractionEvent) string {
+	kind, path := interactionRoute(e)
+	return kind + " " + path
+}
+
+// shutdownHooks are the steps of shutting down that need the Discord client or the databases.
+type shutdownHooks struct {
+	// closeGateway stops new interactions from coming in, responses can still be sent afterward
+	closeGateway func(ctx context.Context)
+	flush        func(ctx context.Context) []error
+	close        func()
+}
+
+// shutdown stops accepting new interactions, waits up to timeout for the interactions, background tasks and
+// scheduled jobs in flight, then flushes and closes the databases. Work still running after the timeout is logged and
+// abandoned.
+func (b *Bot) shutdown(timeout time.Duration, hooks shutdownHooks) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	b.tasks.Close()
+	hooks.closeGateway(ctx)
+
+	var wg sync.WaitGroup
+	wg.Go(func() {
+		if err := b.Scheduler.Stop(ctx); err != nil {
+			slog.Error("abandoned running scheduled jobs", "error", err)
+		}
+	})
+	wg.Go(func() {
+		for _, name := range b.tasks.Wait(ctx) {
+			slog.Error("abandoned task", "task", name, "timeout", timeout)
+		}
+	})
+	wg.Wait()
+
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), flushTimeout)
+	defer cancelFlush()
+	for _, err := range hooks.flush(flushCtx) {
+		slog.Error("failed to flush database", "error", err)
+	}
+	hooks.close()
+}
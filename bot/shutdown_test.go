@@ -0,0 +1,86 @@
+package bot
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestShutdown_Order(t *testing.T) {
+	b := &Bot{tasks: newTasks(), Scheduler: NewScheduler(nil)}
+
+	var mu sync.Mutex
+	var steps []string
+	step := func(name string) {
+		mu.Lock()
+		defer mu.Unlock()
+		steps = append(steps, name)
+	}
+
+	release := make(chan struct{})
+	defer close(release)
+	started := make(chan struct{}, 2)
+	b.background("quick", func() {
+		started <- struct{}{}
+		time.Sleep(50 * time.Millisecond)
+		step("quick done")
+	})
+	b.background("stuck", func() {
+		started <- struct{}{}
+		<-release
+	})
+	<-started
+	<-started
+
+	var abandoned []string
+	b.shutdown(200*time.Millisecond, shutdownHooks{
+		closeGateway: func(ctx context.Context) {
+			step("gateway closed")
+			if b.tasks.Go("late", func() { step("late ran") }) {
+				t.Error("expected tasks started during shutdown to be dropped")
+			}
+		},
+		flush: func(ctx context.Context) []error {
+			b.tasks.mu.Lock()
+			for _, name := range b.tasks.running {
+				abandoned = append(abandoned, name)
+			}
+			b.tasks.mu.Unlock()
+			step("flushed")
+			return nil
+		},
+		close: func() { step("closed") },
+	})
+
+	want := []string{"gateway closed", "quick done", "flushed", "closed"}
+	if !slices.Equal(steps, want) {
+		t.Errorf("expected steps %v, got %v", want, steps)
+	}
+	if !slices.Equal(abandoned, []string{"stuck"}) {
+		t.Errorf("expected only the stuck task to be abandoned, got %v", abandoned)
+	}
+}
+
+func TestTasks_Wait(t *testing.T) {
+	tasks := newTasks()
+	done, ok := tasks.start("interaction")
+	if !ok {
+		t.Fatal("expected task to start")
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if abandoned := tasks.Wait(ctx); !slices.Equal(abandoned, []string{"interaction"}) {
+		t.Errorf("expected the running task to be abandoned, got %v", abandoned)
+	}
+
+	done()
+	if abandoned := tasks.Wait(context.Background()); len(abandoned) != 0 {
+		t.Errorf("expected nothing to be abandoned once done, got %v", abandoned)
+	}
+	tasks.Close()
+	if _, ok := tasks.start("late"); ok {
+		t.Error("expected closed tasks to reject new tasks")
+	}
+}
@@ -0,0 +1,391 @@
+package bot
+
+import (
+	"bytes"
+	"ci6ndex/ci6ndex"
+	"ci6ndex/ci6ndex/generated"
+	"cmp"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	"github.com/disgoorg/disgo/rest"
+	snowflake "github.com/disgoorg/snowflake/v2"
+	md "github.com/nao1215/markdown"
+)
+
+const (
+	snakeSearchInput = "leader"
+	// snakePickOptions is how many of the strongest remaining leaders the pick menu offers, the rest are picked by name
+	snakePickOptions = 25
+	// snakeRemainingLimit keeps the remaining leaders within Discord's text limit for a message
+	snakeRemainingLimit = 2500
+)
+
+func snakeRoute(draftId int64, action string) string {
+	route := "/snake/" + strconv.FormatInt(draftId, 10)
+	if action != "" {
+		route += "/" + action
+	}
+	return route
+}
+
+func (b *Bot) handleStartSnakeDraftButtonCommand() handler.ButtonComponentHandler {
+	return func(bid discord.ButtonInteractionData, e *handler.ComponentEvent) error {
+		guildID, err := parseGuildId(e.GuildID().String())
+		if err != nil {
+			return err
+		}
+		if err := e.DeferCreateMessage(false); err != nil {
+			return err
+		}
+
+		draft, err := b.Ci6ndex.StartSnakeDraft(guildID, ci6ndex.TurnOrder(e.Vars["order"]))
+		if errors.Is(err, ci6ndex.ErrNotEnoughPlayers) {
+			_, err = e.CreateFollowupMessage(discord.NewMessageCreate().
+				WithEphemeral(true).
+				WithContent("Select at least two players before starting a snake draft."))
+			return err
+		}
+		if err != nil {
+			return err
+		}
+
+		components, err := snakeDraftScreen(draft)
+		if err != nil {
+			return err
+		}
+		msg, err := e.CreateFollowupMessage(discord.MessageCreate{
+			Flags:      discord.MessageFlagIsComponentsV2,
+			Components: components,
+		})
+		if err != nil {
+			return errors.Join(err, errors.New("failed to create snake draft screen"))
+		}
+		b.scheduleAutoPick(guildID, msg.ChannelID, msg.ID, draft)
+		return nil
+	}
+}
+
+func (b *Bot) handleSnakeRefreshButtonCommand() handler.ButtonComponentHandler {
+	return func(bid discord.ButtonInteractionData, e *handler.ComponentEvent) error {
+		guildID, err := parseGuildId(e.GuildID().String())
+		if err != nil {
+			return err
+		}
+		draft, err := b.Ci6ndex.GetSnakeDraft(guildID)
+		if err != nil {
+			return err
+		}
+		return b.updateSnakeDraftScreen(e, draft)
+	}
+}
+
+func (b *Bot) handleSnakePickMenuSelectCommand() handler.SelectMenuComponentHandler {
+	return func(data discord.SelectMenuInteractionData, e *handler.ComponentEvent) error {
+		selectData, ok := data.(discord.StringSelectMenuInteractionData)
+		if !ok || len(selectData.Values) == 0 {
+			return fmt.Errorf("unexpected select menu data %T", data)
+		}
+		leaderID, err := strconv.ParseInt(selectData.Values[0], 10, 64)
+		if err != nil {
+			return errors.Join(err, errors.New("failed to parse leader id"))
+		}
+		return b.makeSnakePick(e, e.Vars["draftId"], int64(e.User().ID), leaderID)
+	}
+}
+
+func (b *Bot) handleSnakeSearchButtonCommand() handler.ButtonComponentHandler {
+	return func(bid discord.ButtonInteractionData, e *handler.ComponentEvent) error {
+		return e.Modal(discord.ModalCreate{
+			CustomID: bid.CustomID(),
+			Title:    "Pick a leader",
+			Components: []discord.LayoutComponent{
+				discord.NewLabel("Leader name", discord.TextInputComponent{
+					CustomID:    snakeSearchInput,
+					Style:       discord.TextInputStyleShort,
+					Required:    true,
+					MaxLength:   50,
+					Placeholder: "e.g. Gorgo",
+				}),
+			},
+		})
+	}
+}
+
+func (b *Bot) handleSnakeSearchModal() handler.ModalHandler {
+	return func(e *handler.ModalEvent) error {
+		guildID, err := parseGuildId(e.GuildID().String())
+		if err != nil {
+			return err
+		}
+		draft, err := b.Ci6ndex.GetSnakeDraft(guildID)
+		if err != nil {
+			return err
+		}
+		query := strings.TrimSpace(e.Data.Text(snakeSearchInput))
+		matches := ci6ndex.SearchLeaders(draft.Remaining, query, ci6ndex.SearchLeaderName)
+		if len(matches) == 0 {
+			return e.CreateMessage(discord.NewMessageCreate().
+				WithEphemeral(true).
+				WithContent(fmt.Sprintf("No remaining leader matches %q, try another search", query)))
+		}
+		return b.makeSnakePick(e, e.Vars["draftId"], int64(e.User().ID), matches[0].Leader.ID)
+	}
+}
+
+// snakePickEvent is what picking needs from both the pick menu and the search modal.
+type snakePickEvent interface {
+	GuildID() *snowflake.ID
+	CreateMessage(discord.MessageCreate, ...rest.RequestOpt) error
+	UpdateMessage(discord.MessageUpdate, ...rest.RequestOpt) error
+}
+
+// makeSnakePick picks for the player and updates the draft screen for everyone, or tells the player privately why
+// they can't pick.
+func (b *Bot) makeSnakePick(e snakePickEvent, draftIdVar string, playerID, leaderID int64) error {
+	guildID, err := parseGuildId(e.GuildID().String())
+	if err != nil {
+		return err
+	}
+	draftID, err := strconv.ParseInt(draftIdVar, 10, 64)
+	if err != nil {
+		return errors.Join(err, errors.New("failed to parse draft id"))
+	}
+
+	reply := func(content string) error {
+		return e.CreateMessage(discord.NewMessageCreate().WithEphemeral(true).WithContent(content))
+	}
+	current, err := b.Ci6ndex.GetSnakeDraft(guildID)
+	if err != nil {
+		return err
+	}
+	if current.DraftID != draftID {
+		return reply("This draft is over, start a new one from /draft.")
+	}
+
+	draft, err := b.Ci6ndex.MakePick(guildID, playerID, leaderID)
+	var notYourTurn ci6ndex.NotYourTurnError
+	var unavailable ci6ndex.LeaderUnavailableError
+	switch {
+	case errors.As(err, &notYourTurn):
+		return reply(fmt.Sprintf("It's <@%d>'s turn to pick, wait for yours.", notYourTurn.Current.ID))
+	case errors.As(err, &unavailable):
+		return reply("That leader has already been picked, choose another.")
+	case errors.Is(err, ci6ndex.ErrDraftComplete):
+		return reply("Everybody has already picked.")
+	case err != nil:
+		return err
+	}
+
+	components, err := snakeDraftScreen(draft)
+	if err != nil {
+		return err
+	}
+	if err := e.UpdateMessage(discord.MessageUpdate{Components: &components}); err != nil {
+		return errors.Join(err, errors.New("failed to update snake draft screen"))
+	}
+	if msg := snakeMessage(e); msg != nil {
+		b.scheduleAutoPick(guildID, msg.ChannelID, msg.ID, draft)
+	}
+	return nil
+}
+
+func snakeMessage(e snakePickEvent) *discord.Message {
+	switch e := e.(type) {
+	case *handler.ComponentEvent:
+		return &e.Message
+	case *handler.ModalEvent:
+		return e.Message
+	}
+	return nil
+}
+
+func (b *Bot) updateSnakeDraftScreen(e *handler.ComponentEvent, draft ci6ndex.SnakeDraft) error {
+	components, err := snakeDraftScreen(draft)
+	if err != nil {
+		return err
+	}
+	if err := e.UpdateMessage(discord.MessageUpdate{
+		Components: &components,
+	}); err != nil {
+		return errors.Join(err, errors.New("failed to update snake draft screen"))
+	}
+	return nil
+}
+
+// snakeAutoPickJob is the payload of the job that auto-picks for a player whose turn timed out.
+type snakeAutoPickJob struct {
+	ChannelID snowflake.ID
+	MessageID snowflake.ID
+	DraftID   int64
+	Turn      int
+}
+
+// scheduleAutoPick picks for the current player once their turn times out and updates the draft message. A draft
+// only has one timer, scheduling the next turn's replaces it.
+func (b *Bot) scheduleAutoPick(guildID uint64, channelID, messageID snowflake.ID, draft ci6ndex.SnakeDraft) {
+	if draft.Done() {
+		return
+	}
+	err := b.Scheduler.Schedule(guildID, jobKindSnakeAutoPick, fmt.Sprintf("snake:%d", draft.DraftID),
+		draft.Deadline(), snakeAutoPickJob{
+			ChannelID: channelID,
+			MessageID: messageID,
+			DraftID:   draft.DraftID,
+			Turn:      draft.Current,
+		})
+	if err != nil {
+		slog.Error("failed to schedule auto pick", "draft", draft.DraftID, "turn", draft.Current, "error", err)
+	}
+}
+
+func (b *Bot) runSnakeAutoPickJob(guildID uint64, job ci6ndex.Job) error {
+	var payload snakeAutoPickJob
+	if err := job.Decode(&payload); err != nil {
+		return err
+	}
+	next, picked, err := b.Ci6ndex.AutoPick(guildID, payload.DraftID, payload.Turn)
+	if err != nil {
+		return errors.Join(err, fmt.Errorf("failed to auto pick turn %d", payload.Turn))
+	}
+	if !picked {
+		return nil
+	}
+	components, err := snakeDraftScreen(next)
+	if err != nil {
+		slog.Error("failed to render snake draft screen", "error", err)
+		return nil
+	}
+	_, err = b.Client.Rest.UpdateMessage(payload.ChannelID, payload.MessageID, discord.MessageUpdate{
+		Components: &components,
+	})
+	if err != nil {
+		logError("failed to update snake draft screen", err)
+	}
+	b.scheduleAutoPick(guildID, payload.ChannelID, payload.MessageID, next)
+	return nil
+}
+
+func snakeDraftScreen(draft ci6ndex.SnakeDraft) ([]discord.LayoutComponent, error) {
+	var header, remaining bytes.Buffer
+	if err := renderSnakeDraft(&header, draft); err != nil {
+		return nil, errors.Join(err, errors.New("failed to render snake draft"))
+	}
+	if err := renderRemainingLeaders(&remaining, draft.Remaining); err != nil {
+		return nil, errors.Join(err, errors.New("failed to render remaining leaders"))
+	}
+
+	if draft.Done() {
+		return []discord.LayoutComponent{
+			discord.NewContainer(
+				discord.NewTextDisplay(header.String()),
+			).WithAccentColor(colorSuccess),
+		}, nil
+	}
+
+	return []discord.LayoutComponent{
+		discord.NewContainer(
+			discord.NewTextDisplay(header.String()),
+			discord.NewSmallSeparator(),
+			discord.NewTextDisplay(remaining.String()),
+			discord.NewLargeSeparator(),
+			discord.NewActionRow(discord.NewStringSelectMenu(snakeRoute(draft.DraftID, "pick"), "Pick a leader",
+				snakePickOptionsFor(draft.Remaining)...)),
+			discord.NewActionRow(
+				discord.NewPrimaryButton("Pick by name", snakeRoute(draft.DraftID, "search")).
+					WithEmoji(discord.ComponentEmoji{Name: crossedSwords}),
+				discord.NewSecondaryButton("Refresh", snakeRoute(draft.DraftID, "")).
+					WithEmoji(discord.ComponentEmoji{Name: magnifyingGlass}),
+			),
+		).WithAccentColor(colorSuccess),
+	}, nil
+}
+
+// snakePickOptionsFor offers the strongest remaining leaders.
+func snakePickOptionsFor(remaining []generated.Leader) []discord.StringSelectMenuOption {
+	leaders := slices.Clone(remaining)
+	slices.SortStableFunc(leaders, func(a, b generated.Leader) int {
+		if c := cmp.Compare(ci6ndex.LeaderStrength(b), ci6ndex.LeaderStrength(a)); c != 0 {
+			return c
+		}
+		return cmp.Compare(ci6ndex.LeaderName(a), ci6ndex.LeaderName(b))
+	})
+	leaders = leaders[:min(len(leaders), snakePickOptions)]
+
+	opts := make([]discord.StringSelectMenuOption, len(leaders))
+	for i, l := range leaders {
+		opts[i] = discord.NewStringSelectMenuOption(ci6ndex.LeaderName(l), strconv.FormatInt(l.ID, 10)).
+			WithDescription(l.CivName)
+		if tier, err := ci6ndex.GetTierForLeader(l); err == nil {
+			opts[i] = opts[i].WithDescription(fmt.Sprintf("%s · %s tier", l.CivName, tier.Name()))
+		}
+	}
+	return opts
+}
+
+func renderSnakeDraft(buffer io.Writer, draft ci6ndex.SnakeDraft) error {
+	mdBuilder := md.NewMarkdown(buffer).H2("Snake Draft")
+	for i, turn := range draft.Turns {
+		switch {
+		case turn.Pick != nil && turn.Auto:
+			mdBuilder.PlainTextf("%d. <@%d>: %s %s *(auto-picked)*", i+1, turn.Player.ID,
+				turn.Pick.DiscordEmojiString.String, ci6ndex.LeaderName(*turn.Pick))
+		case turn.Pick != nil:
+			mdBuilder.PlainTextf("%d. <@%d>: %s %s", i+1, turn.Player.ID,
+				turn.Pick.DiscordEmojiString.String, ci6ndex.LeaderName(*turn.Pick))
+		case i == draft.Current:
+			mdBuilder.PlainTextf("%d. <@%d>: **picking...**", i+1, turn.Player.ID)
+		default:
+			mdBuilder.PlainTextf("%d. <@%d>", i+1, turn.Player.ID)
+		}
+	}
+
+	if player, ok := draft.CurrentPlayer(); ok {
+		mdBuilder.PlainTextf("\n%s It's <@%d>'s turn, a leader is picked for them <t:%d:R>.", crossedSwords,
+			player.ID, draft.Deadline().Unix())
+	} else {
+		mdBuilder.PlainTextf("\n%s Everybody has picked, good luck!", partyEmoji)
+	}
+	return mdBuilder.Build()
+}
+
+// renderRemainingLeaders lists the leaders still up for grabs by tier, cutting the list short to fit in a message.
+func renderRemainingLeaders(buffer io.Writer, remaining []generated.Leader) error {
+	byTier := make(map[string][]string)
+	var tierNames []string
+	for _, t := range []ci6ndex.Tier{ci6ndex.S, ci6ndex.A, ci6ndex.B, ci6ndex.C, ci6ndex.F, ci6ndex.Unranked} {
+		tierNames = append(tierNames, t.Name())
+	}
+	for _, l := range remaining {
+		tier, err := ci6ndex.GetTierForLeader(l)
+		if err != nil {
+			return err
+		}
+		byTier[tier.Name()] = append(byTier[tier.Name()], ci6ndex.LeaderName(l))
+	}
+
+	mdBuilder := md.NewMarkdown(buffer).H3f("Remaining Leaders (%d)", len(remaining))
+	written := 0
+	for _, name := range tierNames {
+		names := byTier[name]
+		if len(names) == 0 {
+			continue
+		}
+		slices.Sort(names)
+		line := fmt.Sprintf("**%s**: %s", name, strings.Join(names, ", "))
+		if written+len(line) > snakeRemainingLimit {
+			mdBuilder.PlainText("*...and more, pick by name to find them*")
+			break
+		}
+		written += len(line)
+		mdBuilder.PlainText(line)
+	}
+	return mdBuilder.Build()
+}
@@ -1,22 +1,104 @@
 package bot
 
-//
-//var randomTeamsCommand = discord.SlashCommandCreate{
-//	Name:        "teams",
-//	Description: "Create and assign random teams",
-//	Options: []discord.ApplicationCommandOption{
-//		discord.ApplicationCommandOptionInt{
-//			Name:        "teamSize",
-//			Description: "The number of players per team",
-//			Required:    true,
-//		},
-//	},
-//}
-
-//
-//func HandleRandomizeTeams(c *Bot) handler.CommandHandler {
-//	return func(e *handler.CommandEvent) error {
-//		teamSize := e.M("teamSize")
-//		if teamSize < 1 {
-//			return e.Respond(discord.InteractionResponseTypeChannelMessageWithSource, discord.NewMessageCreateBuilder().SetContent("Team size must be greater than 0").Build())
-//		}
+import (
+	"bytes"
+	"ci6ndex/ci6ndex"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	md "github.com/nao1215/markdown"
+)
+
+var (
+	minTeamCount = 2
+	maxTeamCount = 6
+)
+
+var randomTeamsCommand = discord.SlashCommandCreate{
+	Name:        "teams",
+	Description: "Split the players of the current draft into teams",
+	Options: []discord.ApplicationCommandOption{
+		discord.ApplicationCommandOptionInt{
+			Name:        "teams",
+			Description: "The number of teams",
+			Required:    true,
+			MinValue:    &minTeamCount,
+			MaxValue:    &maxTeamCount,
+		},
+		discord.ApplicationCommandOptionString{
+			Name:        "mode",
+			Description: "how players are split (default random)",
+			Required:    false,
+			Choices: []discord.ApplicationCommandOptionChoiceString{
+				{Name: "Random", Value: string(ci6ndex.TeamsRandom)},
+				{Name: "Balanced by drafts won", Value: string(ci6ndex.TeamsBalanced)},
+			},
+		},
+	},
+}
+
+func (b *Bot) handleRandomizeTeamsSlashCommand() handler.SlashCommandHandler {
+	return func(data discord.SlashCommandInteractionData, e *handler.CommandEvent) error {
+		guildID, err := parseGuildId(e.GuildID().String())
+		if err != nil {
+			return err
+		}
+		mode := ci6ndex.TeamsRandom
+		if m, ok := data.OptString("mode"); ok {
+			mode = ci6ndex.TeamMode(m)
+		}
+
+		teams, err := b.Ci6ndex.GenerateTeams(guildID, data.Int("teams"), mode)
+		var countErr ci6ndex.InvalidTeamCountError
+		if errors.As(err, &countErr) {
+			return e.CreateMessage(discord.NewMessageCreate().
+				WithEphemeral(true).
+				WithContent(fmt.Sprintf("Can't split %d players into %d teams, select more players from /draft.",
+					countErr.Players, countErr.Teams)))
+		}
+		if err != nil {
+			return errors.Join(err, errors.New("failed to generate teams"))
+		}
+
+		var body bytes.Buffer
+		if err := renderTeams(&body, teams, mode); err != nil {
+			return errors.Join(err, errors.New("failed to render teams"))
+		}
+		err = e.CreateMessage(discord.NewMessageCreateV2().
+			WithComponents(
+				discord.NewContainer(
+					discord.NewTextDisplay(body.String()),
+				).WithAccentColor(colorSuccess),
+			))
+		if err != nil {
+			return errors.Join(err, errors.New("failed to create teams"))
+		}
+		return nil
+	}
+}
+
+func renderTeams(output io.Writer, teams []ci6ndex.Team, mode ci6ndex.TeamMode) error {
+	mdBuilder := md.NewMarkdown(output).H2f("%s Teams", crossedSwords)
+	if mode == ci6ndex.TeamsBalanced {
+		mdBuilder.PlainText("Balanced by drafts won, avoiding last game's teammates where possible.")
+	} else {
+		mdBuilder.PlainText("Split at random, avoiding last game's teammates where possible.")
+	}
+	for _, team := range teams {
+		mentions := make([]string, len(team.Players))
+		for i, p := range team.Players {
+			mentions[i] = fmt.Sprintf("<@%d>", p.ID)
+		}
+		if mode == ci6ndex.TeamsBalanced {
+			mdBuilder.PlainTextf("- **Team %d** (%d wins): %s", team.Number, team.Wins, strings.Join(mentions, ", "))
+		} else {
+			mdBuilder.PlainTextf("- **Team %d**: %s", team.Number, strings.Join(mentions, ", "))
+		}
+	}
+	mdBuilder.PlainText("\n-# Rolls for this draft are grouped by team.")
+	return mdBuilder.Build()
+}
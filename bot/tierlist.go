@@ -0,0 +1,299 @@
+package bot
+
+import (
+	"bytes"
+	"ci6ndex/ci6ndex"
+	"context"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"io"
+	"log/slog"
+	"net/http"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/disgoorg/disgo/discord"
+	"github.com/disgoorg/disgo/handler"
+	xdraw "golang.org/x/image/draw"
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/basicfont"
+	"golang.org/x/image/math/fixed"
+)
+
+const (
+	tierListWidth   = 1280
+	tierTitleHeight = 48
+	tierLabelWidth  = 120
+	tierCellWidth   = 144
+	tierCellHeight  = 104
+	tierIconSize    = 48
+	tierRowGap      = 2
+	// basicfont glyphs are 7px wide, keep a glyph of padding on each side of a cell
+	tierNameMaxChars = tierCellWidth/7 - 2
+)
+
+var (
+	tierListBackground = color.RGBA{R: 0x1e, G: 0x1f, B: 0x22, A: 0xff}
+	tierListText       = color.RGBA{R: 0xf2, G: 0xf3, B: 0xf5, A: 0xff}
+	tierLabelText      = color.RGBA{R: 0x1e, G: 0x1f, B: 0x22, A: 0xff}
+	// keyed by tier value
+	tierColors = map[float64]color.RGBA{
+		ci6ndex.S.Value():        {R: 0xff, G: 0x7f, B: 0x7f, A: 0xff},
+		ci6ndex.A.Value():        {R: 0xff, G: 0xbf, B: 0x7f, A: 0xff},
+		ci6ndex.B.Value():        {R: 0xff, G: 0xdf, B: 0x7f, A: 0xff},
+		ci6ndex.C.Value():        {R: 0xbf, G: 0xff, B: 0x7f, A: 0xff},
+		ci6ndex.F.Value():        {R: 0x7f, G: 0xbf, B: 0xff, A: 0xff},
+		ci6ndex.Unranked.Value(): {R: 0xb0, G: 0xb0, B: 0xb0, A: 0xff},
+	}
+	emojiIDPattern = regexp.MustCompile(`^<a?:\w+:(\d+)>$`)
+)
+
+// emojiFetcher downloads a discord emoji by ID.
+type emojiFetcher func(ctx context.Context, emojiID string) (image.Image, error)
+
+// leaderIconCache holds decoded leader emoji keyed by emoji ID. Emoji never change, so entries never expire.
+var leaderIconCache sync.Map
+
+func (b *Bot) handleTierListSlashCommand() handler.SlashCommandHandler {
+	return func(data discord.SlashCommandInteractionData, e *handler.CommandEvent) error {
+		guildID, err := parseGuildId(e.GuildID().String())
+		if err != nil {
+			return err
+		}
+		ruleset := data.String("ruleset")
+		ranks := data.String("ranks")
+		slog.Info("handleTierList", "ruleset", ruleset, "ranks", ranks)
+
+		err = e.DeferCreateMessage(false)
+		if err != nil {
+			return err
+		}
+
+		opts := ci6ndex.TierListOptions{BBG: ruleset == "bbg"}
+		title := "Community Tier List"
+		if ranks == "mine" {
+			opts.PlayerID = int64(e.User().ID)
+			title = fmt.Sprintf("%s's Tier List", e.User().EffectiveName())
+		}
+		if ruleset == "" {
+			if opts.BBG, err = b.Ci6ndex.DefaultsToBBG(guildID, opts.PlayerID); err != nil {
+				return err
+			}
+		}
+		if opts.BBG {
+			title += " (BBG)"
+		} else {
+			title += " (Vanilla)"
+		}
+
+		rows, err := b.Ci6ndex.GetTierList(guildID, opts)
+		if err != nil {
+			return errors.Join(err, errors.New("failed to build tier list"))
+		}
+
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		icons := fetchLeaderIcons(ctx, rows, fetchEmoji)
+
+		var img bytes.Buffer
+		err = renderTierListImage(&img, title, rows, icons)
+		if err != nil {
+			return errors.Join(err, errors.New("failed to render tier list"))
+		}
+
+		_, err = e.CreateFollowupMessage(discord.MessageCreate{
+			Content: "## " + title,
+			Files:   []*discord.File{discord.NewFile("tierlist.png", title, &img)},
+		})
+		if err != nil {
+			return errors.Join(err, errors.New("failed to upload tier list"))
+		}
+		return nil
+	}
+}
+
+// fetchLeaderIcons downloads the discord emoji for every leader in the tier list. Leaders whose emoji can't be
+// fetched before ctx is done are simply rendered without an icon.
+func fetchLeaderIcons(ctx context.Context, rows []ci6ndex.TierListRow, fetch emojiFetcher) map[int64]image.Image {
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	icons := make(map[int64]image.Image)
+
+	for _, row := range rows {
+		for _, l := range row.Leaders {
+			match := emojiIDPattern.FindStringSubmatch(l.DiscordEmojiString.String)
+			if match == nil {
+				continue
+			}
+			wg.Go(func() {
+				icon, err := fetch(ctx, match[1])
+				if err != nil {
+					slog.Debug("failed to fetch leader icon", "leader", l.ID, "error", err)
+					return
+				}
+				mu.Lock()
+				icons[l.ID] = icon
+				mu.Unlock()
+			})
+		}
+	}
+	wg.Wait()
+	return icons
+}
+
+// fetchEmoji downloads an emoji from the discord CDN.
+func fetchEmoji(ctx context.Context, emojiID string) (image.Image, error) {
+	if cached, ok := leaderIconCache.Load(emojiID); ok {
+		return cached.(image.Image), nil
+	}
+	url := fmt.Sprintf("https://cdn.discordapp.com/emojis/%s.png?size=%d", emojiID, tierIconSize)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status fetching emoji %s: %s", emojiID, resp.Status)
+	}
+	icon, err := png.Decode(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	leaderIconCache.Store(emojiID, icon)
+	return icon, nil
+}
+
+// renderTierListImage draws a classic tier list: one colored label per tier on the left, and the leaders in that
+// tier laid out in a grid to its right. icons is keyed by leader ID and may be missing entries.
+func renderTierListImage(w io.Writer, title string, rows []ci6ndex.TierListRow, icons map[int64]image.Image) error {
+	cellsPerLine := (tierListWidth - tierLabelWidth) / tierCellWidth
+
+	height := tierTitleHeight
+	for _, row := range rows {
+		height += tierRowHeight(len(row.Leaders), cellsPerLine) + tierRowGap
+	}
+
+	canvas := image.NewRGBA(image.Rect(0, 0, tierListWidth, height))
+	draw.Draw(canvas, canvas.Bounds(), image.NewUniform(tierListBackground), image.Point{}, draw.Src)
+	drawScaledText(canvas, image.Rect(0, 0, tierListWidth, tierTitleHeight), title, tierListText, 2)
+
+	y := tierTitleHeight
+	for _, row := range rows {
+		rowHeight := tierRowHeight(len(row.Leaders), cellsPerLine)
+		labelColor, ok := tierColors[row.Tier.Value()]
+		if !ok {
+			return fmt.Errorf("no color for tier %s", row.Tier.Name())
+		}
+		label := image.Rect(0, y, tierLabelWidth, y+rowHeight)
+		draw.Draw(canvas, label, image.NewUniform(labelColor), image.Point{}, draw.Src)
+		drawScaledText(canvas, label, tierLabel(row.Tier), tierLabelText, 4)
+
+		for i, l := range row.Leaders {
+			cellX := tierLabelWidth + (i%cellsPerLine)*tierCellWidth
+			cellY := y + (i/cellsPerLine)*tierCellHeight
+			drawLeaderCell(canvas, cellX, cellY, ci6ndex.LeaderName(l), icons[l.ID])
+		}
+		y += rowHeight + tierRowGap
+	}
+
+	return png.Encode(w, canvas)
+}
+
+func tierRowHeight(numLeaders, cellsPerLine int) int {
+	lines := (numLeaders + cellsPerLine - 1) / cellsPerLine
+	return max(lines, 1) * tierCellHeight
+}
+
+// tierLabel strips the emoji from a tier name, which the basic font cannot draw.
+func tierLabel(t ci6ndex.Tier) string {
+	if t.Value() == ci6ndex.Unranked.Value() {
+		return "?"
+	}
+	return strings.Fields(t.Name())[0]
+}
+
+func drawLeaderCell(canvas *image.RGBA, x, y int, name string, icon image.Image) {
+	lines := wrapName(name, tierNameMaxChars, 2)
+	textHeight := len(lines) * basicfont.Face7x13.Height
+	top := y + (tierCellHeight-textHeight)/2
+	if icon != nil {
+		iconX := x + (tierCellWidth-tierIconSize)/2
+		iconRect := image.Rect(iconX, y+8, iconX+tierIconSize, y+8+tierIconSize)
+		xdraw.ApproxBiLinear.Scale(canvas, iconRect, icon, icon.Bounds(), xdraw.Over, nil)
+		top = iconRect.Max.Y + 6
+	}
+	for i, line := range lines {
+		lineWidth := len([]rune(line)) * 7
+		drawText(canvas, x+(tierCellWidth-lineWidth)/2, top+(i+1)*basicfont.Face7x13.Height-3, line, tierListText)
+	}
+}
+
+// wrapName splits a name on spaces into at most maxLines lines of maxChars, truncating the last line if needed.
+func wrapName(name string, maxChars, maxLines int) []string {
+	lines := make([]string, 0, maxLines)
+	current := ""
+	truncated := false
+	for _, word := range strings.Fields(name) {
+		candidate := strings.TrimSpace(current + " " + word)
+		if len([]rune(candidate)) <= maxChars {
+			current = candidate
+			continue
+		}
+		if current != "" {
+			lines = append(lines, current)
+		}
+		current = word
+		if len(lines) == maxLines {
+			truncated = true
+			break
+		}
+	}
+	if current != "" && len(lines) < maxLines {
+		lines = append(lines, current)
+	}
+	if len(lines) == 0 {
+		return lines
+	}
+
+	last := []rune(lines[len(lines)-1])
+	if len(last) > maxChars || truncated {
+		lines[len(lines)-1] = string(last[:min(len(last), maxChars-2)]) + ".."
+	}
+	return lines
+}
+
+func drawText(dst draw.Image, x, y int, text string, col color.Color) {
+	d := font.Drawer{
+		Dst:  dst,
+		Src:  image.NewUniform(col),
+		Face: basicfont.Face7x13,
+		Dot:  fixed.P(x, y),
+	}
+	d.DrawString(text)
+}
+
+// drawScaledText draws text centered in rect, scaled up by an integer factor since basicfont only comes in one size.
+func drawScaledText(dst *image.RGBA, rect image.Rectangle, text string, col color.Color, scale int) {
+	face := basicfont.Face7x13
+	width := font.MeasureString(face, text).Ceil()
+	src := image.NewRGBA(image.Rect(0, 0, width, face.Height))
+	drawText(src, 0, face.Ascent, text, col)
+
+	scaledWidth, scaledHeight := width*scale, face.Height*scale
+	origin := image.Pt(
+		rect.Min.X+(rect.Dx()-scaledWidth)/2,
+		rect.Min.Y+(rect.Dy()-scaledHeight)/2,
+	)
+	target := image.Rectangle{Min: origin, Max: origin.Add(image.Pt(scaledWidth, scaledHeight))}
+	xdraw.NearestNeighbor.Scale(dst, target, src, src.Bounds(), xdraw.Over, nil)
+}
@@ -0,0 +1,89 @@
+package bot
+
+import (
+	"bytes"
+	"ci6ndex/ci6ndex"
+	"ci6ndex/ci6ndex/generated"
+	"context"
+	"database/sql"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"slices"
+	"testing"
+)
+
+func TestWrapName(t *testing.T) {
+	tests := []struct {
+		name     string
+		maxChars int
+		want     []string
+	}{
+		{name: "Gandhi", maxChars: 18, want: []string{"Gandhi"}},
+		{name: "Teddy Roosevelt (Bull Moose)", maxChars: 18, want: []string{"Teddy Roosevelt", "(Bull Moose)"}},
+		{name: "Kupe of the Maori Nation", maxChars: 10, want: []string{"Kupe of", "the Maor.."}},
+		{name: "Pachacuti-the-Great", maxChars: 10, want: []string{"Pachacut.."}},
+		{name: "", maxChars: 10, want: []string{}},
+	}
+	for _, tt := range tests {
+		if got := wrapName(tt.name, tt.maxChars, 2); !slices.Equal(got, tt.want) {
+			t.Errorf("wrapName(%q, %d) = %q, want %q", tt.name, tt.maxChars, got, tt.want)
+		}
+	}
+}
+
+func TestRenderTierListImage(t *testing.T) {
+	iconColor := color.RGBA{R: 0xff, B: 0xff, A: 0xff}
+	fetch := func(ctx context.Context, emojiID string) (image.Image, error) {
+		if emojiID != "1" {
+			return nil, errors.New("no such emoji")
+		}
+		icon := image.NewRGBA(image.Rect(0, 0, tierIconSize, tierIconSize))
+		for x := range tierIconSize {
+			for y := range tierIconSize {
+				icon.Set(x, y, iconColor)
+			}
+		}
+		return icon, nil
+	}
+	emoji := func(s string) sql.NullString { return sql.NullString{String: s, Valid: true} }
+	rows := []ci6ndex.TierListRow{
+		{Tier: ci6ndex.S, Leaders: []generated.Leader{
+			{ID: 1, LeaderName: "Gandhi", DiscordEmojiString: emoji("<:gandhi:1>")},
+			{ID: 2, LeaderName: "Kupe", DiscordEmojiString: emoji("<:kupe:2>")},
+			{ID: 3, LeaderName: "Tamar"},
+		}},
+		{Tier: ci6ndex.A},
+		{Tier: ci6ndex.Unranked},
+	}
+
+	icons := fetchLeaderIcons(context.Background(), rows, fetch)
+	if len(icons) != 1 || icons[1] == nil {
+		t.Fatalf("expected only the icon that could be fetched, got %v", icons)
+	}
+
+	var buf bytes.Buffer
+	if err := renderTierListImage(&buf, "Tier List", rows, icons); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	img, err := png.Decode(&buf)
+	if err != nil {
+		t.Fatalf("failed to decode tier list: %v", err)
+	}
+	wantHeight := tierTitleHeight + len(rows)*(tierCellHeight+tierRowGap)
+	if img.Bounds().Dx() != tierListWidth || img.Bounds().Dy() != wantHeight {
+		t.Fatalf("expected a %dx%d image, got %v", tierListWidth, wantHeight, img.Bounds())
+	}
+	if got := color.RGBAModel.Convert(img.At(1, tierTitleHeight+1)); got != tierColors[ci6ndex.S.Value()] {
+		t.Errorf("expected the S label color, got %v", got)
+	}
+	iconCenter := image.Pt(tierLabelWidth+tierCellWidth/2, tierTitleHeight+8+tierIconSize/2)
+	if got := color.RGBAModel.Convert(img.At(iconCenter.X, iconCenter.Y)); got != iconColor {
+		t.Errorf("expected the fetched icon in the first cell, got %v", got)
+	}
+	iconCenter.X += tierCellWidth
+	if got := color.RGBAModel.Convert(img.At(iconCenter.X, iconCenter.Y)); got == iconColor {
+		t.Error("expected no icon for the leader whose emoji couldn't be fetched")
+	}
+}
@@ -2,9 +2,15 @@ package bot
 
 const (
 	colorSuccess    = 0x5c5fea // Using the Unicode escape sequence
+	colorError      = 0xed4245
 	partyEmoji      = "\U0001F389"
 	magnifyingGlass = "\U0001F50D"
 	crossedSwords   = "\u2694\uFE0F"
 	backArrow       = "\u2B05\uFE0F"
 	notebook        = "\U0001F4D3"
+	gear            = "\u2699\uFE0F"
+	scales          = "\u2696\uFE0F"
+	worldMap        = "\U0001F5FA\uFE0F"
+	calendar        = "\U0001F4C5"
+	warning         = "\u26A0\uFE0F"
 )
@@ -0,0 +1,270 @@
+package ci6ndex
+
+import (
+	"ci6ndex/ci6ndex/generated"
+	"slices"
+)
+
+// BalanceMode is how the strength of an offering is scored when evening out rolls.
+type BalanceMode string
+
+const (
+	// BalanceOff rolls every offering independently
+	BalanceOff BalanceMode = "off"
+	// BalanceBySum scores an offering by the strength of all its leaders together
+	BalanceBySum BalanceMode = "sum"
+	// BalanceByBest scores an offering by its strongest leader, the one a player is most likely to pick
+	BalanceByBest BalanceMode = "best"
+)
+
+const (
+	// balanceAttempts is how many rolls a balanced roll chooses from
+	balanceAttempts = 20
+	// rebalanceSteps caps how many leaders are moved around to even out a single roll
+	rebalanceSteps = 50
+	// MaxBalanceTolerance is the widest spread a balanced roll can be configured to accept
+	MaxBalanceTolerance = 10.0
+)
+
+// unrankedStrength treats unranked leaders as middle of the road, their real strength isn't known yet.
+const unrankedStrength = 3.0
+
+// LeaderStrength turns a leader's tier into a score where higher is stronger: S is 5 and F is 1.
+func LeaderStrength(leader generated.Leader) float64 {
+	tier, err := GetTierForLeader(leader)
+	if err != nil || tier.Value() == Unranked.Value() {
+		return unrankedStrength
+	}
+	return F.Value() + 1 - tier.Value()
+}
+
+// Strength scores a set of leaders. BalanceOff scores like BalanceBySum so every roll can show a strength.
+func (m BalanceMode) Strength(leaders []generated.Leader) float64 {
+	strength := 0.0
+	for _, l := range leaders {
+		if m == BalanceByBest {
+			strength = max(strength, LeaderStrength(l))
+		} else {
+			strength += LeaderStrength(l)
+		}
+	}
+	return strength
+}
+
+// Balance configures a balanced roll. Tolerance is the widest acceptable gap between the strongest and weakest
+// offering.
+type Balance struct {
+	Mode      BalanceMode
+	Tolerance float64
+}
+
+// BalancedRoll is the outcome of RollBalanced.
+type BalancedRoll struct {
+	Offerings []Offering
+	// Spread is the gap between the strongest and weakest offering
+	Spread float64
+	// WithinTolerance is false when no roll met the tolerance and the most balanced one found was used instead
+	WithinTolerance bool
+}
+
+// Spread is the gap between the strongest and weakest offering.
+func Spread(offerings []Offering) float64 {
+	if len(offerings) == 0 {
+		return 0
+	}
+	lo, hi := offerings[0].Strength, offerings[0].Strength
+	for _, o := range offerings[1:] {
+		lo = min(lo, o.Strength)
+		hi = max(hi, o.Strength)
+	}
+	return hi - lo
+}
+
+func scoreOfferings(offerings []Offering, mode BalanceMode) {
+	for i := range offerings {
+		offerings[i].Strength = mode.Strength(offerings[i].Leaders)
+	}
+}
+
+// RollBalanced rolls like RollForPlayers. With balancing on, each roll is evened out by moving leaders between the
+// strongest and weakest offering, and it rolls repeatedly to keep the roll with the smallest spread in strength
+// between players, stopping early once the spread is within tolerance. When no roll is within tolerance the most
+// balanced one is returned with WithinTolerance set to false.
+func (c *Ci6ndex) RollBalanced(
+	guildId uint64,
+	playerIds []int64,
+	rules []Rule,
+	balance Balance,
+) (BalancedRoll, error) {
+	in, err := c.loadRollInput(guildId)
+	if err != nil {
+		return BalancedRoll{}, err
+	}
+	return rollBalanced(in, playerIds, rules, balance)
+}
+
+func rollBalanced(in rollInput, playerIds []int64, rules []Rule, balance Balance) (BalancedRoll, error) {
+	attempts := balanceAttempts
+	if balance.Mode == BalanceOff {
+		attempts = 1
+	}
+
+	var best *BalancedRoll
+	var lastErr error
+	for range attempts {
+		offerings, err := rollOfferings(in, playerIds, rules)
+		if err != nil {
+			// Rolls are random, a later attempt may still find enough leaders
+			lastErr = err
+			continue
+		}
+		scoreOfferings(offerings, balance.Mode)
+		if balance.Mode != BalanceOff {
+			rebalance(in, offerings, rules, balance)
+		}
+		roll := BalancedRoll{
+			Offerings:       offerings,
+			Spread:          Spread(offerings),
+			WithinTolerance: balance.Mode == BalanceOff,
+		}
+		if balance.Mode != BalanceOff && roll.Spread <= balance.Tolerance {
+			roll.WithinTolerance = true
+			return roll, nil
+		}
+		if best == nil || roll.Spread < best.Spread {
+			best = &roll
+		}
+	}
+
+	if best == nil {
+		return BalancedRoll{}, lastErr
+	}
+	return *best, nil
+}
+
+// rebalance evens out scored offerings in place. Each step makes the single move that shrinks the spread the most:
+// swapping a leader between the strongest and weakest offering, or swapping one of theirs for a leader nobody was
+// offered. Moves that would break a rule for either player are skipped.
+func rebalance(in rollInput, offerings []Offering, rules []Rule, balance Balance) {
+	if len(offerings) == 0 {
+		return
+	}
+	for range rebalanceSteps {
+		spread := Spread(offerings)
+		if spread <= balance.Tolerance {
+			return
+		}
+		hi, lo := 0, 0
+		for i, o := range offerings {
+			if o.Strength > offerings[hi].Strength {
+				hi = i
+			}
+			if o.Strength < offerings[lo].Strength {
+				lo = i
+			}
+		}
+
+		assigned := make(map[int64]struct{})
+		for _, o := range offerings {
+			for _, l := range o.Leaders {
+				assigned[l.ID] = struct{}{}
+			}
+		}
+		unassigned := filterAssigned(slices.Clone(in.leaders), assigned)
+
+		best := spread
+		var bestHi, bestLo []generated.Leader
+		// try scores a move, keeping it when it beats the best so far without breaking any rules
+		try := func(newHi, newLo []generated.Leader) {
+			hiStrength, loStrength := balance.Mode.Strength(newHi), balance.Mode.Strength(newLo)
+			if s := spreadWith(offerings, hi, hiStrength, lo, loStrength); s < best &&
+				satisfiesRules(offerings[hi].Player, newHi, rules) &&
+				satisfiesRules(offerings[lo].Player, newLo, rules) {
+				best, bestHi, bestLo = s, newHi, newLo
+			}
+		}
+		for i := range offerings[hi].Leaders {
+			for j := range offerings[lo].Leaders {
+				newHi, newLo := slices.Clone(offerings[hi].Leaders), slices.Clone(offerings[lo].Leaders)
+				newHi[i], newLo[j] = newLo[j], newHi[i]
+				try(newHi, newLo)
+			}
+			for _, u := range unassigned {
+				newHi := slices.Clone(offerings[hi].Leaders)
+				newHi[i] = u
+				try(newHi, offerings[lo].Leaders)
+			}
+		}
+		for j := range offerings[lo].Leaders {
+			for _, u := range unassigned {
+				newLo := slices.Clone(offerings[lo].Leaders)
+				newLo[j] = u
+				try(offerings[hi].Leaders, newLo)
+			}
+		}
+
+		if bestHi == nil {
+			return
+		}
+		offerings[hi].Leaders, offerings[lo].Leaders = bestHi, bestLo
+		offerings[hi].Strength = balance.Mode.Strength(bestHi)
+		offerings[lo].Strength = balance.Mode.Strength(bestLo)
+	}
+}
+
+// spreadWith is the spread of offerings if offerings hi and lo had the given strengths.
+func spreadWith(offerings []Offering, hi int, hiStrength float64, lo int, loStrength float64) float64 {
+	minStrength, maxStrength := min(hiStrength, loStrength), max(hiStrength, loStrength)
+	for i, o := range offerings {
+		if i != hi && i != lo {
+			minStrength = min(minStrength, o.Strength)
+			maxStrength = max(maxStrength, o.Strength)
+		}
+	}
+	return maxStrength - minStrength
+}
+
+// satisfiesRules reports whether leaders would be a valid offering for player: every leader passes the All rules,
+// each AtLeastOne rule is met by a leader of its own and every diversity rule is satisfied.
+func satisfiesRules(player generated.Player, leaders []generated.Leader, rules []Rule) bool {
+	var slots []Rule
+	for _, rule := range rules {
+		switch rule.Type() {
+		case All:
+			for _, l := range leaders {
+				if !rule.IsValid(player, l) {
+					return false
+				}
+			}
+		case Diverse:
+			if d, ok := rule.(*DiversityRule); ok && !d.IsSatisfied(leaders) {
+				return false
+			}
+		default:
+			slots = append(slots, rule)
+		}
+	}
+
+	// Match AtLeastOne rules to distinct leaders with augmenting paths. owner holds the matched rule index + 1.
+	owner := make([]int, len(leaders))
+	var match func(r int, seen []bool) bool
+	match = func(r int, seen []bool) bool {
+		for i, l := range leaders {
+			if seen[i] || !slots[r].IsValid(player, l) {
+				continue
+			}
+			seen[i] = true
+			if owner[i] == 0 || match(owner[i]-1, seen) {
+				owner[i] = r + 1
+				return true
+			}
+		}
+		return false
+	}
+	for r := range slots {
+		if !match(r, make([]bool, len(leaders))) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,93 @@
+package ci6ndex
+
+import (
+	"ci6ndex/ci6ndex/generated"
+	"context"
+	"testing"
+)
+
+func TestLeaderStrength(t *testing.T) {
+	tests := []struct {
+		name   string
+		leader generated.Leader
+		want   float64
+	}{
+		{name: "S", leader: generated.Leader{Tier: 1}, want: 5},
+		{name: "B", leader: generated.Leader{Tier: 3}, want: 3},
+		{name: "F", leader: generated.Leader{Tier: 5}, want: 1},
+		{name: "rounds averaged tiers", leader: generated.Leader{Tier: 1.6}, want: 4},
+		{name: "unranked", leader: generated.Leader{Tier: 1, Unranked: true}, want: unrankedStrength},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LeaderStrength(tt.leader); got != tt.want {
+				t.Fatalf("expected strength %v, got %v", tt.want, got)
+			}
+		})
+	}
+
+	leaders := []generated.Leader{{Tier: 1}, {Tier: 4}, {Tier: 5}}
+	if got := BalanceBySum.Strength(leaders); got != 8 {
+		t.Fatalf("expected summed strength 8, got %v", got)
+	}
+	if got := BalanceByBest.Strength(leaders); got != 5 {
+		t.Fatalf("expected best strength 5, got %v", got)
+	}
+}
+
+func TestRollBalanced(t *testing.T) {
+	ctx := context.Background()
+	players, err := testDB.Queries.GetPlayersFromActiveDraft(ctx)
+	if err != nil {
+		t.Fatalf("failed to get players: %v", err)
+	}
+	playerIds := make([]int64, 6)
+	for i := range 6 {
+		playerIds[i] = players[i].ID
+	}
+
+	for _, mode := range []BalanceMode{BalanceBySum, BalanceByBest} {
+		t.Run(string(mode), func(t *testing.T) {
+			roll, err := testC.RollBalanced(testGuildID, playerIds, standardRules(), Balance{Mode: mode, Tolerance: 1})
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !roll.WithinTolerance {
+				t.Fatalf("expected a roll within tolerance, got a spread of %v", roll.Spread)
+			}
+			if len(roll.Offerings) != len(playerIds) {
+				t.Fatalf("expected %d offerings, got %d", len(playerIds), len(roll.Offerings))
+			}
+			for _, o := range roll.Offerings {
+				if !satisfiesRules(o.Player, o.Leaders, standardRules()) {
+					t.Fatalf("rebalancing broke the rules for player %d: %v", o.Player.ID, o.Leaders)
+				}
+				if o.Strength != mode.Strength(o.Leaders) {
+					t.Fatalf("player %d has strength %v, expected %v", o.Player.ID, o.Strength, mode.Strength(o.Leaders))
+				}
+			}
+			if roll.Spread != Spread(roll.Offerings) || roll.Spread > 1 {
+				t.Fatalf("expected a spread of at most 1, got %v", roll.Spread)
+			}
+		})
+	}
+}
+
+func TestRollBalanced_Fallback(t *testing.T) {
+	// Two players and one S and one F leader: no roll can be balanced
+	in := rollInput{
+		players: []generated.Player{{ID: 1}, {ID: 2}},
+		leaders: []generated.Leader{{ID: 1, Tier: 1}, {ID: 2, Tier: 5}},
+		draftID: 1,
+	}
+	roll, err := rollBalanced(in, []int64{1, 2}, []Rule{&NoOpRule{}}, Balance{Mode: BalanceBySum, Tolerance: 1})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if roll.WithinTolerance {
+		t.Fatal("expected the roll to fall back to the most balanced one found")
+	}
+	if roll.Spread != 4 {
+		t.Fatalf("expected a spread of 4, got %v", roll.Spread)
+	}
+}
@@ -0,0 +1,446 @@
+package ci6ndex
+
+import (
+	"ci6ndex/ci6ndex/generated"
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// BlindConflictRule settles two or more players secretly picking the same leader.
+type BlindConflictRule string
+
+const (
+	// BlindRepick sends every player who picked the clashing leader back to pick again
+	BlindRepick BlindConflictRule = "repick"
+	// BlindRandomWinner lets one of them keep the leader, drawn at random, and the others pick again
+	BlindRandomWinner BlindConflictRule = "random"
+	// BlindAllowDuplicates lets every player keep their pick, even the same leader
+	BlindAllowDuplicates BlindConflictRule = "allow"
+)
+
+const (
+	// BlindPickTimeout is how long a round of blind picks stays open before it's revealed anyway
+	BlindPickTimeout = 5 * time.Minute
+	// maxBlindRounds caps re-picks: clashes in the last round get a random winner so the draft always ends
+	maxBlindRounds = 3
+)
+
+var (
+	ErrNotBlindDraft = errors.New("the active draft isn't a blind pick draft")
+	ErrNotInDraft    = errors.New("player isn't registered for the draft")
+	ErrAlreadyPicked = errors.New("player's pick has already been revealed")
+)
+
+// blindMu serializes submitting and revealing blind picks, so a reveal triggered by the last pick and one
+// triggered by the deadline can't both lock in the same round.
+var blindMu sync.Mutex
+
+// BlindPlayer is a player in a blind pick draft.
+type BlindPlayer struct {
+	Player generated.Player
+	// Pick is the player's revealed leader, nil while they are still picking
+	Pick *generated.Leader
+	// Auto is true when the pick was made for the player because they didn't pick in time
+	Auto bool
+	// Submitted is true once the player has secretly picked in the current round
+	Submitted bool
+}
+
+// BlindDraft is the state of a blind pick draft. It never holds the secret picks of the current round.
+type BlindDraft struct {
+	DraftID int64
+	Rule    BlindConflictRule
+	Round   int
+	Players []BlindPlayer
+	// Available are the leaders that can be picked this round
+	Available      []generated.Leader
+	RoundStartedAt time.Time
+}
+
+func (d BlindDraft) Done() bool {
+	return !slices.ContainsFunc(d.Players, func(p BlindPlayer) bool { return p.Pick == nil })
+}
+
+// Waiting are the players who still have to pick this round.
+func (d BlindDraft) Waiting() []generated.Player {
+	var waiting []generated.Player
+	for _, p := range d.Players {
+		if p.Pick == nil && !p.Submitted {
+			waiting = append(waiting, p.Player)
+		}
+	}
+	return waiting
+}
+
+// Deadline is when the current round is revealed even if not everybody has picked.
+func (d BlindDraft) Deadline() time.Time {
+	return d.RoundStartedAt.Add(BlindPickTimeout)
+}
+
+// BlindRevealPick is a secret pick made public.
+type BlindRevealPick struct {
+	Player generated.Player
+	Leader generated.Leader
+	Auto   bool
+	// Kept is false when the pick clashed and the player has to pick again
+	Kept bool
+}
+
+// BlindClash is a leader picked by more than one player in the same round.
+type BlindClash struct {
+	Leader  generated.Leader
+	Players []generated.Player
+	// Winner kept the leader when the clash was settled by a random draw
+	Winner *generated.Player
+}
+
+// BlindReveal is the outcome of a round of blind picks.
+type BlindReveal struct {
+	Round   int
+	Rule    BlindConflictRule
+	Picks   []BlindRevealPick
+	Clashes []BlindClash
+	// Draft is the draft after the reveal, with the next round started if anybody has to pick again
+	Draft BlindDraft
+}
+
+type blindPick struct {
+	playerId int64
+	leaderId int64
+	auto     bool
+}
+
+// resolveBlindPicks decides which picks are kept and groups the clashes by leader. Random winners are drawn from a
+// source seeded by the draft and leader, so resolving the same picks again gives the same outcome.
+func resolveBlindPicks(picks []blindPick, rule BlindConflictRule, seed int64) (map[int64]bool, [][]blindPick) {
+	byLeader := make(map[int64][]blindPick)
+	for _, p := range picks {
+		byLeader[p.leaderId] = append(byLeader[p.leaderId], p)
+	}
+
+	kept := make(map[int64]bool, len(picks))
+	var clashes [][]blindPick
+	for _, leaderId := range slices.Sorted(maps.Keys(byLeader)) {
+		group := byLeader[leaderId]
+		slices.SortFunc(group, func(a, b blindPick) int { return cmp.Compare(a.playerId, b.playerId) })
+		if len(group) > 1 {
+			clashes = append(clashes, group)
+		}
+		switch {
+		case len(group) == 1 || rule == BlindAllowDuplicates:
+			for _, p := range group {
+				kept[p.playerId] = true
+			}
+		case rule == BlindRandomWinner:
+			r := rand.New(rand.NewPCG(uint64(seed), uint64(leaderId)))
+			kept[group[r.IntN(len(group))].playerId] = true
+		}
+	}
+	return kept, clashes
+}
+
+// StartBlindDraft turns the active draft into a blind pick draft, replacing any earlier picks, and opens the first
+// round of picks.
+func (c *Ci6ndex) StartBlindDraft(guildId uint64, rule BlindConflictRule) (BlindDraft, error) {
+	if rule != BlindRepick && rule != BlindRandomWinner && rule != BlindAllowDuplicates {
+		return BlindDraft{}, fmt.Errorf("unknown blind pick conflict rule %q", rule)
+	}
+	db, err := c.getDB(guildId)
+	if err != nil {
+		return BlindDraft{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	blindMu.Lock()
+	defer blindMu.Unlock()
+
+	draft, err := db.Queries.GetActiveDraft(ctx)
+	if err != nil {
+		return BlindDraft{}, errors.Join(err, errors.New("failed to get active draft"))
+	}
+	players, err := db.Queries.GetPlayersFromDraft(ctx, draft.ID)
+	if err != nil {
+		return BlindDraft{}, errors.Join(err, errors.New("failed to get players"))
+	}
+	if len(players) < 2 {
+		return BlindDraft{}, ErrNotEnoughPlayers
+	}
+
+	if err := db.Writes.DeletePicksForDraft(ctx, draft.ID); err != nil {
+		return BlindDraft{}, errors.Join(err, errors.New("failed to clear picks"))
+	}
+	if err := db.Writes.DeleteBlindPicks(ctx, draft.ID); err != nil {
+		return BlindDraft{}, errors.Join(err, errors.New("failed to clear blind picks"))
+	}
+	err = db.Writes.SetDraftMode(ctx, generated.SetDraftModeParams{Mode: string(DraftModeBlind), ID: draft.ID})
+	if err != nil {
+		return BlindDraft{}, errors.Join(err, errors.New("failed to set draft mode"))
+	}
+	err = db.Writes.SetBlindConflict(ctx, generated.SetBlindConflictParams{BlindConflict: string(rule), ID: draft.ID})
+	if err != nil {
+		return BlindDraft{}, errors.Join(err, errors.New("failed to set conflict rule"))
+	}
+	if err := startBlindRound(ctx, db, draft.ID, 0, true); err != nil {
+		return BlindDraft{}, err
+	}
+	return loadBlindDraft(ctx, db)
+}
+
+func (c *Ci6ndex) GetBlindDraft(guildId uint64) (BlindDraft, error) {
+	db, err := c.getDB(guildId)
+	if err != nil {
+		return BlindDraft{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	return loadBlindDraft(ctx, db)
+}
+
+// SubmitBlindPick secretly picks a leader for the player this round, replacing their earlier pick of the round.
+func (c *Ci6ndex) SubmitBlindPick(guildId uint64, playerId, leaderId int64) (BlindDraft, error) {
+	db, err := c.getDB(guildId)
+	if err != nil {
+		return BlindDraft{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	blindMu.Lock()
+	defer blindMu.Unlock()
+
+	draft, err := loadBlindDraft(ctx, db)
+	if err != nil {
+		return BlindDraft{}, err
+	}
+	i := slices.IndexFunc(draft.Players, func(p BlindPlayer) bool { return p.Player.ID == playerId })
+	if i < 0 {
+		return BlindDraft{}, ErrNotInDraft
+	}
+	if draft.Players[i].Pick != nil {
+		return BlindDraft{}, ErrAlreadyPicked
+	}
+	if !slices.ContainsFunc(draft.Available, func(l generated.Leader) bool { return l.ID == leaderId }) {
+		return BlindDraft{}, LeaderUnavailableError{LeaderID: leaderId}
+	}
+
+	err = db.Writes.SetBlindPick(ctx, generated.SetBlindPickParams{
+		DraftID:  draft.DraftID,
+		Round:    int64(draft.Round),
+		PlayerID: playerId,
+		LeaderID: leaderId,
+	})
+	if err != nil {
+		return BlindDraft{}, errors.Join(err, fmt.Errorf("failed to pick leader %d", leaderId))
+	}
+	return loadBlindDraft(ctx, db)
+}
+
+// ResolveBlindRound reveals a round of blind picks once everybody has picked, or regardless when force is set
+// because the deadline passed. Players who didn't pick get their highest rated available leader. Kept picks are
+// locked in and, if any clashed, the next round starts for the players who have to pick again. Rounds that were
+// already revealed are left alone, so it's safe to call from a timer that may fire late.
+func (c *Ci6ndex) ResolveBlindRound(guildId uint64, draftId int64, round int, force bool) (BlindReveal, bool, error) {
+	db, err := c.getDB(guildId)
+	if err != nil {
+		return BlindReveal{}, false, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	blindMu.Lock()
+	defer blindMu.Unlock()
+
+	draft, err := loadBlindDraft(ctx, db)
+	if err != nil {
+		return BlindReveal{}, false, err
+	}
+	if draft.DraftID != draftId || draft.Round != round || draft.Done() {
+		return BlindReveal{}, false, nil
+	}
+	if len(draft.Waiting()) > 0 && !force {
+		return BlindReveal{}, false, nil
+	}
+
+	secret, err := db.Queries.GetBlindPicks(ctx, generated.GetBlindPicksParams{DraftID: draftId, Round: int64(round)})
+	if err != nil {
+		return BlindReveal{}, false, errors.Join(err, errors.New("failed to get blind picks"))
+	}
+	picks := make([]blindPick, len(secret))
+	taken := make(map[int64]struct{}, len(secret))
+	for i, p := range secret {
+		picks[i] = blindPick{playerId: p.PlayerID, leaderId: p.LeaderID}
+		taken[p.LeaderID] = struct{}{}
+	}
+
+	// Auto-picks avoid the leaders picked this round and each other, so they never clash
+	remaining := filterAssigned(slices.Clone(draft.Available), taken)
+	for _, player := range draft.Waiting() {
+		if len(remaining) == 0 {
+			return BlindReveal{}, false, RanOutOfChoicesError{}
+		}
+		ranks, err := db.Queries.GetRanksForPlayer(ctx, player.ID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return BlindReveal{}, false, errors.Join(err, errors.New("failed to get player ranks"))
+		}
+		leader := autoPickFor(ranks, remaining)
+		picks = append(picks, blindPick{playerId: player.ID, leaderId: leader.ID, auto: true})
+		remaining = slices.DeleteFunc(remaining, func(l generated.Leader) bool { return l.ID == leader.ID })
+	}
+
+	rule := draft.Rule
+	if rule == BlindRepick && round+1 >= maxBlindRounds {
+		rule = BlindRandomWinner
+	}
+	kept, clashGroups := resolveBlindPicks(picks, rule, draftId)
+
+	leaders := make(map[int64]generated.Leader, len(draft.Available))
+	for _, l := range draft.Available {
+		leaders[l.ID] = l
+	}
+	players := make(map[int64]generated.Player, len(draft.Players))
+	for _, p := range draft.Players {
+		players[p.Player.ID] = p.Player
+	}
+
+	reveal := BlindReveal{Round: round, Rule: rule}
+	repick := false
+	for _, p := range picks {
+		if kept[p.playerId] {
+			err := db.Writes.AddPick(ctx, generated.AddPickParams{
+				Player:  strconv.FormatInt(p.playerId, 10),
+				DraftID: draftId,
+				Pick:    p.leaderId,
+				Auto:    p.auto,
+			})
+			if err != nil {
+				return BlindReveal{}, false, errors.Join(err, fmt.Errorf("failed to lock in leader %d", p.leaderId))
+			}
+		} else {
+			repick = true
+		}
+		reveal.Picks = append(reveal.Picks, BlindRevealPick{
+			Player: players[p.playerId],
+			Leader: leaders[p.leaderId],
+			Auto:   p.auto,
+			Kept:   kept[p.playerId],
+		})
+	}
+	slices.SortFunc(reveal.Picks, func(a, b BlindRevealPick) int { return cmp.Compare(a.Player.ID, b.Player.ID) })
+
+	for _, group := range clashGroups {
+		clash := BlindClash{Leader: leaders[group[0].leaderId]}
+		for _, p := range group {
+			clash.Players = append(clash.Players, players[p.playerId])
+			if kept[p.playerId] && rule == BlindRandomWinner {
+				winner := players[p.playerId]
+				clash.Winner = &winner
+			}
+		}
+		reveal.Clashes = append(reveal.Clashes, clash)
+	}
+
+	if repick {
+		err = startBlindRound(ctx, db, draftId, round+1, true)
+	} else {
+		err = startBlindRound(ctx, db, draftId, round, false)
+	}
+	if err != nil {
+		return BlindReveal{}, false, err
+	}
+	reveal.Draft, err = loadBlindDraft(ctx, db)
+	if err != nil {
+		return BlindReveal{}, false, err
+	}
+	return reveal, true, nil
+}
+
+// startBlindRound opens round for picks. Rounds that aren't timed have nothing left to pick.
+func startBlindRound(ctx context.Context, db *DB, draftId int64, round int, timed bool) error {
+	err := db.Writes.StartBlindRound(ctx, generated.StartBlindRoundParams{
+		BlindRound:    int64(round),
+		TurnStartedAt: sql.NullTime{Time: time.Now().UTC(), Valid: timed},
+		ID:            draftId,
+	})
+	if err != nil {
+		return errors.Join(err, errors.New("failed to start blind pick round"))
+	}
+	return nil
+}
+
+func loadBlindDraft(ctx context.Context, db *DB) (BlindDraft, error) {
+	draft, err := db.Queries.GetActiveDraft(ctx)
+	if err != nil {
+		return BlindDraft{}, errors.Join(err, errors.New("failed to get active draft"))
+	}
+	if DraftMode(draft.Mode) != DraftModeBlind {
+		return BlindDraft{}, ErrNotBlindDraft
+	}
+	players, err := db.Queries.GetPlayersFromDraft(ctx, draft.ID)
+	if err != nil {
+		return BlindDraft{}, errors.Join(err, errors.New("failed to get players"))
+	}
+	picks, err := db.Queries.GetPicksForDraft(ctx, draft.ID)
+	if err != nil {
+		return BlindDraft{}, errors.Join(err, errors.New("failed to get picks"))
+	}
+	secret, err := db.Queries.GetBlindPicks(ctx, generated.GetBlindPicksParams{
+		DraftID: draft.ID,
+		Round:   draft.BlindRound,
+	})
+	if err != nil {
+		return BlindDraft{}, errors.Join(err, errors.New("failed to get blind picks"))
+	}
+	leaders, err := db.Queries.GetEligibleLeaders(ctx)
+	if err != nil {
+		return BlindDraft{}, errors.Join(err, errors.New("failed to get leaders"))
+	}
+
+	picked, err := picksByPlayer(picks)
+	if err != nil {
+		return BlindDraft{}, err
+	}
+	submitted := make(map[int64]struct{}, len(secret))
+	for _, p := range secret {
+		submitted[p.PlayerID] = struct{}{}
+	}
+
+	state := BlindDraft{
+		DraftID:        draft.ID,
+		Rule:           BlindConflictRule(draft.BlindConflict),
+		Round:          int(draft.BlindRound),
+		Players:        make([]BlindPlayer, len(players)),
+		Available:      slices.Clone(leaders),
+		RoundStartedAt: draft.TurnStartedAt.Time,
+	}
+	slices.SortFunc(players, func(a, b generated.Player) int { return cmp.Compare(a.ID, b.ID) })
+	locked := make(map[int64]struct{}, len(picks))
+	for i, p := range players {
+		player := BlindPlayer{Player: p}
+		if pick, ok := picked[p.ID]; ok {
+			leader, err := pickedLeader(ctx, db, leaders, pick.Pick)
+			if err != nil {
+				return BlindDraft{}, err
+			}
+			player.Pick = &leader
+			player.Auto = pick.Auto
+			locked[pick.Pick] = struct{}{}
+		} else if _, ok := submitted[p.ID]; ok {
+			player.Submitted = true
+		}
+		state.Players[i] = player
+	}
+	if state.Rule != BlindAllowDuplicates {
+		state.Available = filterAssigned(state.Available, locked)
+	}
+	return state, nil
+}
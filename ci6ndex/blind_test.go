@@ -0,0 +1,124 @@
+package ci6ndex
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestResolveBlindPicks(t *testing.T) {
+	picks := []blindPick{
+		{playerId: 1, leaderId: 10},
+		{playerId: 2, leaderId: 10},
+		{playerId: 3, leaderId: 20},
+	}
+
+	kept, clashes := resolveBlindPicks(picks, BlindRepick, 1)
+	if kept[1] || kept[2] || !kept[3] {
+		t.Fatalf("expected only the unique pick to be kept, got %v", kept)
+	}
+	if len(clashes) != 1 || len(clashes[0]) != 2 || clashes[0][0].leaderId != 10 {
+		t.Fatalf("expected one clash over leader 10, got %v", clashes)
+	}
+
+	kept, _ = resolveBlindPicks(picks, BlindAllowDuplicates, 1)
+	if !kept[1] || !kept[2] || !kept[3] {
+		t.Fatalf("expected every pick to be kept, got %v", kept)
+	}
+
+	kept, _ = resolveBlindPicks(picks, BlindRandomWinner, 7)
+	if kept[1] == kept[2] || !kept[3] {
+		t.Fatalf("expected exactly one winner of the clash, got %v", kept)
+	}
+	for range 10 {
+		again, _ := resolveBlindPicks(picks, BlindRandomWinner, 7)
+		if again[1] != kept[1] || again[2] != kept[2] {
+			t.Fatalf("expected the same draw for the same seed, got %v and %v", kept, again)
+		}
+	}
+}
+
+func TestBlindDraft(t *testing.T) {
+	t.Cleanup(func() { resetActiveDraft(t) })
+
+	draft, err := testC.StartBlindDraft(testGuildID, BlindRepick)
+	if err != nil {
+		t.Fatalf("failed to start blind pick draft: %v", err)
+	}
+	if len(draft.Players) != 18 || len(draft.Waiting()) != 18 || draft.Round != 0 {
+		t.Fatalf("expected 18 players waiting in the first round, got %d of %d in round %d",
+			len(draft.Waiting()), len(draft.Players), draft.Round)
+	}
+
+	a, b := draft.Players[0].Player, draft.Players[1].Player
+	clashing, other := draft.Available[0], draft.Available[1]
+	if _, err := testC.SubmitBlindPick(testGuildID, a.ID, other.ID); err != nil {
+		t.Fatalf("failed to pick: %v", err)
+	}
+	// Picking again replaces the earlier pick of the round
+	if _, err := testC.SubmitBlindPick(testGuildID, a.ID, clashing.ID); err != nil {
+		t.Fatalf("failed to change pick: %v", err)
+	}
+	draft, err = testC.SubmitBlindPick(testGuildID, b.ID, clashing.ID)
+	if err != nil {
+		t.Fatalf("failed to pick: %v", err)
+	}
+	if len(draft.Waiting()) != 16 {
+		t.Fatalf("expected 16 players still picking, got %d", len(draft.Waiting()))
+	}
+	if _, err := testC.SubmitBlindPick(testGuildID, 1, clashing.ID); !errors.Is(err, ErrNotInDraft) {
+		t.Fatalf("expected a player outside the draft to be refused, got %v", err)
+	}
+
+	// The round isn't revealed early while players are still picking
+	if _, resolved, err := testC.ResolveBlindRound(testGuildID, draft.DraftID, 0, false); err != nil || resolved {
+		t.Fatalf("expected the round to stay hidden, got resolved=%v err=%v", resolved, err)
+	}
+
+	reveal, resolved, err := testC.ResolveBlindRound(testGuildID, draft.DraftID, 0, true)
+	if err != nil || !resolved {
+		t.Fatalf("expected the deadline to reveal the round, got resolved=%v err=%v", resolved, err)
+	}
+	if len(reveal.Picks) != 18 || len(reveal.Clashes) != 1 || reveal.Clashes[0].Leader.ID != clashing.ID {
+		t.Fatalf("expected 18 picks with one clash over leader %d, got %d picks and %v",
+			clashing.ID, len(reveal.Picks), reveal.Clashes)
+	}
+	auto := 0
+	for _, p := range reveal.Picks {
+		if p.Auto {
+			auto++
+		}
+		if p.Kept == (p.Player.ID == a.ID || p.Player.ID == b.ID) {
+			t.Fatalf("expected only the clashing players to pick again, got %+v", p)
+		}
+	}
+	if auto != 16 {
+		t.Fatalf("expected 16 auto-picks, got %d", auto)
+	}
+
+	draft = reveal.Draft
+	if draft.Round != 1 || len(draft.Waiting()) != 2 || draft.Done() {
+		t.Fatalf("expected the clashing players to pick again in round 1, got %d waiting in round %d",
+			len(draft.Waiting()), draft.Round)
+	}
+	var taken LeaderUnavailableError
+	if _, err := testC.SubmitBlindPick(testGuildID, a.ID, reveal.Picks[2].Leader.ID); !errors.As(err, &taken) {
+		t.Fatalf("expected a locked in leader to be unavailable, got %v", err)
+	}
+	if _, err := testC.SubmitBlindPick(testGuildID, reveal.Picks[2].Player.ID, other.ID); !errors.Is(err, ErrAlreadyPicked) {
+		t.Fatalf("expected a revealed player to be refused, got %v", err)
+	}
+
+	if _, err := testC.SubmitBlindPick(testGuildID, a.ID, clashing.ID); err != nil {
+		t.Fatalf("failed to pick: %v", err)
+	}
+	if _, err := testC.SubmitBlindPick(testGuildID, b.ID, other.ID); err != nil {
+		t.Fatalf("failed to pick: %v", err)
+	}
+	reveal, resolved, err = testC.ResolveBlindRound(testGuildID, draft.DraftID, 1, false)
+	if err != nil || !resolved {
+		t.Fatalf("expected the round to be revealed once everybody picked, got resolved=%v err=%v", resolved, err)
+	}
+	if !reveal.Draft.Done() || len(reveal.Clashes) != 0 {
+		t.Fatalf("expected the draft to be done without clashes, got %+v", reveal)
+	}
+}
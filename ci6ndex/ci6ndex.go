@@ -3,6 +3,7 @@ package ci6ndex
 import (
 	"embed"
 	"os"
+	"sync"
 	"time"
 
 	"github.com/charmbracelet/log"
@@ -11,6 +12,7 @@ import (
 type Ci6ndex struct {
 	Connections map[uint64]*DB
 	Path        string
+	mu          sync.Mutex
 }
 
 func New(embedMigrations embed.FS) (*Ci6ndex, error) {
@@ -28,6 +30,9 @@ func New(embedMigrations embed.FS) (*Ci6ndex, error) {
 			"error", err,
 		)
 	}
+	if err := ValidateLeaderMetadata(); err != nil {
+		logger.Error("Failed to load leader metadata. Abilities and play-style tags will be missing!", "error", err)
+	}
 
 	// Ensure data directory exists
 	dataPath := "./data/"
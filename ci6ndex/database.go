@@ -2,12 +2,18 @@ package ci6ndex
 
 import (
 	"ci6ndex/ci6ndex/generated"
+	"ci6ndex/metrics"
+	"context"
 	"database/sql"
 	"embed"
 	"fmt"
 	"log/slog"
+	"maps"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	_ "github.com/mattn/go-sqlite3"
 	"github.com/pkg/errors"
@@ -22,7 +28,8 @@ type DB struct {
 }
 
 func (c *Ci6ndex) openNewConnection(path string, guildId uint64) (*DB, error) {
-	dbUrl := "file:" + path + strconv.FormatUint(guildId, 10) + ".db"
+	// WAL lets the read connection keep reading while the write connection writes
+	dbUrl := "file:" + path + strconv.FormatUint(guildId, 10) + ".db?_journal_mode=WAL"
 
 	_, err := os.Stat(c.Path + strconv.FormatUint(guildId, 10) + ".db")
 
@@ -65,12 +72,15 @@ func (c *Ci6ndex) openNewConnection(path string, guildId uint64) (*DB, error) {
 	return &DB{
 		readConn:  readConn,
 		writeConn: writeConn,
-		Queries:   generated.New(readConn),
-		Writes:    generated.New(writeConn),
+		Queries:   generated.New(timedDB{db: readConn}),
+		Writes:    generated.New(timedDB{db: writeConn}),
 	}, nil
 }
 
 func (c *Ci6ndex) getDB(guildId uint64) (*DB, error) {
+	// the API serves requests concurrently, a guild's database must only be opened once
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	var db *DB
 	db, exists := c.Connections[guildId]
 	if !exists {
@@ -84,9 +94,36 @@ func (c *Ci6ndex) getDB(guildId uint64) (*DB, error) {
 	return db, nil
 }
 
+// Guilds returns the guilds that have a database in the data directory, without opening them.
+func (c *Ci6ndex) Guilds() ([]uint64, error) {
+	entries, err := os.ReadDir(c.Path)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read data directory")
+	}
+	guilds := make([]uint64, 0, len(entries))
+	for _, entry := range entries {
+		name, ok := strings.CutSuffix(entry.Name(), ".db")
+		if !ok || entry.IsDir() {
+			continue
+		}
+		if id, err := strconv.ParseUint(name, 10, 64); err == nil {
+			guilds = append(guilds, id)
+		}
+	}
+	slices.Sort(guilds)
+	return guilds, nil
+}
+
+// openDBs returns the databases opened so far by guild.
+func (c *Ci6ndex) openDBs() map[uint64]*DB {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return maps.Clone(c.Connections)
+}
+
 func (c *Ci6ndex) Health() []error {
 	var errs = make([]error, 0)
-	for _, db := range c.Connections {
+	for _, db := range c.openDBs() {
 		if err := db.readConn.Ping(); err != nil {
 			errs = append(errs, err)
 		}
@@ -97,8 +134,29 @@ func (c *Ci6ndex) Health() []error {
 	return errs
 }
 
+// Flush waits for the writes in progress to finish and checkpoints the WAL of every open database into its main file,
+// so nothing is left to replay when the databases are opened again.
+func (c *Ci6ndex) Flush(ctx context.Context) []error {
+	var errs = make([]error, 0)
+	for guildId, db := range c.openDBs() {
+		// the write connection only has one connection, so getting it waits for the pending writes
+		conn, err := db.writeConn.Conn(ctx)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to wait for pending writes of guild %d", guildId))
+			continue
+		}
+		if _, err := conn.ExecContext(ctx, "PRAGMA wal_checkpoint(TRUNCATE)"); err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to checkpoint database of guild %d", guildId))
+		}
+		if err := conn.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errs
+}
+
 func (c *Ci6ndex) Close() {
-	for _, db := range c.Connections {
+	for _, db := range c.openDBs() {
 		if err := db.readConn.Close(); err != nil {
 			slog.Error("failed to close read connection", "error", err)
 		}
@@ -136,3 +194,37 @@ func (c *Ci6ndex) migrateUp(db *sql.DB) error {
 	}
 	return nil
 }
+
+// timedDB records the latency of every query run through it in metrics.DBQueryDuration.
+type timedDB struct {
+	db *sql.DB
+}
+
+func (t timedDB) ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error) {
+	defer metrics.DBQueryDuration.ObserveDuration(time.Now(), queryName(query))
+	return t.db.ExecContext(ctx, query, args...)
+}
+
+func (t timedDB) PrepareContext(ctx context.Context, query string) (*sql.Stmt, error) {
+	return t.db.PrepareContext(ctx, query)
+}
+
+func (t timedDB) QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error) {
+	defer metrics.DBQueryDuration.ObserveDuration(time.Now(), queryName(query))
+	return t.db.QueryContext(ctx, query, args...)
+}
+
+func (t timedDB) QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row {
+	defer metrics.DBQueryDuration.ObserveDuration(time.Now(), queryName(query))
+	return t.db.QueryRowContext(ctx, query, args...)
+}
+
+// queryName reads the name sqlc puts at the start of every generated query, e.g. "-- name: GetPlayers :many".
+func queryName(query string) string {
+	rest, ok := strings.CutPrefix(query, "-- name: ")
+	if !ok {
+		return "unknown"
+	}
+	name, _, _ := strings.Cut(rest, " ")
+	return name
+}
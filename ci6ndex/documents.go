@@ -0,0 +1,260 @@
+package ci6ndex
+
+import (
+	"ci6ndex/ci6ndex/generated"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// MaxDocumentNameLength matches the longest label discord allows on a button
+	MaxDocumentNameLength = 80
+	// MaxDocumentNoteLength keeps notes short enough to show several on one screen
+	MaxDocumentNoteLength = 1000
+)
+
+// ErrDocumentPending is returned when voting for a document that is still waiting for an admin's approval.
+var ErrDocumentPending = errors.New("the document is waiting for approval")
+
+// DocumentValidationError is returned when a submitted document is rejected. The message is meant for the player.
+type DocumentValidationError struct {
+	Reason string
+}
+
+func (e DocumentValidationError) Error() string {
+	return e.Reason
+}
+
+// LeaderDocument is a guide, changelog or note about a leader along with its votes.
+type LeaderDocument struct {
+	Document generated.Document
+	Votes    int64
+}
+
+// IsNote reports whether the document is a markdown note rather than a link.
+func (d LeaderDocument) IsNote() bool {
+	return d.Document.Link == ""
+}
+
+// DocumentSubmission is a document a player wants to add to a leader. Exactly one of Link and Note is set.
+type DocumentSubmission struct {
+	LeaderID int64
+	PlayerID int64
+	Name     string
+	Link     string
+	Note     string
+	// Approved documents are visible right away, otherwise they wait for an admin
+	Approved bool
+}
+
+// Validate trims the submission and checks it can be shown in discord.
+func (s *DocumentSubmission) Validate() error {
+	s.Name = strings.TrimSpace(s.Name)
+	s.Link = strings.TrimSpace(s.Link)
+	s.Note = strings.TrimSpace(s.Note)
+
+	switch {
+	case s.Name == "":
+		return DocumentValidationError{Reason: "a name is required"}
+	case utf8.RuneCountInString(s.Name) > MaxDocumentNameLength:
+		return DocumentValidationError{
+			Reason: fmt.Sprintf("the name can be at most %d characters", MaxDocumentNameLength),
+		}
+	case s.Link == "" && s.Note == "":
+		return DocumentValidationError{Reason: "either a link or a note is required"}
+	case s.Link != "" && s.Note != "":
+		return DocumentValidationError{Reason: "submit either a link or a note, not both"}
+	case utf8.RuneCountInString(s.Note) > MaxDocumentNoteLength:
+		return DocumentValidationError{
+			Reason: fmt.Sprintf("notes can be at most %d characters", MaxDocumentNoteLength),
+		}
+	}
+	if s.Link != "" {
+		return validateDocumentLink(s.Link)
+	}
+	return nil
+}
+
+// validateDocumentLink only accepts absolute http(s) URLs, discord refuses anything else on a link button.
+func validateDocumentLink(link string) error {
+	u, err := url.Parse(link)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return DocumentValidationError{Reason: fmt.Sprintf("%q is not a valid http(s) link", link)}
+	}
+	if len(link) > 512 {
+		return DocumentValidationError{Reason: "links can be at most 512 characters"}
+	}
+	return nil
+}
+
+// GetDocumentsForLeader returns every document for the leader, including ones waiting for approval, most voted first.
+func (c *Ci6ndex) GetDocumentsForLeader(guildID uint64, leaderID int64) ([]LeaderDocument, error) {
+	db, err := c.getDB(guildID)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := db.Queries.GetDocumentsForLeader(ctx, leaderID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return make([]LeaderDocument, 0), nil
+		}
+		return nil, err
+	}
+	docs := make([]LeaderDocument, len(rows))
+	for i, r := range rows {
+		docs[i] = LeaderDocument{
+			Document: generated.Document{
+				ID:          r.ID,
+				LeaderID:    r.LeaderID,
+				DocName:     r.DocName,
+				Link:        r.Link,
+				Note:        r.Note,
+				SubmittedBy: r.SubmittedBy,
+				Approved:    r.Approved,
+				CreatedAt:   r.CreatedAt,
+			},
+			Votes: r.Votes,
+		}
+	}
+	return docs, nil
+}
+
+// GetPendingDocuments returns the documents of every leader waiting for approval, oldest first.
+func (c *Ci6ndex) GetPendingDocuments(guildID uint64) ([]generated.Document, error) {
+	db, err := c.getDB(guildID)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	docs, err := db.Queries.GetPendingDocuments(ctx)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return make([]generated.Document, 0), nil
+		}
+		return nil, errors.Join(err, errors.New("failed to query pending documents"))
+	}
+	return docs, nil
+}
+
+// SubmitDocument validates and stores a document, see DocumentSubmission.
+func (c *Ci6ndex) SubmitDocument(guildID uint64, submission DocumentSubmission) (generated.Document, error) {
+	if err := submission.Validate(); err != nil {
+		return generated.Document{}, err
+	}
+	db, err := c.getDB(guildID)
+	if err != nil {
+		return generated.Document{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	note := sql.NullString{}
+	if submission.Note != "" {
+		note = sql.NullString{String: submission.Note, Valid: true}
+	}
+	doc, err := db.Writes.AddDocument(ctx, generated.AddDocumentParams{
+		LeaderID:    submission.LeaderID,
+		DocName:     submission.Name,
+		Link:        submission.Link,
+		Note:        note,
+		SubmittedBy: sql.NullInt64{Int64: submission.PlayerID, Valid: submission.PlayerID != 0},
+		Approved:    submission.Approved,
+	})
+	if err != nil {
+		return generated.Document{}, errors.Join(err, fmt.Errorf("failed to add document for leader=%d",
+			submission.LeaderID))
+	}
+	return doc, nil
+}
+
+// ApproveDocument makes a pending document visible to every player.
+func (c *Ci6ndex) ApproveDocument(guildID uint64, documentID int64) error {
+	db, err := c.getDB(guildID)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if _, err := db.Queries.GetDocument(ctx, documentID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return fmt.Errorf("document with ID %d not found", documentID)
+		}
+		return errors.Join(err, fmt.Errorf("failed to fetch document=%d", documentID))
+	}
+	if err := db.Writes.ApproveDocument(ctx, documentID); err != nil {
+		return errors.Join(err, fmt.Errorf("failed to approve document=%d", documentID))
+	}
+	return nil
+}
+
+// RemoveDocument deletes a document and its votes.
+func (c *Ci6ndex) RemoveDocument(guildID uint64, documentID int64) error {
+	db, err := c.getDB(guildID)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if err := db.Writes.DeleteDocumentVotes(ctx, documentID); err != nil {
+		return errors.Join(err, fmt.Errorf("failed to remove votes for document=%d", documentID))
+	}
+	if err := db.Writes.DeleteDocument(ctx, documentID); err != nil {
+		return errors.Join(err, fmt.Errorf("failed to remove document=%d", documentID))
+	}
+	return nil
+}
+
+// ToggleDocumentVote adds the player's vote to a document, or takes it back if they already voted. Returns whether
+// the player has voted afterwards. Pending documents can't be voted for, see ErrDocumentPending.
+func (c *Ci6ndex) ToggleDocumentVote(guildID uint64, documentID, playerID int64) (bool, error) {
+	db, err := c.getDB(guildID)
+	if err != nil {
+		return false, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	doc, err := db.Queries.GetDocument(ctx, documentID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, fmt.Errorf("document with ID %d not found", documentID)
+		}
+		return false, errors.Join(err, fmt.Errorf("failed to fetch document=%d", documentID))
+	}
+	if !doc.Approved {
+		return false, ErrDocumentPending
+	}
+
+	added, err := db.Writes.AddDocumentVote(ctx, generated.AddDocumentVoteParams{
+		DocumentID: documentID,
+		PlayerID:   playerID,
+	})
+	if err != nil {
+		return false, errors.Join(err, fmt.Errorf("failed to vote for document=%d", documentID))
+	}
+	if added > 0 {
+		return true, nil
+	}
+
+	err = db.Writes.RemoveDocumentVote(ctx, generated.RemoveDocumentVoteParams{
+		DocumentID: documentID,
+		PlayerID:   playerID,
+	})
+	if err != nil {
+		return false, errors.Join(err, fmt.Errorf("failed to remove vote for document=%d", documentID))
+	}
+	return false, nil
+}
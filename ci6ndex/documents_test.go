@@ -0,0 +1,128 @@
+package ci6ndex
+
+import (
+	"ci6ndex/ci6ndex/generated"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestDocumentSubmission_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		sub     DocumentSubmission
+		wantErr bool
+	}{
+		{name: "link", sub: DocumentSubmission{Name: "Guide", Link: "https://example.com/guide"}},
+		{name: "note", sub: DocumentSubmission{Name: "Opening", Note: "Settle on the river, **always**."}},
+		{name: "missing name", sub: DocumentSubmission{Name: "  ", Link: "https://example.com"}, wantErr: true},
+		{name: "long name", sub: DocumentSubmission{Name: strings.Repeat("a", 81), Note: "x"}, wantErr: true},
+		{name: "neither", sub: DocumentSubmission{Name: "Guide"}, wantErr: true},
+		{name: "both", sub: DocumentSubmission{Name: "Guide", Link: "https://example.com", Note: "x"}, wantErr: true},
+		{name: "long note", sub: DocumentSubmission{Name: "Guide", Note: strings.Repeat("a", 1001)}, wantErr: true},
+		{name: "no scheme", sub: DocumentSubmission{Name: "Guide", Link: "example.com/guide"}, wantErr: true},
+		{name: "bad scheme", sub: DocumentSubmission{Name: "Guide", Link: "javascript:alert(1)"}, wantErr: true},
+		{name: "no host", sub: DocumentSubmission{Name: "Guide", Link: "https:///guide"}, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.sub.Validate()
+			if tt.wantErr {
+				var validationErr DocumentValidationError
+				if !errors.As(err, &validationErr) {
+					t.Fatalf("expected a validation error, got %v", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		})
+	}
+}
+
+func TestDocumentLifecycle(t *testing.T) {
+	// Chandragupta's seeded guides have no votes, so a voted note sorts first
+	const leaderID int64 = 40
+	const author, voter int64 = 1014, 1015
+
+	doc, err := testC.SubmitDocument(testGuildID, DocumentSubmission{
+		LeaderID: leaderID,
+		PlayerID: author,
+		Name:     " Terrace farm opener ",
+		Note:     "Build terrace farms next to mountains.",
+	})
+	if err != nil {
+		t.Fatalf("failed to submit document: %v", err)
+	}
+	if doc.Approved || doc.DocName != "Terrace farm opener" || doc.SubmittedBy.Int64 != author {
+		t.Fatalf("unexpected document: %+v", doc)
+	}
+
+	pending, err := testC.GetPendingDocuments(testGuildID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !containsDocument(pending, doc.ID) {
+		t.Fatalf("expected document %d to be pending", doc.ID)
+	}
+
+	if _, err := testC.ToggleDocumentVote(testGuildID, doc.ID, voter); !errors.Is(err, ErrDocumentPending) {
+		t.Fatalf("expected voting on a pending document to fail, got %v", err)
+	}
+	if err := testC.ApproveDocument(testGuildID, doc.ID); err != nil {
+		t.Fatalf("failed to approve: %v", err)
+	}
+	pending, err = testC.GetPendingDocuments(testGuildID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if containsDocument(pending, doc.ID) {
+		t.Fatalf("expected document %d to no longer be pending", doc.ID)
+	}
+
+	voted, err := testC.ToggleDocumentVote(testGuildID, doc.ID, voter)
+	if err != nil || !voted {
+		t.Fatalf("expected vote to be added, got %v, %v", voted, err)
+	}
+	docs, err := testC.GetDocumentsForLeader(testGuildID, leaderID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if docs[0].Document.ID != doc.ID || docs[0].Votes != 1 || !docs[0].IsNote() {
+		t.Fatalf("expected the voted note first with 1 vote, got %+v", docs[0])
+	}
+
+	voted, err = testC.ToggleDocumentVote(testGuildID, doc.ID, voter)
+	if err != nil || voted {
+		t.Fatalf("expected vote to be removed, got %v, %v", voted, err)
+	}
+
+	if err := testC.RemoveDocument(testGuildID, doc.ID); err != nil {
+		t.Fatalf("failed to remove: %v", err)
+	}
+	docs, err = testC.GetDocumentsForLeader(testGuildID, leaderID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for _, d := range docs {
+		if d.Document.ID == doc.ID {
+			t.Fatalf("expected document %d to be removed", doc.ID)
+		}
+	}
+	if _, err := testC.ToggleDocumentVote(testGuildID, doc.ID, voter); err == nil {
+		t.Fatalf("expected voting on a removed document to fail")
+	}
+	if err := testC.ApproveDocument(testGuildID, doc.ID); err == nil {
+		t.Fatalf("expected approving a removed document to fail")
+	}
+}
+
+func containsDocument(docs []generated.Document, id int64) bool {
+	for _, d := range docs {
+		if d.ID == id {
+			return true
+		}
+	}
+	return false
+}
@@ -6,7 +6,21 @@ import (
 	"database/sql"
 	"github.com/pkg/errors"
 	"log/slog"
+	"strconv"
 	"sync"
+	"time"
+)
+
+// DraftMode is how players get their leaders in a draft.
+type DraftMode string
+
+const (
+	// DraftModeRandom offers every player their own random pool of leaders
+	DraftModeRandom DraftMode = "random"
+	// DraftModeSnake has players take turns picking from one shared list of eligible leaders
+	DraftModeSnake DraftMode = "snake"
+	// DraftModeBlind has every player secretly pick any eligible leader, revealed all at once
+	DraftModeBlind DraftMode = "blind"
 )
 
 func (c *Ci6ndex) GetOrCreateActiveDraft(guildId uint64) (generated.Draft, error) {
@@ -77,3 +91,121 @@ func (c *Ci6ndex) SetPlayersForDraft(guildId uint64, draftId int64,
 
 	return nil
 }
+
+// SetDraftWinner records playerId as the winner of draftId, replacing any earlier result
+func (c *Ci6ndex) SetDraftWinner(guildId uint64, draftId int64, playerId int64) error {
+	db, err := c.getDB(guildId)
+	if err != nil {
+		return err
+	}
+	err = db.Writes.SetDraftWinner(context.Background(), generated.SetDraftWinnerParams{
+		DraftID: draftId,
+		Winner:  strconv.FormatInt(playerId, 10),
+	})
+	if err != nil {
+		return errors.Wrapf(err, "failed to set winner for draft=%d", draftId)
+	}
+	return nil
+}
+
+// ErrDraftNotFound is returned when a guild has no draft with the requested ID.
+var ErrDraftNotFound = errors.New("draft not found")
+
+// DraftSummary is a draft and its result, Result is nil until a winner is recorded.
+type DraftSummary struct {
+	Draft  generated.Draft
+	Result *generated.DraftResult
+}
+
+// DraftRecord is everything recorded about a single draft.
+type DraftRecord struct {
+	DraftSummary
+	Players   []generated.Player
+	Offerings []generated.Pool
+	Picks     []generated.Pick
+}
+
+// GetDrafts returns every draft of the guild with its result, newest first.
+func (c *Ci6ndex) GetDrafts(guildId uint64) ([]DraftSummary, error) {
+	db, err := c.getDB(guildId)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	rows, err := db.Queries.GetDrafts(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get drafts")
+	}
+	drafts := make([]DraftSummary, 0, len(rows))
+	for _, row := range rows {
+		summary := DraftSummary{Draft: generated.Draft{
+			ID:            row.ID,
+			Active:        row.Active,
+			Mode:          row.Mode,
+			TurnStartedAt: row.TurnStartedAt,
+			BlindConflict: row.BlindConflict,
+			BlindRound:    row.BlindRound,
+		}}
+		if row.Winner.Valid {
+			summary.Result = &generated.DraftResult{
+				DraftID:    row.ID,
+				Winner:     row.Winner.String,
+				RecordedAt: row.RecordedAt.Time,
+			}
+		}
+		drafts = append(drafts, summary)
+	}
+	return drafts, nil
+}
+
+// GetDraft returns the players, offerings, picks and result of draftId, or ErrDraftNotFound.
+func (c *Ci6ndex) GetDraft(guildId uint64, draftId int64) (DraftRecord, error) {
+	db, err := c.getDB(guildId)
+	if err != nil {
+		return DraftRecord{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	draft, err := db.Queries.GetDraft(ctx, draftId)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return DraftRecord{}, ErrDraftNotFound
+		}
+		return DraftRecord{}, errors.Wrapf(err, "failed to get draft=%d", draftId)
+	}
+	record := DraftRecord{DraftSummary: DraftSummary{Draft: draft}}
+
+	result, err := db.Queries.GetDraftResult(ctx, draftId)
+	switch {
+	case err == nil:
+		record.Result = &result
+	case !errors.Is(err, sql.ErrNoRows):
+		return DraftRecord{}, errors.Wrapf(err, "failed to get result of draft=%d", draftId)
+	}
+	if record.Players, err = db.Queries.GetPlayersFromDraft(ctx, draftId); err != nil {
+		return DraftRecord{}, errors.Wrapf(err, "failed to get players of draft=%d", draftId)
+	}
+	if record.Offerings, err = db.Queries.GetOffersByDraftId(ctx, draftId); err != nil {
+		return DraftRecord{}, errors.Wrapf(err, "failed to get offerings of draft=%d", draftId)
+	}
+	if record.Picks, err = db.Queries.GetPicksForDraft(ctx, draftId); err != nil {
+		return DraftRecord{}, errors.Wrapf(err, "failed to get picks of draft=%d", draftId)
+	}
+	return record, nil
+}
+
+// GetDraftResults returns the recorded results of the guild's drafts, most recent first.
+func (c *Ci6ndex) GetDraftResults(guildId uint64) ([]generated.DraftResult, error) {
+	db, err := c.getDB(guildId)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	results, err := db.Queries.GetDraftResults(ctx)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get draft results")
+	}
+	return results, nil
+}
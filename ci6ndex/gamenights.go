@@ -0,0 +1,266 @@
+package ci6ndex
+
+import (
+	"ci6ndex/ci6ndex/generated"
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// RSVP is a player's answer to a game night.
+type RSVP string
+
+const (
+	RSVPYes   RSVP = "yes"
+	RSVPMaybe RSVP = "maybe"
+	RSVPNo    RSVP = "no"
+)
+
+// GameNightReminderLead is how long before a game night starts its reminder is posted.
+const GameNightReminderLead = 30 * time.Minute
+
+// gameNightLayout is the date and time format hosts schedule game nights in.
+const gameNightLayout = "2006-01-02 15:04"
+
+var ErrNoRSVPs = errors.New("nobody has said yes to the game night")
+
+// GameNight is a scheduled game, announced in a channel players RSVP in.
+type GameNight struct {
+	ID       int64
+	StartsAt time.Time
+	// Timezone is the IANA zone the game night was scheduled in, StartsAt is shown in it
+	Timezone  string
+	ChannelID uint64
+	MessageID uint64
+	CreatedBy int64
+	Reminded  bool
+	RSVPs     []GameNightRSVP
+}
+
+type GameNightRSVP struct {
+	Player   generated.Player
+	Response RSVP
+}
+
+// ReminderAt is when the reminder is due, right away if the game night starts sooner than GameNightReminderLead.
+func (g GameNight) ReminderAt() time.Time {
+	return g.StartsAt.Add(-GameNightReminderLead)
+}
+
+// Local is StartsAt in the zone the game night was scheduled in.
+func (g GameNight) Local() time.Time {
+	loc, err := time.LoadLocation(g.Timezone)
+	if err != nil {
+		return g.StartsAt
+	}
+	return g.StartsAt.In(loc)
+}
+
+// Players returns the players who answered with response, in the order they answered.
+func (g GameNight) Players(response RSVP) []generated.Player {
+	var players []generated.Player
+	for _, r := range g.RSVPs {
+		if r.Response == response {
+			players = append(players, r.Player)
+		}
+	}
+	return players
+}
+
+// InvalidGameNightError explains why a game night can't be scheduled, the reason is safe to show to users.
+type InvalidGameNightError struct {
+	Reason string
+}
+
+func (e InvalidGameNightError) Error() string {
+	return e.Reason
+}
+
+// ParseGameNightTime parses a date (2006-01-02) and time (15:04) in an IANA timezone such as Europe/Berlin. The game
+// night has to start after now.
+func ParseGameNightTime(date, clock, timezone string, now time.Time) (time.Time, error) {
+	loc, err := time.LoadLocation(timezone)
+	if err != nil || timezone == "" || timezone == "Local" {
+		return time.Time{}, InvalidGameNightError{
+			Reason: fmt.Sprintf("Unknown timezone %q, use a name like Europe/Berlin or America/New_York", timezone),
+		}
+	}
+	startsAt, err := time.ParseInLocation(gameNightLayout, date+" "+clock, loc)
+	if err != nil {
+		return time.Time{}, InvalidGameNightError{
+			Reason: fmt.Sprintf("Couldn't read %q %q, use a date like 2025-01-31 and a time like 20:30", date, clock),
+		}
+	}
+	if !startsAt.After(now) {
+		return time.Time{}, InvalidGameNightError{Reason: "Game nights have to start in the future"}
+	}
+	return startsAt.UTC(), nil
+}
+
+// ScheduleGameNight creates a game night announced in the given channel.
+func (c *Ci6ndex) ScheduleGameNight(
+	guildId uint64,
+	startsAt time.Time,
+	timezone string,
+	channelId uint64,
+	createdBy int64,
+) (GameNight, error) {
+	db, err := c.getDB(guildId)
+	if err != nil {
+		return GameNight{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	row, err := db.Writes.CreateGameNight(ctx, generated.CreateGameNightParams{
+		StartsAt:  startsAt.UTC(),
+		Timezone:  timezone,
+		ChannelID: int64(channelId),
+		CreatedBy: createdBy,
+	})
+	if err != nil {
+		return GameNight{}, errors.Join(err, errors.New("failed to create game night"))
+	}
+	return gameNightFromRow(row), nil
+}
+
+// SetGameNightMessage remembers the message announcing a game night, so it can be updated as players RSVP.
+func (c *Ci6ndex) SetGameNightMessage(guildId uint64, gameNightId int64, messageId uint64) error {
+	db, err := c.getDB(guildId)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err = db.Writes.SetGameNightMessage(ctx, generated.SetGameNightMessageParams{
+		MessageID: int64(messageId),
+		ID:        gameNightId,
+	})
+	if err != nil {
+		return errors.Join(err, errors.New("failed to set game night message"))
+	}
+	return nil
+}
+
+// RespondToGameNight records a player's RSVP, replacing their earlier answer. The player has to be registered first.
+func (c *Ci6ndex) RespondToGameNight(guildId uint64, gameNightId, playerId int64, response RSVP) (GameNight, error) {
+	if response != RSVPYes && response != RSVPMaybe && response != RSVPNo {
+		return GameNight{}, fmt.Errorf("unknown rsvp %q", response)
+	}
+	db, err := c.getDB(guildId)
+	if err != nil {
+		return GameNight{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err = db.Writes.SetRSVP(ctx, generated.SetRSVPParams{
+		GameNightID: gameNightId,
+		PlayerID:    playerId,
+		Response:    string(response),
+	})
+	if err != nil {
+		return GameNight{}, errors.Join(err, errors.New("failed to save rsvp"))
+	}
+	return loadGameNight(ctx, db, gameNightId)
+}
+
+func (c *Ci6ndex) GetGameNight(guildId uint64, gameNightId int64) (GameNight, error) {
+	db, err := c.getDB(guildId)
+	if err != nil {
+		return GameNight{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	return loadGameNight(ctx, db, gameNightId)
+}
+
+// MarkReminded records that a game night's reminder is being posted. It returns false when it already was, so a
+// reminder that is retried is only posted once.
+func (c *Ci6ndex) MarkReminded(guildId uint64, gameNightId int64) (GameNight, bool, error) {
+	db, err := c.getDB(guildId)
+	if err != nil {
+		return GameNight{}, false, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	n, err := db.Writes.MarkGameNightReminded(ctx, gameNightId)
+	if err != nil {
+		return GameNight{}, false, errors.Join(err, errors.New("failed to mark game night reminded"))
+	}
+	if n == 0 {
+		return GameNight{}, false, nil
+	}
+	night, err := loadGameNight(ctx, db, gameNightId)
+	return night, err == nil, err
+}
+
+// StartDraftFromRSVPs registers every player who said yes to a game night on the active draft, replacing the players
+// registered before.
+func (c *Ci6ndex) StartDraftFromRSVPs(guildId uint64, gameNightId int64) ([]generated.Player, error) {
+	night, err := c.GetGameNight(guildId, gameNightId)
+	if err != nil {
+		return nil, err
+	}
+	players := night.Players(RSVPYes)
+	if len(players) == 0 {
+		return nil, ErrNoRSVPs
+	}
+
+	draft, err := c.GetOrCreateActiveDraft(guildId)
+	if err != nil {
+		return nil, errors.Join(err, errors.New("failed to get active draft"))
+	}
+	params := make([]generated.AddPlayerParams, len(players))
+	for i, p := range players {
+		params[i] = generated.AddPlayerParams{
+			ID:            p.ID,
+			Username:      p.Username,
+			GlobalName:    p.GlobalName,
+			DiscordAvatar: p.DiscordAvatar,
+		}
+	}
+	if errs := c.SetPlayersForDraft(guildId, draft.ID, params); len(errs) > 0 {
+		return nil, errors.Join(append(errs, errors.New("failed to register players"))...)
+	}
+	return players, nil
+}
+
+func loadGameNight(ctx context.Context, db *DB, gameNightId int64) (GameNight, error) {
+	row, err := db.Queries.GetGameNight(ctx, gameNightId)
+	if err != nil {
+		return GameNight{}, errors.Join(err, fmt.Errorf("failed to get game night %d", gameNightId))
+	}
+	rsvps, err := db.Queries.GetGameNightRSVPs(ctx, gameNightId)
+	if err != nil {
+		return GameNight{}, errors.Join(err, errors.New("failed to get rsvps"))
+	}
+	night := gameNightFromRow(row)
+	for _, r := range rsvps {
+		night.RSVPs = append(night.RSVPs, GameNightRSVP{
+			Player: generated.Player{
+				ID:            r.ID,
+				Username:      r.Username,
+				GlobalName:    r.GlobalName,
+				DiscordAvatar: r.DiscordAvatar,
+			},
+			Response: RSVP(r.Response),
+		})
+	}
+	return night, nil
+}
+
+func gameNightFromRow(row generated.GameNight) GameNight {
+	return GameNight{
+		ID:        row.ID,
+		StartsAt:  row.StartsAt.UTC(),
+		Timezone:  row.Timezone,
+		ChannelID: uint64(row.ChannelID),
+		MessageID: uint64(row.MessageID),
+		CreatedBy: row.CreatedBy,
+		Reminded:  row.Reminded,
+	}
+}
@@ -0,0 +1,114 @@
+package ci6ndex
+
+import (
+	"ci6ndex/ci6ndex/generated"
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseGameNightTime(t *testing.T) {
+	now := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		name     string
+		date     string
+		clock    string
+		timezone string
+		want     time.Time
+		valid    bool
+	}{
+		{name: "utc", date: "2025-03-02", clock: "20:30", timezone: "UTC",
+			want: time.Date(2025, 3, 2, 20, 30, 0, 0, time.UTC), valid: true},
+		{name: "berlin", date: "2025-03-02", clock: "20:30", timezone: "Europe/Berlin",
+			want: time.Date(2025, 3, 2, 19, 30, 0, 0, time.UTC), valid: true},
+		{name: "unknown timezone", date: "2025-03-02", clock: "20:30", timezone: "Mars/Olympus"},
+		{name: "no timezone", date: "2025-03-02", clock: "20:30", timezone: ""},
+		{name: "bad date", date: "03/02/2025", clock: "20:30", timezone: "UTC"},
+		{name: "in the past", date: "2025-02-28", clock: "20:30", timezone: "UTC"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseGameNightTime(tt.date, tt.clock, tt.timezone, now)
+			if tt.valid {
+				if err != nil || !got.Equal(tt.want) {
+					t.Fatalf("expected %v, got %v (%v)", tt.want, got, err)
+				}
+				return
+			}
+			var invalid InvalidGameNightError
+			if !errors.As(err, &invalid) {
+				t.Fatalf("expected an InvalidGameNightError, got %v", err)
+			}
+		})
+	}
+}
+
+func TestGameNight(t *testing.T) {
+	ctx := context.Background()
+	registered, err := testDB.Queries.GetPlayersFromActiveDraft(ctx)
+	if err != nil {
+		t.Fatalf("failed to get players: %v", err)
+	}
+	t.Cleanup(func() {
+		draft, err := testDB.Queries.GetActiveDraft(ctx)
+		if err != nil {
+			t.Fatalf("failed to get active draft: %v", err)
+		}
+		params := make([]generated.AddPlayerParams, len(registered))
+		for i, p := range registered {
+			params[i] = generated.AddPlayerParams{ID: p.ID, Username: p.Username}
+		}
+		if errs := testC.SetPlayersForDraft(testGuildID, draft.ID, params); len(errs) > 0 {
+			t.Fatalf("failed to restore players: %v", errors.Join(errs...))
+		}
+	})
+
+	startsAt := time.Now().Add(2 * time.Hour).Truncate(time.Minute).UTC()
+	night, err := testC.ScheduleGameNight(testGuildID, startsAt, "Europe/Berlin", 42, registered[0].ID)
+	if err != nil {
+		t.Fatalf("failed to schedule game night: %v", err)
+	}
+	if !night.StartsAt.Equal(startsAt) || night.Reminded {
+		t.Fatalf("expected a game night at %v, got %+v", startsAt, night)
+	}
+
+	if _, err := testC.StartDraftFromRSVPs(testGuildID, night.ID); !errors.Is(err, ErrNoRSVPs) {
+		t.Fatalf("expected a game night without rsvps to not start a draft, got %v", err)
+	}
+
+	responses := []RSVP{RSVPYes, RSVPMaybe, RSVPYes, RSVPNo}
+	for i, r := range responses {
+		if _, err := testC.RespondToGameNight(testGuildID, night.ID, registered[i].ID, r); err != nil {
+			t.Fatalf("failed to rsvp: %v", err)
+		}
+	}
+	// Changing your mind replaces the earlier answer
+	night, err = testC.RespondToGameNight(testGuildID, night.ID, registered[1].ID, RSVPYes)
+	if err != nil {
+		t.Fatalf("failed to rsvp: %v", err)
+	}
+	if len(night.RSVPs) != 4 || len(night.Players(RSVPYes)) != 3 || len(night.Players(RSVPMaybe)) != 0 {
+		t.Fatalf("expected 3 yes and 1 no, got %+v", night.RSVPs)
+	}
+
+	if _, ok, err := testC.MarkReminded(testGuildID, night.ID); err != nil || !ok {
+		t.Fatalf("expected the first reminder to be posted, got ok=%v err=%v", ok, err)
+	}
+	if _, ok, err := testC.MarkReminded(testGuildID, night.ID); err != nil || ok {
+		t.Fatalf("expected the reminder to only be posted once, got ok=%v err=%v", ok, err)
+	}
+
+	players, err := testC.StartDraftFromRSVPs(testGuildID, night.ID)
+	if err != nil {
+		t.Fatalf("failed to start draft: %v", err)
+	}
+	draftPlayers, err := testDB.Queries.GetPlayersFromActiveDraft(ctx)
+	if err != nil {
+		t.Fatalf("failed to get players: %v", err)
+	}
+	if len(players) != 3 || len(draftPlayers) != 3 {
+		t.Fatalf("expected the 3 players who said yes to be registered, got %d", len(draftPlayers))
+	}
+}
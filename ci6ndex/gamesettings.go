@@ -0,0 +1,273 @@
+package ci6ndex
+
+import (
+	"ci6ndex/ci6ndex/generated"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+// GameSettingCategory is a lobby setting rolled alongside the leaders, one value per draft.
+type GameSettingCategory string
+
+const (
+	GameSettingMapType     GameSettingCategory = "map_type"
+	GameSettingMapSize     GameSettingCategory = "map_size"
+	GameSettingGameSpeed   GameSettingCategory = "game_speed"
+	GameSettingDisasters   GameSettingCategory = "disasters"
+	GameSettingStartingEra GameSettingCategory = "starting_era"
+	GameSettingVictory     GameSettingCategory = "victory"
+	GameSettingCityStates  GameSettingCategory = "city_states"
+)
+
+// GameSettingCategories lists every category in the order they're shown in.
+var GameSettingCategories = []GameSettingCategory{
+	GameSettingMapType,
+	GameSettingMapSize,
+	GameSettingGameSpeed,
+	GameSettingDisasters,
+	GameSettingStartingEra,
+	GameSettingVictory,
+	GameSettingCityStates,
+}
+
+// Name is how the category is called in the game's lobby.
+func (c GameSettingCategory) Name() string {
+	switch c {
+	case GameSettingMapType:
+		return "Map Type"
+	case GameSettingMapSize:
+		return "Map Size"
+	case GameSettingGameSpeed:
+		return "Game Speed"
+	case GameSettingDisasters:
+		return "Disaster Intensity"
+	case GameSettingStartingEra:
+		return "Starting Era"
+	case GameSettingVictory:
+		return "Victory Conditions"
+	case GameSettingCityStates:
+		return "City-States"
+	}
+	return string(c)
+}
+
+// GameSettingStatus is whether a value is on the guild's allowed or blocked list.
+type GameSettingStatus string
+
+const (
+	// GameSettingDefault values are rolled by their weight, unless the category has allowed values
+	GameSettingDefault GameSettingStatus = "default"
+	// GameSettingAllowed values are the only ones their category is rolled from
+	GameSettingAllowed GameSettingStatus = "allowed"
+	// GameSettingBlocked values are never rolled
+	GameSettingBlocked GameSettingStatus = "blocked"
+)
+
+// GameSettingOption is one value a category can be rolled to. Values are picked with a chance proportional to their
+// Weight, a weight of 0 is only rolled when nothing else in the category can be.
+type GameSettingOption struct {
+	Category GameSettingCategory
+	Value    string
+	Weight   int64
+	Status   GameSettingStatus
+}
+
+// GameSetting is the value a category was rolled to for a draft.
+type GameSetting struct {
+	Category GameSettingCategory
+	Value    string
+}
+
+// NoGameSettingOptionsError is returned when every value of a category is blocked.
+type NoGameSettingOptionsError struct {
+	Category GameSettingCategory
+}
+
+func (e NoGameSettingOptionsError) Error() string {
+	return fmt.Sprintf("every %s is blocked", e.Category.Name())
+}
+
+// UnknownGameSettingError is returned when a value isn't in the guild's catalogue.
+type UnknownGameSettingError struct {
+	Category GameSettingCategory
+	Value    string
+}
+
+func (e UnknownGameSettingError) Error() string {
+	return fmt.Sprintf("unknown %s %q", e.Category.Name(), e.Value)
+}
+
+// candidates are the options a category may be rolled from: its allowed values if it has any, otherwise everything
+// that isn't blocked.
+func candidates(options []GameSettingOption) []GameSettingOption {
+	allowed := slices.DeleteFunc(slices.Clone(options), func(o GameSettingOption) bool {
+		return o.Status != GameSettingAllowed
+	})
+	if len(allowed) > 0 {
+		return allowed
+	}
+	return slices.DeleteFunc(slices.Clone(options), func(o GameSettingOption) bool {
+		return o.Status == GameSettingBlocked
+	})
+}
+
+// pickWeighted picks one option with a chance proportional to its weight. When every weight is 0 they're equally
+// likely, so allowing a value nobody weighted still gets it rolled.
+func pickWeighted(options []GameSettingOption, r *rand.Rand) GameSettingOption {
+	total := int64(0)
+	for _, o := range options {
+		total += max(o.Weight, 0)
+	}
+	if total == 0 {
+		return options[r.IntN(len(options))]
+	}
+	n := r.Int64N(total)
+	for _, o := range options {
+		n -= max(o.Weight, 0)
+		if n < 0 {
+			return o
+		}
+	}
+	return options[len(options)-1]
+}
+
+// rollGameSettings rolls one value for every category in GameSettingCategories.
+func rollGameSettings(options []GameSettingOption, r *rand.Rand) ([]GameSetting, error) {
+	byCategory := make(map[GameSettingCategory][]GameSettingOption)
+	for _, o := range options {
+		byCategory[o.Category] = append(byCategory[o.Category], o)
+	}
+
+	settings := make([]GameSetting, 0, len(GameSettingCategories))
+	for _, category := range GameSettingCategories {
+		eligible := candidates(byCategory[category])
+		if len(eligible) == 0 {
+			return nil, NoGameSettingOptionsError{Category: category}
+		}
+		picked := pickWeighted(eligible, r)
+		settings = append(settings, GameSetting{Category: category, Value: picked.Value})
+	}
+	return settings, nil
+}
+
+// GetGameSettingOptions returns the guild's catalogue of game settings.
+func (c *Ci6ndex) GetGameSettingOptions(guildId uint64) ([]GameSettingOption, error) {
+	db, err := c.getDB(guildId)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := db.Queries.GetGameSettingOptions(ctx)
+	if err != nil {
+		return nil, errors.Join(err, errors.New("failed to get game setting options"))
+	}
+	options := make([]GameSettingOption, len(rows))
+	for i, row := range rows {
+		options[i] = GameSettingOption{
+			Category: GameSettingCategory(row.Category),
+			Value:    row.Value,
+			Weight:   row.Weight,
+			Status:   GameSettingStatus(row.Status),
+		}
+	}
+	return options, nil
+}
+
+// SetGameSettingStatuses puts the given values of a category on the allowed or blocked list, and takes every other
+// value of the category off that list.
+func (c *Ci6ndex) SetGameSettingStatuses(
+	guildId uint64,
+	category GameSettingCategory,
+	status GameSettingStatus,
+	values []string,
+) error {
+	if status != GameSettingAllowed && status != GameSettingBlocked {
+		return fmt.Errorf("unknown game setting status %q", status)
+	}
+	options, err := c.GetGameSettingOptions(guildId)
+	if err != nil {
+		return err
+	}
+	for _, v := range values {
+		if !slices.ContainsFunc(options, func(o GameSettingOption) bool {
+			return o.Category == category && o.Value == v
+		}) {
+			return UnknownGameSettingError{Category: category, Value: v}
+		}
+	}
+
+	db, err := c.getDB(guildId)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	for _, o := range options {
+		if o.Category != category {
+			continue
+		}
+		next := o.Status
+		if slices.Contains(values, o.Value) {
+			next = status
+		} else if o.Status == status {
+			next = GameSettingDefault
+		}
+		if next == o.Status {
+			continue
+		}
+		err := db.Writes.SetGameSettingStatus(ctx, generated.SetGameSettingStatusParams{
+			Status:   string(next),
+			Category: string(o.Category),
+			Value:    o.Value,
+		})
+		if err != nil {
+			return errors.Join(err, fmt.Errorf("failed to update %s %q", o.Category.Name(), o.Value))
+		}
+	}
+	return nil
+}
+
+// RollGameSettings rolls every game setting for the active draft and stores them on it, replacing any earlier roll.
+func (c *Ci6ndex) RollGameSettings(guildId uint64) ([]GameSetting, error) {
+	options, err := c.GetGameSettingOptions(guildId)
+	if err != nil {
+		return nil, err
+	}
+	settings, err := rollGameSettings(options, rand.New(rand.NewPCG(rand.Uint64(), rand.Uint64())))
+	if err != nil {
+		return nil, err
+	}
+
+	db, err := c.getDB(guildId)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	draft, err := db.Queries.GetActiveDraft(ctx)
+	if err != nil {
+		return nil, errors.Join(err, errors.New("failed to get active draft"))
+	}
+	if err := db.Writes.DeleteDraftGameSettings(ctx, draft.ID); err != nil {
+		return nil, errors.Join(err, errors.New("failed to clear game settings"))
+	}
+	for _, s := range settings {
+		err := db.Writes.AddDraftGameSetting(ctx, generated.AddDraftGameSettingParams{
+			DraftID:  draft.ID,
+			Category: string(s.Category),
+			Value:    s.Value,
+		})
+		if err != nil {
+			return nil, errors.Join(err, fmt.Errorf("failed to store %s", s.Category.Name()))
+		}
+	}
+	return settings, nil
+}
@@ -0,0 +1,123 @@
+package ci6ndex
+
+import (
+	"context"
+	"errors"
+	"math/rand/v2"
+	"testing"
+)
+
+func TestRollGameSettings(t *testing.T) {
+	var options []GameSettingOption
+	for _, category := range GameSettingCategories {
+		options = append(options,
+			GameSettingOption{Category: category, Value: "common", Weight: 3},
+			GameSettingOption{Category: category, Value: "rare", Weight: 1},
+			GameSettingOption{Category: category, Value: "never", Weight: 0},
+		)
+	}
+	r := rand.New(rand.NewPCG(1, 2))
+
+	counts := make(map[string]int)
+	for range 400 {
+		settings, err := rollGameSettings(options, r)
+		if err != nil {
+			t.Fatalf("failed to roll game settings: %v", err)
+		}
+		if len(settings) != len(GameSettingCategories) {
+			t.Fatalf("expected a setting per category, got %v", settings)
+		}
+		counts[settings[0].Value]++
+	}
+	if counts["never"] != 0 {
+		t.Fatalf("expected a weight of 0 to never be rolled, got %v", counts)
+	}
+	// 3 to 1 odds over 400 rolls
+	if counts["common"] < 250 || counts["rare"] < 50 {
+		t.Fatalf("expected rolls to follow the weights, got %v", counts)
+	}
+}
+
+func TestRollGameSettings_AllowedAndBlocked(t *testing.T) {
+	var options []GameSettingOption
+	for _, category := range GameSettingCategories {
+		options = append(options,
+			GameSettingOption{Category: category, Value: "blocked", Weight: 100, Status: GameSettingBlocked},
+			GameSettingOption{Category: category, Value: "default", Weight: 1, Status: GameSettingDefault},
+		)
+	}
+	// Allowing a value restricts its category to the allowed values, even one weighted 0
+	options = append(options,
+		GameSettingOption{Category: GameSettingMapType, Value: "allowed", Weight: 0, Status: GameSettingAllowed})
+	r := rand.New(rand.NewPCG(3, 4))
+
+	for range 50 {
+		settings, err := rollGameSettings(options, r)
+		if err != nil {
+			t.Fatalf("failed to roll game settings: %v", err)
+		}
+		for _, s := range settings {
+			want := "default"
+			if s.Category == GameSettingMapType {
+				want = "allowed"
+			}
+			if s.Value != want {
+				t.Fatalf("expected %s to be rolled to %q, got %q", s.Category, want, s.Value)
+			}
+		}
+	}
+
+	options = options[:0]
+	for _, category := range GameSettingCategories {
+		options = append(options, GameSettingOption{Category: category, Value: "blocked", Status: GameSettingBlocked})
+	}
+	var noOptions NoGameSettingOptionsError
+	if _, err := rollGameSettings(options, r); !errors.As(err, &noOptions) {
+		t.Fatalf("expected every value being blocked to fail, got %v", err)
+	}
+}
+
+func TestRollGameSettings_Stored(t *testing.T) {
+	t.Cleanup(func() {
+		for _, status := range []GameSettingStatus{GameSettingAllowed, GameSettingBlocked} {
+			if err := testC.SetGameSettingStatuses(testGuildID, GameSettingGameSpeed, status, nil); err != nil {
+				t.Fatalf("failed to reset game speed: %v", err)
+			}
+		}
+	})
+
+	if err := testC.SetGameSettingStatuses(testGuildID, GameSettingGameSpeed, GameSettingAllowed,
+		[]string{"Marathon"}); err != nil {
+		t.Fatalf("failed to allow game speed: %v", err)
+	}
+	var unknown UnknownGameSettingError
+	err := testC.SetGameSettingStatuses(testGuildID, GameSettingGameSpeed, GameSettingBlocked, []string{"Ludicrous"})
+	if !errors.As(err, &unknown) {
+		t.Fatalf("expected an unknown game speed to be refused, got %v", err)
+	}
+
+	settings, err := testC.RollGameSettings(testGuildID)
+	if err != nil {
+		t.Fatalf("failed to roll game settings: %v", err)
+	}
+	ctx := context.Background()
+	draft, err := testDB.Queries.GetActiveDraft(ctx)
+	if err != nil {
+		t.Fatalf("failed to get active draft: %v", err)
+	}
+	stored, err := testDB.Queries.GetDraftGameSettings(ctx, draft.ID)
+	if err != nil {
+		t.Fatalf("failed to get game settings: %v", err)
+	}
+	if len(stored) != len(settings) {
+		t.Fatalf("expected %d stored settings, got %d", len(settings), len(stored))
+	}
+	for i, s := range stored {
+		if GameSettingCategory(s.Category) != settings[i].Category || s.Value != settings[i].Value {
+			t.Fatalf("expected %v to be stored, got %v", settings[i], s)
+		}
+		if GameSettingCategory(s.Category) == GameSettingGameSpeed && s.Value != "Marathon" {
+			t.Fatalf("expected the only allowed game speed, got %q", s.Value)
+		}
+	}
+}
@@ -9,16 +9,44 @@ import (
 	"time"
 )
 
-type Document struct {
-	ID       int64
+type BlindPick struct {
+	DraftID  int64
+	Round    int64
+	PlayerID int64
 	LeaderID int64
-	DocName  string
-	Link     string
+	PickedAt time.Time
+}
+
+type Document struct {
+	ID          int64
+	LeaderID    int64
+	DocName     string
+	Link        string
+	Note        sql.NullString
+	SubmittedBy sql.NullInt64
+	Approved    bool
+	CreatedAt   sql.NullTime
+}
+
+type DocumentVote struct {
+	DocumentID int64
+	PlayerID   int64
+	VotedAt    time.Time
 }
 
 type Draft struct {
-	ID     int64
-	Active bool
+	ID            int64
+	Active        bool
+	Mode          string
+	TurnStartedAt sql.NullTime
+	BlindConflict string
+	BlindRound    int64
+}
+
+type DraftGameSetting struct {
+	DraftID  int64
+	Category string
+	Value    string
 }
 
 type DraftRegistry struct {
@@ -26,6 +54,49 @@ type DraftRegistry struct {
 	DraftID  int64
 }
 
+type DraftResult struct {
+	DraftID    int64
+	Winner     string
+	RecordedAt time.Time
+}
+
+type DraftTeam struct {
+	DraftID  int64
+	PlayerID int64
+	Team     int64
+}
+
+type DraftTurn struct {
+	DraftID  int64
+	Turn     int64
+	PlayerID int64
+}
+
+type GameNight struct {
+	ID        int64
+	StartsAt  time.Time
+	Timezone  string
+	ChannelID int64
+	MessageID int64
+	CreatedBy int64
+	Reminded  bool
+	CreatedAt time.Time
+}
+
+type GameNightRsvp struct {
+	GameNightID int64
+	PlayerID    int64
+	Response    string
+	UpdatedAt   time.Time
+}
+
+type GameSettingOption struct {
+	Category string
+	Value    string
+	Weight   int64
+	Status   string
+}
+
 type GameVersion struct {
 	ID          int64
 	Name        string
@@ -33,6 +104,18 @@ type GameVersion struct {
 	Current     bool
 }
 
+type Job struct {
+	ID        int64
+	Kind      string
+	Tag       string
+	Payload   string
+	DueAt     time.Time
+	Status    string
+	Attempts  int64
+	LastError string
+	CreatedAt time.Time
+}
+
 type Leader struct {
 	ID                 int64
 	CivName            string
@@ -42,12 +125,14 @@ type Leader struct {
 	Tier               float64
 	FriendlyName       sql.NullString
 	Unranked           bool
+	BbgExpanded        bool
 }
 
 type Pick struct {
 	Player  string
 	DraftID int64
 	Pick    int64
+	Auto    bool
 }
 
 type Player struct {
@@ -71,3 +156,33 @@ type Rank struct {
 	UpdatedAt time.Time
 	Bbg       bool
 }
+
+type RankHistory struct {
+	ID          int64
+	LeaderID    int64
+	PlayerID    int64
+	Tier        float64
+	Bbg         bool
+	SubmittedAt time.Time
+}
+
+type RollSetting struct {
+	ID               int64
+	PoolSize         int64
+	MinTier          float64
+	DiversityMin     int64
+	DiversityMode    string
+	UpdatedAt        time.Time
+	BalanceMode      string
+	BalanceTolerance float64
+	TeamTag          string
+	PlayerTag        string
+}
+
+type TierHistory struct {
+	ID           int64
+	LeaderID     int64
+	Tier         float64
+	PlayerID     sql.NullInt64
+	CalculatedAt time.Time
+}
@@ -8,41 +8,57 @@ package generated
 import (
 	"context"
 	"database/sql"
+	"time"
 )
 
 const getActiveDraft = `-- name: GetActiveDraft :one
-SELECT id, active FROM drafts WHERE active = true
+SELECT id, active, mode, turn_started_at, blind_conflict, blind_round FROM drafts WHERE active = true
 `
 
 func (q *Queries) GetActiveDraft(ctx context.Context) (Draft, error) {
 	row := q.db.QueryRowContext(ctx, getActiveDraft)
 	var i Draft
-	err := row.Scan(&i.ID, &i.Active)
+	err := row.Scan(
+		&i.ID,
+		&i.Active,
+		&i.Mode,
+		&i.TurnStartedAt,
+		&i.BlindConflict,
+		&i.BlindRound,
+	)
 	return i, err
 }
 
-const getAllRanks = `-- name: GetAllRanks :many
-SELECT id, leader_id, player_id, tier, updated_at, bbg
+const getAllRanksForLeader = `-- name: GetAllRanksForLeader :many
+SELECT
+    r.player_id,
+    r.tier,
+    r.leader_id  
 FROM ranks r
+WHERE r.leader_id = ? AND r.bbg = ?
 `
 
-func (q *Queries) GetAllRanks(ctx context.Context) ([]Rank, error) {
-	rows, err := q.db.QueryContext(ctx, getAllRanks)
+type GetAllRanksForLeaderParams struct {
+	LeaderID int64
+	Bbg      bool
+}
+
+type GetAllRanksForLeaderRow struct {
+	PlayerID int64
+	Tier     float64
+	LeaderID int64
+}
+
+func (q *Queries) GetAllRanksForLeader(ctx context.Context, arg GetAllRanksForLeaderParams) ([]GetAllRanksForLeaderRow, error) {
+	rows, err := q.db.QueryContext(ctx, getAllRanksForLeader, arg.LeaderID, arg.Bbg)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Rank
+	var items []GetAllRanksForLeaderRow
 	for rows.Next() {
-		var i Rank
-		if err := rows.Scan(
-			&i.ID,
-			&i.LeaderID,
-			&i.PlayerID,
-			&i.Tier,
-			&i.UpdatedAt,
-			&i.Bbg,
-		); err != nil {
+		var i GetAllRanksForLeaderRow
+		if err := rows.Scan(&i.PlayerID, &i.Tier, &i.LeaderID); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -56,31 +72,33 @@ func (q *Queries) GetAllRanks(ctx context.Context) ([]Rank, error) {
 	return items, nil
 }
 
-const getAllRanksForLeader = `-- name: GetAllRanksForLeader :many
-SELECT
-    r.player_id,
-    r.tier,
-    r.leader_id  
-FROM ranks r
-WHERE r.leader_id = ?
+const getBlindPicks = `-- name: GetBlindPicks :many
+SELECT draft_id, round, player_id, leader_id, picked_at
+FROM blind_picks
+WHERE draft_id = ? AND round = ?
 `
 
-type GetAllRanksForLeaderRow struct {
-	PlayerID int64
-	Tier     float64
-	LeaderID int64
+type GetBlindPicksParams struct {
+	DraftID int64
+	Round   int64
 }
 
-func (q *Queries) GetAllRanksForLeader(ctx context.Context, leaderID int64) ([]GetAllRanksForLeaderRow, error) {
-	rows, err := q.db.QueryContext(ctx, getAllRanksForLeader, leaderID)
+func (q *Queries) GetBlindPicks(ctx context.Context, arg GetBlindPicksParams) ([]BlindPick, error) {
+	rows, err := q.db.QueryContext(ctx, getBlindPicks, arg.DraftID, arg.Round)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetAllRanksForLeaderRow
+	var items []BlindPick
 	for rows.Next() {
-		var i GetAllRanksForLeaderRow
-		if err := rows.Scan(&i.PlayerID, &i.Tier, &i.LeaderID); err != nil {
+		var i BlindPick
+		if err := rows.Scan(
+			&i.DraftID,
+			&i.Round,
+			&i.PlayerID,
+			&i.LeaderID,
+			&i.PickedAt,
+		); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -94,27 +112,68 @@ func (q *Queries) GetAllRanksForLeader(ctx context.Context, leaderID int64) ([]G
 	return items, nil
 }
 
+const getDocument = `-- name: GetDocument :one
+SELECT id, leader_id, doc_name, link, note, submitted_by, approved, created_at
+FROM documents d
+WHERE d.id = ?
+`
+
+func (q *Queries) GetDocument(ctx context.Context, id int64) (Document, error) {
+	row := q.db.QueryRowContext(ctx, getDocument, id)
+	var i Document
+	err := row.Scan(
+		&i.ID,
+		&i.LeaderID,
+		&i.DocName,
+		&i.Link,
+		&i.Note,
+		&i.SubmittedBy,
+		&i.Approved,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
 const getDocumentsForLeader = `-- name: GetDocumentsForLeader :many
 SELECT
-    d.id, d.leader_id, d.doc_name, d.link
+    d.id, d.leader_id, d.doc_name, d.link, d.note, d.submitted_by, d.approved, d.created_at,
+    (SELECT COUNT(*) FROM document_votes v WHERE v.document_id = d.id) AS votes
 FROM documents d
 WHERE d.leader_id = ?
+ORDER BY votes DESC, d.id
 `
 
-func (q *Queries) GetDocumentsForLeader(ctx context.Context, leaderID int64) ([]Document, error) {
+type GetDocumentsForLeaderRow struct {
+	ID          int64
+	LeaderID    int64
+	DocName     string
+	Link        string
+	Note        sql.NullString
+	SubmittedBy sql.NullInt64
+	Approved    bool
+	CreatedAt   sql.NullTime
+	Votes       int64
+}
+
+func (q *Queries) GetDocumentsForLeader(ctx context.Context, leaderID int64) ([]GetDocumentsForLeaderRow, error) {
 	rows, err := q.db.QueryContext(ctx, getDocumentsForLeader, leaderID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Document
+	var items []GetDocumentsForLeaderRow
 	for rows.Next() {
-		var i Document
+		var i GetDocumentsForLeaderRow
 		if err := rows.Scan(
 			&i.ID,
 			&i.LeaderID,
 			&i.DocName,
 			&i.Link,
+			&i.Note,
+			&i.SubmittedBy,
+			&i.Approved,
+			&i.CreatedAt,
+			&i.Votes,
 		); err != nil {
 			return nil, err
 		}
@@ -129,29 +188,41 @@ func (q *Queries) GetDocumentsForLeader(ctx context.Context, leaderID int64) ([]
 	return items, nil
 }
 
-const getEligibleLeaders = `-- name: GetEligibleLeaders :many
-SELECT id, civ_name, leader_name, discord_emoji_string, banned, tier, friendly_name, unranked FROM leaders WHERE banned = false
+const getDraft = `-- name: GetDraft :one
+SELECT id, active, mode, turn_started_at, blind_conflict, blind_round FROM drafts WHERE id = ?
 `
 
-func (q *Queries) GetEligibleLeaders(ctx context.Context) ([]Leader, error) {
-	rows, err := q.db.QueryContext(ctx, getEligibleLeaders)
+func (q *Queries) GetDraft(ctx context.Context, id int64) (Draft, error) {
+	row := q.db.QueryRowContext(ctx, getDraft, id)
+	var i Draft
+	err := row.Scan(
+		&i.ID,
+		&i.Active,
+		&i.Mode,
+		&i.TurnStartedAt,
+		&i.BlindConflict,
+		&i.BlindRound,
+	)
+	return i, err
+}
+
+const getDraftGameSettings = `-- name: GetDraftGameSettings :many
+SELECT draft_id, category, value
+FROM draft_game_settings
+WHERE draft_id = ?
+ORDER BY rowid
+`
+
+func (q *Queries) GetDraftGameSettings(ctx context.Context, draftID int64) ([]DraftGameSetting, error) {
+	rows, err := q.db.QueryContext(ctx, getDraftGameSettings, draftID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Leader
+	var items []DraftGameSetting
 	for rows.Next() {
-		var i Leader
-		if err := rows.Scan(
-			&i.ID,
-			&i.CivName,
-			&i.LeaderName,
-			&i.DiscordEmojiString,
-			&i.Banned,
-			&i.Tier,
-			&i.FriendlyName,
-			&i.Unranked,
-		); err != nil {
+		var i DraftGameSetting
+		if err := rows.Scan(&i.DraftID, &i.Category, &i.Value); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -165,52 +236,33 @@ func (q *Queries) GetEligibleLeaders(ctx context.Context) ([]Leader, error) {
 	return items, nil
 }
 
-const getLeaderById = `-- name: GetLeaderById :one
-SELECT id, civ_name, leader_name, discord_emoji_string, banned, tier, friendly_name, unranked
-FROM leaders l
-WHERE l.id = ?
+const getDraftResult = `-- name: GetDraftResult :one
+SELECT draft_id, winner, recorded_at FROM draft_results WHERE draft_id = ?
 `
 
-func (q *Queries) GetLeaderById(ctx context.Context, id int64) (Leader, error) {
-	row := q.db.QueryRowContext(ctx, getLeaderById, id)
-	var i Leader
-	err := row.Scan(
-		&i.ID,
-		&i.CivName,
-		&i.LeaderName,
-		&i.DiscordEmojiString,
-		&i.Banned,
-		&i.Tier,
-		&i.FriendlyName,
-		&i.Unranked,
-	)
+func (q *Queries) GetDraftResult(ctx context.Context, draftID int64) (DraftResult, error) {
+	row := q.db.QueryRowContext(ctx, getDraftResult, draftID)
+	var i DraftResult
+	err := row.Scan(&i.DraftID, &i.Winner, &i.RecordedAt)
 	return i, err
 }
 
-const getLeaders = `-- name: GetLeaders :many
-SELECT id, civ_name, leader_name, discord_emoji_string, banned, tier, friendly_name, unranked FROM leaders
-ORDER BY civ_name, leader_name
+const getDraftResults = `-- name: GetDraftResults :many
+SELECT draft_id, winner, recorded_at
+FROM draft_results
+ORDER BY recorded_at DESC, draft_id DESC
 `
 
-func (q *Queries) GetLeaders(ctx context.Context) ([]Leader, error) {
-	rows, err := q.db.QueryContext(ctx, getLeaders)
+func (q *Queries) GetDraftResults(ctx context.Context) ([]DraftResult, error) {
+	rows, err := q.db.QueryContext(ctx, getDraftResults)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Leader
+	var items []DraftResult
 	for rows.Next() {
-		var i Leader
-		if err := rows.Scan(
-			&i.ID,
-			&i.CivName,
-			&i.LeaderName,
-			&i.DiscordEmojiString,
-			&i.Banned,
-			&i.Tier,
-			&i.FriendlyName,
-			&i.Unranked,
-		); err != nil {
+		var i DraftResult
+		if err := rows.Scan(&i.DraftID, &i.Winner, &i.RecordedAt); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -224,36 +276,37 @@ func (q *Queries) GetLeaders(ctx context.Context) ([]Leader, error) {
 	return items, nil
 }
 
-const getLeadersByLimitAndOffset = `-- name: GetLeadersByLimitAndOffset :many
-SELECT id, civ_name, leader_name, discord_emoji_string, banned, tier, friendly_name, unranked
-FROM leaders l
-ORDER BY l.civ_name, l.leader_name
-LIMIT ? OFFSET ?
+const getDraftTurns = `-- name: GetDraftTurns :many
+SELECT t.turn, p.id, p.username, p.global_name, p.discord_avatar
+FROM draft_turns t
+JOIN players p ON t.player_id = p.id
+WHERE t.draft_id = ?
+ORDER BY t.turn
 `
 
-type GetLeadersByLimitAndOffsetParams struct {
-	Limit  int64
-	Offset int64
+type GetDraftTurnsRow struct {
+	Turn          int64
+	ID            int64
+	Username      string
+	GlobalName    sql.NullString
+	DiscordAvatar sql.NullString
 }
 
-func (q *Queries) GetLeadersByLimitAndOffset(ctx context.Context, arg GetLeadersByLimitAndOffsetParams) ([]Leader, error) {
-	rows, err := q.db.QueryContext(ctx, getLeadersByLimitAndOffset, arg.Limit, arg.Offset)
+func (q *Queries) GetDraftTurns(ctx context.Context, draftID int64) ([]GetDraftTurnsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getDraftTurns, draftID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Leader
+	var items []GetDraftTurnsRow
 	for rows.Next() {
-		var i Leader
+		var i GetDraftTurnsRow
 		if err := rows.Scan(
+			&i.Turn,
 			&i.ID,
-			&i.CivName,
-			&i.LeaderName,
-			&i.DiscordEmojiString,
-			&i.Banned,
-			&i.Tier,
-			&i.FriendlyName,
-			&i.Unranked,
+			&i.Username,
+			&i.GlobalName,
+			&i.DiscordAvatar,
 		); err != nil {
 			return nil, err
 		}
@@ -268,20 +321,29 @@ func (q *Queries) GetLeadersByLimitAndOffset(ctx context.Context, arg GetLeaders
 	return items, nil
 }
 
-const getOffersByDraftId = `-- name: GetOffersByDraftId :many
-SELECT player_id, draft_id, leader FROM pool WHERE draft_id = ?
+const getDraftWinCounts = `-- name: GetDraftWinCounts :many
+SELECT dr.player_id, COUNT(r.draft_id) AS wins
+FROM draft_registry dr
+LEFT JOIN draft_results r ON r.winner = CAST(dr.player_id AS TEXT)
+WHERE dr.draft_id = ?
+GROUP BY dr.player_id
 `
 
-func (q *Queries) GetOffersByDraftId(ctx context.Context, draftID int64) ([]Pool, error) {
-	rows, err := q.db.QueryContext(ctx, getOffersByDraftId, draftID)
+type GetDraftWinCountsRow struct {
+	PlayerID int64
+	Wins     int64
+}
+
+func (q *Queries) GetDraftWinCounts(ctx context.Context, draftID int64) ([]GetDraftWinCountsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getDraftWinCounts, draftID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Pool
+	var items []GetDraftWinCountsRow
 	for rows.Next() {
-		var i Pool
-		if err := rows.Scan(&i.PlayerID, &i.DraftID, &i.Leader); err != nil {
+		var i GetDraftWinCountsRow
+		if err := rows.Scan(&i.PlayerID, &i.Wins); err != nil {
 			return nil, err
 		}
 		items = append(items, i)
@@ -295,42 +357,42 @@ func (q *Queries) GetOffersByDraftId(ctx context.Context, draftID int64) ([]Pool
 	return items, nil
 }
 
-const getPlayer = `-- name: GetPlayer :one
-SELECT id, username, global_name, discord_avatar
-FROM players
-WHERE id = ?
+const getDrafts = `-- name: GetDrafts :many
+SELECT d.id, d.active, d.mode, d.turn_started_at, d.blind_conflict, d.blind_round, r.winner, r.recorded_at
+FROM drafts d
+LEFT JOIN draft_results r ON r.draft_id = d.id
+ORDER BY d.id DESC
 `
 
-func (q *Queries) GetPlayer(ctx context.Context, id int64) (Player, error) {
-	row := q.db.QueryRowContext(ctx, getPlayer, id)
-	var i Player
-	err := row.Scan(
-		&i.ID,
-		&i.Username,
-		&i.GlobalName,
-		&i.DiscordAvatar,
-	)
-	return i, err
+type GetDraftsRow struct {
+	ID            int64
+	Active        bool
+	Mode          string
+	TurnStartedAt sql.NullTime
+	BlindConflict string
+	BlindRound    int64
+	Winner        sql.NullString
+	RecordedAt    sql.NullTime
 }
 
-const getPlayers = `-- name: GetPlayers :many
-SELECT id, username, global_name, discord_avatar FROM players
-`
-
-func (q *Queries) GetPlayers(ctx context.Context) ([]Player, error) {
-	rows, err := q.db.QueryContext(ctx, getPlayers)
+func (q *Queries) GetDrafts(ctx context.Context) ([]GetDraftsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getDrafts)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Player
+	var items []GetDraftsRow
 	for rows.Next() {
-		var i Player
+		var i GetDraftsRow
 		if err := rows.Scan(
 			&i.ID,
-			&i.Username,
-			&i.GlobalName,
-			&i.DiscordAvatar,
+			&i.Active,
+			&i.Mode,
+			&i.TurnStartedAt,
+			&i.BlindConflict,
+			&i.BlindRound,
+			&i.Winner,
+			&i.RecordedAt,
 		); err != nil {
 			return nil, err
 		}
@@ -345,28 +407,29 @@ func (q *Queries) GetPlayers(ctx context.Context) ([]Player, error) {
 	return items, nil
 }
 
-const getPlayersFromActiveDraft = `-- name: GetPlayersFromActiveDraft :many
-SELECT p.id, p.username, p.global_name, p.discord_avatar
-FROM draft_registry dr
-JOIN players p ON dr.player_id = p.id
-JOIN drafts d ON dr.draft_id = d.id
-WHERE d.active = true
+const getEligibleLeaders = `-- name: GetEligibleLeaders :many
+SELECT id, civ_name, leader_name, discord_emoji_string, banned, tier, friendly_name, unranked, bbg_expanded FROM leaders WHERE banned = false
 `
 
-func (q *Queries) GetPlayersFromActiveDraft(ctx context.Context) ([]Player, error) {
-	rows, err := q.db.QueryContext(ctx, getPlayersFromActiveDraft)
+func (q *Queries) GetEligibleLeaders(ctx context.Context) ([]Leader, error) {
+	rows, err := q.db.QueryContext(ctx, getEligibleLeaders)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Player
+	var items []Leader
 	for rows.Next() {
-		var i Player
+		var i Leader
 		if err := rows.Scan(
 			&i.ID,
-			&i.Username,
-			&i.GlobalName,
-			&i.DiscordAvatar,
+			&i.CivName,
+			&i.LeaderName,
+			&i.DiscordEmojiString,
+			&i.Banned,
+			&i.Tier,
+			&i.FriendlyName,
+			&i.Unranked,
+			&i.BbgExpanded,
 		); err != nil {
 			return nil, err
 		}
@@ -381,23 +444,55 @@ func (q *Queries) GetPlayersFromActiveDraft(ctx context.Context) ([]Player, erro
 	return items, nil
 }
 
-const getPlayersFromDraft = `-- name: GetPlayersFromDraft :many
-SELECT p.id, p.username, p.global_name, p.discord_avatar
-FROM draft_registry dr
-JOIN players p ON dr.player_id = p.id
-WHERE dr.draft_id = ?
+const getGameNight = `-- name: GetGameNight :one
+SELECT id, starts_at, timezone, channel_id, message_id, created_by, reminded, created_at
+FROM game_nights
+WHERE id = ?
 `
 
-func (q *Queries) GetPlayersFromDraft(ctx context.Context, draftID int64) ([]Player, error) {
-	rows, err := q.db.QueryContext(ctx, getPlayersFromDraft, draftID)
+func (q *Queries) GetGameNight(ctx context.Context, id int64) (GameNight, error) {
+	row := q.db.QueryRowContext(ctx, getGameNight, id)
+	var i GameNight
+	err := row.Scan(
+		&i.ID,
+		&i.StartsAt,
+		&i.Timezone,
+		&i.ChannelID,
+		&i.MessageID,
+		&i.CreatedBy,
+		&i.Reminded,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const getGameNightRSVPs = `-- name: GetGameNightRSVPs :many
+SELECT r.response, p.id, p.username, p.global_name, p.discord_avatar
+FROM game_night_rsvps r
+JOIN players p ON r.player_id = p.id
+WHERE r.game_night_id = ?
+ORDER BY r.updated_at, p.id
+`
+
+type GetGameNightRSVPsRow struct {
+	Response      string
+	ID            int64
+	Username      string
+	GlobalName    sql.NullString
+	DiscordAvatar sql.NullString
+}
+
+func (q *Queries) GetGameNightRSVPs(ctx context.Context, gameNightID int64) ([]GetGameNightRSVPsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getGameNightRSVPs, gameNightID)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []Player
+	var items []GetGameNightRSVPsRow
 	for rows.Next() {
-		var i Player
+		var i GetGameNightRSVPsRow
 		if err := rows.Scan(
+			&i.Response,
 			&i.ID,
 			&i.Username,
 			&i.GlobalName,
@@ -416,39 +511,838 @@ func (q *Queries) GetPlayersFromDraft(ctx context.Context, draftID int64) ([]Pla
 	return items, nil
 }
 
-const getRanksForLeaderWithPlayers = `-- name: GetRanksForLeaderWithPlayers :many
-SELECT
-    r.tier,
-    p.id AS player_id,
-    p.username,
-    p.global_name
-FROM ranks r
-JOIN players p ON r.player_id = p.id
-WHERE r.leader_id = ?
-ORDER BY r.tier, p.username
+const getGameSettingOptions = `-- name: GetGameSettingOptions :many
+SELECT category, value, weight, status
+FROM game_setting_options
+ORDER BY rowid
 `
 
-type GetRanksForLeaderWithPlayersRow struct {
-	Tier       float64
-	PlayerID   int64
-	Username   string
-	GlobalName sql.NullString
-}
-
-func (q *Queries) GetRanksForLeaderWithPlayers(ctx context.Context, leaderID int64) ([]GetRanksForLeaderWithPlayersRow, error) {
-	rows, err := q.db.QueryContext(ctx, getRanksForLeaderWithPlayers, leaderID)
+func (q *Queries) GetGameSettingOptions(ctx context.Context) ([]GameSettingOption, error) {
+	rows, err := q.db.QueryContext(ctx, getGameSettingOptions)
 	if err != nil {
 		return nil, err
 	}
 	defer rows.Close()
-	var items []GetRanksForLeaderWithPlayersRow
+	var items []GameSettingOption
 	for rows.Next() {
-		var i GetRanksForLeaderWithPlayersRow
+		var i GameSettingOption
 		if err := rows.Scan(
-			&i.Tier,
-			&i.PlayerID,
-			&i.Username,
-			&i.GlobalName,
+			&i.Category,
+			&i.Value,
+			&i.Weight,
+			&i.Status,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLatestRankHistoryForLeader = `-- name: GetLatestRankHistoryForLeader :one
+SELECT id, leader_id, player_id, tier, bbg, submitted_at
+FROM rank_history rh
+WHERE rh.leader_id = ? AND rh.bbg = ?
+ORDER BY rh.submitted_at DESC, rh.id DESC
+LIMIT 1
+`
+
+type GetLatestRankHistoryForLeaderParams struct {
+	LeaderID int64
+	Bbg      bool
+}
+
+func (q *Queries) GetLatestRankHistoryForLeader(ctx context.Context, arg GetLatestRankHistoryForLeaderParams) (RankHistory, error) {
+	row := q.db.QueryRowContext(ctx, getLatestRankHistoryForLeader, arg.LeaderID, arg.Bbg)
+	var i RankHistory
+	err := row.Scan(
+		&i.ID,
+		&i.LeaderID,
+		&i.PlayerID,
+		&i.Tier,
+		&i.Bbg,
+		&i.SubmittedAt,
+	)
+	return i, err
+}
+
+const getLeaderById = `-- name: GetLeaderById :one
+SELECT id, civ_name, leader_name, discord_emoji_string, banned, tier, friendly_name, unranked, bbg_expanded
+FROM leaders l
+WHERE l.id = ?
+`
+
+func (q *Queries) GetLeaderById(ctx context.Context, id int64) (Leader, error) {
+	row := q.db.QueryRowContext(ctx, getLeaderById, id)
+	var i Leader
+	err := row.Scan(
+		&i.ID,
+		&i.CivName,
+		&i.LeaderName,
+		&i.DiscordEmojiString,
+		&i.Banned,
+		&i.Tier,
+		&i.FriendlyName,
+		&i.Unranked,
+		&i.BbgExpanded,
+	)
+	return i, err
+}
+
+const getLeaders = `-- name: GetLeaders :many
+SELECT id, civ_name, leader_name, discord_emoji_string, banned, tier, friendly_name, unranked, bbg_expanded FROM leaders
+ORDER BY civ_name, leader_name
+`
+
+func (q *Queries) GetLeaders(ctx context.Context) ([]Leader, error) {
+	rows, err := q.db.QueryContext(ctx, getLeaders)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Leader
+	for rows.Next() {
+		var i Leader
+		if err := rows.Scan(
+			&i.ID,
+			&i.CivName,
+			&i.LeaderName,
+			&i.DiscordEmojiString,
+			&i.Banned,
+			&i.Tier,
+			&i.FriendlyName,
+			&i.Unranked,
+			&i.BbgExpanded,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getLeadersByLimitAndOffset = `-- name: GetLeadersByLimitAndOffset :many
+SELECT id, civ_name, leader_name, discord_emoji_string, banned, tier, friendly_name, unranked, bbg_expanded
+FROM leaders l
+ORDER BY l.civ_name, l.leader_name
+LIMIT ? OFFSET ?
+`
+
+type GetLeadersByLimitAndOffsetParams struct {
+	Limit  int64
+	Offset int64
+}
+
+func (q *Queries) GetLeadersByLimitAndOffset(ctx context.Context, arg GetLeadersByLimitAndOffsetParams) ([]Leader, error) {
+	rows, err := q.db.QueryContext(ctx, getLeadersByLimitAndOffset, arg.Limit, arg.Offset)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Leader
+	for rows.Next() {
+		var i Leader
+		if err := rows.Scan(
+			&i.ID,
+			&i.CivName,
+			&i.LeaderName,
+			&i.DiscordEmojiString,
+			&i.Banned,
+			&i.Tier,
+			&i.FriendlyName,
+			&i.Unranked,
+			&i.BbgExpanded,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getOffersByDraftId = `-- name: GetOffersByDraftId :many
+SELECT player_id, draft_id, leader FROM pool WHERE draft_id = ?
+`
+
+func (q *Queries) GetOffersByDraftId(ctx context.Context, draftID int64) ([]Pool, error) {
+	rows, err := q.db.QueryContext(ctx, getOffersByDraftId, draftID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Pool
+	for rows.Next() {
+		var i Pool
+		if err := rows.Scan(&i.PlayerID, &i.DraftID, &i.Leader); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPendingDocuments = `-- name: GetPendingDocuments :many
+SELECT id, leader_id, doc_name, link, note, submitted_by, approved, created_at
+FROM documents d
+WHERE d.approved = FALSE
+ORDER BY d.created_at, d.id
+`
+
+func (q *Queries) GetPendingDocuments(ctx context.Context) ([]Document, error) {
+	rows, err := q.db.QueryContext(ctx, getPendingDocuments)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Document
+	for rows.Next() {
+		var i Document
+		if err := rows.Scan(
+			&i.ID,
+			&i.LeaderID,
+			&i.DocName,
+			&i.Link,
+			&i.Note,
+			&i.SubmittedBy,
+			&i.Approved,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPicksForDraft = `-- name: GetPicksForDraft :many
+SELECT player, draft_id, pick, auto
+FROM picks
+WHERE draft_id = ?
+`
+
+func (q *Queries) GetPicksForDraft(ctx context.Context, draftID int64) ([]Pick, error) {
+	rows, err := q.db.QueryContext(ctx, getPicksForDraft, draftID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Pick
+	for rows.Next() {
+		var i Pick
+		if err := rows.Scan(
+			&i.Player,
+			&i.DraftID,
+			&i.Pick,
+			&i.Auto,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPlayer = `-- name: GetPlayer :one
+SELECT id, username, global_name, discord_avatar
+FROM players
+WHERE id = ?
+`
+
+func (q *Queries) GetPlayer(ctx context.Context, id int64) (Player, error) {
+	row := q.db.QueryRowContext(ctx, getPlayer, id)
+	var i Player
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.GlobalName,
+		&i.DiscordAvatar,
+	)
+	return i, err
+}
+
+const getPlayerDrafts = `-- name: GetPlayerDrafts :many
+SELECT d.id, d.mode, pk.pick, r.winner
+FROM draft_registry dr
+JOIN drafts d ON d.id = dr.draft_id
+LEFT JOIN picks pk ON pk.draft_id = d.id AND pk.player = CAST(dr.player_id AS TEXT)
+LEFT JOIN draft_results r ON r.draft_id = d.id
+WHERE dr.player_id = ?
+ORDER BY d.id DESC
+`
+
+type GetPlayerDraftsRow struct {
+	ID     int64
+	Mode   string
+	Pick   sql.NullInt64
+	Winner sql.NullString
+}
+
+func (q *Queries) GetPlayerDrafts(ctx context.Context, playerID int64) ([]GetPlayerDraftsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPlayerDrafts, playerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPlayerDraftsRow
+	for rows.Next() {
+		var i GetPlayerDraftsRow
+		if err := rows.Scan(
+			&i.ID,
+			&i.Mode,
+			&i.Pick,
+			&i.Winner,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPlayers = `-- name: GetPlayers :many
+SELECT id, username, global_name, discord_avatar FROM players
+`
+
+func (q *Queries) GetPlayers(ctx context.Context) ([]Player, error) {
+	rows, err := q.db.QueryContext(ctx, getPlayers)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Player
+	for rows.Next() {
+		var i Player
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.GlobalName,
+			&i.DiscordAvatar,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPlayersFromActiveDraft = `-- name: GetPlayersFromActiveDraft :many
+SELECT p.id, p.username, p.global_name, p.discord_avatar
+FROM draft_registry dr
+JOIN players p ON dr.player_id = p.id
+JOIN drafts d ON dr.draft_id = d.id
+WHERE d.active = true
+`
+
+func (q *Queries) GetPlayersFromActiveDraft(ctx context.Context) ([]Player, error) {
+	rows, err := q.db.QueryContext(ctx, getPlayersFromActiveDraft)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Player
+	for rows.Next() {
+		var i Player
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.GlobalName,
+			&i.DiscordAvatar,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPlayersFromDraft = `-- name: GetPlayersFromDraft :many
+SELECT p.id, p.username, p.global_name, p.discord_avatar
+FROM draft_registry dr
+JOIN players p ON dr.player_id = p.id
+WHERE dr.draft_id = ?
+`
+
+func (q *Queries) GetPlayersFromDraft(ctx context.Context, draftID int64) ([]Player, error) {
+	rows, err := q.db.QueryContext(ctx, getPlayersFromDraft, draftID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Player
+	for rows.Next() {
+		var i Player
+		if err := rows.Scan(
+			&i.ID,
+			&i.Username,
+			&i.GlobalName,
+			&i.DiscordAvatar,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getPreviousDraftTeams = `-- name: GetPreviousDraftTeams :many
+SELECT player_id, team
+FROM draft_teams
+WHERE draft_id = (
+    SELECT MAX(draft_id) FROM draft_teams WHERE draft_id < ?
+)
+`
+
+type GetPreviousDraftTeamsRow struct {
+	PlayerID int64
+	Team     int64
+}
+
+func (q *Queries) GetPreviousDraftTeams(ctx context.Context, draftID int64) ([]GetPreviousDraftTeamsRow, error) {
+	rows, err := q.db.QueryContext(ctx, getPreviousDraftTeams, draftID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetPreviousDraftTeamsRow
+	for rows.Next() {
+		var i GetPreviousDraftTeamsRow
+		if err := rows.Scan(&i.PlayerID, &i.Team); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRankHistoryForLeader = `-- name: GetRankHistoryForLeader :many
+SELECT
+    rh.tier,
+    rh.bbg,
+    rh.submitted_at,
+    p.id AS player_id,
+    p.username,
+    p.global_name
+FROM rank_history rh
+JOIN players p ON rh.player_id = p.id
+WHERE rh.leader_id = ?
+ORDER BY rh.submitted_at, rh.id
+`
+
+type GetRankHistoryForLeaderRow struct {
+	Tier        float64
+	Bbg         bool
+	SubmittedAt time.Time
+	PlayerID    int64
+	Username    string
+	GlobalName  sql.NullString
+}
+
+func (q *Queries) GetRankHistoryForLeader(ctx context.Context, leaderID int64) ([]GetRankHistoryForLeaderRow, error) {
+	rows, err := q.db.QueryContext(ctx, getRankHistoryForLeader, leaderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRankHistoryForLeaderRow
+	for rows.Next() {
+		var i GetRankHistoryForLeaderRow
+		if err := rows.Scan(
+			&i.Tier,
+			&i.Bbg,
+			&i.SubmittedAt,
+			&i.PlayerID,
+			&i.Username,
+			&i.GlobalName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRanksForLeaderWithPlayers = `-- name: GetRanksForLeaderWithPlayers :many
+SELECT
+    r.tier,
+    r.bbg,
+    p.id AS player_id,
+    p.username,
+    p.global_name
+FROM ranks r
+JOIN players p ON r.player_id = p.id
+WHERE r.leader_id = ?
+ORDER BY r.bbg, r.tier, p.username
+`
+
+type GetRanksForLeaderWithPlayersRow struct {
+	Tier       float64
+	Bbg        bool
+	PlayerID   int64
+	Username   string
+	GlobalName sql.NullString
+}
+
+func (q *Queries) GetRanksForLeaderWithPlayers(ctx context.Context, leaderID int64) ([]GetRanksForLeaderWithPlayersRow, error) {
+	rows, err := q.db.QueryContext(ctx, getRanksForLeaderWithPlayers, leaderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetRanksForLeaderWithPlayersRow
+	for rows.Next() {
+		var i GetRanksForLeaderWithPlayersRow
+		if err := rows.Scan(
+			&i.Tier,
+			&i.Bbg,
+			&i.PlayerID,
+			&i.Username,
+			&i.GlobalName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRanksForPlayer = `-- name: GetRanksForPlayer :many
+SELECT id, leader_id, player_id, tier, updated_at, bbg
+FROM ranks r
+WHERE r.player_id = ?
+`
+
+func (q *Queries) GetRanksForPlayer(ctx context.Context, playerID int64) ([]Rank, error) {
+	rows, err := q.db.QueryContext(ctx, getRanksForPlayer, playerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Rank
+	for rows.Next() {
+		var i Rank
+		if err := rows.Scan(
+			&i.ID,
+			&i.LeaderID,
+			&i.PlayerID,
+			&i.Tier,
+			&i.UpdatedAt,
+			&i.Bbg,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRanksForRuleset = `-- name: GetRanksForRuleset :many
+SELECT id, leader_id, player_id, tier, updated_at, bbg
+FROM ranks r
+WHERE r.bbg = ?
+`
+
+func (q *Queries) GetRanksForRuleset(ctx context.Context, bbg bool) ([]Rank, error) {
+	rows, err := q.db.QueryContext(ctx, getRanksForRuleset, bbg)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Rank
+	for rows.Next() {
+		var i Rank
+		if err := rows.Scan(
+			&i.ID,
+			&i.LeaderID,
+			&i.PlayerID,
+			&i.Tier,
+			&i.UpdatedAt,
+			&i.Bbg,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getRollSettings = `-- name: GetRollSettings :one
+SELECT id, pool_size, min_tier, diversity_min, diversity_mode, updated_at, balance_mode, balance_tolerance, team_tag, player_tag
+FROM roll_settings
+WHERE id = 1
+`
+
+func (q *Queries) GetRollSettings(ctx context.Context) (RollSetting, error) {
+	row := q.db.QueryRowContext(ctx, getRollSettings)
+	var i RollSetting
+	err := row.Scan(
+		&i.ID,
+		&i.PoolSize,
+		&i.MinTier,
+		&i.DiversityMin,
+		&i.DiversityMode,
+		&i.UpdatedAt,
+		&i.BalanceMode,
+		&i.BalanceTolerance,
+		&i.TeamTag,
+		&i.PlayerTag,
+	)
+	return i, err
+}
+
+const getTeamsForDraft = `-- name: GetTeamsForDraft :many
+SELECT t.team, p.id, p.username, p.global_name, p.discord_avatar
+FROM draft_teams t
+JOIN players p ON t.player_id = p.id
+WHERE t.draft_id = ?
+ORDER BY t.team, p.id
+`
+
+type GetTeamsForDraftRow struct {
+	Team          int64
+	ID            int64
+	Username      string
+	GlobalName    sql.NullString
+	DiscordAvatar sql.NullString
+}
+
+func (q *Queries) GetTeamsForDraft(ctx context.Context, draftID int64) ([]GetTeamsForDraftRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTeamsForDraft, draftID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTeamsForDraftRow
+	for rows.Next() {
+		var i GetTeamsForDraftRow
+		if err := rows.Scan(
+			&i.Team,
+			&i.ID,
+			&i.Username,
+			&i.GlobalName,
+			&i.DiscordAvatar,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const getTierHistoryForLeader = `-- name: GetTierHistoryForLeader :many
+SELECT
+    th.tier,
+    th.calculated_at,
+    th.player_id,
+    p.username,
+    p.global_name
+FROM tier_history th
+LEFT JOIN players p ON th.player_id = p.id
+WHERE th.leader_id = ?
+ORDER BY th.calculated_at, th.id
+`
+
+type GetTierHistoryForLeaderRow struct {
+	Tier         float64
+	CalculatedAt time.Time
+	PlayerID     sql.NullInt64
+	Username     sql.NullString
+	GlobalName   sql.NullString
+}
+
+func (q *Queries) GetTierHistoryForLeader(ctx context.Context, leaderID int64) ([]GetTierHistoryForLeaderRow, error) {
+	rows, err := q.db.QueryContext(ctx, getTierHistoryForLeader, leaderID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []GetTierHistoryForLeaderRow
+	for rows.Next() {
+		var i GetTierHistoryForLeaderRow
+		if err := rows.Scan(
+			&i.Tier,
+			&i.CalculatedAt,
+			&i.PlayerID,
+			&i.Username,
+			&i.GlobalName,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const queryLeaderStats = `-- name: QueryLeaderStats :many
+SELECT
+    l.id, l.civ_name, l.leader_name, l.discord_emoji_string, l.banned, l.tier, l.friendly_name, l.unranked, l.bbg_expanded,
+    (SELECT COUNT(*) FROM ranks r WHERE r.leader_id = l.id) AS ratings,
+    (SELECT COUNT(*) FROM picks p WHERE p.pick = l.id) AS picks,
+    (SELECT COUNT(*)
+     FROM picks p
+     JOIN draft_results dr ON dr.draft_id = p.draft_id AND dr.winner = p.player
+     WHERE p.pick = l.id) AS wins,
+    (SELECT COUNT(*) FROM documents d WHERE d.leader_id = l.id AND d.approved = TRUE) AS documents
+FROM leaders l
+WHERE (?1 IS NULL OR (l.unranked = FALSE AND l.tier >= ?1))
+  AND (?2 IS NULL OR (l.unranked = FALSE AND l.tier < ?2))
+  AND (?3 IS NULL OR l.banned = ?3)
+  AND (?4 IS NULL OR l.unranked = ?4)
+  AND (?5 IS NULL OR l.bbg_expanded = ?5)
+  AND (?6 IS NULL OR EXISTS (
+      SELECT 1 FROM documents d WHERE d.leader_id = l.id AND d.approved = TRUE
+  ) = ?6)
+ORDER BY l.civ_name, l.leader_name
+`
+
+type QueryLeaderStatsParams struct {
+	MinTier     sql.NullFloat64
+	MaxTier     sql.NullFloat64
+	Banned      sql.NullBool
+	Unranked    sql.NullBool
+	BbgExpanded sql.NullBool
+	HasGuide    sql.NullBool
+}
+
+type QueryLeaderStatsRow struct {
+	Leader    Leader
+	Ratings   int64
+	Picks     int64
+	Wins      int64
+	Documents int64
+}
+
+func (q *Queries) QueryLeaderStats(ctx context.Context, arg QueryLeaderStatsParams) ([]QueryLeaderStatsRow, error) {
+	rows, err := q.db.QueryContext(ctx, queryLeaderStats,
+		arg.MinTier,
+		arg.MaxTier,
+		arg.Banned,
+		arg.Unranked,
+		arg.BbgExpanded,
+		arg.HasGuide,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []QueryLeaderStatsRow
+	for rows.Next() {
+		var i QueryLeaderStatsRow
+		if err := rows.Scan(
+			&i.Leader.ID,
+			&i.Leader.CivName,
+			&i.Leader.LeaderName,
+			&i.Leader.DiscordEmojiString,
+			&i.Leader.Banned,
+			&i.Leader.Tier,
+			&i.Leader.FriendlyName,
+			&i.Leader.Unranked,
+			&i.Leader.BbgExpanded,
+			&i.Ratings,
+			&i.Picks,
+			&i.Wins,
+			&i.Documents,
 		); err != nil {
 			return nil, err
 		}
@@ -8,8 +8,120 @@ package generated
 import (
 	"context"
 	"database/sql"
+	"time"
 )
 
+const addDocument = `-- name: AddDocument :one
+INSERT INTO documents (leader_id, doc_name, link, note, submitted_by, approved, created_at)
+VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+RETURNING id, leader_id, doc_name, link, note, submitted_by, approved, created_at
+`
+
+type AddDocumentParams struct {
+	LeaderID    int64
+	DocName     string
+	Link        string
+	Note        sql.NullString
+	SubmittedBy sql.NullInt64
+	Approved    bool
+}
+
+func (q *Queries) AddDocument(ctx context.Context, arg AddDocumentParams) (Document, error) {
+	row := q.db.QueryRowContext(ctx, addDocument,
+		arg.LeaderID,
+		arg.DocName,
+		arg.Link,
+		arg.Note,
+		arg.SubmittedBy,
+		arg.Approved,
+	)
+	var i Document
+	err := row.Scan(
+		&i.ID,
+		&i.LeaderID,
+		&i.DocName,
+		&i.Link,
+		&i.Note,
+		&i.SubmittedBy,
+		&i.Approved,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const addDocumentVote = `-- name: AddDocumentVote :execrows
+INSERT INTO document_votes (document_id, player_id)
+VALUES (?, ?)
+ON CONFLICT (document_id, player_id) DO NOTHING
+`
+
+type AddDocumentVoteParams struct {
+	DocumentID int64
+	PlayerID   int64
+}
+
+func (q *Queries) AddDocumentVote(ctx context.Context, arg AddDocumentVoteParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, addDocumentVote, arg.DocumentID, arg.PlayerID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const addDraftGameSetting = `-- name: AddDraftGameSetting :exec
+INSERT INTO draft_game_settings (draft_id, category, value)
+VALUES (?, ?, ?)
+`
+
+type AddDraftGameSettingParams struct {
+	DraftID  int64
+	Category string
+	Value    string
+}
+
+func (q *Queries) AddDraftGameSetting(ctx context.Context, arg AddDraftGameSettingParams) error {
+	_, err := q.db.ExecContext(ctx, addDraftGameSetting, arg.DraftID, arg.Category, arg.Value)
+	return err
+}
+
+const addDraftTurn = `-- name: AddDraftTurn :exec
+INSERT INTO draft_turns (draft_id, turn, player_id)
+VALUES (?, ?, ?)
+`
+
+type AddDraftTurnParams struct {
+	DraftID  int64
+	Turn     int64
+	PlayerID int64
+}
+
+func (q *Queries) AddDraftTurn(ctx context.Context, arg AddDraftTurnParams) error {
+	_, err := q.db.ExecContext(ctx, addDraftTurn, arg.DraftID, arg.Turn, arg.PlayerID)
+	return err
+}
+
+const addPick = `-- name: AddPick :exec
+INSERT INTO picks (player, draft_id, pick, auto)
+VALUES (?, ?, ?, ?)
+`
+
+type AddPickParams struct {
+	Player  string
+	DraftID int64
+	Pick    int64
+	Auto    bool
+}
+
+func (q *Queries) AddPick(ctx context.Context, arg AddPickParams) error {
+	_, err := q.db.ExecContext(ctx, addPick,
+		arg.Player,
+		arg.DraftID,
+		arg.Pick,
+		arg.Auto,
+	)
+	return err
+}
+
 const addPlayer = `-- name: AddPlayer :exec
 INSERT INTO players (
     id,
@@ -83,19 +195,295 @@ func (q *Queries) AddPool(ctx context.Context, arg AddPoolParams) error {
 	return err
 }
 
+const addRankHistory = `-- name: AddRankHistory :exec
+INSERT INTO rank_history (player_id, leader_id, tier, bbg)
+VALUES (?, ?, ?, ?)
+`
+
+type AddRankHistoryParams struct {
+	PlayerID int64
+	LeaderID int64
+	Tier     float64
+	Bbg      bool
+}
+
+func (q *Queries) AddRankHistory(ctx context.Context, arg AddRankHistoryParams) error {
+	_, err := q.db.ExecContext(ctx, addRankHistory,
+		arg.PlayerID,
+		arg.LeaderID,
+		arg.Tier,
+		arg.Bbg,
+	)
+	return err
+}
+
+const addTeamMember = `-- name: AddTeamMember :exec
+INSERT INTO draft_teams (draft_id, player_id, team)
+VALUES (?, ?, ?)
+`
+
+type AddTeamMemberParams struct {
+	DraftID  int64
+	PlayerID int64
+	Team     int64
+}
+
+func (q *Queries) AddTeamMember(ctx context.Context, arg AddTeamMemberParams) error {
+	_, err := q.db.ExecContext(ctx, addTeamMember, arg.DraftID, arg.PlayerID, arg.Team)
+	return err
+}
+
+const addTierHistory = `-- name: AddTierHistory :exec
+INSERT INTO tier_history (leader_id, tier, player_id)
+VALUES (?, ?, ?)
+`
+
+type AddTierHistoryParams struct {
+	LeaderID int64
+	Tier     float64
+	PlayerID sql.NullInt64
+}
+
+func (q *Queries) AddTierHistory(ctx context.Context, arg AddTierHistoryParams) error {
+	_, err := q.db.ExecContext(ctx, addTierHistory, arg.LeaderID, arg.Tier, arg.PlayerID)
+	return err
+}
+
+const approveDocument = `-- name: ApproveDocument :exec
+UPDATE documents
+SET approved = TRUE
+WHERE id = ?
+`
+
+func (q *Queries) ApproveDocument(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, approveDocument, id)
+	return err
+}
+
+const cancelJobs = `-- name: CancelJobs :execrows
+UPDATE jobs
+SET status = 'cancelled'
+WHERE tag = ? AND status = 'pending'
+`
+
+func (q *Queries) CancelJobs(ctx context.Context, tag string) (int64, error) {
+	result, err := q.db.ExecContext(ctx, cancelJobs, tag)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const claimDueJobs = `-- name: ClaimDueJobs :many
+UPDATE jobs
+SET status = 'running', attempts = attempts + 1
+WHERE id IN (
+    SELECT id FROM jobs
+    WHERE status = 'pending' AND due_at <= ?
+    ORDER BY due_at, id
+    LIMIT ?
+)
+RETURNING id, kind, tag, payload, due_at, status, attempts, last_error, created_at
+`
+
+type ClaimDueJobsParams struct {
+	DueAt time.Time
+	Limit int64
+}
+
+func (q *Queries) ClaimDueJobs(ctx context.Context, arg ClaimDueJobsParams) ([]Job, error) {
+	rows, err := q.db.QueryContext(ctx, claimDueJobs, arg.DueAt, arg.Limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	var items []Job
+	for rows.Next() {
+		var i Job
+		if err := rows.Scan(
+			&i.ID,
+			&i.Kind,
+			&i.Tag,
+			&i.Payload,
+			&i.DueAt,
+			&i.Status,
+			&i.Attempts,
+			&i.LastError,
+			&i.CreatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const completeJob = `-- name: CompleteJob :exec
+UPDATE jobs
+SET status = 'done'
+WHERE id = ?
+`
+
+func (q *Queries) CompleteJob(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, completeJob, id)
+	return err
+}
+
 const createActiveDraft = `-- name: CreateActiveDraft :one
 INSERT INTO drafts (
     active
-) VALUES (true) RETURNING id, active
+) VALUES (true) RETURNING id, active, mode, turn_started_at, blind_conflict, blind_round
 `
 
 func (q *Queries) CreateActiveDraft(ctx context.Context) (Draft, error) {
 	row := q.db.QueryRowContext(ctx, createActiveDraft)
 	var i Draft
-	err := row.Scan(&i.ID, &i.Active)
+	err := row.Scan(
+		&i.ID,
+		&i.Active,
+		&i.Mode,
+		&i.TurnStartedAt,
+		&i.BlindConflict,
+		&i.BlindRound,
+	)
+	return i, err
+}
+
+const createGameNight = `-- name: CreateGameNight :one
+INSERT INTO game_nights (starts_at, timezone, channel_id, created_by)
+VALUES (?, ?, ?, ?)
+RETURNING id, starts_at, timezone, channel_id, message_id, created_by, reminded, created_at
+`
+
+type CreateGameNightParams struct {
+	StartsAt  time.Time
+	Timezone  string
+	ChannelID int64
+	CreatedBy int64
+}
+
+func (q *Queries) CreateGameNight(ctx context.Context, arg CreateGameNightParams) (GameNight, error) {
+	row := q.db.QueryRowContext(ctx, createGameNight,
+		arg.StartsAt,
+		arg.Timezone,
+		arg.ChannelID,
+		arg.CreatedBy,
+	)
+	var i GameNight
+	err := row.Scan(
+		&i.ID,
+		&i.StartsAt,
+		&i.Timezone,
+		&i.ChannelID,
+		&i.MessageID,
+		&i.CreatedBy,
+		&i.Reminded,
+		&i.CreatedAt,
+	)
 	return i, err
 }
 
+const createJob = `-- name: CreateJob :one
+INSERT INTO jobs (kind, tag, payload, due_at)
+VALUES (?, ?, ?, ?)
+RETURNING id, kind, tag, payload, due_at, status, attempts, last_error, created_at
+`
+
+type CreateJobParams struct {
+	Kind    string
+	Tag     string
+	Payload string
+	DueAt   time.Time
+}
+
+func (q *Queries) CreateJob(ctx context.Context, arg CreateJobParams) (Job, error) {
+	row := q.db.QueryRowContext(ctx, createJob,
+		arg.Kind,
+		arg.Tag,
+		arg.Payload,
+		arg.DueAt,
+	)
+	var i Job
+	err := row.Scan(
+		&i.ID,
+		&i.Kind,
+		&i.Tag,
+		&i.Payload,
+		&i.DueAt,
+		&i.Status,
+		&i.Attempts,
+		&i.LastError,
+		&i.CreatedAt,
+	)
+	return i, err
+}
+
+const deleteBlindPicks = `-- name: DeleteBlindPicks :exec
+DELETE FROM blind_picks
+WHERE draft_id = ?
+`
+
+func (q *Queries) DeleteBlindPicks(ctx context.Context, draftID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteBlindPicks, draftID)
+	return err
+}
+
+const deleteDocument = `-- name: DeleteDocument :exec
+DELETE FROM documents
+WHERE id = ?
+`
+
+func (q *Queries) DeleteDocument(ctx context.Context, id int64) error {
+	_, err := q.db.ExecContext(ctx, deleteDocument, id)
+	return err
+}
+
+const deleteDocumentVotes = `-- name: DeleteDocumentVotes :exec
+DELETE FROM document_votes
+WHERE document_id = ?
+`
+
+func (q *Queries) DeleteDocumentVotes(ctx context.Context, documentID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteDocumentVotes, documentID)
+	return err
+}
+
+const deleteDraftGameSettings = `-- name: DeleteDraftGameSettings :exec
+DELETE FROM draft_game_settings
+WHERE draft_id = ?
+`
+
+func (q *Queries) DeleteDraftGameSettings(ctx context.Context, draftID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteDraftGameSettings, draftID)
+	return err
+}
+
+const deleteDraftTurns = `-- name: DeleteDraftTurns :exec
+DELETE FROM draft_turns
+WHERE draft_id = ?
+`
+
+func (q *Queries) DeleteDraftTurns(ctx context.Context, draftID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteDraftTurns, draftID)
+	return err
+}
+
+const deletePicksForDraft = `-- name: DeletePicksForDraft :exec
+DELETE FROM picks
+WHERE draft_id = ?
+`
+
+func (q *Queries) DeletePicksForDraft(ctx context.Context, draftID int64) error {
+	_, err := q.db.ExecContext(ctx, deletePicksForDraft, draftID)
+	return err
+}
+
 const deletePoolForPlayer = `-- name: DeletePoolForPlayer :exec
 DELETE FROM pool
        WHERE player_id = ?
@@ -121,6 +509,45 @@ func (q *Queries) DeletePoolsForDraftId(ctx context.Context, draftID int64) erro
 	return err
 }
 
+const deleteTeamsForDraft = `-- name: DeleteTeamsForDraft :exec
+DELETE FROM draft_teams
+WHERE draft_id = ?
+`
+
+func (q *Queries) DeleteTeamsForDraft(ctx context.Context, draftID int64) error {
+	_, err := q.db.ExecContext(ctx, deleteTeamsForDraft, draftID)
+	return err
+}
+
+const markGameNightReminded = `-- name: MarkGameNightReminded :execrows
+UPDATE game_nights
+SET reminded = TRUE
+WHERE id = ? AND reminded = FALSE
+`
+
+func (q *Queries) MarkGameNightReminded(ctx context.Context, id int64) (int64, error) {
+	result, err := q.db.ExecContext(ctx, markGameNightReminded, id)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const removeDocumentVote = `-- name: RemoveDocumentVote :exec
+DELETE FROM document_votes
+WHERE document_id = ? AND player_id = ?
+`
+
+type RemoveDocumentVoteParams struct {
+	DocumentID int64
+	PlayerID   int64
+}
+
+func (q *Queries) RemoveDocumentVote(ctx context.Context, arg RemoveDocumentVoteParams) error {
+	_, err := q.db.ExecContext(ctx, removeDocumentVote, arg.DocumentID, arg.PlayerID)
+	return err
+}
+
 const removePlayersFromDraft = `-- name: RemovePlayersFromDraft :exec
 DELETE FROM draft_registry WHERE draft_id = ?
 `
@@ -130,6 +557,43 @@ func (q *Queries) RemovePlayersFromDraft(ctx context.Context, draftID int64) err
 	return err
 }
 
+const resetRunningJobs = `-- name: ResetRunningJobs :execrows
+UPDATE jobs
+SET status = 'pending'
+WHERE status = 'running'
+`
+
+func (q *Queries) ResetRunningJobs(ctx context.Context) (int64, error) {
+	result, err := q.db.ExecContext(ctx, resetRunningJobs)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const retryJob = `-- name: RetryJob :exec
+UPDATE jobs
+SET status = ?, due_at = ?, last_error = ?
+WHERE id = ?
+`
+
+type RetryJobParams struct {
+	Status    string
+	DueAt     time.Time
+	LastError string
+	ID        int64
+}
+
+func (q *Queries) RetryJob(ctx context.Context, arg RetryJobParams) error {
+	_, err := q.db.ExecContext(ctx, retryJob,
+		arg.Status,
+		arg.DueAt,
+		arg.LastError,
+		arg.ID,
+	)
+	return err
+}
+
 const returnOffering = `-- name: ReturnOffering :exec
 DELETE FROM pool
    WHERE player_id = ?
@@ -146,9 +610,184 @@ func (q *Queries) ReturnOffering(ctx context.Context, arg ReturnOfferingParams)
 	return err
 }
 
-const submitRankForPlayer = `-- name: SubmitRankForPlayer :exec
-INSERT INTO ranks (player_id, leader_id, tier)
+const setBlindConflict = `-- name: SetBlindConflict :exec
+UPDATE drafts
+SET blind_conflict = ?
+WHERE id = ?
+`
+
+type SetBlindConflictParams struct {
+	BlindConflict string
+	ID            int64
+}
+
+func (q *Queries) SetBlindConflict(ctx context.Context, arg SetBlindConflictParams) error {
+	_, err := q.db.ExecContext(ctx, setBlindConflict, arg.BlindConflict, arg.ID)
+	return err
+}
+
+const setBlindPick = `-- name: SetBlindPick :exec
+INSERT INTO blind_picks (draft_id, round, player_id, leader_id)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (draft_id, round, player_id) DO UPDATE
+SET leader_id = excluded.leader_id, picked_at = CURRENT_TIMESTAMP
+`
+
+type SetBlindPickParams struct {
+	DraftID  int64
+	Round    int64
+	PlayerID int64
+	LeaderID int64
+}
+
+func (q *Queries) SetBlindPick(ctx context.Context, arg SetBlindPickParams) error {
+	_, err := q.db.ExecContext(ctx, setBlindPick,
+		arg.DraftID,
+		arg.Round,
+		arg.PlayerID,
+		arg.LeaderID,
+	)
+	return err
+}
+
+const setDraftMode = `-- name: SetDraftMode :exec
+UPDATE drafts
+SET mode = ?
+WHERE id = ?
+`
+
+type SetDraftModeParams struct {
+	Mode string
+	ID   int64
+}
+
+func (q *Queries) SetDraftMode(ctx context.Context, arg SetDraftModeParams) error {
+	_, err := q.db.ExecContext(ctx, setDraftMode, arg.Mode, arg.ID)
+	return err
+}
+
+const setDraftWinner = `-- name: SetDraftWinner :exec
+INSERT INTO draft_results (draft_id, winner)
+VALUES (?, ?)
+ON CONFLICT (draft_id)
+DO UPDATE SET
+    winner = excluded.winner,
+    recorded_at = CURRENT_TIMESTAMP
+`
+
+type SetDraftWinnerParams struct {
+	DraftID int64
+	Winner  string
+}
+
+func (q *Queries) SetDraftWinner(ctx context.Context, arg SetDraftWinnerParams) error {
+	_, err := q.db.ExecContext(ctx, setDraftWinner, arg.DraftID, arg.Winner)
+	return err
+}
+
+const setGameNightMessage = `-- name: SetGameNightMessage :exec
+UPDATE game_nights
+SET message_id = ?
+WHERE id = ?
+`
+
+type SetGameNightMessageParams struct {
+	MessageID int64
+	ID        int64
+}
+
+func (q *Queries) SetGameNightMessage(ctx context.Context, arg SetGameNightMessageParams) error {
+	_, err := q.db.ExecContext(ctx, setGameNightMessage, arg.MessageID, arg.ID)
+	return err
+}
+
+const setGameSettingStatus = `-- name: SetGameSettingStatus :exec
+UPDATE game_setting_options
+SET status = ?
+WHERE category = ? AND value = ?
+`
+
+type SetGameSettingStatusParams struct {
+	Status   string
+	Category string
+	Value    string
+}
+
+func (q *Queries) SetGameSettingStatus(ctx context.Context, arg SetGameSettingStatusParams) error {
+	_, err := q.db.ExecContext(ctx, setGameSettingStatus, arg.Status, arg.Category, arg.Value)
+	return err
+}
+
+const setLeaderBanned = `-- name: SetLeaderBanned :exec
+UPDATE leaders
+SET banned = ?
+WHERE id = ?
+`
+
+type SetLeaderBannedParams struct {
+	Banned bool
+	ID     int64
+}
+
+func (q *Queries) SetLeaderBanned(ctx context.Context, arg SetLeaderBannedParams) error {
+	_, err := q.db.ExecContext(ctx, setLeaderBanned, arg.Banned, arg.ID)
+	return err
+}
+
+const setRSVP = `-- name: SetRSVP :exec
+INSERT INTO game_night_rsvps (game_night_id, player_id, response)
 VALUES (?, ?, ?)
+ON CONFLICT (game_night_id, player_id) DO UPDATE
+SET response = excluded.response, updated_at = CURRENT_TIMESTAMP
+`
+
+type SetRSVPParams struct {
+	GameNightID int64
+	PlayerID    int64
+	Response    string
+}
+
+func (q *Queries) SetRSVP(ctx context.Context, arg SetRSVPParams) error {
+	_, err := q.db.ExecContext(ctx, setRSVP, arg.GameNightID, arg.PlayerID, arg.Response)
+	return err
+}
+
+const setTurnStartedAt = `-- name: SetTurnStartedAt :exec
+UPDATE drafts
+SET turn_started_at = ?
+WHERE id = ?
+`
+
+type SetTurnStartedAtParams struct {
+	TurnStartedAt sql.NullTime
+	ID            int64
+}
+
+func (q *Queries) SetTurnStartedAt(ctx context.Context, arg SetTurnStartedAtParams) error {
+	_, err := q.db.ExecContext(ctx, setTurnStartedAt, arg.TurnStartedAt, arg.ID)
+	return err
+}
+
+const startBlindRound = `-- name: StartBlindRound :exec
+UPDATE drafts
+SET blind_round = ?, turn_started_at = ?
+WHERE id = ?
+`
+
+type StartBlindRoundParams struct {
+	BlindRound    int64
+	TurnStartedAt sql.NullTime
+	ID            int64
+}
+
+func (q *Queries) StartBlindRound(ctx context.Context, arg StartBlindRoundParams) error {
+	_, err := q.db.ExecContext(ctx, startBlindRound, arg.BlindRound, arg.TurnStartedAt, arg.ID)
+	return err
+}
+
+const submitRankForPlayer = `-- name: SubmitRankForPlayer :exec
+INSERT INTO ranks (player_id, leader_id, tier, bbg)
+VALUES (?, ?, ?, ?)
 ON CONFLICT (leader_id, player_id, bbg)
 DO UPDATE SET
     tier = excluded.tier,
@@ -159,10 +798,16 @@ type SubmitRankForPlayerParams struct {
 	PlayerID int64
 	LeaderID int64
 	Tier     float64
+	Bbg      bool
 }
 
 func (q *Queries) SubmitRankForPlayer(ctx context.Context, arg SubmitRankForPlayerParams) error {
-	_, err := q.db.ExecContext(ctx, submitRankForPlayer, arg.PlayerID, arg.LeaderID, arg.Tier)
+	_, err := q.db.ExecContext(ctx, submitRankForPlayer,
+		arg.PlayerID,
+		arg.LeaderID,
+		arg.Tier,
+		arg.Bbg,
+	)
 	return err
 }
 
@@ -183,3 +828,42 @@ func (q *Queries) UpdateLeaderTier(ctx context.Context, arg UpdateLeaderTierPara
 	_, err := q.db.ExecContext(ctx, updateLeaderTier, arg.Tier, arg.ID)
 	return err
 }
+
+const updateRollSettings = `-- name: UpdateRollSettings :exec
+UPDATE roll_settings
+SET pool_size = ?,
+    min_tier = ?,
+    diversity_min = ?,
+    diversity_mode = ?,
+    balance_mode = ?,
+    balance_tolerance = ?,
+    team_tag = ?,
+    player_tag = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = 1
+`
+
+type UpdateRollSettingsParams struct {
+	PoolSize         int64
+	MinTier          float64
+	DiversityMin     int64
+	DiversityMode    string
+	BalanceMode      string
+	BalanceTolerance float64
+	TeamTag          string
+	PlayerTag        string
+}
+
+func (q *Queries) UpdateRollSettings(ctx context.Context, arg UpdateRollSettingsParams) error {
+	_, err := q.db.ExecContext(ctx, updateRollSettings,
+		arg.PoolSize,
+		arg.MinTier,
+		arg.DiversityMin,
+		arg.DiversityMode,
+		arg.BalanceMode,
+		arg.BalanceTolerance,
+		arg.TeamTag,
+		arg.PlayerTag,
+	)
+	return err
+}
@@ -0,0 +1,102 @@
+package ci6ndex
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"time"
+)
+
+// tierEpsilon is the smallest difference between two computed tiers that is
+// considered a change worth recording.
+const tierEpsilon = 0.005
+
+func tierChanged(previous, current float64) bool {
+	return math.Abs(previous-current) >= tierEpsilon
+}
+
+// TierChange is a snapshot of a leader's community tier at a point in time.
+type TierChange struct {
+	Tier         float64
+	PreviousTier float64
+	ChangedAt    time.Time
+	// PlayerID is the player whose submission moved the tier. Invalid for bulk recalculations.
+	PlayerID   sql.NullInt64
+	Username   sql.NullString
+	GlobalName sql.NullString
+}
+
+// RankSubmission is a single historical rank a player submitted for a leader.
+type RankSubmission struct {
+	Tier        float64
+	BBG         bool
+	SubmittedAt time.Time
+	PlayerID    int64
+	Username    string
+	GlobalName  sql.NullString
+}
+
+// GetTierHistory returns the computed tier snapshots for a leader, oldest first.
+func (c *Ci6ndex) GetTierHistory(guildID uint64, leaderID int64) ([]TierChange, error) {
+	db, err := c.getDB(guildID)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := db.Queries.GetTierHistoryForLeader(ctx, leaderID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return make([]TierChange, 0), nil
+		}
+		return nil, errors.Join(err, errors.New("failed to query tier history"))
+	}
+
+	history := make([]TierChange, len(rows))
+	for i, r := range rows {
+		history[i] = TierChange{
+			Tier:       r.Tier,
+			ChangedAt:  r.CalculatedAt,
+			PlayerID:   r.PlayerID,
+			Username:   r.Username,
+			GlobalName: r.GlobalName,
+		}
+		if i > 0 {
+			history[i].PreviousTier = rows[i-1].Tier
+		}
+	}
+	return history, nil
+}
+
+// GetRankHistory returns every rank ever submitted for a leader, oldest first.
+func (c *Ci6ndex) GetRankHistory(guildID uint64, leaderID int64) ([]RankSubmission, error) {
+	db, err := c.getDB(guildID)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := db.Queries.GetRankHistoryForLeader(ctx, leaderID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return make([]RankSubmission, 0), nil
+		}
+		return nil, errors.Join(err, errors.New("failed to query rank history"))
+	}
+
+	history := make([]RankSubmission, len(rows))
+	for i, r := range rows {
+		history[i] = RankSubmission{
+			Tier:        r.Tier,
+			BBG:         r.Bbg,
+			SubmittedAt: r.SubmittedAt,
+			PlayerID:    r.PlayerID,
+			Username:    r.Username,
+			GlobalName:  r.GlobalName,
+		}
+	}
+	return history, nil
+}
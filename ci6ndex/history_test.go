@@ -0,0 +1,162 @@
+package ci6ndex
+
+import (
+	"ci6ndex/ci6ndex/generated"
+	"context"
+	"testing"
+)
+
+func TestGetTierHistory_RecordsSubmissions(t *testing.T) {
+	// Theodora, tier 1.83 in the seed data
+	const leaderID int64 = 11
+	const playerID int64 = 1000
+
+	before, err := testC.GetTierHistory(testGuildID, leaderID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(before) != 1 {
+		t.Fatalf("expected seeded tier snapshot, got %d entries", len(before))
+	}
+
+	if err := testC.SubmitRankForPlayer(testGuildID, "S", playerID, leaderID, false); err != nil {
+		t.Fatalf("failed to submit rank: %v", err)
+	}
+	if err := testC.CalculateTierForLeader(testGuildID, leaderID); err != nil {
+		t.Fatalf("failed to calculate tier: %v", err)
+	}
+
+	after, err := testC.GetTierHistory(testGuildID, leaderID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(after) != len(before)+1 {
+		t.Fatalf("expected %d entries, got %d", len(before)+1, len(after))
+	}
+	latest := after[len(after)-1]
+	if latest.Tier != S.Value() {
+		t.Fatalf("expected tier %v, got %v", S.Value(), latest.Tier)
+	}
+	if latest.PreviousTier != before[0].Tier {
+		t.Fatalf("expected previous tier %v, got %v", before[0].Tier, latest.PreviousTier)
+	}
+	if !latest.PlayerID.Valid || latest.PlayerID.Int64 != playerID {
+		t.Fatalf("expected change attributed to player %d, got %v", playerID, latest.PlayerID)
+	}
+
+	// Recalculating without new submissions must not append a snapshot
+	if err := testC.CalculateTierForLeader(testGuildID, leaderID); err != nil {
+		t.Fatalf("failed to calculate tier: %v", err)
+	}
+	unchanged, err := testC.GetTierHistory(testGuildID, leaderID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(unchanged) != len(after) {
+		t.Fatalf("expected %d entries after no-op recalculation, got %d", len(after), len(unchanged))
+	}
+
+	ranks, err := testC.GetRankHistory(testGuildID, leaderID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ranks) != 1 || ranks[0].PlayerID != playerID {
+		t.Fatalf("expected a single rank submission from player %d, got %+v", playerID, ranks)
+	}
+}
+
+func TestCalculateTierForLeader_SmallMoves(t *testing.T) {
+	const leaderID int64 = 20
+	const playerID int64 = 1016
+	ctx := context.Background()
+
+	if err := testC.SubmitRankForPlayer(testGuildID, "F", playerID, leaderID, false); err != nil {
+		t.Fatalf("failed to submit rank: %v", err)
+	}
+	if err := testC.CalculateTierForLeader(testGuildID, leaderID); err != nil {
+		t.Fatalf("failed to calculate tier: %v", err)
+	}
+	before, err := testC.GetTierHistory(testGuildID, leaderID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	// A move too small to record still has to reach the leader
+	err = testDB.Writes.UpdateLeaderTier(ctx, generated.UpdateLeaderTierParams{Tier: 4.999, ID: leaderID})
+	if err != nil {
+		t.Fatalf("failed to update tier: %v", err)
+	}
+	if err := testC.CalculateTierForLeader(testGuildID, leaderID); err != nil {
+		t.Fatalf("failed to calculate tier: %v", err)
+	}
+	leader, err := testDB.Queries.GetLeaderById(ctx, leaderID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if leader.Tier != F.Value() {
+		t.Fatalf("expected tier %v, got %v", F.Value(), leader.Tier)
+	}
+	after, err := testC.GetTierHistory(testGuildID, leaderID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(after) != len(before) {
+		t.Fatalf("expected the small move to not be recorded, got %d entries instead of %d", len(after), len(before))
+	}
+}
+
+func TestSubmitRankForPlayer_Ruleset(t *testing.T) {
+	const leaderID int64 = 33
+	const playerID int64 = 1016
+	ctx := context.Background()
+
+	if err := testC.SubmitRankForPlayer(testGuildID, "A", playerID, leaderID, false); err != nil {
+		t.Fatalf("failed to submit rank: %v", err)
+	}
+	if err := testC.SubmitRankForPlayer(testGuildID, "S", playerID, leaderID, true); err != nil {
+		t.Fatalf("failed to submit rank: %v", err)
+	}
+	latest, err := testDB.Queries.GetLatestRankHistoryForLeader(ctx, generated.GetLatestRankHistoryForLeaderParams{
+		LeaderID: leaderID,
+		Bbg:      true,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if !latest.Bbg || latest.Tier != S.Value() {
+		t.Fatalf("expected the BBG rank to be recorded in the history, got %+v", latest)
+	}
+
+	// Only the vanilla rank counts towards the stored tier
+	if err := testC.CalculateTierForLeader(testGuildID, leaderID); err != nil {
+		t.Fatalf("failed to calculate tier: %v", err)
+	}
+	leader, err := testDB.Queries.GetLeaderById(ctx, leaderID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if leader.Tier != A.Value() {
+		t.Fatalf("expected the vanilla tier %v, got %v", A.Value(), leader.Tier)
+	}
+	leaderRanks, err := testC.GetRanksForLeader(testGuildID, leaderID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(leaderRanks) != 2 || leaderRanks[0].BBG || !leaderRanks[1].BBG {
+		t.Fatalf("expected the vanilla rank before the BBG one, got %+v", leaderRanks)
+	}
+
+	ranks, err := testDB.Queries.GetRanksForPlayer(ctx, playerID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	byRuleset := make(map[bool]float64)
+	for _, r := range ranks {
+		if r.LeaderID == leaderID {
+			byRuleset[r.Bbg] = r.Tier
+		}
+	}
+	if byRuleset[true] != S.Value() || byRuleset[false] != A.Value() {
+		t.Fatalf("expected separate BBG and vanilla ranks, got %v", byRuleset)
+	}
+}
@@ -0,0 +1,197 @@
+package ci6ndex
+
+import (
+	"ci6ndex/ci6ndex/generated"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// JobStatus is where a job is in its life, see the jobs table.
+type JobStatus string
+
+const (
+	JobPending   JobStatus = "pending"
+	JobRunning   JobStatus = "running"
+	JobDone      JobStatus = "done"
+	JobFailed    JobStatus = "failed"
+	JobCancelled JobStatus = "cancelled"
+)
+
+const (
+	// MaxJobAttempts is how often a job is run before it's given up on
+	MaxJobAttempts = 5
+	// jobRetryDelay is how long the first retry of a failed job waits, every retry after waits twice as long
+	jobRetryDelay = 30 * time.Second
+)
+
+// Job is a timed action persisted in the guild's database, run by the bot's scheduler once it's due.
+type Job struct {
+	ID   int64
+	Kind string
+	// Tag groups jobs so they can be cancelled together
+	Tag     string
+	Payload json.RawMessage
+	DueAt   time.Time
+	// Attempts counts the runs so far, including the one in progress
+	Attempts int
+}
+
+// Decode unmarshals the job's payload into v.
+func (j Job) Decode(v any) error {
+	if err := json.Unmarshal(j.Payload, v); err != nil {
+		return errors.Join(err, fmt.Errorf("failed to decode payload of %s job %d", j.Kind, j.ID))
+	}
+	return nil
+}
+
+// jobTime is how due times are stored: in UTC to the second, so they compare correctly as text.
+func jobTime(t time.Time) time.Time {
+	return t.UTC().Truncate(time.Second)
+}
+
+// retryAt is when a job that failed its attempt-th run is tried again.
+func retryAt(now time.Time, attempt int) time.Time {
+	return now.Add(jobRetryDelay << max(attempt-1, 0))
+}
+
+// ScheduleJob persists a job of the given kind due at dueAt. A non-empty tag replaces the pending jobs with the same
+// tag, so rescheduling a timer doesn't leave the old one behind.
+func (c *Ci6ndex) ScheduleJob(guildId uint64, kind, tag string, dueAt time.Time, payload any) (Job, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Job{}, errors.Join(err, fmt.Errorf("failed to encode payload of %s job", kind))
+	}
+	db, err := c.getDB(guildId)
+	if err != nil {
+		return Job{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if tag != "" {
+		if _, err := db.Writes.CancelJobs(ctx, tag); err != nil {
+			return Job{}, errors.Join(err, fmt.Errorf("failed to replace %s jobs", tag))
+		}
+	}
+	row, err := db.Writes.CreateJob(ctx, generated.CreateJobParams{
+		Kind:    kind,
+		Tag:     tag,
+		Payload: string(data),
+		DueAt:   jobTime(dueAt),
+	})
+	if err != nil {
+		return Job{}, errors.Join(err, fmt.Errorf("failed to schedule %s job", kind))
+	}
+	return jobFromRow(row), nil
+}
+
+// CancelJobs cancels the pending jobs with the given tag and returns how many there were.
+func (c *Ci6ndex) CancelJobs(guildId uint64, tag string) (int64, error) {
+	db, err := c.getDB(guildId)
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	n, err := db.Writes.CancelJobs(ctx, tag)
+	if err != nil {
+		return 0, errors.Join(err, fmt.Errorf("failed to cancel %s jobs", tag))
+	}
+	return n, nil
+}
+
+// ClaimDueJobs marks up to limit jobs due by now as running and returns them, oldest first.
+func (c *Ci6ndex) ClaimDueJobs(guildId uint64, now time.Time, limit int) ([]Job, error) {
+	db, err := c.getDB(guildId)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	rows, err := db.Writes.ClaimDueJobs(ctx, generated.ClaimDueJobsParams{
+		DueAt: jobTime(now),
+		Limit: int64(limit),
+	})
+	if err != nil {
+		return nil, errors.Join(err, errors.New("failed to claim due jobs"))
+	}
+	jobs := make([]Job, len(rows))
+	for i, row := range rows {
+		jobs[i] = jobFromRow(row)
+	}
+	return jobs, nil
+}
+
+func (c *Ci6ndex) CompleteJob(guildId uint64, jobId int64) error {
+	db, err := c.getDB(guildId)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	if err := db.Writes.CompleteJob(ctx, jobId); err != nil {
+		return errors.Join(err, fmt.Errorf("failed to complete job %d", jobId))
+	}
+	return nil
+}
+
+// FailJob records why a job's run failed and schedules it again with exponential backoff, or gives up on it after
+// MaxJobAttempts runs. It returns whether the job will be retried.
+func (c *Ci6ndex) FailJob(guildId uint64, job Job, cause error, now time.Time) (bool, error) {
+	db, err := c.getDB(guildId)
+	if err != nil {
+		return false, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	retry := job.Attempts < MaxJobAttempts
+	params := generated.RetryJobParams{
+		Status:    string(JobFailed),
+		DueAt:     jobTime(job.DueAt),
+		LastError: cause.Error(),
+		ID:        job.ID,
+	}
+	if retry {
+		params.Status = string(JobPending)
+		params.DueAt = jobTime(retryAt(now, job.Attempts))
+	}
+	if err := db.Writes.RetryJob(ctx, params); err != nil {
+		return false, errors.Join(err, fmt.Errorf("failed to record failure of job %d", job.ID))
+	}
+	return retry, nil
+}
+
+// ResetRunningJobs puts jobs that were running when the bot stopped back in the queue and returns how many there
+// were.
+func (c *Ci6ndex) ResetRunningJobs(guildId uint64) (int64, error) {
+	db, err := c.getDB(guildId)
+	if err != nil {
+		return 0, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	n, err := db.Writes.ResetRunningJobs(ctx)
+	if err != nil {
+		return 0, errors.Join(err, errors.New("failed to reset running jobs"))
+	}
+	return n, nil
+}
+
+func jobFromRow(row generated.Job) Job {
+	return Job{
+		ID:       row.ID,
+		Kind:     row.Kind,
+		Tag:      row.Tag,
+		Payload:  json.RawMessage(row.Payload),
+		DueAt:    row.DueAt.UTC(),
+		Attempts: int(row.Attempts),
+	}
+}
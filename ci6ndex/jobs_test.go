@@ -0,0 +1,96 @@
+package ci6ndex
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+type testPayload struct {
+	DraftID int64
+}
+
+func TestJobs(t *testing.T) {
+	now := time.Now()
+	// Claim whatever earlier tests left behind, so only this test's jobs are due below
+	if _, err := testC.ClaimDueJobs(testGuildID, now.Add(time.Hour), 100); err != nil {
+		t.Fatalf("failed to claim jobs: %v", err)
+	}
+
+	due, err := testC.ScheduleJob(testGuildID, "test", "test:1", now.Add(-time.Minute), testPayload{DraftID: 1})
+	if err != nil {
+		t.Fatalf("failed to schedule job: %v", err)
+	}
+	if _, err := testC.ScheduleJob(testGuildID, "test", "test:2", now.Add(time.Hour), testPayload{DraftID: 2}); err != nil {
+		t.Fatalf("failed to schedule job: %v", err)
+	}
+
+	jobs, err := testC.ClaimDueJobs(testGuildID, now, 10)
+	if err != nil {
+		t.Fatalf("failed to claim jobs: %v", err)
+	}
+	if len(jobs) != 1 || jobs[0].ID != due.ID || jobs[0].Attempts != 1 {
+		t.Fatalf("expected only the overdue job to be claimed, got %+v", jobs)
+	}
+	var payload testPayload
+	if err := jobs[0].Decode(&payload); err != nil || payload.DraftID != 1 {
+		t.Fatalf("expected the payload to round trip, got %+v (%v)", payload, err)
+	}
+	if again, _ := testC.ClaimDueJobs(testGuildID, now, 10); len(again) != 0 {
+		t.Fatalf("expected a running job to not be claimed twice, got %+v", again)
+	}
+
+	// A failed run is retried later, not right away
+	retry, err := testC.FailJob(testGuildID, jobs[0], errors.New("discord is down"), now)
+	if err != nil || !retry {
+		t.Fatalf("expected the job to be retried, got retry=%v err=%v", retry, err)
+	}
+	if again, _ := testC.ClaimDueJobs(testGuildID, now, 10); len(again) != 0 {
+		t.Fatalf("expected the retry to wait, got %+v", again)
+	}
+	jobs, err = testC.ClaimDueJobs(testGuildID, retryAt(now, 1), 10)
+	if err != nil || len(jobs) != 1 || jobs[0].Attempts != 2 {
+		t.Fatalf("expected the job to be retried, got %+v (%v)", jobs, err)
+	}
+	if err := testC.CompleteJob(testGuildID, jobs[0].ID); err != nil {
+		t.Fatalf("failed to complete job: %v", err)
+	}
+
+	// Rescheduling with the same tag replaces the pending job
+	if _, err := testC.ScheduleJob(testGuildID, "test", "test:2", now.Add(-time.Minute), testPayload{DraftID: 3}); err != nil {
+		t.Fatalf("failed to schedule job: %v", err)
+	}
+	jobs, err = testC.ClaimDueJobs(testGuildID, now.Add(2*time.Hour), 10)
+	if err != nil || len(jobs) != 1 {
+		t.Fatalf("expected only the rescheduled job, got %+v (%v)", jobs, err)
+	}
+	if err := jobs[0].Decode(&payload); err != nil || payload.DraftID != 3 {
+		t.Fatalf("expected the rescheduled payload, got %+v (%v)", payload, err)
+	}
+
+	// Jobs left running by a crash go back in the queue
+	if n, err := testC.ResetRunningJobs(testGuildID); err != nil || n != 1 {
+		t.Fatalf("expected one running job to be reset, got %d (%v)", n, err)
+	}
+	if n, err := testC.CancelJobs(testGuildID, "test:2"); err != nil || n != 1 {
+		t.Fatalf("expected one job to be cancelled, got %d (%v)", n, err)
+	}
+	if jobs, _ := testC.ClaimDueJobs(testGuildID, now.Add(2*time.Hour), 10); len(jobs) != 0 {
+		t.Fatalf("expected cancelled jobs to not run, got %+v", jobs)
+	}
+}
+
+func TestFailJob_GivesUp(t *testing.T) {
+	job, err := testC.ScheduleJob(testGuildID, "test", "test:give-up", time.Now(), nil)
+	if err != nil {
+		t.Fatalf("failed to schedule job: %v", err)
+	}
+	job.Attempts = MaxJobAttempts
+	retry, err := testC.FailJob(testGuildID, job, errors.New("still down"), time.Now())
+	if err != nil || retry {
+		t.Fatalf("expected the job to be given up on, got retry=%v err=%v", retry, err)
+	}
+	if n, _ := testC.CancelJobs(testGuildID, "test:give-up"); n != 0 {
+		t.Fatalf("expected a failed job to no longer be pending")
+	}
+}
@@ -2,6 +2,7 @@ package ci6ndex
 
 import (
 	"ci6ndex/ci6ndex/generated"
+	"cmp"
 	"context"
 	"database/sql"
 	"errors"
@@ -11,6 +12,14 @@ import (
 	"time"
 )
 
+// LeaderName is the leader's friendly name when it has one.
+func LeaderName(l generated.Leader) string {
+	if l.FriendlyName.Valid && l.FriendlyName.String != "" {
+		return l.FriendlyName.String
+	}
+	return l.LeaderName
+}
+
 // GetLeaders returns an alphabatized slice of leaders
 func (c *Ci6ndex) GetLeaders(guildId uint64) ([]generated.Leader, error) {
 	db, err := c.getDB(guildId)
@@ -48,6 +57,9 @@ func (c *Ci6ndex) GetLeadersInRange(guildId, offset, limit uint64) ([]generated.
 	return leaders, nil
 }
 
+// ErrLeaderNotFound is returned when a guild has no leader with the requested ID.
+var ErrLeaderNotFound = errors.New("leader not found")
+
 func (c *Ci6ndex) GetLeaderById(guildId uint64, leaderId uint64) (generated.Leader, error) {
 	db, err := c.getDB(guildId)
 	if err != nil {
@@ -59,7 +71,7 @@ func (c *Ci6ndex) GetLeaderById(guildId uint64, leaderId uint64) (generated.Lead
 
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			return generated.Leader{}, fmt.Errorf("leader with ID %d not found", leaderId)
+			return generated.Leader{}, fmt.Errorf("leader with ID %d: %w", leaderId, ErrLeaderNotFound)
 		}
 		return generated.Leader{}, errors.Join(err, fmt.Errorf("failed to fetch leader with ID %d", leaderId))
 	}
@@ -67,20 +79,187 @@ func (c *Ci6ndex) GetLeaderById(guildId uint64, leaderId uint64) (generated.Lead
 	return leader, nil
 }
 
-func (c *Ci6ndex) GetDocumentsForLeader(guildID uint64, leaderID int64) ([]generated.Document, error) {
-	db, err := c.getDB(guildID)
+// SetLeaderBanned bans a leader from drafts, or lifts the ban.
+func (c *Ci6ndex) SetLeaderBanned(guildId uint64, leaderId int64, banned bool) error {
+	db, err := c.getDB(guildId)
 	if err != nil {
-		return nil, err
+		return err
 	}
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
 	defer cancel()
+	err = db.Writes.SetLeaderBanned(ctx, generated.SetLeaderBannedParams{Banned: banned, ID: leaderId})
+	if err != nil {
+		return errors.Join(err, fmt.Errorf("failed to set banned=%t for leader %d", banned, leaderId))
+	}
+	return nil
+}
+
+// LeaderSort is the order QueryLeaders returns leaders in.
+type LeaderSort int
+
+const (
+	// SortByName orders by civ then leader name
+	SortByName LeaderSort = iota
+	// SortByTier orders best community tier first, unranked leaders last
+	SortByTier
+	// SortByRatings orders by how many players have rated the leader, most first
+	SortByRatings
+	// SortByWinRate orders by the share of picks that won their draft, highest first
+	SortByWinRate
+)
+
+// LeaderVersion restricts leaders by the game version they are available in.
+type LeaderVersion int
 
-	docs, err := db.Queries.GetDocumentsForLeader(ctx, leaderID)
+const (
+	// VersionAll includes every leader
+	VersionAll LeaderVersion = iota
+	// VersionVanilla excludes leaders that only exist in BBG Expanded
+	VersionVanilla
+	// VersionBBGExpanded only includes leaders added by BBG Expanded
+	VersionBBGExpanded
+)
+
+// LeaderQuery filters, sorts and pages the guild's leaders. The zero value returns every leader by name.
+type LeaderQuery struct {
+	// MinTier and MaxTier bound the tier the community tier value rounds to, S is 1 and F is 5, so a leader at
+	// 2.4 is within an A to A range. 0 leaves a bound open. Unranked leaders never fall in a bounded range.
+	MinTier float64
+	MaxTier float64
+	// Banned, Unranked and HasGuide only filter when Valid
+	Banned   sql.NullBool
+	Unranked sql.NullBool
+	HasGuide sql.NullBool
+	// CivName fuzzily matches the civ, see SearchLeaders
+	CivName string
+	Version LeaderVersion
+	Sort    LeaderSort
+	// Limit of 0 returns every match
+	Offset uint64
+	Limit  uint64
+}
+
+// LeaderStats is a leader along with how it has been rated and played.
+type LeaderStats struct {
+	Leader    generated.Leader
+	Ratings   int64
+	Picks     int64
+	Wins      int64
+	Documents int64
+}
+
+// WinRate is the share of picks that won their draft, 0 when the leader has never been picked.
+func (s LeaderStats) WinRate() float64 {
+	if s.Picks == 0 {
+		return 0
+	}
+	return float64(s.Wins) / float64(s.Picks)
+}
+
+// QueryLeaders returns the page of leaders matching q, and how many leaders matched in total. The filters run in
+// the database, the fuzzy civ match, sorting and paging afterward.
+func (c *Ci6ndex) QueryLeaders(guildID uint64, q LeaderQuery) ([]LeaderStats, int, error) {
+	db, err := c.getDB(guildID)
 	if err != nil {
-		if errors.Is(err, sql.ErrNoRows) {
-			return make([]generated.Document, 0), nil
+		return nil, 0, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	rows, err := db.Queries.QueryLeaderStats(ctx, q.params())
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, 0, errors.Join(err, errors.New("failed to query leader stats"))
+	}
+
+	var civMatches map[int64]bool
+	if q.CivName != "" {
+		leaders, err := c.GetLeaders(guildID)
+		if err != nil {
+			return nil, 0, err
+		}
+		// only keep the best matching civ so "india" doesn't also list every civ one typo away
+		matches := SearchLeaders(leaders, q.CivName, SearchCivName)
+		civMatches = make(map[int64]bool, len(matches))
+		for _, m := range matches {
+			if m.Score == matches[0].Score {
+				civMatches[m.Leader.ID] = true
+			}
+		}
+	}
+
+	matched := make([]LeaderStats, 0, len(rows))
+	for _, r := range rows {
+		if civMatches != nil && !civMatches[r.Leader.ID] {
+			continue
+		}
+		matched = append(matched, LeaderStats{Leader: r.Leader, Ratings: r.Ratings, Picks: r.Picks, Wins: r.Wins,
+			Documents: r.Documents})
+	}
+	slices.SortStableFunc(matched, q.compare)
+
+	total := len(matched)
+	if q.Offset >= uint64(total) {
+		return make([]LeaderStats, 0), total, nil
+	}
+	matched = matched[q.Offset:]
+	if q.Limit > 0 && q.Limit < uint64(len(matched)) {
+		matched = matched[:q.Limit]
+	}
+	return matched, total, nil
+}
+
+// params turns the filters into query parameters, leaving the ones that don't filter NULL. The tier bounds are
+// widened to [MinTier-0.5, MaxTier+0.5), the values GetTierByValue rounds into the range.
+func (q LeaderQuery) params() generated.QueryLeaderStatsParams {
+	p := generated.QueryLeaderStatsParams{
+		Banned:   q.Banned,
+		Unranked: q.Unranked,
+		HasGuide: q.HasGuide,
+	}
+	if q.MinTier > 0 {
+		p.MinTier = sql.NullFloat64{Float64: q.MinTier - 0.5, Valid: true}
+	}
+	if q.MaxTier > 0 {
+		p.MaxTier = sql.NullFloat64{Float64: q.MaxTier + 0.5, Valid: true}
+	}
+	switch q.Version {
+	case VersionVanilla:
+		p.BbgExpanded = sql.NullBool{Bool: false, Valid: true}
+	case VersionBBGExpanded:
+		p.BbgExpanded = sql.NullBool{Bool: true, Valid: true}
+	}
+	return p
+}
+
+func (q LeaderQuery) compare(a, b LeaderStats) int {
+	byName := func() int {
+		if c := strings.Compare(a.Leader.CivName, b.Leader.CivName); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Leader.LeaderName, b.Leader.LeaderName)
+	}
+	switch q.Sort {
+	case SortByTier:
+		// unranked leaders have no tier, keep them after F
+		if a.Leader.Unranked != b.Leader.Unranked {
+			if a.Leader.Unranked {
+				return 1
+			}
+			return -1
+		}
+		if c := cmp.Compare(a.Leader.Tier, b.Leader.Tier); c != 0 {
+			return c
+		}
+	case SortByRatings:
+		if c := cmp.Compare(b.Ratings, a.Ratings); c != 0 {
+			return c
+		}
+	case SortByWinRate:
+		if c := cmp.Compare(b.WinRate(), a.WinRate()); c != 0 {
+			return c
+		}
+		if c := cmp.Compare(b.Picks, a.Picks); c != 0 {
+			return c
 		}
-		return nil, err
 	}
-	return docs, nil
+	return byName()
 }
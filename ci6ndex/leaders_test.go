@@ -0,0 +1,170 @@
+package ci6ndex
+
+import (
+	"database/sql"
+	"strconv"
+	"testing"
+)
+
+func TestQueryLeaders_Filters(t *testing.T) {
+	tests := []struct {
+		name  string
+		query LeaderQuery
+		check func(s LeaderStats) bool
+	}{
+		{
+			name:  "not banned",
+			query: LeaderQuery{Banned: sql.NullBool{Bool: false, Valid: true}},
+			check: func(s LeaderStats) bool { return !s.Leader.Banned },
+		},
+		{
+			name:  "unranked",
+			query: LeaderQuery{Unranked: sql.NullBool{Bool: true, Valid: true}},
+			check: func(s LeaderStats) bool { return s.Leader.Unranked },
+		},
+		{
+			name:  "tier range",
+			query: LeaderQuery{MinTier: A.Value(), MaxTier: B.Value()},
+			check: func(s LeaderStats) bool {
+				tier, err := GetTierByValue(s.Leader.Tier)
+				return !s.Leader.Unranked && err == nil && (*tier == A || *tier == B)
+			},
+		},
+		{
+			name:  "has guide",
+			query: LeaderQuery{HasGuide: sql.NullBool{Bool: true, Valid: true}},
+			check: func(s LeaderStats) bool { return s.Documents > 0 },
+		},
+		{
+			name:  "vanilla",
+			query: LeaderQuery{Version: VersionVanilla},
+			check: func(s LeaderStats) bool { return !s.Leader.BbgExpanded },
+		},
+		{
+			name:  "bbg expanded",
+			query: LeaderQuery{Version: VersionBBGExpanded},
+			check: func(s LeaderStats) bool { return s.Leader.BbgExpanded },
+		},
+		{
+			name:  "civ",
+			query: LeaderQuery{CivName: "amerca"},
+			check: func(s LeaderStats) bool { return s.Leader.CivName == "AMERICA" },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			leaders, total, err := testC.QueryLeaders(testGuildID, tt.query)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(leaders) == 0 || total != len(leaders) {
+				t.Fatalf("expected matches, got %d of %d", len(leaders), total)
+			}
+			for _, s := range leaders {
+				if !tt.check(s) {
+					t.Fatalf("leader %d (%s) should have been filtered out", s.Leader.ID, s.Leader.LeaderName)
+				}
+			}
+		})
+	}
+}
+
+func TestLeaderQuery_TierBounds(t *testing.T) {
+	p := LeaderQuery{MinTier: A.Value(), MaxTier: B.Value()}.params()
+	for _, value := range []float64{1.49, 1.5, 2.4, 2.5, 3.49, 3.5} {
+		tier, err := GetTierByValue(value)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		inTiers := *tier == A || *tier == B
+		inBounds := value >= p.MinTier.Float64 && value < p.MaxTier.Float64
+		if inTiers != inBounds {
+			t.Fatalf("expected %v to be in the A to B bounds %v, got %v", value, inTiers, inBounds)
+		}
+	}
+}
+
+func TestQueryLeaders_Paging(t *testing.T) {
+	all, total, err := testC.QueryLeaders(testGuildID, LeaderQuery{})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(all) != total {
+		t.Fatalf("expected every leader without a limit, got %d of %d", len(all), total)
+	}
+
+	page, pageTotal, err := testC.QueryLeaders(testGuildID, LeaderQuery{Offset: 5, Limit: 5})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pageTotal != total || len(page) != 5 {
+		t.Fatalf("expected 5 of %d leaders, got %d of %d", total, len(page), pageTotal)
+	}
+	for i, s := range page {
+		if s.Leader.ID != all[5+i].Leader.ID {
+			t.Fatalf("expected page to continue the name order at %d", i)
+		}
+	}
+
+	past, _, err := testC.QueryLeaders(testGuildID, LeaderQuery{Offset: uint64(total)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(past) != 0 {
+		t.Fatalf("expected no leaders past the end, got %d", len(past))
+	}
+}
+
+func TestQueryLeaders_SortByTier(t *testing.T) {
+	leaders, _, err := testC.QueryLeaders(testGuildID, LeaderQuery{Sort: SortByTier})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i := 1; i < len(leaders); i++ {
+		prev, cur := leaders[i-1].Leader, leaders[i].Leader
+		if prev.Unranked && !cur.Unranked {
+			t.Fatalf("expected unranked leaders last, %d came before %d", prev.ID, cur.ID)
+		}
+		if !prev.Unranked && !cur.Unranked && prev.Tier > cur.Tier {
+			t.Fatalf("expected best tier first, %v came before %v", prev.Tier, cur.Tier)
+		}
+	}
+}
+
+func TestQueryLeaders_SortByWinRate(t *testing.T) {
+	// Mali (Mansa Musa) and Maori (Kupe) are picked by the winner and a loser of a finished draft
+	const winner, loser int64 = 1012, 1013
+	const musa, kupe int64 = 51, 53
+
+	res, err := testDB.writeConn.Exec("INSERT INTO drafts (active) VALUES (false)")
+	if err != nil {
+		t.Fatalf("failed to create draft: %v", err)
+	}
+	draftID, err := res.LastInsertId()
+	if err != nil {
+		t.Fatalf("failed to create draft: %v", err)
+	}
+	_, err = testDB.writeConn.Exec("INSERT INTO picks (player, draft_id, pick) VALUES (?, ?, ?), (?, ?, ?)",
+		strconv.FormatInt(winner, 10), draftID, musa, strconv.FormatInt(loser, 10), draftID, kupe)
+	if err != nil {
+		t.Fatalf("failed to add picks: %v", err)
+	}
+	if err := testC.SetDraftWinner(testGuildID, draftID, winner); err != nil {
+		t.Fatalf("failed to set winner: %v", err)
+	}
+
+	leaders, _, err := testC.QueryLeaders(testGuildID, LeaderQuery{Sort: SortByWinRate})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if leaders[0].Leader.ID != musa || leaders[0].WinRate() != 1 {
+		t.Fatalf("expected leader %d to have the best win rate, got %d (%v)",
+			musa, leaders[0].Leader.ID, leaders[0].WinRate())
+	}
+	for _, s := range leaders {
+		if s.Leader.ID == kupe && (s.Picks != 1 || s.Wins != 0) {
+			t.Fatalf("expected leader %d to have 1 pick and no wins, got %d and %d", kupe, s.Picks, s.Wins)
+		}
+	}
+}
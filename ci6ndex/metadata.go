@@ -0,0 +1,152 @@
+package ci6ndex
+
+import (
+	"ci6ndex/ci6ndex/generated"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"slices"
+	"sync"
+)
+
+// supportedMetadataVersion is the version of data/leaders.json this build understands. Bump it alongside any
+// breaking change to the file's shape.
+const supportedMetadataVersion = 1
+
+//go:embed data/leaders.json
+var leaderMetadataFile []byte
+
+// PlayStyleTags are the tags a leader may be labelled with in data/leaders.json.
+var PlayStyleTags = []string{
+	"science",
+	"culture",
+	"religion",
+	"gold",
+	"production",
+	"wonders",
+	"great-people",
+	"expansion",
+	"diplomacy",
+	"naval",
+	"defensive",
+	"early-war",
+	"late-war",
+}
+
+// UniqueKind is what kind of unique a civ or leader unlocks.
+type UniqueKind string
+
+const (
+	UniqueUnit        UniqueKind = "unit"
+	UniqueBuilding    UniqueKind = "building"
+	UniqueDistrict    UniqueKind = "district"
+	UniqueImprovement UniqueKind = "improvement"
+)
+
+type Ability struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type Unique struct {
+	Name string     `json:"name"`
+	Kind UniqueKind `json:"kind"`
+}
+
+// LeaderMetadata describes how a leader plays: the civ and leader abilities, the uniques both unlock and
+// play-style tags such as "science" or "early-war".
+type LeaderMetadata struct {
+	CivAbility    Ability
+	LeaderAbility Ability
+	Uniques       []Unique
+	Tags          []string
+}
+
+// HasTag reports whether the leader is labelled with any of the given tags.
+func (m LeaderMetadata) HasTag(tags ...string) bool {
+	for _, t := range tags {
+		if slices.Contains(m.Tags, t) {
+			return true
+		}
+	}
+	return false
+}
+
+type metadataFile struct {
+	Version int `json:"version"`
+	Civs    []struct {
+		Civ     string   `json:"civ"`
+		Ability Ability  `json:"ability"`
+		Uniques []Unique `json:"uniques"`
+	} `json:"civs"`
+	Leaders []struct {
+		Civ     string   `json:"civ"`
+		Leader  string   `json:"leader"`
+		Ability Ability  `json:"ability"`
+		Uniques []Unique `json:"uniques"`
+		Tags    []string `json:"tags"`
+	} `json:"leaders"`
+}
+
+type metadataKey struct {
+	civ    string
+	leader string
+}
+
+var loadLeaderMetadata = sync.OnceValues(func() (map[metadataKey]LeaderMetadata, error) {
+	return parseLeaderMetadata(leaderMetadataFile)
+})
+
+func parseLeaderMetadata(data []byte) (map[metadataKey]LeaderMetadata, error) {
+	var file metadataFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		return nil, fmt.Errorf("failed to parse leader metadata: %w", err)
+	}
+	if file.Version != supportedMetadataVersion {
+		return nil, fmt.Errorf("unsupported leader metadata version %d, expected %d", file.Version, supportedMetadataVersion)
+	}
+
+	type civMetadata struct {
+		ability Ability
+		uniques []Unique
+	}
+	civs := make(map[string]civMetadata, len(file.Civs))
+	for _, c := range file.Civs {
+		civs[c.Civ] = civMetadata{ability: c.Ability, uniques: c.Uniques}
+	}
+
+	metadata := make(map[metadataKey]LeaderMetadata, len(file.Leaders))
+	for _, l := range file.Leaders {
+		for _, t := range l.Tags {
+			if !slices.Contains(PlayStyleTags, t) {
+				return nil, fmt.Errorf("leader %s (%s) has unknown tag %q", l.Leader, l.Civ, t)
+			}
+		}
+		civ := civs[l.Civ]
+		metadata[metadataKey{civ: l.Civ, leader: l.Leader}] = LeaderMetadata{
+			CivAbility:    civ.ability,
+			LeaderAbility: l.Ability,
+			Uniques:       append(slices.Clone(civ.uniques), l.Uniques...),
+			Tags:          l.Tags,
+		}
+	}
+	return metadata, nil
+}
+
+// MetadataForLeader returns the abilities, uniques and play-style tags of a leader, or false if the data file has
+// no entry for it.
+func MetadataForLeader(leader generated.Leader) (LeaderMetadata, bool) {
+	metadata, err := loadLeaderMetadata()
+	if err != nil {
+		return LeaderMetadata{}, false
+	}
+	m, ok := metadata[metadataKey{civ: leader.CivName, leader: leader.LeaderName}]
+	return m, ok
+}
+
+// ValidateLeaderMetadata checks that the embedded data file is readable, so a broken file is caught at startup
+// rather than by leaders silently missing their metadata.
+func ValidateLeaderMetadata() error {
+	_, err := loadLeaderMetadata()
+	return err
+}
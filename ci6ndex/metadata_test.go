@@ -0,0 +1,130 @@
+package ci6ndex
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+func TestParseLeaderMetadata(t *testing.T) {
+	metadata, err := parseLeaderMetadata(leaderMetadataFile)
+	if err != nil {
+		t.Fatalf("failed to parse embedded leader metadata: %v", err)
+	}
+	if len(metadata) == 0 {
+		t.Fatal("expected embedded leader metadata to have entries")
+	}
+
+	tests := []struct {
+		name string
+		data string
+	}{
+		{name: "unsupported version", data: `{"version": 99, "civs": [], "leaders": []}`},
+		{name: "unknown tag", data: `{"version": 1, "civs": [], "leaders": [{"civ": "GREECE", "leader": "GORGO", "tags": ["bananas"]}]}`},
+		{name: "malformed", data: `{"version": 1, "leaders": {}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := parseLeaderMetadata([]byte(tt.data)); err == nil {
+				t.Fatalf("expected an error for %s", tt.name)
+			}
+		})
+	}
+}
+
+func TestMetadataForLeader_CoversSeededLeaders(t *testing.T) {
+	leaders, err := testC.GetLeaders(testGuildID)
+	if err != nil {
+		t.Fatalf("failed to get leaders: %v", err)
+	}
+
+	for _, l := range leaders {
+		metadata, ok := MetadataForLeader(l)
+		if !ok {
+			t.Errorf("leader %d %s (%s) has no metadata", l.ID, l.LeaderName, l.CivName)
+			continue
+		}
+		if len(metadata.Tags) == 0 {
+			t.Errorf("leader %d %s (%s) has no play-style tags", l.ID, l.LeaderName, l.CivName)
+		}
+		// The base game leaders are fully described, BBG Expanded ones only carry tags for now
+		if l.ID <= 77 && (metadata.CivAbility.Name == "" || metadata.LeaderAbility.Name == "" || len(metadata.Uniques) == 0) {
+			t.Errorf("leader %d %s (%s) is missing abilities or uniques", l.ID, l.LeaderName, l.CivName)
+		}
+	}
+}
+
+func TestSearchLeaders_Keyword(t *testing.T) {
+	leaders, err := testC.GetLeaders(testGuildID)
+	if err != nil {
+		t.Fatalf("failed to get leaders: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  int64
+	}{
+		{name: "unique unit", query: "Hoplite", want: 36},
+		{name: "leader ability", query: "Thermopylae", want: 36},
+		{name: "civ ability typo", query: "Sahel Merchnts", want: 51},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches := SearchLeaders(leaders, tt.query, SearchKeyword)
+			ids := make([]int64, len(matches))
+			for i, m := range matches {
+				ids[i] = m.Leader.ID
+			}
+			if !slices.Contains(ids, tt.want) {
+				t.Fatalf("expected leader %d in results for %q, got %v", tt.want, tt.query, ids)
+			}
+		})
+	}
+
+	// "naval" is a typo away from "cavalry", so only exact matches have to carry the tag
+	for _, m := range SearchLeaders(leaders, "naval", SearchKeyword) {
+		if m.Score < scoreExact {
+			continue
+		}
+		metadata, _ := MetadataForLeader(m.Leader)
+		if !metadata.HasTag("naval") {
+			t.Fatalf("leader %d matched the naval tag without having it", m.Leader.ID)
+		}
+	}
+}
+
+func TestRollForPlayers_TagRule(t *testing.T) {
+	ctx := context.Background()
+	players, err := testDB.Queries.GetPlayersFromActiveDraft(ctx)
+	if err != nil {
+		t.Fatalf("failed to get players: %v", err)
+	}
+
+	playerIds := make([]int64, 4)
+	for i := range 4 {
+		playerIds[i] = players[i].ID
+	}
+
+	rules := []Rule{
+		&TagRule{Tags: []string{"naval"}},
+		&TagRule{Tags: []string{"science", "culture"}},
+		&NoOpRule{},
+	}
+	offerings, err := testC.RollForPlayers(testGuildID, playerIds, rules)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, o := range offerings {
+		hasNaval, hasScienceOrCulture := false, false
+		for _, l := range o.Leaders {
+			metadata, _ := MetadataForLeader(l)
+			hasNaval = hasNaval || metadata.HasTag("naval")
+			hasScienceOrCulture = hasScienceOrCulture || metadata.HasTag("science", "culture")
+		}
+		if !hasNaval || !hasScienceOrCulture {
+			t.Fatalf("player %d was not offered the required play styles: %v", o.Player.ID, o.Leaders)
+		}
+	}
+}
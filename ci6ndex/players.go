@@ -5,8 +5,18 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strconv"
+	"time"
 )
 
+// PlayerName is the player's display name on Discord, falling back to their username.
+func PlayerName(p generated.Player) string {
+	if p.GlobalName.Valid && p.GlobalName.String != "" {
+		return p.GlobalName.String
+	}
+	return p.Username
+}
+
 func (c *Ci6ndex) GetPlayersFromActiveDraft(guildId uint64) ([]generated.Player, error) {
 	db, err := c.getDB(guildId)
 	if err != nil {
@@ -80,3 +90,40 @@ func (c *Ci6ndex) AddPlayer(ctx context.Context, guildId uint64, params generate
 	}
 	return nil
 }
+
+// PlayerDraft is a draft a player was registered for, along with what they picked and whether they won.
+type PlayerDraft struct {
+	DraftID int64
+	Mode    DraftMode
+	// PickID is the leader the player picked, 0 when the draft had no picks
+	PickID int64
+	// Decided is set once a winner is recorded for the draft
+	Decided bool
+	Won     bool
+}
+
+// GetPlayerDrafts returns every draft the player was registered for, newest first.
+func (c *Ci6ndex) GetPlayerDrafts(guildId uint64, playerId int64) ([]PlayerDraft, error) {
+	db, err := c.getDB(guildId)
+	if err != nil {
+		return nil, errors.Join(err, errors.New("failed to get db"))
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	rows, err := db.Queries.GetPlayerDrafts(ctx, playerId)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.Join(err, errors.New("failed to get drafts of player"))
+	}
+	drafts := make([]PlayerDraft, len(rows))
+	for i, r := range rows {
+		drafts[i] = PlayerDraft{
+			DraftID: r.ID,
+			Mode:    DraftMode(r.Mode),
+			PickID:  r.Pick.Int64,
+			Decided: r.Winner.Valid,
+			// winners are stored as the player ID in text, like picks
+			Won: r.Winner.Valid && r.Winner.String == strconv.FormatInt(playerId, 10),
+		}
+	}
+	return drafts, nil
+}
@@ -2,18 +2,23 @@ package ci6ndex
 
 import (
 	"ci6ndex/ci6ndex/generated"
+	"cmp"
 	"context"
 	"database/sql"
 	"errors"
 	"fmt"
 	"log/slog"
+	"math"
+	"slices"
 	"time"
 
 	"golang.org/x/sync/errgroup"
 	"golang.org/x/sync/semaphore"
 )
 
-func (c *Ci6ndex) SubmitRankForPlayer(guildID uint64, rank string, playerID int64, leaderID int64) error {
+// SubmitRankForPlayer records a player's rank of a leader under the BBG or the vanilla ruleset, replacing their
+// previous rank of the leader under that ruleset.
+func (c *Ci6ndex) SubmitRankForPlayer(guildID uint64, rank string, playerID int64, leaderID int64, bbg bool) error {
 	db, err := c.getDB(guildID)
 	if err != nil {
 		return err
@@ -27,21 +32,33 @@ func (c *Ci6ndex) SubmitRankForPlayer(guildID uint64, rank string, playerID int6
 		PlayerID: playerID,
 		LeaderID: leaderID,
 		Tier:     tier.Value(),
+		Bbg:      bbg,
 	})
 	if err != nil {
 		return fmt.Errorf("failed to submit ranking of %v for playerID %d: %w", tier, playerID, err)
 	}
+
+	err = db.Writes.AddRankHistory(context.Background(), generated.AddRankHistoryParams{
+		PlayerID: playerID,
+		LeaderID: leaderID,
+		Tier:     tier.Value(),
+		Bbg:      bbg,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to record ranking history for playerID %d: %w", playerID, err)
+	}
 	return nil
 }
 
 type LeaderRankWithPlayer struct {
 	Tier       float64
+	BBG        bool
 	PlayerID   int64
 	Username   string
 	GlobalName sql.NullString
 }
 
-// GetRanksForLeader returns all player-submitted ranks for a leader with player info.
+// GetRanksForLeader returns all player-submitted ranks for a leader with player info, the vanilla ranks first.
 func (c *Ci6ndex) GetRanksForLeader(guildID uint64, leaderID int64) ([]LeaderRankWithPlayer, error) {
 	db, err := c.getDB(guildID)
 	if err != nil {
@@ -62,6 +79,7 @@ func (c *Ci6ndex) GetRanksForLeader(guildID uint64, leaderID int64) ([]LeaderRan
 	for i, r := range ranks {
 		result[i] = LeaderRankWithPlayer{
 			Tier:       r.Tier,
+			BBG:        r.Bbg,
 			PlayerID:   r.PlayerID,
 			Username:   r.Username,
 			GlobalName: r.GlobalName,
@@ -70,6 +88,8 @@ func (c *Ci6ndex) GetRanksForLeader(guildID uint64, leaderID int64) ([]LeaderRan
 	return result, nil
 }
 
+// CalculateTierForLeader averages the leader's vanilla ranks into its community tier. Drafts are rolled by the
+// vanilla tiers, so ranks submitted under BBG never move the stored tier.
 func (c *Ci6ndex) CalculateTierForLeader(guildID uint64, leaderID int64) error {
 	slog.Info("calculating tier", "guild", guildID, "leader", leaderID)
 	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
@@ -88,7 +108,10 @@ func (c *Ci6ndex) CalculateTierForLeader(guildID uint64, leaderID int64) error {
 		return nil
 	}
 
-	ranks, err := db.Queries.GetAllRanksForLeader(ctx, leaderID)
+	ranks, err := db.Queries.GetAllRanksForLeader(ctx, generated.GetAllRanksForLeaderParams{
+		LeaderID: leaderID,
+		Bbg:      false,
+	})
 	if err != nil {
 		return err
 	}
@@ -107,10 +130,33 @@ func (c *Ci6ndex) CalculateTierForLeader(guildID uint64, leaderID int64) error {
 		Tier: averageRank,
 		ID:   leaderID,
 	})
-	return err
+	if err != nil {
+		return err
+	}
+	// the tier is always kept exact, only moves worth showing are recorded in its history
+	if !tierChanged(leader.Tier, averageRank) {
+		return nil
+	}
+
+	// Attribute the change to whoever submitted the most recent rank
+	movedBy := sql.NullInt64{}
+	latest, err := db.Queries.GetLatestRankHistoryForLeader(ctx, generated.GetLatestRankHistoryForLeaderParams{
+		LeaderID: leaderID,
+		Bbg:      false,
+	})
+	if err == nil {
+		movedBy = sql.NullInt64{Int64: latest.PlayerID, Valid: true}
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return err
+	}
+	return db.Writes.AddTierHistory(ctx, generated.AddTierHistoryParams{
+		LeaderID: leaderID,
+		Tier:     averageRank,
+		PlayerID: movedBy,
+	})
 }
 
-// CalculateTiers will compute average tiers based on user rankings and update leaders table with the result
+// CalculateTiers will compute average tiers based on the vanilla user rankings and update leaders table with the result
 func (c *Ci6ndex) CalculateTiers(guildID uint64) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancel()
@@ -119,7 +165,7 @@ func (c *Ci6ndex) CalculateTiers(guildID uint64) error {
 		return err
 	}
 
-	ranks, err := db.Queries.GetAllRanks(ctx)
+	ranks, err := db.Queries.GetRanksForRuleset(ctx, false)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return nil
@@ -141,10 +187,12 @@ func (c *Ci6ndex) CalculateTiers(guildID uint64) error {
 		return err
 	}
 	unrankedLeaders := make(map[int64]bool)
+	currentTiers := make(map[int64]float64)
 	for _, l := range leaders {
 		if l.Unranked {
 			unrankedLeaders[l.ID] = true
 		}
+		currentTiers[l.ID] = l.Tier
 	}
 
 	for leaderID, rs := range ranksByLeads {
@@ -156,6 +204,7 @@ func (c *Ci6ndex) CalculateTiers(guildID uint64) error {
 			sum += r.Tier
 		}
 		averageRank := sum / float64(len(rs))
+		recordHistory := tierChanged(currentTiers[leaderID], averageRank)
 
 		eg.Go(func() error {
 			err := sem.Acquire(egCtx, 1)
@@ -167,10 +216,116 @@ func (c *Ci6ndex) CalculateTiers(guildID uint64) error {
 				Tier: averageRank,
 				ID:   leaderID,
 			})
-			return err
+			if err != nil || !recordHistory {
+				return err
+			}
+			return db.Writes.AddTierHistory(egCtx, generated.AddTierHistoryParams{
+				LeaderID: leaderID,
+				Tier:     averageRank,
+			})
 		})
 	}
 
 	err = eg.Wait()
 	return err
 }
+
+// PlayerRank is a player's rank of a single leader alongside the community tier under the same ruleset.
+type PlayerRank struct {
+	Leader generated.Leader
+	Tier   float64
+	// Community is the leader's community tier, Unranked when it has none under the ruleset
+	Community float64
+	// Deviation is how far the player's rank is from the community tier. Negative means the player rates the
+	// leader better than the community does.
+	Deviation float64
+}
+
+// GetPlayerRanks returns every rank a player has submitted under the BBG or the vanilla ruleset, best tier first.
+func (c *Ci6ndex) GetPlayerRanks(guildID uint64, playerID int64, bbg bool) ([]PlayerRank, error) {
+	db, err := c.getDB(guildID)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	ranks, err := db.Queries.GetRanksForPlayer(ctx, playerID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, errors.Join(err, errors.New("failed to query ranks for player"))
+	}
+	leaders, err := c.GetLeaders(guildID)
+	if err != nil {
+		return nil, err
+	}
+	leadersByID := make(map[int64]generated.Leader, len(leaders))
+	for _, l := range leaders {
+		leadersByID[l.ID] = l
+	}
+	community, err := communityTiers(ctx, db, leaders, bbg)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]PlayerRank, 0, len(ranks))
+	for _, r := range ranks {
+		leader, ok := leadersByID[r.LeaderID]
+		if !ok || r.Bbg != bbg {
+			continue
+		}
+		pr := PlayerRank{Leader: leader, Tier: r.Tier}
+		if tier, ok := community[leader.ID]; ok {
+			pr.Community = tier
+			pr.Deviation = r.Tier - tier
+		}
+		result = append(result, pr)
+	}
+	slices.SortStableFunc(result, func(a, b PlayerRank) int {
+		return cmp.Compare(a.Tier, b.Tier)
+	})
+	return result, nil
+}
+
+// GetUnratedLeaders returns the alphabetized leaders a player has not submitted a rank for under the ruleset yet.
+// Leaders that only exist in BBG Expanded are never unrated under vanilla.
+func (c *Ci6ndex) GetUnratedLeaders(guildID uint64, playerID int64, bbg bool) ([]generated.Leader, error) {
+	ranks, err := c.GetPlayerRanks(guildID, playerID, bbg)
+	if err != nil {
+		return nil, err
+	}
+	rated := make(map[int64]bool, len(ranks))
+	for _, r := range ranks {
+		rated[r.Leader.ID] = true
+	}
+
+	leaders, err := c.GetLeaders(guildID)
+	if err != nil {
+		return nil, err
+	}
+	unrated := make([]generated.Leader, 0, len(leaders))
+	for _, l := range leaders {
+		if !rated[l.ID] && (bbg || !l.BbgExpanded) {
+			unrated = append(unrated, l)
+		}
+	}
+	return unrated, nil
+}
+
+// GetHotTakes returns up to limit of the player's ranks under the ruleset that deviate the most from its community
+// tier. Leaders without a community tier are skipped since there is nothing to disagree with.
+func (c *Ci6ndex) GetHotTakes(guildID uint64, playerID int64, bbg bool, limit int) ([]PlayerRank, error) {
+	ranks, err := c.GetPlayerRanks(guildID, playerID, bbg)
+	if err != nil {
+		return nil, err
+	}
+	takes := slices.DeleteFunc(ranks, func(r PlayerRank) bool {
+		return r.Community == Unranked.Value() || !tierChanged(r.Tier, r.Community)
+	})
+	slices.SortStableFunc(takes, func(a, b PlayerRank) int {
+		return cmp.Compare(math.Abs(b.Deviation), math.Abs(a.Deviation))
+	})
+	if len(takes) > limit {
+		takes = takes[:limit]
+	}
+	return takes, nil
+}
@@ -0,0 +1,85 @@
+package ci6ndex
+
+import (
+	"testing"
+)
+
+func TestPlayerRankViews(t *testing.T) {
+	const playerID int64 = 1001
+	// Teddy Roosevelt (Bull Moose) is seeded at 2.0, Teddy Roosevelt (Rough Rider) at 3.33
+	const bullMoose, roughRider int64 = 2, 3
+
+	if err := testC.SubmitRankForPlayer(testGuildID, "S", playerID, bullMoose, false); err != nil {
+		t.Fatalf("failed to submit rank: %v", err)
+	}
+	if err := testC.SubmitRankForPlayer(testGuildID, "F", playerID, roughRider, false); err != nil {
+		t.Fatalf("failed to submit rank: %v", err)
+	}
+	// the BBG rank mustn't show up next to the vanilla ones
+	if err := testC.SubmitRankForPlayer(testGuildID, "A", playerID, bullMoose, true); err != nil {
+		t.Fatalf("failed to submit rank: %v", err)
+	}
+
+	ranks, err := testC.GetPlayerRanks(testGuildID, playerID, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(ranks) != 2 {
+		t.Fatalf("expected 2 ranks, got %d", len(ranks))
+	}
+	if ranks[0].Leader.ID != bullMoose || ranks[1].Leader.ID != roughRider {
+		t.Fatalf("expected ranks sorted best tier first, got %d then %d", ranks[0].Leader.ID, ranks[1].Leader.ID)
+	}
+
+	leaders, err := testC.GetLeaders(testGuildID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	vanillaLeaders := 0
+	for _, l := range leaders {
+		if !l.BbgExpanded {
+			vanillaLeaders++
+		}
+	}
+	unrated, err := testC.GetUnratedLeaders(testGuildID, playerID, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(unrated) != vanillaLeaders-2 {
+		t.Fatalf("expected %d unrated leaders, got %d", vanillaLeaders-2, len(unrated))
+	}
+	for _, l := range unrated {
+		if l.ID == bullMoose || l.ID == roughRider {
+			t.Fatalf("rated leader %d listed as unrated", l.ID)
+		}
+		if l.BbgExpanded {
+			t.Fatalf("BBG Expanded leader %d listed as unrated under vanilla", l.ID)
+		}
+	}
+
+	bbgRanks, err := testC.GetPlayerRanks(testGuildID, playerID, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(bbgRanks) != 1 || bbgRanks[0].Tier != A.Value() || bbgRanks[0].Community != A.Value() {
+		t.Fatalf("expected only the BBG rank compared to the BBG ranks, got %+v", bbgRanks)
+	}
+	bbgUnrated, err := testC.GetUnratedLeaders(testGuildID, playerID, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(bbgUnrated) != len(leaders)-1 {
+		t.Fatalf("expected %d unrated leaders under BBG, got %d", len(leaders)-1, len(bbgUnrated))
+	}
+
+	takes, err := testC.GetHotTakes(testGuildID, playerID, false, 1)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(takes) != 1 || takes[0].Leader.ID != roughRider {
+		t.Fatalf("expected rough rider to be the hottest take, got %+v", takes)
+	}
+	if takes[0].Deviation <= 0 {
+		t.Fatalf("expected positive deviation for a worse than community rank, got %v", takes[0].Deviation)
+	}
+}
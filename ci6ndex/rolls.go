@@ -3,15 +3,25 @@ package ci6ndex
 import (
 	"ci6ndex/ci6ndex/generated"
 	"context"
+	"database/sql"
+	"errors"
 	"fmt"
 	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
 )
 
+// offeringSearchBudget caps the steps an offeringSearch takes before an offering is given up as not diverse enough.
+const offeringSearchBudget = 1000
+
 // Offering represents a set of leaders offered to a player in a draft.
 type Offering struct {
 	Player  generated.Player
 	Leaders []generated.Leader
 	DraftId int64
+	// Strength scores the offering's leaders by tier, see BalanceMode.Strength
+	Strength float64
 }
 
 // RollForPlayers rolls leaders for a set of players based on the provided rules.
@@ -20,34 +30,103 @@ func (c *Ci6ndex) RollForPlayers(
 	playerIds []int64,
 	rules []Rule,
 ) ([]Offering, error) {
-	ctx := context.TODO()
+	in, err := c.loadRollInput(guildId)
+	if err != nil {
+		return nil, err
+	}
+	offerings, err := rollOfferings(in, playerIds, rules)
+	if err != nil {
+		return nil, err
+	}
+	scoreOfferings(offerings, BalanceBySum)
+	return offerings, nil
+}
+
+// SaveOfferings replaces the offerings recorded for the draft the offerings were rolled for.
+func (c *Ci6ndex) SaveOfferings(guildId uint64, offerings []Offering) error {
+	if len(offerings) == 0 {
+		return nil
+	}
 	db, err := c.getDB(guildId)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get database for guild %d: %w", guildId, err)
+		return fmt.Errorf("failed to get database for guild %d: %w", guildId, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	draftID := offerings[0].DraftId
+	if err := db.Writes.DeletePoolsForDraftId(ctx, draftID); err != nil {
+		return fmt.Errorf("failed to clear offerings of draft %d: %w", draftID, err)
+	}
+	for _, o := range offerings {
+		for _, l := range o.Leaders {
+			err := db.Writes.AddPool(ctx, generated.AddPoolParams{PlayerID: o.Player.ID, DraftID: draftID, Leader: l.ID})
+			if err != nil {
+				return fmt.Errorf("failed to offer leader %d to player %d: %w", l.ID, o.Player.ID, err)
+			}
+		}
 	}
+	return nil
+}
+
+// rollInput is everything a roll is made from, loaded once so a roll can be repeated without hitting the database.
+type rollInput struct {
+	players []generated.Player
+	leaders []generated.Leader
+	draftID int64
+}
 
-	players, err := db.Queries.GetPlayersFromActiveDraft(ctx)
+func (c *Ci6ndex) loadRollInput(guildId uint64) (rollInput, error) {
+	ctx := context.TODO()
+	db, err := c.getDB(guildId)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get players: %w", err)
+		return rollInput{}, fmt.Errorf("failed to get database for guild %d: %w", guildId, err)
+	}
+
+	// every registered player, so a roll isn't limited to the players of the active draft
+	players, err := db.Queries.GetPlayers(ctx)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return rollInput{}, fmt.Errorf("failed to get players: %w", err)
 	}
 
 	allLeaders, err := db.Queries.GetEligibleLeaders(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get leaders: %w", err)
+		return rollInput{}, fmt.Errorf("failed to get leaders: %w", err)
 	}
 
 	draft, err := db.Queries.GetActiveDraft(ctx)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get active draft: %w", err)
+		return rollInput{}, fmt.Errorf("failed to get active draft: %w", err)
 	}
+	return rollInput{players: players, leaders: allLeaders, draftID: draft.ID}, nil
+}
 
+// rollOfferings rolls one offering per player from in. It doesn't modify in, so it can be called repeatedly.
+func rollOfferings(in rollInput, playerIds []int64, rules []Rule) ([]Offering, error) {
+	players, allLeaders := in.players, in.leaders
 	playerMap := make(map[int64]generated.Player, len(players))
 	for _, p := range players {
 		playerMap[p.ID] = p
 	}
 
+	// Separate rules by type.
+	var allRules, atLeastOneRules []Rule
+	var diverseRules []*DiversityRule
+	for _, rule := range rules {
+		switch rule.Type() {
+		case All:
+			allRules = append(allRules, rule)
+		case Diverse:
+			if d, ok := rule.(*DiversityRule); ok {
+				diverseRules = append(diverseRules, d)
+			}
+		default:
+			atLeastOneRules = append(atLeastOneRules, rule)
+		}
+	}
+
 	assigned := make(map[int64]struct{})
-	poolSize := len(rules)
+	// Diverse rules constrain the offering as a whole, so they don't get a slot of their own
+	poolSize := len(allRules) + len(atLeastOneRules)
 	offerings := make([]Offering, 0, len(playerIds))
 
 	for _, playerId := range playerIds {
@@ -56,16 +135,6 @@ func (c *Ci6ndex) RollForPlayers(
 			continue
 		}
 
-		// Separate rules by type.
-		var allRules, atLeastOneRules []Rule
-		for _, rule := range rules {
-			if rule.Type() == All {
-				allRules = append(allRules, rule)
-			} else {
-				atLeastOneRules = append(atLeastOneRules, rule)
-			}
-		}
-
 		// Start with leaders that satisfy all "All" rules.
 		valid := make([]generated.Leader, len(allLeaders))
 		copy(valid, allLeaders)
@@ -80,36 +149,9 @@ func (c *Ci6ndex) RollForPlayers(
 			return nil, RanOutOfChoicesError{}
 		}
 
-		var selected []generated.Leader
-
-		if len(atLeastOneRules) > 0 && len(valid) > 0 {
-			// Satisfy each "AtLeastOne" rule with a distinct leader.
-			for _, rule := range atLeastOneRules {
-				candidates := rule.Filter(player, valid)
-				if len(candidates) == 0 {
-					continue
-				}
-				picked := candidates[rand.IntN(len(candidates))]
-				selected = append(selected, picked)
-				valid = removeLeader(valid, picked.ID)
-			}
-
-			if len(selected) < len(atLeastOneRules) {
-				return nil, RanOutOfChoicesError{}
-			}
-
-			remaining := poolSize - len(selected)
-			if remaining > 0 && len(valid) > 0 {
-				if remaining > len(valid) {
-					remaining = len(valid)
-				}
-				selected = append(selected, pickN(valid, remaining)...)
-			}
-		} else {
-			if len(valid) < poolSize {
-				return nil, RanOutOfChoicesError{}
-			}
-			selected = pickN(valid, poolSize)
+		selected, err := pickOffering(player, valid, poolSize, atLeastOneRules, diverseRules)
+		if err != nil {
+			return nil, err
 		}
 
 		for _, l := range selected {
@@ -119,13 +161,71 @@ func (c *Ci6ndex) RollForPlayers(
 		offerings = append(offerings, Offering{
 			Player:  player,
 			Leaders: selected,
-			DraftId: draft.ID,
+			DraftId: in.draftID,
 		})
 	}
 
 	return offerings, nil
 }
 
+// pickOffering picks poolSize leaders for player from valid, satisfying each "AtLeastOne" rule with a distinct
+// leader and every diversity rule. The leaders are picked one slot at a time, searching the slots again when those
+// picks run into a dead end. valid is left untouched.
+func pickOffering(player generated.Player, valid []generated.Leader, poolSize int, atLeastOneRules []Rule,
+	diverseRules []*DiversityRule) ([]generated.Leader, error) {
+	original := valid
+	valid = slices.Clone(valid)
+	var selected []generated.Leader
+
+	if len(atLeastOneRules) > 0 && len(valid) > 0 {
+		// Satisfy each "AtLeastOne" rule with a distinct leader.
+		for _, rule := range atLeastOneRules {
+			candidates := rule.Filter(player, valid)
+			if len(candidates) == 0 {
+				continue
+			}
+			picked := pickDiverse(candidates, selected, diverseRules)
+			selected = append(selected, picked)
+			valid = removeLeader(valid, picked.ID)
+		}
+
+		if len(selected) < len(atLeastOneRules) {
+			return nil, RanOutOfChoicesError{}
+		}
+
+		remaining := poolSize - len(selected)
+		if remaining > 0 && len(valid) > 0 {
+			if remaining > len(valid) {
+				remaining = len(valid)
+			}
+			selected = fillOffering(valid, selected, remaining, diverseRules)
+		}
+	} else {
+		if len(valid) < poolSize {
+			return nil, RanOutOfChoicesError{}
+		}
+		selected = fillOffering(valid, selected, poolSize, diverseRules)
+	}
+
+	if diverseEnough(selected, diverseRules) {
+		return selected, nil
+	}
+	slots := slices.Clone(atLeastOneRules)
+	for len(slots) < len(selected) {
+		slots = append(slots, nil)
+	}
+	search := offeringSearch{player: player, slots: slots, diverseRules: diverseRules, budget: offeringSearchBudget}
+	if offering, ok := search.search(original, nil); ok {
+		return offering, nil
+	}
+	for _, rule := range diverseRules {
+		if !rule.IsSatisfied(selected) {
+			return nil, NotDiverseEnoughError{MinCategories: rule.MinCategories, Spanned: rule.Spanned(selected)}
+		}
+	}
+	return selected, nil
+}
+
 // filterAssigned returns leaders that have not been assigned yet.
 func filterAssigned(leaders []generated.Leader, assigned map[int64]struct{}) []generated.Leader {
 	filtered := leaders[:0]
@@ -148,6 +248,141 @@ func removeLeader(leaders []generated.Leader, id int64) []generated.Leader {
 	return filtered
 }
 
+// pickDiverse picks a random candidate, preferring the ones that add a category an unsatisfied DiversityRule is
+// still missing.
+func pickDiverse(candidates, selected []generated.Leader, diverseRules []*DiversityRule) generated.Leader {
+	for _, rule := range diverseRules {
+		if rule.IsSatisfied(selected) {
+			continue
+		}
+		spanned := rule.Spanned(selected)
+		widening := make([]generated.Leader, 0, len(candidates))
+		for _, c := range candidates {
+			if rule.Spanned(append(slices.Clone(selected), c)) > spanned {
+				widening = append(widening, c)
+			}
+		}
+		if len(widening) > 0 {
+			candidates = widening
+		}
+	}
+	return candidates[rand.IntN(len(candidates))]
+}
+
+// fillOffering adds n more leaders from valid to selected. Without diversity rules they are picked at random,
+// otherwise one at a time so each pick can widen the offering.
+func fillOffering(valid, selected []generated.Leader, n int, diverseRules []*DiversityRule) []generated.Leader {
+	if len(diverseRules) == 0 {
+		return append(selected, pickN(valid, n)...)
+	}
+	remaining := slices.Clone(valid)
+	for range min(n, len(remaining)) {
+		picked := pickDiverse(remaining, selected, diverseRules)
+		selected = append(selected, picked)
+		remaining = removeLeader(remaining, picked.ID)
+	}
+	return selected
+}
+
+// offeringSearch backtracks over the slots of an offering for leaders that satisfy every diversity rule. It is the
+// fallback for when pickOffering's one pick per slot runs into a dead end, e.g. by widening the offering with a
+// leader of one new category where one of two was needed.
+type offeringSearch struct {
+	player generated.Player
+	// slots is the rule each leader of the offering is picked for, nil slots take any leader
+	slots        []Rule
+	diverseRules []*DiversityRule
+	// budget is how many more steps the search may take, so hopeless rules fail fast
+	budget int
+}
+
+func (s *offeringSearch) search(valid, selected []generated.Leader) ([]generated.Leader, bool) {
+	if len(selected) == len(s.slots) {
+		return selected, diverseEnough(selected, s.diverseRules)
+	}
+	if s.budget == 0 || !diverseEnough(append(slices.Clone(selected), valid...), s.diverseRules) {
+		return nil, false
+	}
+	s.budget--
+
+	candidates := valid
+	if rule := s.slots[len(selected)]; rule != nil {
+		candidates = rule.Filter(s.player, valid)
+	}
+	// like pickDiverse, only try the leaders that widen the offering when there are any
+	var widening, others []generated.Leader
+	for _, i := range rand.Perm(len(candidates)) {
+		if s.widens(selected, candidates[i]) {
+			widening = append(widening, candidates[i])
+		} else {
+			others = append(others, candidates[i])
+		}
+	}
+	if len(widening) > 0 {
+		candidates = widening
+	} else {
+		candidates = others
+	}
+
+	// leaders with the same categories that fit the same slots are interchangeable, only one of them is tried
+	tried := make(map[string]bool)
+	for _, c := range candidates {
+		key := s.key(c, len(selected))
+		if tried[key] {
+			continue
+		}
+		tried[key] = true
+		rest := slices.DeleteFunc(slices.Clone(valid), func(l generated.Leader) bool { return l.ID == c.ID })
+		if offering, ok := s.search(rest, append(slices.Clone(selected), c)); ok {
+			return offering, true
+		}
+	}
+	return nil, false
+}
+
+// widens reports whether leader adds a category to selected that an unsatisfied diversity rule is missing.
+func (s *offeringSearch) widens(selected []generated.Leader, leader generated.Leader) bool {
+	widened := append(slices.Clone(selected), leader)
+	for _, rule := range s.diverseRules {
+		if !rule.IsSatisfied(selected) && rule.Spanned(widened) > rule.Spanned(selected) {
+			return true
+		}
+	}
+	return false
+}
+
+// key identifies leader by its categories and which of the slots after slot it fits.
+func (s *offeringSearch) key(leader generated.Leader, slot int) string {
+	var key strings.Builder
+	key.WriteString(categoryKey(leader, s.diverseRules))
+	for _, rule := range s.slots[slot+1:] {
+		if rule != nil && rule.IsValid(s.player, leader) {
+			key.WriteString(";1")
+		} else {
+			key.WriteString(";0")
+		}
+	}
+	return key.String()
+}
+
+// categoryKey identifies the categories a leader counts towards for every rule.
+func categoryKey(leader generated.Leader, diverseRules []*DiversityRule) string {
+	key := make([]string, len(diverseRules))
+	for i, rule := range diverseRules {
+		key[i] = strings.Join(slices.Sorted(slices.Values(rule.Categories(leader))), ",")
+	}
+	return strings.Join(key, ";")
+}
+
+func diverseEnough(leaders []generated.Leader, diverseRules []*DiversityRule) bool {
+	for _, rule := range diverseRules {
+		if !rule.IsSatisfied(leaders) {
+			return false
+		}
+	}
+	return true
+}
+
 // pickN returns n randomly chosen distinct leaders from the slice.
 func pickN(leaders []generated.Leader, n int) []generated.Leader {
 	if n >= len(leaders) {
@@ -168,3 +403,13 @@ type RanOutOfChoicesError struct{}
 func (e RanOutOfChoicesError) Error() string {
 	return "no leaders left to pick from"
 }
+
+// NotDiverseEnoughError is returned when the leaders left can't give an offering the variety a DiversityRule asks for.
+type NotDiverseEnoughError struct {
+	MinCategories int
+	Spanned       int
+}
+
+func (e NotDiverseEnoughError) Error() string {
+	return fmt.Sprintf("offering spans %d categories, at least %d are required", e.Spanned, e.MinCategories)
+}
@@ -222,3 +222,92 @@ func TestRollForPlayers_RanOutOfChoices(t *testing.T) {
 		t.Fatalf("expected RanOutOfChoicesError, got %T: %v", err, err)
 	}
 }
+
+func TestRollForPlayers_Diversity(t *testing.T) {
+	ctx := context.Background()
+	players, err := testDB.Queries.GetPlayersFromActiveDraft(ctx)
+	if err != nil {
+		t.Fatalf("failed to get players: %v", err)
+	}
+
+	playerIds := make([]int64, 4)
+	for i := range 4 {
+		playerIds[i] = players[i].ID
+	}
+
+	diversity := &DiversityRule{MinCategories: 4, Mode: DiversityByVictoryType}
+	rules := append(standardRules(), diversity)
+	offerings, err := testC.RollForPlayers(testGuildID, playerIds, rules)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, o := range offerings {
+		if len(o.Leaders) != 5 {
+			t.Fatalf("expected the diversity rule not to take a slot, player %d got %d leaders", o.Player.ID, len(o.Leaders))
+		}
+		if spanned := diversity.Spanned(o.Leaders); spanned < 4 {
+			t.Fatalf("player %d got %d victory types, expected at least 4", o.Player.ID, spanned)
+		}
+		hasLowTier := false
+		for _, l := range o.Leaders {
+			hasLowTier = hasLowTier || (!l.Unranked && l.Tier <= 3)
+		}
+		if !hasLowTier {
+			t.Fatalf("player %d has no leader with tier <= 3 alongside the diversity rule", o.Player.ID)
+		}
+	}
+}
+
+func TestRollForPlayers_NotDiverseEnough(t *testing.T) {
+	ctx := context.Background()
+	players, err := testDB.Queries.GetPlayersFromActiveDraft(ctx)
+	if err != nil {
+		t.Fatalf("failed to get players: %v", err)
+	}
+
+	// A single leader can't lean towards every victory type
+	rules := []Rule{
+		&NoOpRule{},
+		&DiversityRule{MinCategories: len(VictoryTypes), Mode: DiversityByVictoryType},
+	}
+	_, err = testC.RollForPlayers(testGuildID, []int64{players[0].ID}, rules)
+	if _, ok := err.(NotDiverseEnoughError); !ok {
+		t.Fatalf("expected NotDiverseEnoughError, got %T: %v", err, err)
+	}
+}
+
+func TestRollOfferings_DiversityDeadEnd(t *testing.T) {
+	// Hammurabi leans science, Pedro II culture and Tamar both diplomatic and religious. Picking Hammurabi and then
+	// Pedro II widens the offering every pick but only spans two victory types, only offerings with Tamar span three.
+	leaders := []generated.Leader{
+		{ID: 1, CivName: "BABYLON", LeaderName: "HAMMURABI", Tier: S.Value()},
+		{ID: 2, CivName: "BRAZIL", LeaderName: "PEDRO II", Tier: F.Value()},
+		{ID: 3, CivName: "GEORGIA", LeaderName: "TAMAR", Tier: F.Value()},
+	}
+	in := rollInput{players: []generated.Player{{ID: 1}}, leaders: leaders}
+	diversity := &DiversityRule{MinCategories: 3, Mode: DiversityByVictoryType}
+
+	for _, rules := range [][]Rule{
+		{&NoOpRule{}, &NoOpRule{}, diversity},
+		// the S slot can only be Hammurabi
+		{&MinTierRule{MinTier: S.Value()}, &NoOpRule{}, diversity},
+	} {
+		for range 50 {
+			offerings, err := rollOfferings(in, []int64{1}, rules)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			offered := offerings[0].Leaders
+			if spanned := diversity.Spanned(offered); len(offered) != 2 || spanned < 3 {
+				t.Fatalf("expected two leaders spanning three victory types, got %d spanning %d", len(offered), spanned)
+			}
+		}
+	}
+
+	_, err := rollOfferings(in, []int64{1}, []Rule{&NoOpRule{}, &NoOpRule{},
+		&DiversityRule{MinCategories: 5, Mode: DiversityByVictoryType}})
+	if _, ok := err.(NotDiverseEnoughError); !ok {
+		t.Fatalf("expected NotDiverseEnoughError, got %T: %v", err, err)
+	}
+}
@@ -0,0 +1,181 @@
+package ci6ndex
+
+import (
+	"ci6ndex/ci6ndex/generated"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+const (
+	MinPoolSize = 2
+	MaxPoolSize = 8
+)
+
+// RollSettings are the per-guild rules a draft is rolled with.
+type RollSettings struct {
+	// PoolSize is how many leaders every player is offered
+	PoolSize int
+	// MinTier guarantees one leader at or above this tier, 0 turns the guarantee off
+	MinTier float64
+	// DiversityMin is how many distinct categories every offering has to span, 0 turns the rule off
+	DiversityMin  int
+	DiversityMode DiversityMode
+	// BalanceMode evens out the strength of every player's pool, within BalanceTolerance
+	BalanceMode      BalanceMode
+	BalanceTolerance float64
+	// TeamTag guarantees every team of a team roll a leader with this play-style tag, "" turns the rule off
+	TeamTag string
+	// PlayerTag guarantees every offering a leader with this play-style tag, "" turns the rule off
+	PlayerTag string
+}
+
+// DefaultRollSettings matches the rules drafts were rolled with before they were configurable.
+var DefaultRollSettings = RollSettings{
+	PoolSize:         5,
+	MinTier:          B.Value(),
+	DiversityMin:     0,
+	DiversityMode:    DiversityByVictoryType,
+	BalanceMode:      BalanceOff,
+	BalanceTolerance: 2,
+}
+
+// RollSettingsValidationError explains why settings can't be saved, the reason is safe to show to users.
+type RollSettingsValidationError struct {
+	Reason string
+}
+
+func (e RollSettingsValidationError) Error() string {
+	return e.Reason
+}
+
+// MaxDiversity is the most categories an offering can be asked to span in the given mode.
+func MaxDiversity(mode DiversityMode) int {
+	if mode == DiversityByTag {
+		return len(PlayStyleTags)
+	}
+	return len(VictoryTypes)
+}
+
+func (s RollSettings) Validate() error {
+	if s.PoolSize < MinPoolSize || s.PoolSize > MaxPoolSize {
+		return RollSettingsValidationError{Reason: fmt.Sprintf("Pool size must be between %d and %d", MinPoolSize, MaxPoolSize)}
+	}
+	if s.MinTier != 0 && (s.MinTier < S.Value() || s.MinTier > F.Value()) {
+		return RollSettingsValidationError{Reason: "Minimum tier must be one of S, A, B, C or F"}
+	}
+	if s.DiversityMode != DiversityByVictoryType && s.DiversityMode != DiversityByTag {
+		return RollSettingsValidationError{Reason: fmt.Sprintf("Unknown diversity mode %q", s.DiversityMode)}
+	}
+	if s.DiversityMin < 0 || s.DiversityMin > MaxDiversity(s.DiversityMode) {
+		return RollSettingsValidationError{
+			Reason: fmt.Sprintf("Diversity must be between 0 and %d", MaxDiversity(s.DiversityMode)),
+		}
+	}
+	if s.BalanceMode != BalanceOff && s.BalanceMode != BalanceBySum && s.BalanceMode != BalanceByBest {
+		return RollSettingsValidationError{Reason: fmt.Sprintf("Unknown balance mode %q", s.BalanceMode)}
+	}
+	if s.BalanceTolerance < 0 || s.BalanceTolerance > MaxBalanceTolerance {
+		return RollSettingsValidationError{
+			Reason: fmt.Sprintf("Balance tolerance must be between 0 and %g", MaxBalanceTolerance),
+		}
+	}
+	if s.TeamTag != "" && !slices.Contains(PlayStyleTags, s.TeamTag) {
+		return RollSettingsValidationError{Reason: fmt.Sprintf("Unknown play-style tag %q", s.TeamTag)}
+	}
+	if s.PlayerTag != "" && !slices.Contains(PlayStyleTags, s.PlayerTag) {
+		return RollSettingsValidationError{Reason: fmt.Sprintf("Unknown play-style tag %q", s.PlayerTag)}
+	}
+	return nil
+}
+
+func (s RollSettings) Balance() Balance {
+	return Balance{Mode: s.BalanceMode, Tolerance: s.BalanceTolerance}
+}
+
+// Rules turns the settings into the rules RollForPlayers understands: one slot per leader in the pool, the first
+// guaranteeing the minimum tier and the next a PlayerTag leader, plus the diversity constraint over the whole
+// offering.
+func (s RollSettings) Rules() []Rule {
+	rules := make([]Rule, 0, s.PoolSize+1)
+	if s.MinTier > 0 {
+		rules = append(rules, &MinTierRule{MinTier: s.MinTier})
+	}
+	if s.PlayerTag != "" {
+		rules = append(rules, &TagRule{Tags: []string{s.PlayerTag}})
+	}
+	for len(rules) < s.PoolSize {
+		rules = append(rules, &NoOpRule{})
+	}
+	if s.DiversityMin > 0 {
+		rules = append(rules, &DiversityRule{MinCategories: s.DiversityMin, Mode: s.DiversityMode})
+	}
+	return rules
+}
+
+// TeamRules are the rules a team game is rolled with on top of Rules: teammates are never offered the same civ, every
+// team gets a TeamTag leader when set, and with balancing on the teams' total strength is evened out instead of every
+// player's.
+func (s RollSettings) TeamRules() []TeamRule {
+	rules := []TeamRule{&DistinctCivsRule{}}
+	if s.TeamTag != "" {
+		rules = append(rules, &TeamTagRule{Tags: []string{s.TeamTag}})
+	}
+	if s.BalanceMode != BalanceOff {
+		rules = append(rules, &TeamBalanceRule{Mode: s.BalanceMode, Tolerance: s.BalanceTolerance})
+	}
+	return rules
+}
+
+func (c *Ci6ndex) GetRollSettings(guildId uint64) (RollSettings, error) {
+	db, err := c.getDB(guildId)
+	if err != nil {
+		return RollSettings{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	row, err := db.Queries.GetRollSettings(ctx)
+	if err != nil {
+		return RollSettings{}, errors.Join(err, errors.New("failed to get roll settings"))
+	}
+	return RollSettings{
+		PoolSize:         int(row.PoolSize),
+		MinTier:          row.MinTier,
+		DiversityMin:     int(row.DiversityMin),
+		DiversityMode:    DiversityMode(row.DiversityMode),
+		BalanceMode:      BalanceMode(row.BalanceMode),
+		BalanceTolerance: row.BalanceTolerance,
+		TeamTag:          row.TeamTag,
+		PlayerTag:        row.PlayerTag,
+	}, nil
+}
+
+func (c *Ci6ndex) UpdateRollSettings(guildId uint64, settings RollSettings) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+	db, err := c.getDB(guildId)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	err = db.Writes.UpdateRollSettings(ctx, generated.UpdateRollSettingsParams{
+		PoolSize:         int64(settings.PoolSize),
+		MinTier:          settings.MinTier,
+		DiversityMin:     int64(settings.DiversityMin),
+		DiversityMode:    string(settings.DiversityMode),
+		BalanceMode:      string(settings.BalanceMode),
+		BalanceTolerance: settings.BalanceTolerance,
+		TeamTag:          settings.TeamTag,
+		PlayerTag:        settings.PlayerTag,
+	})
+	if err != nil {
+		return errors.Join(err, errors.New("failed to update roll settings"))
+	}
+	return nil
+}
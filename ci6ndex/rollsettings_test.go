@@ -0,0 +1,126 @@
+package ci6ndex
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestRollSettings_Validate(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings RollSettings
+		valid    bool
+	}{
+		{name: "default", settings: DefaultRollSettings, valid: true},
+		{name: "no min tier", settings: RollSettings{PoolSize: 3, DiversityMode: DiversityByTag, BalanceMode: BalanceOff}, valid: true},
+		{name: "every play style", settings: RollSettings{PoolSize: 8, DiversityMin: len(PlayStyleTags), DiversityMode: DiversityByTag, BalanceMode: BalanceOff}, valid: true},
+		{name: "balanced", settings: RollSettings{PoolSize: 5, DiversityMode: DiversityByTag, BalanceMode: BalanceByBest, BalanceTolerance: 1}, valid: true},
+		{name: "unknown balance", settings: RollSettings{PoolSize: 5, DiversityMode: DiversityByTag, BalanceMode: "fair"}},
+		{name: "negative tolerance", settings: RollSettings{PoolSize: 5, DiversityMode: DiversityByTag, BalanceMode: BalanceBySum, BalanceTolerance: -1}},
+		{name: "pool too small", settings: RollSettings{PoolSize: 1, DiversityMode: DiversityByVictoryType}},
+		{name: "pool too large", settings: RollSettings{PoolSize: 9, DiversityMode: DiversityByVictoryType}},
+		{name: "tier out of range", settings: RollSettings{PoolSize: 5, MinTier: 6, DiversityMode: DiversityByVictoryType}},
+		{name: "unknown mode", settings: RollSettings{PoolSize: 5, DiversityMode: "vibes"}},
+		{name: "too diverse", settings: RollSettings{PoolSize: 5, DiversityMin: len(VictoryTypes) + 1, DiversityMode: DiversityByVictoryType}},
+		{name: "team tag", settings: RollSettings{PoolSize: 5, DiversityMode: DiversityByTag, BalanceMode: BalanceOff, TeamTag: "naval"}, valid: true},
+		{name: "unknown team tag", settings: RollSettings{PoolSize: 5, DiversityMode: DiversityByTag, BalanceMode: BalanceOff, TeamTag: "vibes"}},
+		{name: "player tag", settings: RollSettings{PoolSize: 5, DiversityMode: DiversityByTag, BalanceMode: BalanceOff, PlayerTag: "science"}, valid: true},
+		{name: "unknown player tag", settings: RollSettings{PoolSize: 5, DiversityMode: DiversityByTag, BalanceMode: BalanceOff, PlayerTag: "vibes"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.settings.Validate()
+			if tt.valid && err != nil {
+				t.Fatalf("expected valid settings, got %v", err)
+			}
+			var validationErr RollSettingsValidationError
+			if !tt.valid && !errors.As(err, &validationErr) {
+				t.Fatalf("expected a RollSettingsValidationError, got %v", err)
+			}
+		})
+	}
+}
+
+func TestRollSettings_Rules(t *testing.T) {
+	rules := RollSettings{PoolSize: 4, MinTier: 2, DiversityMin: 3, DiversityMode: DiversityByTag}.Rules()
+	if len(rules) != 5 {
+		t.Fatalf("expected 4 slots and a diversity rule, got %d rules", len(rules))
+	}
+	if r, ok := rules[0].(*MinTierRule); !ok || r.MinTier != 2 {
+		t.Fatalf("expected the first rule to guarantee tier 2, got %#v", rules[0])
+	}
+	if r, ok := rules[4].(*DiversityRule); !ok || r.MinCategories != 3 || r.Mode != DiversityByTag {
+		t.Fatalf("expected the last rule to require 3 play styles, got %#v", rules[4])
+	}
+
+	rules = RollSettings{PoolSize: 3, MinTier: 2, PlayerTag: "naval", DiversityMode: DiversityByTag}.Rules()
+	if len(rules) != 3 {
+		t.Fatalf("expected 3 slots, got %d rules", len(rules))
+	}
+	if r, ok := rules[1].(*TagRule); !ok || len(r.Tags) != 1 || r.Tags[0] != "naval" {
+		t.Fatalf("expected the second rule to guarantee a naval leader, got %#v", rules[1])
+	}
+
+	rules = RollSettings{PoolSize: 3, DiversityMode: DiversityByVictoryType}.Rules()
+	for _, r := range rules {
+		if _, ok := r.(*NoOpRule); !ok {
+			t.Fatalf("expected only open slots without a min tier or diversity, got %#v", r)
+		}
+	}
+}
+
+func TestRollSettings_TeamRules(t *testing.T) {
+	rules := RollSettings{BalanceMode: BalanceOff}.TeamRules()
+	if len(rules) != 1 {
+		t.Fatalf("expected only distinct civs without a team tag or balancing, got %#v", rules)
+	}
+
+	rules = RollSettings{BalanceMode: BalanceBySum, BalanceTolerance: 2, TeamTag: "naval"}.TeamRules()
+	if len(rules) != 3 {
+		t.Fatalf("expected distinct civs, a team tag and balancing, got %#v", rules)
+	}
+	if r, ok := rules[1].(*TeamTagRule); !ok || len(r.Tags) != 1 || r.Tags[0] != "naval" {
+		t.Fatalf("expected every team to be offered a naval leader, got %#v", rules[1])
+	}
+}
+
+func TestRollSettings_Update(t *testing.T) {
+	settings, err := testC.GetRollSettings(testGuildID)
+	if err != nil {
+		t.Fatalf("failed to get roll settings: %v", err)
+	}
+	if settings != DefaultRollSettings {
+		t.Fatalf("expected default roll settings, got %+v", settings)
+	}
+	t.Cleanup(func() {
+		if err := testC.UpdateRollSettings(testGuildID, DefaultRollSettings); err != nil {
+			t.Fatalf("failed to restore roll settings: %v", err)
+		}
+	})
+
+	want := RollSettings{
+		PoolSize:         6,
+		MinTier:          2,
+		DiversityMin:     3,
+		DiversityMode:    DiversityByTag,
+		BalanceMode:      BalanceBySum,
+		BalanceTolerance: 1.5,
+		TeamTag:          "early-war",
+		PlayerTag:        "science",
+	}
+	if err := testC.UpdateRollSettings(testGuildID, want); err != nil {
+		t.Fatalf("failed to update roll settings: %v", err)
+	}
+	got, err := testC.GetRollSettings(testGuildID)
+	if err != nil {
+		t.Fatalf("failed to get roll settings: %v", err)
+	}
+	if got != want {
+		t.Fatalf("expected %+v, got %+v", want, got)
+	}
+
+	if err := testC.UpdateRollSettings(testGuildID, RollSettings{PoolSize: 0}); err == nil {
+		t.Fatal("expected invalid settings to be rejected")
+	}
+}
@@ -2,6 +2,7 @@ package ci6ndex
 
 import (
 	"ci6ndex/ci6ndex/generated"
+	"slices"
 )
 
 type RuleType string
@@ -9,6 +10,8 @@ type RuleType string
 const (
 	All        RuleType = "all"
 	AtLeastOne RuleType = "atLeastOne"
+	// Diverse rules constrain a player's whole offering instead of single leaders, and don't take up a slot in it
+	Diverse RuleType = "diverse"
 )
 
 // Rule is a method of filtering leaders based on player metadata
@@ -55,3 +58,111 @@ func (r *NoOpRule) Filter(player generated.Player, leaders []generated.Leader) [
 func (r *NoOpRule) Type() RuleType {
 	return AtLeastOne
 }
+
+// TagRule guarantees at least one leader whose play style matches any of Tags, e.g. "naval" or "science".
+type TagRule struct {
+	Tags []string
+}
+
+func (r *TagRule) IsValid(player generated.Player, leader generated.Leader) bool {
+	metadata, ok := MetadataForLeader(leader)
+	return ok && metadata.HasTag(r.Tags...)
+}
+
+func (r *TagRule) Filter(player generated.Player, leaders []generated.Leader) []generated.Leader {
+	filtered := make([]generated.Leader, 0)
+	for _, leader := range leaders {
+		if r.IsValid(player, leader) {
+			filtered = append(filtered, leader)
+		}
+	}
+	return filtered
+}
+
+func (r *TagRule) Type() RuleType {
+	return AtLeastOne
+}
+
+// DiversityMode is what a DiversityRule counts as a distinct category.
+type DiversityMode string
+
+const (
+	// DiversityByVictoryType groups play-style tags into the victory types they lean towards
+	DiversityByVictoryType DiversityMode = "victory"
+	// DiversityByTag counts every play-style tag as its own category
+	DiversityByTag DiversityMode = "tag"
+)
+
+// VictoryTypes are the categories of DiversityByVictoryType.
+var VictoryTypes = []string{"science", "culture", "religious", "diplomatic", "domination"}
+
+// victoryTypeForTag maps the play-style tags that lean towards a victory type onto it. Other tags, e.g. "gold", don't
+// count towards any victory type.
+var victoryTypeForTag = map[string]string{
+	"science":   "science",
+	"culture":   "culture",
+	"religion":  "religious",
+	"diplomacy": "diplomatic",
+	"early-war": "domination",
+	"late-war":  "domination",
+}
+
+// DiversityRule guarantees a player's offering spans at least MinCategories distinct victory types or play-style
+// tags, so nobody is offered five leaders that all want the same victory.
+type DiversityRule struct {
+	MinCategories int
+	Mode          DiversityMode
+}
+
+// Categories returns the distinct categories leader counts towards.
+func (r *DiversityRule) Categories(leader generated.Leader) []string {
+	metadata, ok := MetadataForLeader(leader)
+	if !ok {
+		return nil
+	}
+	if r.Mode == DiversityByTag {
+		return metadata.Tags
+	}
+	categories := make([]string, 0, len(metadata.Tags))
+	for _, t := range metadata.Tags {
+		if v, ok := victoryTypeForTag[t]; ok && !slices.Contains(categories, v) {
+			categories = append(categories, v)
+		}
+	}
+	return categories
+}
+
+// Spanned counts the distinct categories covered by leaders.
+func (r *DiversityRule) Spanned(leaders []generated.Leader) int {
+	seen := make(map[string]struct{})
+	for _, l := range leaders {
+		for _, c := range r.Categories(l) {
+			seen[c] = struct{}{}
+		}
+	}
+	return len(seen)
+}
+
+// IsSatisfied reports whether an offering spans enough categories.
+func (r *DiversityRule) IsSatisfied(leaders []generated.Leader) bool {
+	return r.Spanned(leaders) >= r.MinCategories
+}
+
+// IsValid reports whether the leader counts towards any category at all.
+func (r *DiversityRule) IsValid(player generated.Player, leader generated.Leader) bool {
+	return len(r.Categories(leader)) > 0
+}
+
+func (r *DiversityRule) Filter(player generated.Player, leaders []generated.Leader) []generated.Leader {
+	filtered := make([]generated.Leader, 0)
+	for _, leader := range leaders {
+		if r.IsValid(player, leader) {
+			filtered = append(filtered, leader)
+		}
+	}
+	return filtered
+}
+
+func (r *DiversityRule) Type() RuleType {
+	return Diverse
+}
@@ -0,0 +1,217 @@
+package ci6ndex
+
+import (
+	"ci6ndex/ci6ndex/generated"
+	"cmp"
+	"slices"
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/runes"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+// SearchField is the part of a leader a search query is matched against.
+type SearchField int
+
+const (
+	// SearchLeaderName matches the leader name and friendly name, e.g. "BULLMOOSE TEDDY" and "Teddy Roosevelt"
+	SearchLeaderName SearchField = iota
+	// SearchCivName matches the civ name, e.g. "AMERICA"
+	SearchCivName
+	// SearchKeyword matches the leader's metadata: ability names, uniques and play-style tags, e.g. "naval" or "Hoplite"
+	SearchKeyword
+)
+
+const (
+	scoreExact     = 100.0
+	scorePrefix    = 90.0
+	scoreSubstring = 80.0
+	// token matches score between scoreTokens and scoreTokens+20 depending on how close each token is
+	scoreTokens = 50.0
+)
+
+// LeaderMatch is a leader that matched a search query. Higher scores are better matches.
+type LeaderMatch struct {
+	Leader generated.Leader
+	Score  float64
+}
+
+// SearchLeaders returns the leaders that fuzzily match query on the given field, best match first. Matching ignores
+// case, diacritics and punctuation, and tolerates a small number of typos per word.
+func SearchLeaders(leaders []generated.Leader, query string, field SearchField) []LeaderMatch {
+	q := normalizeSearchText(query)
+	if q == "" {
+		return make([]LeaderMatch, 0)
+	}
+
+	matches := make([]LeaderMatch, 0)
+	for _, l := range leaders {
+		var candidates []string
+		switch field {
+		case SearchCivName:
+			candidates = []string{l.CivName}
+		case SearchKeyword:
+			candidates = metadataKeywords(l)
+		default:
+			candidates = []string{l.LeaderName}
+			if l.FriendlyName.Valid {
+				candidates = append(candidates, l.FriendlyName.String)
+			}
+		}
+
+		best := 0.0
+		for _, c := range candidates {
+			best = max(best, matchScore(q, normalizeSearchText(c)))
+		}
+		if best > 0 {
+			matches = append(matches, LeaderMatch{Leader: l, Score: best})
+		}
+	}
+
+	slices.SortStableFunc(matches, func(a, b LeaderMatch) int {
+		if c := cmp.Compare(b.Score, a.Score); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Leader.CivName, b.Leader.CivName); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Leader.LeaderName, b.Leader.LeaderName)
+	})
+	return matches
+}
+
+// metadataKeywords are the searchable names in a leader's metadata.
+func metadataKeywords(l generated.Leader) []string {
+	metadata, ok := MetadataForLeader(l)
+	if !ok {
+		return nil
+	}
+	keywords := slices.Clone(metadata.Tags)
+	for _, a := range []Ability{metadata.CivAbility, metadata.LeaderAbility} {
+		if a.Name != "" {
+			keywords = append(keywords, a.Name)
+		}
+	}
+	for _, u := range metadata.Uniques {
+		keywords = append(keywords, u.Name)
+	}
+	return keywords
+}
+
+// normalizeSearchText lower cases s, strips diacritics and replaces punctuation with spaces so "Te' K'inich II" and
+// "te kinich ii" compare equal.
+func normalizeSearchText(s string) string {
+	t := transform.Chain(norm.NFD, runes.Remove(runes.In(unicode.Mn)), norm.NFC)
+	folded, _, err := transform.String(t, s)
+	if err != nil {
+		folded = s
+	}
+	folded = strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			return unicode.ToLower(r)
+		case r == '\'' || r == '’':
+			return -1
+		default:
+			return ' '
+		}
+	}, folded)
+	return strings.Join(strings.Fields(folded), " ")
+}
+
+// matchScore scores an already normalized query against an already normalized candidate, 0 means no match.
+func matchScore(query, candidate string) float64 {
+	switch {
+	case query == candidate:
+		return scoreExact
+	case strings.HasPrefix(candidate, query):
+		return scorePrefix
+	case strings.Contains(candidate, query):
+		return scoreSubstring
+	}
+
+	// Every word of the query has to match some word of the candidate
+	candidateTokens := strings.Fields(candidate)
+	total := 0.0
+	queryTokens := strings.Fields(query)
+	for _, qt := range queryTokens {
+		best := 0.0
+		for _, ct := range candidateTokens {
+			best = max(best, tokenScore(qt, ct))
+		}
+		if best == 0 {
+			return 0
+		}
+		total += best
+	}
+	return scoreTokens + 20*total/float64(len(queryTokens))
+}
+
+// tokenScore scores a single query word against a single candidate word from 0 to 1.
+func tokenScore(query, candidate string) float64 {
+	if query == candidate {
+		return 1
+	}
+	if len(query) >= 2 && strings.HasPrefix(candidate, query) {
+		return 0.9
+	}
+	allowed := allowedTypos(query)
+	if allowed == 0 {
+		return 0
+	}
+	// Compare against the candidate cut to the query length as well, so typos in a prefix still match
+	d := editDistance(query, candidate)
+	if r := []rune(candidate); len(r) > len([]rune(query)) {
+		d = min(d, editDistance(query, string(r[:len([]rune(query))])))
+	}
+	if d > allowed {
+		return 0
+	}
+	return 0.8 - 0.1*float64(d)
+}
+
+// allowedTypos is how many edits a query word may be away from a match. Short words must match exactly, otherwise
+// almost everything would match.
+func allowedTypos(word string) int {
+	switch n := len([]rune(word)); {
+	case n <= 3:
+		return 0
+	case n <= 6:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// editDistance is the optimal string alignment distance between a and b: the number of insertions, deletions,
+// substitutions and adjacent transpositions to turn a into b.
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	rows := make([][]int, len(ra)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(rb)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			rows[i][j] = min(
+				rows[i-1][j]+1,
+				rows[i][j-1]+1,
+				rows[i-1][j-1]+cost,
+			)
+			if i > 1 && j > 1 && ra[i-1] == rb[j-2] && ra[i-2] == rb[j-1] {
+				rows[i][j] = min(rows[i][j], rows[i-2][j-2]+1)
+			}
+		}
+	}
+	return rows[len(ra)][len(rb)]
+}
@@ -0,0 +1,85 @@
+package ci6ndex
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestSearchLeaders(t *testing.T) {
+	leaders, err := testC.GetLeaders(testGuildID)
+	if err != nil {
+		t.Fatalf("failed to get leaders: %v", err)
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		field SearchField
+		// leader IDs that must be returned, the first one must be the best match
+		want []int64
+	}{
+		{name: "friendly nickname", query: "Teddy", field: SearchLeaderName, want: []int64{2, 3}},
+		{name: "exact friendly name", query: "Gorgo", field: SearchLeaderName, want: []int64{36}},
+		{name: "case insensitive", query: "gORGo", field: SearchLeaderName, want: []int64{36}},
+		{name: "typo", query: "Gorgp", field: SearchLeaderName, want: []int64{36}},
+		{name: "transposition", query: "Montzeuma", field: SearchLeaderName, want: []int64{7}},
+		{name: "diacritics in leader", query: "ba trieu", field: SearchLeaderName, want: []int64{76}},
+		{name: "diacritics in query", query: "Gändhi", field: SearchLeaderName, want: []int64{41}},
+		{name: "cedilla", query: "suleiman muhtesem", field: SearchLeaderName, want: []int64{61}},
+		{name: "punctuation", query: "te kinich", field: SearchLeaderName, want: []int64{80}},
+		{name: "civ typo", query: "amerca", field: SearchCivName, want: []int64{1, 2, 3}},
+		{name: "civ diacritics", query: "taino", field: SearchCivName, want: []int64{86}},
+		{name: "civ prefix", query: "byz", field: SearchCivName, want: []int64{10, 11}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matches := SearchLeaders(leaders, tt.query, tt.field)
+			if len(matches) == 0 {
+				t.Fatalf("expected matches for %q, got none", tt.query)
+			}
+			ids := make([]int64, len(matches))
+			for i, m := range matches {
+				ids[i] = m.Leader.ID
+			}
+			for _, id := range tt.want {
+				if !slices.Contains(ids, id) {
+					t.Fatalf("expected leader %d in results for %q, got %v", id, tt.query, ids)
+				}
+			}
+			if !slices.Contains(tt.want, ids[0]) {
+				t.Fatalf("expected best match for %q to be one of %v, got %d", tt.query, tt.want, ids[0])
+			}
+		})
+	}
+}
+
+func TestSearchLeaders_NoMatch(t *testing.T) {
+	leaders, err := testC.GetLeaders(testGuildID)
+	if err != nil {
+		t.Fatalf("failed to get leaders: %v", err)
+	}
+	for _, query := range []string{"", "   ", "xyzzy", "zzz"} {
+		if matches := SearchLeaders(leaders, query, SearchLeaderName); len(matches) != 0 {
+			t.Fatalf("expected no matches for %q, got %d", query, len(matches))
+		}
+	}
+}
+
+func TestEditDistance(t *testing.T) {
+	tests := []struct {
+		a, b string
+		want int
+	}{
+		{"gorgo", "gorgo", 0},
+		{"gorgo", "gorgp", 1},
+		{"montezuma", "montzeuma", 1},
+		{"kitten", "sitting", 3},
+		{"", "abc", 3},
+	}
+	for _, tt := range tests {
+		if got := editDistance(tt.a, tt.b); got != tt.want {
+			t.Fatalf("editDistance(%q, %q) = %d, want %d", tt.a, tt.b, got, tt.want)
+		}
+	}
+}
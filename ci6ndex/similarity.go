@@ -0,0 +1,226 @@
+package ci6ndex
+
+import (
+	"ci6ndex/ci6ndex/generated"
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"math"
+	"slices"
+	"time"
+)
+
+// minCommonRanks is how many leaders two players must both have rated before their taste is compared.
+// Correlations over fewer points are mostly noise.
+const minCommonRanks = 3
+
+// Similarity describes how alike two players rate leaders.
+type Similarity struct {
+	PlayerA int64
+	PlayerB int64
+	// Score is the Spearman rank correlation of the two players' tiers over Common leaders, from -1 (opposite
+	// taste) to 1 (identical taste). Players that rated every common leader exactly the same score 1.
+	Score  float64
+	Common int
+	// Flat is true when the players' tiers differ but one of them rated every common leader the same, leaving no
+	// order to correlate. Score is 0 and meaningless then.
+	Flat bool
+	// Disagreements are the commonly rated leaders, biggest difference first.
+	Disagreements []Disagreement
+}
+
+// Disagreement is a leader rated by both players of a Similarity.
+type Disagreement struct {
+	Leader generated.Leader
+	TierA  float64
+	TierB  float64
+}
+
+// Difference is how many tiers apart the two players are on the leader.
+func (d Disagreement) Difference() float64 {
+	return math.Abs(d.TierA - d.TierB)
+}
+
+// Agreement maps Score to a 0-100 percentage which is easier to read at a glance.
+func (s Similarity) Agreement() float64 {
+	return (s.Score + 1) / 2 * 100
+}
+
+// HasScore reports whether enough leaders were rated by both players for Score to be meaningful. Flat similarities
+// still have no meaningful score.
+func (s Similarity) HasScore() bool {
+	return s.Common >= minCommonRanks
+}
+
+// PlayerSimilarity compares every pair of players in the guild that have rated at least minCommonRanks of the
+// same leaders under the ruleset with enough variation to have a score, most similar pair first.
+func (c *Ci6ndex) PlayerSimilarity(guildID uint64, bbg bool) ([]Similarity, error) {
+	ranksByPlayer, leadersByID, err := c.ranksByPlayer(guildID, bbg)
+	if err != nil {
+		return nil, err
+	}
+
+	playerIDs := make([]int64, 0, len(ranksByPlayer))
+	for id := range ranksByPlayer {
+		playerIDs = append(playerIDs, id)
+	}
+	slices.Sort(playerIDs)
+
+	result := make([]Similarity, 0)
+	for i, a := range playerIDs {
+		for _, b := range playerIDs[i+1:] {
+			s := compareRanks(a, b, ranksByPlayer[a], ranksByPlayer[b], leadersByID)
+			if !s.HasScore() || s.Flat {
+				continue
+			}
+			result = append(result, s)
+		}
+	}
+	slices.SortStableFunc(result, func(x, y Similarity) int {
+		return cmp.Compare(y.Score, x.Score)
+	})
+	return result, nil
+}
+
+// ComparePlayers compares two players' ranks under the BBG or the vanilla ruleset. Unlike PlayerSimilarity, the
+// result is returned even when the players have fewer than minCommonRanks leaders in common so callers can explain
+// why there is no score.
+func (c *Ci6ndex) ComparePlayers(guildID uint64, playerA, playerB int64, bbg bool) (Similarity, error) {
+	ranksByPlayer, leadersByID, err := c.ranksByPlayer(guildID, bbg)
+	if err != nil {
+		return Similarity{}, err
+	}
+	return compareRanks(playerA, playerB, ranksByPlayer[playerA], ranksByPlayer[playerB], leadersByID), nil
+}
+
+// ranksByPlayer maps every player to their ranks under a single ruleset, so a player's BBG and vanilla ranks of a
+// leader never overwrite each other.
+func (c *Ci6ndex) ranksByPlayer(guildID uint64,
+	bbg bool) (map[int64]map[int64]float64, map[int64]generated.Leader, error) {
+	db, err := c.getDB(guildID)
+	if err != nil {
+		return nil, nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ranks, err := db.Queries.GetRanksForRuleset(ctx, bbg)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, errors.Join(err, errors.New("failed to query ranks"))
+	}
+	leaders, err := c.GetLeaders(guildID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	leadersByID := make(map[int64]generated.Leader, len(leaders))
+	for _, l := range leaders {
+		leadersByID[l.ID] = l
+	}
+	ranksByPlayer := make(map[int64]map[int64]float64)
+	for _, r := range ranks {
+		if ranksByPlayer[r.PlayerID] == nil {
+			ranksByPlayer[r.PlayerID] = make(map[int64]float64)
+		}
+		ranksByPlayer[r.PlayerID][r.LeaderID] = r.Tier
+	}
+	return ranksByPlayer, leadersByID, nil
+}
+
+func compareRanks(playerA, playerB int64, ranksA, ranksB map[int64]float64,
+	leadersByID map[int64]generated.Leader) Similarity {
+	s := Similarity{PlayerA: playerA, PlayerB: playerB}
+
+	var xs, ys []float64
+	for leaderID, tierA := range ranksA {
+		tierB, ok := ranksB[leaderID]
+		if !ok {
+			continue
+		}
+		leader, ok := leadersByID[leaderID]
+		if !ok {
+			continue
+		}
+		xs = append(xs, tierA)
+		ys = append(ys, tierB)
+		s.Disagreements = append(s.Disagreements, Disagreement{Leader: leader, TierA: tierA, TierB: tierB})
+	}
+	s.Common = len(xs)
+	switch {
+	case slices.Equal(xs, ys):
+		s.Score = 1
+	case !hasVariance(xs) || !hasVariance(ys):
+		s.Flat = true
+	default:
+		s.Score = spearman(xs, ys)
+	}
+
+	slices.SortStableFunc(s.Disagreements, func(x, y Disagreement) int {
+		if c := cmp.Compare(y.Difference(), x.Difference()); c != 0 {
+			return c
+		}
+		return cmp.Compare(x.Leader.ID, y.Leader.ID)
+	})
+	return s
+}
+
+// spearman computes the Spearman rank correlation of xs and ys, averaging the ranks of ties. Returns 0 if either
+// series has no variance, since correlation is undefined there.
+func spearman(xs, ys []float64) float64 {
+	if len(xs) != len(ys) || len(xs) < 2 {
+		return 0
+	}
+	return pearson(fractionalRanks(xs), fractionalRanks(ys))
+}
+
+func hasVariance(values []float64) bool {
+	return slices.ContainsFunc(values, func(v float64) bool { return v != values[0] })
+}
+
+// fractionalRanks replaces each value with its 1-based rank, giving tied values the average of their ranks.
+func fractionalRanks(values []float64) []float64 {
+	idx := make([]int, len(values))
+	for i := range idx {
+		idx[i] = i
+	}
+	slices.SortStableFunc(idx, func(a, b int) int {
+		return cmp.Compare(values[a], values[b])
+	})
+
+	ranks := make([]float64, len(values))
+	for start := 0; start < len(idx); {
+		end := start
+		for end+1 < len(idx) && values[idx[end+1]] == values[idx[start]] {
+			end++
+		}
+		avg := float64(start+end)/2 + 1
+		for k := start; k <= end; k++ {
+			ranks[idx[k]] = avg
+		}
+		start = end + 1
+	}
+	return ranks
+}
+
+func pearson(xs, ys []float64) float64 {
+	n := float64(len(xs))
+	var sumX, sumY float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+	}
+	meanX, meanY := sumX/n, sumY/n
+
+	var cov, varX, varY float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		cov += dx * dy
+		varX += dx * dx
+		varY += dy * dy
+	}
+	if varX == 0 || varY == 0 {
+		return 0
+	}
+	return cov / math.Sqrt(varX*varY)
+}
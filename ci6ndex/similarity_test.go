@@ -0,0 +1,137 @@
+package ci6ndex
+
+import (
+	"ci6ndex/ci6ndex/generated"
+	"math"
+	"testing"
+)
+
+func TestSpearman(t *testing.T) {
+	tests := []struct {
+		name string
+		xs   []float64
+		ys   []float64
+		want float64
+	}{
+		{name: "identical", xs: []float64{1, 2, 3, 4, 5}, ys: []float64{1, 2, 3, 4, 5}, want: 1},
+		{name: "opposite", xs: []float64{1, 2, 3, 4, 5}, ys: []float64{5, 4, 3, 2, 1}, want: -1},
+		{name: "monotonic but not linear", xs: []float64{1, 2, 3}, ys: []float64{1, 4, 5}, want: 1},
+		// ranks of ys with ties are 1.5, 1.5, 3 → rho = sqrt(3)/2
+		{name: "ties", xs: []float64{1, 2, 3}, ys: []float64{2, 2, 5}, want: math.Sqrt(3) / 2},
+		{name: "no variance", xs: []float64{1, 2, 3}, ys: []float64{3, 3, 3}, want: 0},
+		{name: "too few", xs: []float64{1}, ys: []float64{1}, want: 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := spearman(tt.xs, tt.ys)
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Fatalf("expected %v, got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestComparePlayers(t *testing.T) {
+	const playerA, playerB int64 = 1010, 1011
+	ranks := []struct {
+		leader int64
+		a, b   string
+	}{
+		{leader: 20, a: "S", b: "S"},
+		{leader: 21, a: "A", b: "B"},
+		{leader: 22, a: "C", b: "C"},
+		{leader: 23, a: "F", b: "A"},
+	}
+	for _, r := range ranks {
+		if err := testC.SubmitRankForPlayer(testGuildID, r.a, playerA, r.leader, false); err != nil {
+			t.Fatalf("failed to submit rank: %v", err)
+		}
+		if err := testC.SubmitRankForPlayer(testGuildID, r.b, playerB, r.leader, false); err != nil {
+			t.Fatalf("failed to submit rank: %v", err)
+		}
+	}
+
+	// BBG ranks in the opposite order must not leak into the vanilla comparison
+	bbgRanks := []struct {
+		leader int64
+		a, b   string
+	}{
+		{leader: 20, a: "S", b: "C"},
+		{leader: 21, a: "A", b: "B"},
+		{leader: 22, a: "B", b: "A"},
+		{leader: 23, a: "C", b: "S"},
+	}
+	for _, r := range bbgRanks {
+		if err := testC.SubmitRankForPlayer(testGuildID, r.a, playerA, r.leader, true); err != nil {
+			t.Fatalf("failed to submit rank: %v", err)
+		}
+		if err := testC.SubmitRankForPlayer(testGuildID, r.b, playerB, r.leader, true); err != nil {
+			t.Fatalf("failed to submit rank: %v", err)
+		}
+	}
+
+	s, err := testC.ComparePlayers(testGuildID, playerA, playerB, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if s.Common != len(ranks) || !s.HasScore() {
+		t.Fatalf("expected %d common leaders, got %d", len(ranks), s.Common)
+	}
+	if s.Score <= -1 || s.Score >= 1 {
+		t.Fatalf("expected partial agreement, got %v", s.Score)
+	}
+	if s.Disagreements[0].Leader.ID != 23 {
+		t.Fatalf("expected biggest disagreement on leader 23, got %d", s.Disagreements[0].Leader.ID)
+	}
+
+	bbg, err := testC.ComparePlayers(testGuildID, playerA, playerB, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if bbg.Common != len(bbgRanks) || bbg.Score != -1 {
+		t.Fatalf("expected BBG ranks to disagree completely over %d leaders, got %+v", len(bbgRanks), bbg)
+	}
+
+	all, err := testC.PlayerSimilarity(testGuildID, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	found := false
+	for _, pair := range all {
+		if pair.PlayerA == playerA && pair.PlayerB == playerB {
+			found = true
+			if pair.Score != s.Score {
+				t.Fatalf("expected score %v, got %v", s.Score, pair.Score)
+			}
+		}
+	}
+	if !found {
+		t.Fatalf("expected pair (%d, %d) in guild similarity", playerA, playerB)
+	}
+}
+
+func TestCompareRanks_NoVariance(t *testing.T) {
+	leadersByID := map[int64]generated.Leader{1: {ID: 1}, 2: {ID: 2}, 3: {ID: 3}}
+	tests := []struct {
+		name      string
+		ranksA    map[int64]float64
+		ranksB    map[int64]float64
+		wantScore float64
+		wantFlat  bool
+	}{
+		{name: "same tier for everything", ranksA: map[int64]float64{1: 2, 2: 2, 3: 2},
+			ranksB: map[int64]float64{1: 2, 2: 2, 3: 2}, wantScore: 1},
+		{name: "different flat tiers", ranksA: map[int64]float64{1: 2, 2: 2, 3: 2},
+			ranksB: map[int64]float64{1: 3, 2: 3, 3: 3}, wantFlat: true},
+		{name: "one player flat", ranksA: map[int64]float64{1: 1, 2: 2, 3: 3},
+			ranksB: map[int64]float64{1: 3, 2: 3, 3: 3}, wantFlat: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := compareRanks(1, 2, tt.ranksA, tt.ranksB, leadersByID)
+			if s.Common != 3 || s.Score != tt.wantScore || s.Flat != tt.wantFlat {
+				t.Fatalf("expected score %v and flat %v over 3 leaders, got %+v", tt.wantScore, tt.wantFlat, s)
+			}
+		})
+	}
+}
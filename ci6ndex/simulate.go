@@ -0,0 +1,172 @@
+package ci6ndex
+
+import (
+	"ci6ndex/ci6ndex/generated"
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+)
+
+// feasibilityRolls is how many rolls every player count is sampled with when looking for Simulation.MaxPlayers
+const feasibilityRolls = 200
+
+// SimulationOptions is what SimulateRolls rolls for.
+type SimulationOptions struct {
+	// Players is how many players every roll is for
+	Players int
+	// Rolls is how many times to roll
+	Rolls    int
+	Settings RollSettings
+}
+
+// LeaderOffers is how often a leader was offered across the successful rolls of a simulation.
+type LeaderOffers struct {
+	Leader generated.Leader
+	Offers int
+	// Rate is the share of successful rolls that offered the leader to someone
+	Rate float64
+}
+
+// TierShare is how many leaders of a tier an offering has on average.
+type TierShare struct {
+	Tier    Tier
+	PerPool float64
+}
+
+// Simulation is the outcome of rolling the same rules over and over.
+type Simulation struct {
+	Options  SimulationOptions
+	Failures int
+	// FailureReasons counts the failed rolls by why they failed
+	FailureReasons map[string]int
+	// OutOfTolerance counts the successful rolls that couldn't be balanced within the balance tolerance
+	OutOfTolerance int
+	// Leaders is every eligible leader, most offered first
+	Leaders []LeaderOffers
+	// Tiers is the make up of an average offering, best tier first
+	Tiers []TierShare
+	// MaxPlayers is the most players every sampled roll succeeded for, 0 when the rules fail even for one player
+	MaxPlayers int
+}
+
+// FailureRate is the share of rolls that failed.
+func (s Simulation) FailureRate() float64 {
+	if s.Options.Rolls == 0 {
+		return 0
+	}
+	return float64(s.Failures) / float64(s.Options.Rolls)
+}
+
+// SimulateRolls rolls for made up players against the guild's eligible leaders like the bot's roll button does,
+// without teams, to show how the rules play out. Nothing is written to the database.
+func (c *Ci6ndex) SimulateRolls(guildId uint64, opts SimulationOptions) (Simulation, error) {
+	if err := opts.Settings.Validate(); err != nil {
+		return Simulation{}, err
+	}
+	if opts.Players < 1 || opts.Rolls < 1 {
+		return Simulation{}, errors.New("a simulation needs at least one player and one roll")
+	}
+	db, err := c.getDB(guildId)
+	if err != nil {
+		return Simulation{}, fmt.Errorf("failed to get database for guild %d: %w", guildId, err)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	leaders, err := db.Queries.GetEligibleLeaders(ctx)
+	if err != nil {
+		return Simulation{}, fmt.Errorf("failed to get leaders: %w", err)
+	}
+	return simulateRolls(leaders, opts), nil
+}
+
+func simulateRolls(leaders []generated.Leader, opts SimulationOptions) Simulation {
+	rules, balance := opts.Settings.Rules(), opts.Settings.Balance()
+	in, playerIds := simulationInput(leaders, opts.Players)
+	sim := Simulation{Options: opts, FailureReasons: make(map[string]int)}
+
+	offers := make(map[int64]int, len(leaders))
+	tiers := make(map[float64]int)
+	for range opts.Rolls {
+		roll, err := rollBalanced(in, playerIds, rules, balance)
+		if err != nil {
+			sim.Failures++
+			sim.FailureReasons[failureReason(err)]++
+			continue
+		}
+		if !roll.WithinTolerance {
+			sim.OutOfTolerance++
+		}
+		for _, o := range roll.Offerings {
+			for _, l := range o.Leaders {
+				offers[l.ID]++
+				if tier, err := GetTierForLeader(l); err == nil {
+					tiers[tier.Value()]++
+				}
+			}
+		}
+	}
+
+	successes := opts.Rolls - sim.Failures
+	sim.Leaders = make([]LeaderOffers, len(leaders))
+	for i, l := range leaders {
+		sim.Leaders[i] = LeaderOffers{Leader: l, Offers: offers[l.ID]}
+		if successes > 0 {
+			sim.Leaders[i].Rate = float64(offers[l.ID]) / float64(successes)
+		}
+	}
+	slices.SortStableFunc(sim.Leaders, func(a, b LeaderOffers) int {
+		return cmp.Compare(b.Offers, a.Offers)
+	})
+	sim.Tiers = make([]TierShare, len(tierListOrder))
+	for i, tier := range tierListOrder {
+		sim.Tiers[i] = TierShare{Tier: tier}
+		if successes > 0 {
+			sim.Tiers[i].PerPool = float64(tiers[tier.Value()]) / float64(successes*opts.Players)
+		}
+	}
+	sim.MaxPlayers = maxPlayers(leaders, opts.Settings)
+	return sim
+}
+
+// maxPlayers finds the most players every one of feasibilityRolls rolls succeeds for. Rolls only get harder with
+// more players, so it stops at the first player count that fails.
+func maxPlayers(leaders []generated.Leader, settings RollSettings) int {
+	rules, balance := settings.Rules(), settings.Balance()
+	// every player is offered PoolSize distinct leaders, so no roll can go past this
+	limit := len(leaders) / settings.PoolSize
+	for n := 1; n <= limit; n++ {
+		in, playerIds := simulationInput(leaders, n)
+		for range feasibilityRolls {
+			if _, err := rollBalanced(in, playerIds, rules, balance); err != nil {
+				return n - 1
+			}
+		}
+	}
+	return limit
+}
+
+// simulationInput makes up n players to roll for.
+func simulationInput(leaders []generated.Leader, n int) (rollInput, []int64) {
+	in := rollInput{players: make([]generated.Player, n), leaders: leaders}
+	playerIds := make([]int64, n)
+	for i := range n {
+		playerIds[i] = int64(i + 1)
+		in.players[i] = generated.Player{ID: playerIds[i], Username: fmt.Sprintf("player %d", i+1)}
+	}
+	return in, playerIds
+}
+
+func failureReason(err error) string {
+	var notDiverse NotDiverseEnoughError
+	var outOfChoices RanOutOfChoicesError
+	switch {
+	case errors.As(err, &notDiverse):
+		return "not diverse enough"
+	case errors.As(err, &outOfChoices):
+		return "ran out of choices"
+	}
+	return err.Error()
+}
@@ -0,0 +1,66 @@
+package ci6ndex
+
+import (
+	"ci6ndex/ci6ndex/generated"
+	"math"
+	"testing"
+)
+
+func TestSimulateRolls(t *testing.T) {
+	// Two S leaders and eight F leaders: the first player's second leader is sometimes the other S leader, leaving
+	// none to guarantee the second player an S
+	leaders := make([]generated.Leader, 10)
+	for i := range leaders {
+		leaders[i] = generated.Leader{ID: int64(i + 1), Tier: F.Value()}
+	}
+	leaders[0].Tier, leaders[1].Tier = S.Value(), S.Value()
+	settings := RollSettings{PoolSize: 2, MinTier: S.Value(), DiversityMode: DiversityByVictoryType,
+		BalanceMode: BalanceOff}
+
+	sim := simulateRolls(leaders, SimulationOptions{Players: 1, Rolls: 500, Settings: settings})
+	if sim.Failures != 0 {
+		t.Fatalf("expected every roll for one player to succeed, got %d failures", sim.Failures)
+	}
+	if sim.MaxPlayers != 1 {
+		t.Fatalf("expected the rules to only reliably support one player, got %d", sim.MaxPlayers)
+	}
+	if sim.Leaders[0].Leader.Tier != S.Value() || sim.Leaders[1].Leader.Tier != S.Value() {
+		t.Fatalf("expected the S leaders to be offered most, got %+v", sim.Leaders[:2])
+	}
+	perPool := make(map[Tier]float64)
+	for _, share := range sim.Tiers {
+		perPool[share.Tier] = share.PerPool
+	}
+	if perPool[S] < 1 || math.Abs(perPool[S]+perPool[F]-2) > 1e-9 || perPool[A] != 0 {
+		t.Fatalf("expected at least one S leader in every pool of two, got %+v", sim.Tiers)
+	}
+
+	sim = simulateRolls(leaders, SimulationOptions{Players: 3, Rolls: 100, Settings: settings})
+	if sim.FailureRate() != 1 || sim.FailureReasons["ran out of choices"] != 100 {
+		t.Fatalf("expected every roll for three players to run out of choices, got %+v", sim.FailureReasons)
+	}
+	for _, l := range sim.Leaders {
+		if l.Offers != 0 || l.Rate != 0 {
+			t.Fatalf("expected failed rolls to offer nothing, got %+v", l)
+		}
+	}
+}
+
+func TestSimulateRolls_Guild(t *testing.T) {
+	sim, err := testC.SimulateRolls(testGuildID, SimulationOptions{Players: 4, Rolls: 50, Settings: DefaultRollSettings})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if sim.Failures != 0 {
+		t.Fatalf("expected the default rules to roll for four players, got %+v", sim.FailureReasons)
+	}
+	// 85 eligible leaders / 5 per player
+	if sim.MaxPlayers < 4 || sim.MaxPlayers > 17 {
+		t.Fatalf("expected the default rules to support between 4 and 17 players, got %d", sim.MaxPlayers)
+	}
+
+	_, err = testC.SimulateRolls(testGuildID, SimulationOptions{Players: 0, Rolls: 50, Settings: DefaultRollSettings})
+	if err == nil {
+		t.Fatal("expected a simulation without players to fail")
+	}
+}
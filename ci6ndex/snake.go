@@ -0,0 +1,401 @@
+package ci6ndex
+
+import (
+	"ci6ndex/ci6ndex/generated"
+	"cmp"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// TurnOrder is how the order of a snake draft is decided.
+type TurnOrder string
+
+const (
+	TurnOrderRandom TurnOrder = "random"
+	// TurnOrderByRating rates players by the drafts they've won, the lowest rated player picks first
+	TurnOrderByRating TurnOrder = "rating"
+)
+
+// SnakeTurnTimeout is how long a player has to pick before a leader is picked for them.
+const SnakeTurnTimeout = 2 * time.Minute
+
+// NotYourTurnError is returned when a player picks out of turn.
+type NotYourTurnError struct {
+	Current generated.Player
+}
+
+func (e NotYourTurnError) Error() string {
+	return fmt.Sprintf("it's %s's turn to pick", e.Current.Username)
+}
+
+// LeaderUnavailableError is returned when the leader was already picked, is banned or doesn't exist.
+type LeaderUnavailableError struct {
+	LeaderID int64
+}
+
+func (e LeaderUnavailableError) Error() string {
+	return fmt.Sprintf("leader %d can't be picked", e.LeaderID)
+}
+
+var (
+	ErrNotSnakeDraft    = errors.New("the active draft isn't a snake draft")
+	ErrDraftComplete    = errors.New("every player has already picked")
+	ErrNotEnoughPlayers = errors.New("a draft needs at least two players")
+)
+
+// snakeMu serializes snake draft picks, so a pick and the auto-pick of a timed out turn can't both take the same
+// turn or the same leader. Blind pick drafts may give two players the same leader, so picks aren't unique per draft.
+var snakeMu sync.Mutex
+
+// SnakeTurn is one player's turn in a snake draft, with their pick once it's made.
+type SnakeTurn struct {
+	Player generated.Player
+	Pick   *generated.Leader
+	// Auto is true when the pick was made for the player because their turn timed out
+	Auto bool
+}
+
+// SnakeDraft is the state of a snake draft.
+type SnakeDraft struct {
+	DraftID int64
+	Turns   []SnakeTurn
+	// Current is the index of the turn being picked, len(Turns) once everybody has picked
+	Current int
+	// Remaining are the eligible leaders nobody has picked yet
+	Remaining     []generated.Leader
+	TurnStartedAt time.Time
+}
+
+func (d SnakeDraft) Done() bool {
+	return d.Current >= len(d.Turns)
+}
+
+// CurrentPlayer is the player whose turn it is, false once the draft is done.
+func (d SnakeDraft) CurrentPlayer() (generated.Player, bool) {
+	if d.Done() {
+		return generated.Player{}, false
+	}
+	return d.Turns[d.Current].Player, true
+}
+
+// Deadline is when the current turn times out.
+func (d SnakeDraft) Deadline() time.Time {
+	return d.TurnStartedAt.Add(SnakeTurnTimeout)
+}
+
+// orderTurns puts players in picking order. Ties, and every player with TurnOrderRandom, are shuffled.
+func orderTurns(players []generated.Player, wins map[int64]int64, order TurnOrder) []generated.Player {
+	ordered := slices.Clone(players)
+	rand.Shuffle(len(ordered), func(i, j int) {
+		ordered[i], ordered[j] = ordered[j], ordered[i]
+	})
+	if order == TurnOrderByRating {
+		slices.SortStableFunc(ordered, func(a, b generated.Player) int {
+			return cmp.Compare(wins[a.ID], wins[b.ID])
+		})
+	}
+	return ordered
+}
+
+// StartSnakeDraft turns the active draft into a snake draft, replacing any earlier turns and picks, and starts the
+// first turn.
+func (c *Ci6ndex) StartSnakeDraft(guildId uint64, order TurnOrder) (SnakeDraft, error) {
+	db, err := c.getDB(guildId)
+	if err != nil {
+		return SnakeDraft{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	draft, err := db.Queries.GetActiveDraft(ctx)
+	if err != nil {
+		return SnakeDraft{}, errors.Join(err, errors.New("failed to get active draft"))
+	}
+	players, err := db.Queries.GetPlayersFromDraft(ctx, draft.ID)
+	if err != nil {
+		return SnakeDraft{}, errors.Join(err, errors.New("failed to get players"))
+	}
+	if len(players) < 2 {
+		return SnakeDraft{}, ErrNotEnoughPlayers
+	}
+	winCounts, err := db.Queries.GetDraftWinCounts(ctx, draft.ID)
+	if err != nil {
+		return SnakeDraft{}, errors.Join(err, errors.New("failed to get player ratings"))
+	}
+	wins := make(map[int64]int64, len(winCounts))
+	for _, w := range winCounts {
+		wins[w.PlayerID] = w.Wins
+	}
+
+	if err := db.Writes.DeletePicksForDraft(ctx, draft.ID); err != nil {
+		return SnakeDraft{}, errors.Join(err, errors.New("failed to clear picks"))
+	}
+	if err := db.Writes.DeleteDraftTurns(ctx, draft.ID); err != nil {
+		return SnakeDraft{}, errors.Join(err, errors.New("failed to clear turns"))
+	}
+	for i, p := range orderTurns(players, wins, order) {
+		err := db.Writes.AddDraftTurn(ctx, generated.AddDraftTurnParams{
+			DraftID:  draft.ID,
+			Turn:     int64(i),
+			PlayerID: p.ID,
+		})
+		if err != nil {
+			return SnakeDraft{}, errors.Join(err, errors.New("failed to add turn"))
+		}
+	}
+	err = db.Writes.SetDraftMode(ctx, generated.SetDraftModeParams{Mode: string(DraftModeSnake), ID: draft.ID})
+	if err != nil {
+		return SnakeDraft{}, errors.Join(err, errors.New("failed to set draft mode"))
+	}
+	if err := startTurn(ctx, db, draft.ID, true); err != nil {
+		return SnakeDraft{}, err
+	}
+	return loadSnakeDraft(ctx, db)
+}
+
+// SetDraftMode records how the active draft is being drafted.
+func (c *Ci6ndex) SetDraftMode(guildId uint64, mode DraftMode) error {
+	db, err := c.getDB(guildId)
+	if err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	draft, err := db.Queries.GetActiveDraft(ctx)
+	if err != nil {
+		return errors.Join(err, errors.New("failed to get active draft"))
+	}
+	err = db.Writes.SetDraftMode(ctx, generated.SetDraftModeParams{Mode: string(mode), ID: draft.ID})
+	if err != nil {
+		return errors.Join(err, errors.New("failed to set draft mode"))
+	}
+	return nil
+}
+
+func (c *Ci6ndex) GetSnakeDraft(guildId uint64) (SnakeDraft, error) {
+	db, err := c.getDB(guildId)
+	if err != nil {
+		return SnakeDraft{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	return loadSnakeDraft(ctx, db)
+}
+
+// MakePick picks a leader for the player whose turn it is. Picking out of turn, or a leader that isn't available,
+// is an error.
+func (c *Ci6ndex) MakePick(guildId uint64, playerId, leaderId int64) (SnakeDraft, error) {
+	snakeMu.Lock()
+	defer snakeMu.Unlock()
+
+	db, err := c.getDB(guildId)
+	if err != nil {
+		return SnakeDraft{}, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	draft, err := loadSnakeDraft(ctx, db)
+	if err != nil {
+		return SnakeDraft{}, err
+	}
+	current, ok := draft.CurrentPlayer()
+	if !ok {
+		return SnakeDraft{}, ErrDraftComplete
+	}
+	if current.ID != playerId {
+		return SnakeDraft{}, NotYourTurnError{Current: current}
+	}
+	if !slices.ContainsFunc(draft.Remaining, func(l generated.Leader) bool { return l.ID == leaderId }) {
+		return SnakeDraft{}, LeaderUnavailableError{LeaderID: leaderId}
+	}
+	return recordPick(ctx, db, draft, leaderId, false)
+}
+
+// AutoPick picks for the player on turn when their turn has timed out, choosing the remaining leader they rated
+// highest, or the highest tier leader if they rated none of them. Turns that have since been picked are left alone,
+// so it's safe to call from a timer that may fire late.
+func (c *Ci6ndex) AutoPick(guildId uint64, draftId int64, turn int) (SnakeDraft, bool, error) {
+	snakeMu.Lock()
+	defer snakeMu.Unlock()
+
+	db, err := c.getDB(guildId)
+	if err != nil {
+		return SnakeDraft{}, false, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	draft, err := loadSnakeDraft(ctx, db)
+	if err != nil {
+		return SnakeDraft{}, false, err
+	}
+	if draft.DraftID != draftId || draft.Current != turn || draft.Done() {
+		return draft, false, nil
+	}
+	if len(draft.Remaining) == 0 {
+		return draft, false, LeaderUnavailableError{}
+	}
+
+	current, _ := draft.CurrentPlayer()
+	ranks, err := db.Queries.GetRanksForPlayer(ctx, current.ID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return draft, false, errors.Join(err, errors.New("failed to get player ranks"))
+	}
+	draft, err = recordPick(ctx, db, draft, autoPickFor(ranks, draft.Remaining).ID, true)
+	return draft, err == nil, err
+}
+
+// autoPickFor returns the leader in remaining the player ranked highest under vanilla, like the leaders' tiers, or the
+// highest tier leader if they ranked none of them. remaining must not be empty.
+func autoPickFor(ranks []generated.Rank, remaining []generated.Leader) generated.Leader {
+	playerTier := make(map[int64]float64, len(ranks))
+	for _, r := range ranks {
+		if !r.Bbg {
+			playerTier[r.LeaderID] = r.Tier
+		}
+	}
+	return slices.MinFunc(remaining, func(a, b generated.Leader) int {
+		ta, aRanked := playerTier[a.ID]
+		tb, bRanked := playerTier[b.ID]
+		switch {
+		case aRanked && !bRanked:
+			return -1
+		case !aRanked && bRanked:
+			return 1
+		case aRanked && bRanked && ta != tb:
+			return cmp.Compare(ta, tb)
+		}
+		// Unranked leaders have a tier of 0, so they go after every ranked leader
+		if a.Unranked != b.Unranked {
+			if a.Unranked {
+				return 1
+			}
+			return -1
+		}
+		if byTier := cmp.Compare(a.Tier, b.Tier); byTier != 0 {
+			return byTier
+		}
+		return cmp.Compare(a.ID, b.ID)
+	})
+}
+
+func recordPick(ctx context.Context, db *DB, draft SnakeDraft, leaderId int64, auto bool) (SnakeDraft, error) {
+	current, _ := draft.CurrentPlayer()
+	err := db.Writes.AddPick(ctx, generated.AddPickParams{
+		Player:  strconv.FormatInt(current.ID, 10),
+		DraftID: draft.DraftID,
+		Pick:    leaderId,
+		Auto:    auto,
+	})
+	if err != nil {
+		return SnakeDraft{}, errors.Join(err, fmt.Errorf("failed to pick leader %d", leaderId))
+	}
+	// The last pick ends the draft, so there's no turn left to time
+	if err := startTurn(ctx, db, draft.DraftID, draft.Current+1 < len(draft.Turns)); err != nil {
+		return SnakeDraft{}, err
+	}
+	return loadSnakeDraft(ctx, db)
+}
+
+func startTurn(ctx context.Context, db *DB, draftId int64, timed bool) error {
+	startedAt := sql.NullTime{Time: time.Now().UTC(), Valid: timed}
+	err := db.Writes.SetTurnStartedAt(ctx, generated.SetTurnStartedAtParams{TurnStartedAt: startedAt, ID: draftId})
+	if err != nil {
+		return errors.Join(err, errors.New("failed to start turn"))
+	}
+	return nil
+}
+
+func loadSnakeDraft(ctx context.Context, db *DB) (SnakeDraft, error) {
+	draft, err := db.Queries.GetActiveDraft(ctx)
+	if err != nil {
+		return SnakeDraft{}, errors.Join(err, errors.New("failed to get active draft"))
+	}
+	if DraftMode(draft.Mode) != DraftModeSnake {
+		return SnakeDraft{}, ErrNotSnakeDraft
+	}
+	turns, err := db.Queries.GetDraftTurns(ctx, draft.ID)
+	if err != nil {
+		return SnakeDraft{}, errors.Join(err, errors.New("failed to get turns"))
+	}
+	picks, err := db.Queries.GetPicksForDraft(ctx, draft.ID)
+	if err != nil {
+		return SnakeDraft{}, errors.Join(err, errors.New("failed to get picks"))
+	}
+	leaders, err := db.Queries.GetEligibleLeaders(ctx)
+	if err != nil {
+		return SnakeDraft{}, errors.Join(err, errors.New("failed to get leaders"))
+	}
+
+	picked, err := picksByPlayer(picks)
+	if err != nil {
+		return SnakeDraft{}, err
+	}
+	pickedLeaders := make(map[int64]struct{}, len(picks))
+	for _, p := range picks {
+		pickedLeaders[p.Pick] = struct{}{}
+	}
+
+	state := SnakeDraft{
+		DraftID:       draft.ID,
+		Turns:         make([]SnakeTurn, len(turns)),
+		Current:       len(turns),
+		Remaining:     filterAssigned(slices.Clone(leaders), pickedLeaders),
+		TurnStartedAt: draft.TurnStartedAt.Time,
+	}
+	for i, t := range turns {
+		turn := SnakeTurn{Player: generated.Player{
+			ID:            t.ID,
+			Username:      t.Username,
+			GlobalName:    t.GlobalName,
+			DiscordAvatar: t.DiscordAvatar,
+		}}
+		if p, ok := picked[t.ID]; ok {
+			leader, err := pickedLeader(ctx, db, leaders, p.Pick)
+			if err != nil {
+				return SnakeDraft{}, err
+			}
+			turn.Pick = &leader
+			turn.Auto = p.Auto
+		} else if state.Current == len(turns) {
+			state.Current = i
+		}
+		state.Turns[i] = turn
+	}
+	return state, nil
+}
+
+// picksByPlayer indexes the picks of a draft by the player who made them.
+func picksByPlayer(picks []generated.Pick) (map[int64]generated.Pick, error) {
+	picked := make(map[int64]generated.Pick, len(picks))
+	for _, p := range picks {
+		playerId, err := strconv.ParseInt(p.Player, 10, 64)
+		if err != nil {
+			return nil, errors.Join(err, fmt.Errorf("invalid player %q on pick", p.Player))
+		}
+		picked[playerId] = p
+	}
+	return picked, nil
+}
+
+// pickedLeader finds a picked leader among the eligible leaders. A leader banned after it was picked is still the
+// player's pick, so it falls back to looking the leader up.
+func pickedLeader(ctx context.Context, db *DB, eligible []generated.Leader, leaderId int64) (generated.Leader, error) {
+	if i := slices.IndexFunc(eligible, func(l generated.Leader) bool { return l.ID == leaderId }); i >= 0 {
+		return eligible[i], nil
+	}
+	leader, err := db.Queries.GetLeaderById(ctx, leaderId)
+	if err != nil {
+		return generated.Leader{}, errors.Join(err, fmt.Errorf("failed to get picked leader %d", leaderId))
+	}
+	return leader, nil
+}
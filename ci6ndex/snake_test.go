@@ -0,0 +1,162 @@
+package ci6ndex
+
+import (
+	"ci6ndex/ci6ndex/generated"
+	"context"
+	"errors"
+	"sync"
+	"testing"
+)
+
+func TestOrderTurns_ByRating(t *testing.T) {
+	players := []generated.Player{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}}
+	wins := map[int64]int64{1: 3, 2: 0, 4: 1}
+
+	for range 20 {
+		ordered := orderTurns(players, wins, TurnOrderByRating)
+		if len(ordered) != len(players) {
+			t.Fatalf("expected %d turns, got %d", len(players), len(ordered))
+		}
+		// Players 2 and 3 have no wins, so they pick first in either order
+		if first := ordered[0].ID + ordered[1].ID; first != 5 {
+			t.Fatalf("expected the players without wins to pick first, got %v", ordered)
+		}
+		if ordered[2].ID != 4 || ordered[3].ID != 1 {
+			t.Fatalf("expected the most wins to pick last, got %v", ordered)
+		}
+	}
+}
+
+func TestAutoPickFor(t *testing.T) {
+	remaining := []generated.Leader{
+		{ID: 1, Tier: 1},
+		{ID: 2, Tier: 3},
+		{ID: 3, Tier: 4},
+		{ID: 4, Unranked: true},
+	}
+	if got := autoPickFor(nil, remaining); got.ID != 1 {
+		t.Fatalf("expected the highest tier leader without ranks, got %d", got.ID)
+	}
+
+	ranks := []generated.Rank{
+		{LeaderID: 2, Tier: 2},
+		{LeaderID: 3, Tier: 1},
+		// Ranks for leaders that were already picked are ignored
+		{LeaderID: 99, Tier: 1},
+		// and so are BBG ranks, a vanilla rank of the same leader wins
+		{LeaderID: 2, Tier: 1, Bbg: true},
+		{LeaderID: 4, Tier: 1, Bbg: true},
+	}
+	if got := autoPickFor(ranks, remaining); got.ID != 3 {
+		t.Fatalf("expected the player's highest rated leader, got %d", got.ID)
+	}
+
+	if got := autoPickFor(nil, remaining[3:]); got.ID != 4 {
+		t.Fatalf("expected an unranked leader when nothing else is left, got %d", got.ID)
+	}
+}
+
+func TestSnakeDraft(t *testing.T) {
+	t.Cleanup(func() { resetActiveDraft(t) })
+
+	if _, err := testC.GetSnakeDraft(testGuildID); !errors.Is(err, ErrNotSnakeDraft) {
+		t.Fatalf("expected a random draft to not be a snake draft, got %v", err)
+	}
+
+	draft, err := testC.StartSnakeDraft(testGuildID, TurnOrderRandom)
+	if err != nil {
+		t.Fatalf("failed to start snake draft: %v", err)
+	}
+	if len(draft.Turns) != 18 || draft.Current != 0 || draft.TurnStartedAt.IsZero() {
+		t.Fatalf("expected 18 turns starting at the first, got %d turns at %d", len(draft.Turns), draft.Current)
+	}
+
+	first, _ := draft.CurrentPlayer()
+	second := draft.Turns[1].Player
+	leader := draft.Remaining[0]
+
+	var notYourTurn NotYourTurnError
+	if _, err := testC.MakePick(testGuildID, second.ID, leader.ID); !errors.As(err, &notYourTurn) {
+		t.Fatalf("expected picking out of turn to fail, got %v", err)
+	}
+	if notYourTurn.Current.ID != first.ID {
+		t.Fatalf("expected it to be player %d's turn, got %d", first.ID, notYourTurn.Current.ID)
+	}
+
+	draft, err = testC.MakePick(testGuildID, first.ID, leader.ID)
+	if err != nil {
+		t.Fatalf("failed to pick: %v", err)
+	}
+	if draft.Current != 1 || draft.Turns[0].Pick == nil || draft.Turns[0].Pick.ID != leader.ID || draft.Turns[0].Auto {
+		t.Fatalf("expected the first turn to hold the pick, got %+v", draft.Turns[0])
+	}
+	for _, l := range draft.Remaining {
+		if l.ID == leader.ID {
+			t.Fatalf("expected leader %d to no longer be remaining", leader.ID)
+		}
+	}
+
+	var unavailable LeaderUnavailableError
+	if _, err := testC.MakePick(testGuildID, second.ID, leader.ID); !errors.As(err, &unavailable) {
+		t.Fatalf("expected picking a taken leader to fail, got %v", err)
+	}
+
+	// A timer for a turn that has already been picked does nothing
+	if _, picked, err := testC.AutoPick(testGuildID, draft.DraftID, 0); err != nil || picked {
+		t.Fatalf("expected no auto pick for a finished turn, got picked=%v err=%v", picked, err)
+	}
+	draft, picked, err := testC.AutoPick(testGuildID, draft.DraftID, 1)
+	if err != nil || !picked {
+		t.Fatalf("expected the second turn to be auto-picked, got picked=%v err=%v", picked, err)
+	}
+	if draft.Current != 2 || draft.Turns[1].Pick == nil || !draft.Turns[1].Auto {
+		t.Fatalf("expected the second turn to be auto-picked, got %+v", draft.Turns[1])
+	}
+
+	// A pick racing the timer of the same turn takes the turn once
+	third, _ := draft.CurrentPlayer()
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		_, _ = testC.MakePick(testGuildID, third.ID, draft.Remaining[0].ID)
+	}()
+	go func() {
+		defer wg.Done()
+		_, _, _ = testC.AutoPick(testGuildID, draft.DraftID, 2)
+	}()
+	wg.Wait()
+	picks, err := testDB.Queries.GetPicksForDraft(context.Background(), draft.DraftID)
+	if err != nil {
+		t.Fatalf("failed to get picks: %v", err)
+	}
+	draft, err = testC.GetSnakeDraft(testGuildID)
+	if err != nil {
+		t.Fatalf("failed to get snake draft: %v", err)
+	}
+	if len(picks) != draft.Current {
+		t.Fatalf("expected one pick per finished turn, got %d picks after %d turns", len(picks), draft.Current)
+	}
+}
+
+// resetActiveDraft clears the picks made by a draft mode test and turns the active draft back into a random draft,
+// so the picks don't leak into the leader stats of other tests.
+func resetActiveDraft(t *testing.T) {
+	ctx := context.Background()
+	draft, err := testDB.Queries.GetActiveDraft(ctx)
+	if err != nil {
+		t.Fatalf("failed to get active draft: %v", err)
+	}
+	if err := testDB.Writes.DeletePicksForDraft(ctx, draft.ID); err != nil {
+		t.Fatalf("failed to clear picks: %v", err)
+	}
+	if err := testDB.Writes.DeleteDraftTurns(ctx, draft.ID); err != nil {
+		t.Fatalf("failed to clear turns: %v", err)
+	}
+	if err := testDB.Writes.DeleteBlindPicks(ctx, draft.ID); err != nil {
+		t.Fatalf("failed to clear blind picks: %v", err)
+	}
+	if err := testC.SetDraftMode(testGuildID, DraftModeRandom); err != nil {
+		t.Fatalf("failed to reset draft mode: %v", err)
+	}
+}
@@ -0,0 +1,256 @@
+package ci6ndex
+
+import (
+	"ci6ndex/ci6ndex/generated"
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// TeamRule constrains the offerings of a whole team rather than a single player's, e.g. no two teammates being
+// offered the same civ.
+type TeamRule interface {
+	// Violation measures how far teams, each holding the offerings of one team, are from satisfying the rule. Zero
+	// means the rule is satisfied, smaller is closer.
+	Violation(teams [][]Offering) float64
+	// Description explains the rule to players
+	Description() string
+}
+
+// DistinctCivsRule keeps a civ from being offered twice within a team, even as two different leaders.
+type DistinctCivsRule struct{}
+
+func (r *DistinctCivsRule) Violation(teams [][]Offering) float64 {
+	duplicates := 0
+	for _, team := range teams {
+		seen := make(map[string]struct{})
+		for _, o := range team {
+			for _, l := range o.Leaders {
+				if _, ok := seen[l.CivName]; ok {
+					duplicates++
+				}
+				seen[l.CivName] = struct{}{}
+			}
+		}
+	}
+	return float64(duplicates)
+}
+
+func (r *DistinctCivsRule) Description() string {
+	return "no civ offered twice within a team"
+}
+
+// TeamTagRule guarantees every team is offered at least one leader whose play style matches any of Tags, e.g. one
+// strong military civ with "early-war".
+type TeamTagRule struct {
+	Tags []string
+}
+
+func (r *TeamTagRule) Violation(teams [][]Offering) float64 {
+	missing := 0
+	for _, team := range teams {
+		found := slices.ContainsFunc(team, func(o Offering) bool {
+			return slices.ContainsFunc(o.Leaders, func(l generated.Leader) bool {
+				metadata, ok := MetadataForLeader(l)
+				return ok && metadata.HasTag(r.Tags...)
+			})
+		})
+		if !found {
+			missing++
+		}
+	}
+	return float64(missing)
+}
+
+func (r *TeamTagRule) Description() string {
+	return fmt.Sprintf("every team offered a %s leader", strings.Join(r.Tags, " or "))
+}
+
+// TeamBalanceRule keeps the total strength of every team's offerings within Tolerance of each other.
+type TeamBalanceRule struct {
+	Mode      BalanceMode
+	Tolerance float64
+}
+
+// Strengths scores every team as the sum of its offerings' strengths.
+func (r *TeamBalanceRule) Strengths(teams [][]Offering) []float64 {
+	strengths := make([]float64, len(teams))
+	for i, team := range teams {
+		for _, o := range team {
+			strengths[i] += r.Mode.Strength(o.Leaders)
+		}
+	}
+	return strengths
+}
+
+func (r *TeamBalanceRule) Violation(teams [][]Offering) float64 {
+	strengths := r.Strengths(teams)
+	if len(strengths) == 0 {
+		return 0
+	}
+	return max(0, slices.Max(strengths)-slices.Min(strengths)-r.Tolerance)
+}
+
+func (r *TeamBalanceRule) Description() string {
+	return fmt.Sprintf("team strength within %g of each other", r.Tolerance)
+}
+
+// TeamRuleError is returned when no roll could satisfy a team rule.
+type TeamRuleError struct {
+	Rule TeamRule
+}
+
+func (e TeamRuleError) Error() string {
+	return fmt.Sprintf("couldn't roll offerings with %s", e.Rule.Description())
+}
+
+const (
+	// teamRollAttempts is how many rolls a team roll tries to repair into one that satisfies every team rule
+	teamRollAttempts = 10
+	// teamRepairSteps caps how many leaders are moved around to repair a single roll
+	teamRepairSteps = 50
+)
+
+// RollForTeams rolls like RollForPlayers for players grouped into teams, then moves leaders between teams and swaps
+// them for leaders nobody was offered until every team rule is satisfied. Player rules still hold for every
+// offering. Offerings come back in the order of the players in teams.
+func (c *Ci6ndex) RollForTeams(
+	guildId uint64,
+	teams [][]int64,
+	rules []Rule,
+	teamRules []TeamRule,
+) ([]Offering, error) {
+	in, err := c.loadRollInput(guildId)
+	if err != nil {
+		return nil, err
+	}
+	offerings, err := rollForTeams(in, teams, rules, teamRules)
+	if err != nil {
+		return nil, err
+	}
+	scoreOfferings(offerings, BalanceBySum)
+	return offerings, nil
+}
+
+func rollForTeams(in rollInput, teams [][]int64, rules []Rule, teamRules []TeamRule) ([]Offering, error) {
+	var playerIds []int64
+	teamOf := make(map[int64]int)
+	for i, team := range teams {
+		for _, id := range team {
+			playerIds = append(playerIds, id)
+			teamOf[id] = i
+		}
+	}
+
+	var lastErr error
+	for range teamRollAttempts {
+		offerings, err := rollOfferings(in, playerIds, rules)
+		if err != nil {
+			// Rolls are random, a later attempt may still find enough leaders
+			lastErr = err
+			continue
+		}
+		membership := make([]int, len(offerings))
+		for i, o := range offerings {
+			membership[i] = teamOf[o.Player.ID]
+		}
+
+		if repairTeams(in, offerings, membership, len(teams), rules, teamRules) == 0 {
+			return offerings, nil
+		}
+		for _, rule := range teamRules {
+			if rule.Violation(groupByTeam(offerings, membership, len(teams))) > 0 {
+				lastErr = TeamRuleError{Rule: rule}
+				break
+			}
+		}
+	}
+	return nil, lastErr
+}
+
+// groupByTeam groups offerings by the team at the same index of membership.
+func groupByTeam(offerings []Offering, membership []int, teamCount int) [][]Offering {
+	teams := make([][]Offering, teamCount)
+	for i, o := range offerings {
+		teams[membership[i]] = append(teams[membership[i]], o)
+	}
+	return teams
+}
+
+func teamViolation(offerings []Offering, membership []int, teamCount int, teamRules []TeamRule) float64 {
+	teams := groupByTeam(offerings, membership, teamCount)
+	total := 0.0
+	for _, rule := range teamRules {
+		total += rule.Violation(teams)
+	}
+	return total
+}
+
+// repairTeams lowers the total violation of the team rules in place and returns what's left of it. Each step makes
+// the single move that lowers it the most: swapping leaders between players of different teams, or swapping a
+// player's leader for one nobody was offered. Moves that would break a player rule are skipped.
+func repairTeams(
+	in rollInput,
+	offerings []Offering,
+	membership []int,
+	teamCount int,
+	rules []Rule,
+	teamRules []TeamRule,
+) float64 {
+	violation := teamViolation(offerings, membership, teamCount, teamRules)
+	for range teamRepairSteps {
+		if violation == 0 {
+			return 0
+		}
+
+		assigned := make(map[int64]struct{})
+		for _, o := range offerings {
+			for _, l := range o.Leaders {
+				assigned[l.ID] = struct{}{}
+			}
+		}
+		unassigned := filterAssigned(slices.Clone(in.leaders), assigned)
+
+		best := violation
+		var bestA, bestB int
+		var bestALeaders, bestBLeaders []generated.Leader
+		// try scores replacing the leaders of offerings a and b, b may be a too
+		try := func(a int, newA []generated.Leader, b int, newB []generated.Leader) {
+			oldA, oldB := offerings[a].Leaders, offerings[b].Leaders
+			offerings[a].Leaders, offerings[b].Leaders = newA, newB
+			v := teamViolation(offerings, membership, teamCount, teamRules)
+			offerings[a].Leaders, offerings[b].Leaders = oldA, oldB
+			if v < best &&
+				satisfiesRules(offerings[a].Player, newA, rules) &&
+				satisfiesRules(offerings[b].Player, newB, rules) {
+				best, bestA, bestB, bestALeaders, bestBLeaders = v, a, b, newA, newB
+			}
+		}
+		for a := range offerings {
+			for i := range offerings[a].Leaders {
+				for _, u := range unassigned {
+					newA := slices.Clone(offerings[a].Leaders)
+					newA[i] = u
+					try(a, newA, a, newA)
+				}
+				for b := a + 1; b < len(offerings); b++ {
+					if membership[a] == membership[b] {
+						continue
+					}
+					for j := range offerings[b].Leaders {
+						newA, newB := slices.Clone(offerings[a].Leaders), slices.Clone(offerings[b].Leaders)
+						newA[i], newB[j] = newB[j], newA[i]
+						try(a, newA, b, newB)
+					}
+				}
+			}
+		}
+
+		if bestALeaders == nil {
+			return violation
+		}
+		offerings[bestA].Leaders, offerings[bestB].Leaders = bestALeaders, bestBLeaders
+		violation = best
+	}
+	return violation
+}
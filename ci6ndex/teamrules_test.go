@@ -0,0 +1,129 @@
+package ci6ndex
+
+import (
+	"ci6ndex/ci6ndex/generated"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestDistinctCivsRule_Violation(t *testing.T) {
+	rule := &DistinctCivsRule{}
+	teams := [][]Offering{
+		{
+			{Leaders: []generated.Leader{{ID: 1, CivName: "Greece"}, {ID: 2, CivName: "Rome"}}},
+			{Leaders: []generated.Leader{{ID: 3, CivName: "Greece"}}},
+		},
+		// The same civ on different teams is fine
+		{
+			{Leaders: []generated.Leader{{ID: 4, CivName: "Rome"}}},
+		},
+	}
+	if v := rule.Violation(teams); v != 1 {
+		t.Fatalf("expected one duplicate civ, got %g", v)
+	}
+	teams[0][1].Leaders[0].CivName = "Egypt"
+	if v := rule.Violation(teams); v != 0 {
+		t.Fatalf("expected no duplicate civs, got %g", v)
+	}
+}
+
+func TestTeamBalanceRule_Violation(t *testing.T) {
+	rule := &TeamBalanceRule{Mode: BalanceBySum, Tolerance: 1}
+	teams := [][]Offering{
+		{{Leaders: []generated.Leader{{ID: 1, Tier: 1}}}},
+		{{Leaders: []generated.Leader{{ID: 2, Tier: 1}}}},
+	}
+	if v := rule.Violation(teams); v != 0 {
+		t.Fatalf("expected even teams to satisfy the rule, got %g", v)
+	}
+	teams[1] = append(teams[1], Offering{Leaders: []generated.Leader{{ID: 3, Tier: 1}, {ID: 4, Tier: 1}}})
+	strengths := rule.Strengths(teams)
+	if want := strengths[1] - strengths[0] - 1; rule.Violation(teams) != want {
+		t.Fatalf("expected a violation of %g, got %g", want, rule.Violation(teams))
+	}
+}
+
+func TestRollForTeams(t *testing.T) {
+	ctx := context.Background()
+	players, err := testDB.Queries.GetPlayersFromActiveDraft(ctx)
+	if err != nil {
+		t.Fatalf("failed to get players: %v", err)
+	}
+	teams := [][]int64{
+		{players[0].ID, players[1].ID, players[2].ID},
+		{players[3].ID, players[4].ID, players[5].ID},
+	}
+	rules := []Rule{&MinTierRule{MinTier: 3}, &NoOpRule{}, &NoOpRule{}}
+	teamRules := []TeamRule{
+		&DistinctCivsRule{},
+		&TeamTagRule{Tags: []string{"early-war"}},
+		&TeamBalanceRule{Mode: BalanceBySum, Tolerance: 1},
+	}
+
+	for range 5 {
+		offerings, err := testC.RollForTeams(testGuildID, teams, rules, teamRules)
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(offerings) != 6 {
+			t.Fatalf("expected 6 offerings, got %d", len(offerings))
+		}
+		grouped := [][]Offering{offerings[:3], offerings[3:]}
+		for _, rule := range teamRules {
+			if v := rule.Violation(grouped); v != 0 {
+				t.Fatalf("expected %s to hold, got a violation of %g", rule.Description(), v)
+			}
+		}
+		seen := make(map[int64]struct{})
+		for _, o := range offerings {
+			if !satisfiesRules(o.Player, o.Leaders, rules) {
+				t.Fatalf("player %d's offering broke a player rule: %v", o.Player.ID, o.Leaders)
+			}
+			for _, l := range o.Leaders {
+				if _, ok := seen[l.ID]; ok {
+					t.Fatalf("leader %d was offered twice", l.ID)
+				}
+				seen[l.ID] = struct{}{}
+			}
+		}
+	}
+}
+
+func TestRollForTeams_Infeasible(t *testing.T) {
+	ctx := context.Background()
+	players, err := testDB.Queries.GetPlayersFromActiveDraft(ctx)
+	if err != nil {
+		t.Fatalf("failed to get players: %v", err)
+	}
+	teams := [][]int64{{players[0].ID, players[1].ID}, {players[2].ID, players[3].ID}}
+
+	var ruleErr TeamRuleError
+	_, err = testC.RollForTeams(
+		testGuildID,
+		teams,
+		[]Rule{&NoOpRule{}, &NoOpRule{}},
+		[]TeamRule{&TeamTagRule{Tags: []string{"not-a-tag"}}},
+	)
+	if !errors.As(err, &ruleErr) {
+		t.Fatalf("expected a tag nobody has to be impossible, got %v", err)
+	}
+	if _, ok := ruleErr.Rule.(*TeamTagRule); !ok {
+		t.Fatalf("expected the tag rule to be reported, got %s", ruleErr.Rule.Description())
+	}
+
+	// Every leader leads the same civ, so teammates can't help sharing it
+	in := rollInput{
+		players: players[:2],
+		leaders: []generated.Leader{
+			{ID: 1, CivName: "Greece", LeaderName: "Pericles"},
+			{ID: 2, CivName: "Greece", LeaderName: "Gorgo"},
+			{ID: 3, CivName: "Greece", LeaderName: "Pericles"},
+			{ID: 4, CivName: "Greece", LeaderName: "Gorgo"},
+		},
+	}
+	_, err = rollForTeams(in, [][]int64{{players[0].ID, players[1].ID}}, []Rule{&NoOpRule{}}, []TeamRule{&DistinctCivsRule{}})
+	if !errors.As(err, &ruleErr) {
+		t.Fatalf("expected distinct civs to be impossible, got %v", err)
+	}
+}
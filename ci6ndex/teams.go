@@ -0,0 +1,253 @@
+package ci6ndex
+
+import (
+	"ci6ndex/ci6ndex/generated"
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"time"
+)
+
+// TeamMode is how players are split into teams.
+type TeamMode string
+
+const (
+	TeamsRandom TeamMode = "random"
+	// TeamsBalanced evens out the drafts won by every team, the only rating players have
+	TeamsBalanced TeamMode = "balanced"
+)
+
+const (
+	// teamAttempts is how many random splits are improved on before keeping the best one
+	teamAttempts = 20
+	// teamSwapSteps caps how many players are swapped between teams to improve a single split
+	teamSwapSteps = 50
+)
+
+// Team is one team of a draft, numbered from 1.
+type Team struct {
+	Number  int
+	Players []generated.Player
+	// Wins are the drafts won by the team's players together
+	Wins int64
+}
+
+// InvalidTeamCountError is returned when the players can't be split into the requested number of teams.
+type InvalidTeamCountError struct {
+	Teams   int
+	Players int
+}
+
+func (e InvalidTeamCountError) Error() string {
+	return fmt.Sprintf("can't split %d players into %d teams", e.Players, e.Teams)
+}
+
+// teamCost scores a split, lower is better.
+type teamCost struct {
+	// repeats counts pairs of teammates who were also teammates in the previous game
+	repeats int
+	// imbalance is the gap between the team with the most and fewest wins per player
+	imbalance float64
+}
+
+func (c teamCost) less(o teamCost, mode TeamMode) bool {
+	if mode == TeamsBalanced && c.imbalance != o.imbalance {
+		return c.imbalance < o.imbalance
+	}
+	return c.repeats < o.repeats
+}
+
+func scoreTeams(teams [][]generated.Player, wins map[int64]int64, previous map[int64]int64) teamCost {
+	var cost teamCost
+	lo, hi := 0.0, 0.0
+	for i, team := range teams {
+		total := int64(0)
+		for j, p := range team {
+			total += wins[p.ID]
+			for _, q := range team[j+1:] {
+				pt, pOk := previous[p.ID]
+				qt, qOk := previous[q.ID]
+				if pOk && qOk && pt == qt {
+					cost.repeats++
+				}
+			}
+		}
+		avg := float64(total) / float64(max(len(team), 1))
+		if i == 0 {
+			lo, hi = avg, avg
+		}
+		lo, hi = min(lo, avg), max(hi, avg)
+	}
+	cost.imbalance = hi - lo
+	return cost
+}
+
+// generateTeams splits players into n teams whose sizes differ by at most one. Splits are random, then players are
+// swapped between teams while that avoids repeating the previous game's teammates and, with TeamsBalanced, evens out
+// the wins of every team.
+func generateTeams(
+	players []generated.Player,
+	n int,
+	mode TeamMode,
+	wins map[int64]int64,
+	previous map[int64]int64,
+) ([][]generated.Player, error) {
+	if n < 2 || n > len(players) {
+		return nil, InvalidTeamCountError{Teams: n, Players: len(players)}
+	}
+
+	var best [][]generated.Player
+	var bestCost teamCost
+	for range teamAttempts {
+		shuffled := slices.Clone(players)
+		rand.Shuffle(len(shuffled), func(i, j int) {
+			shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+		})
+		teams := make([][]generated.Player, n)
+		for i, p := range shuffled {
+			teams[i%n] = append(teams[i%n], p)
+		}
+
+		cost := improveTeams(teams, mode, wins, previous)
+		if best == nil || cost.less(bestCost, mode) {
+			best, bestCost = teams, cost
+		}
+		if bestCost.repeats == 0 && (mode != TeamsBalanced || bestCost.imbalance == 0) {
+			break
+		}
+	}
+	return best, nil
+}
+
+// improveTeams swaps players between teams in place, one swap at a time, while a swap lowers the cost.
+func improveTeams(teams [][]generated.Player, mode TeamMode, wins, previous map[int64]int64) teamCost {
+	cost := scoreTeams(teams, wins, previous)
+	for range teamSwapSteps {
+		improved := false
+		for a := range teams {
+			for b := a + 1; b < len(teams); b++ {
+				for i := range teams[a] {
+					for j := range teams[b] {
+						teams[a][i], teams[b][j] = teams[b][j], teams[a][i]
+						if c := scoreTeams(teams, wins, previous); c.less(cost, mode) {
+							cost, improved = c, true
+							continue
+						}
+						teams[a][i], teams[b][j] = teams[b][j], teams[a][i]
+					}
+				}
+			}
+		}
+		if !improved {
+			break
+		}
+	}
+	return cost
+}
+
+// GenerateTeams splits the players of the active draft into n teams and stores them on the draft, replacing any
+// earlier teams.
+func (c *Ci6ndex) GenerateTeams(guildId uint64, n int, mode TeamMode) ([]Team, error) {
+	db, err := c.getDB(guildId)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	draft, err := db.Queries.GetActiveDraft(ctx)
+	if err != nil {
+		return nil, errors.Join(err, errors.New("failed to get active draft"))
+	}
+	players, err := db.Queries.GetPlayersFromDraft(ctx, draft.ID)
+	if err != nil {
+		return nil, errors.Join(err, errors.New("failed to get players"))
+	}
+	winCounts, err := db.Queries.GetDraftWinCounts(ctx, draft.ID)
+	if err != nil {
+		return nil, errors.Join(err, errors.New("failed to get player ratings"))
+	}
+	wins := make(map[int64]int64, len(winCounts))
+	for _, w := range winCounts {
+		wins[w.PlayerID] = w.Wins
+	}
+	previousTeams, err := db.Queries.GetPreviousDraftTeams(ctx, draft.ID)
+	if err != nil {
+		return nil, errors.Join(err, errors.New("failed to get previous teams"))
+	}
+	previous := make(map[int64]int64, len(previousTeams))
+	for _, t := range previousTeams {
+		previous[t.PlayerID] = t.Team
+	}
+
+	split, err := generateTeams(players, n, mode, wins, previous)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Writes.DeleteTeamsForDraft(ctx, draft.ID); err != nil {
+		return nil, errors.Join(err, errors.New("failed to clear teams"))
+	}
+	teams := make([]Team, len(split))
+	for i, members := range split {
+		teams[i] = Team{Number: i + 1}
+		for _, p := range members {
+			err := db.Writes.AddTeamMember(ctx, generated.AddTeamMemberParams{
+				DraftID:  draft.ID,
+				PlayerID: p.ID,
+				Team:     int64(i),
+			})
+			if err != nil {
+				return nil, errors.Join(err, fmt.Errorf("failed to add player %d to team %d", p.ID, i+1))
+			}
+			teams[i].Players = append(teams[i].Players, p)
+			teams[i].Wins += wins[p.ID]
+		}
+	}
+	return teams, nil
+}
+
+// GetTeamsForActiveDraft returns the teams of the active draft, none when it isn't a team game.
+func (c *Ci6ndex) GetTeamsForActiveDraft(guildId uint64) ([]Team, error) {
+	db, err := c.getDB(guildId)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	draft, err := db.Queries.GetActiveDraft(ctx)
+	if err != nil {
+		return nil, errors.Join(err, errors.New("failed to get active draft"))
+	}
+	rows, err := db.Queries.GetTeamsForDraft(ctx, draft.ID)
+	if err != nil {
+		return nil, errors.Join(err, errors.New("failed to get teams"))
+	}
+	winCounts, err := db.Queries.GetDraftWinCounts(ctx, draft.ID)
+	if err != nil {
+		return nil, errors.Join(err, errors.New("failed to get player ratings"))
+	}
+	wins := make(map[int64]int64, len(winCounts))
+	for _, w := range winCounts {
+		wins[w.PlayerID] = w.Wins
+	}
+
+	var teams []Team
+	for _, row := range rows {
+		if len(teams) == 0 || teams[len(teams)-1].Number != int(row.Team)+1 {
+			teams = append(teams, Team{Number: int(row.Team) + 1})
+		}
+		team := &teams[len(teams)-1]
+		team.Players = append(team.Players, generated.Player{
+			ID:            row.ID,
+			Username:      row.Username,
+			GlobalName:    row.GlobalName,
+			DiscordAvatar: row.DiscordAvatar,
+		})
+		team.Wins += wins[row.ID]
+	}
+	return teams, nil
+}
@@ -0,0 +1,94 @@
+package ci6ndex
+
+import (
+	"ci6ndex/ci6ndex/generated"
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestGenerateTeams(t *testing.T) {
+	players := []generated.Player{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}, {ID: 5}}
+
+	teams, err := generateTeams(players, 2, TeamsRandom, nil, nil)
+	if err != nil {
+		t.Fatalf("failed to generate teams: %v", err)
+	}
+	if len(teams) != 2 || len(teams[0])+len(teams[1]) != 5 || len(teams[0])-len(teams[1]) != 1 {
+		t.Fatalf("expected teams of 3 and 2 players, got %v", teams)
+	}
+
+	var countErr InvalidTeamCountError
+	if _, err := generateTeams(players, 6, TeamsRandom, nil, nil); !errors.As(err, &countErr) {
+		t.Fatalf("expected too many teams to be refused, got %v", err)
+	}
+	if _, err := generateTeams(players, 1, TeamsRandom, nil, nil); !errors.As(err, &countErr) {
+		t.Fatalf("expected a single team to be refused, got %v", err)
+	}
+}
+
+func TestGenerateTeams_AvoidsPreviousTeammates(t *testing.T) {
+	players := []generated.Player{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}}
+	previous := map[int64]int64{1: 0, 2: 0, 3: 1, 4: 1}
+
+	for range 20 {
+		teams, err := generateTeams(players, 2, TeamsRandom, nil, previous)
+		if err != nil {
+			t.Fatalf("failed to generate teams: %v", err)
+		}
+		if cost := scoreTeams(teams, nil, previous); cost.repeats != 0 {
+			t.Fatalf("expected no repeated teammates, got %v", teams)
+		}
+	}
+}
+
+func TestGenerateTeams_Balanced(t *testing.T) {
+	players := []generated.Player{{ID: 1}, {ID: 2}, {ID: 3}, {ID: 4}, {ID: 5}, {ID: 6}}
+	wins := map[int64]int64{1: 6, 2: 5, 3: 3, 4: 2, 5: 2}
+	// 1 and 4 were teammates, a balanced split should still keep them apart when it can
+	previous := map[int64]int64{1: 0, 4: 0, 2: 1, 3: 1}
+
+	for range 20 {
+		teams, err := generateTeams(players, 2, TeamsBalanced, wins, previous)
+		if err != nil {
+			t.Fatalf("failed to generate teams: %v", err)
+		}
+		cost := scoreTeams(teams, wins, previous)
+		if cost.imbalance != 0 || cost.repeats != 0 {
+			t.Fatalf("expected 9 wins per team without repeats, got %v (%+v)", teams, cost)
+		}
+	}
+}
+
+func TestGenerateTeams_Stored(t *testing.T) {
+	t.Cleanup(func() {
+		ctx := context.Background()
+		draft, err := testDB.Queries.GetActiveDraft(ctx)
+		if err != nil {
+			t.Fatalf("failed to get active draft: %v", err)
+		}
+		if err := testDB.Writes.DeleteTeamsForDraft(ctx, draft.ID); err != nil {
+			t.Fatalf("failed to clear teams: %v", err)
+		}
+	})
+
+	teams, err := testC.GenerateTeams(testGuildID, 3, TeamsBalanced)
+	if err != nil {
+		t.Fatalf("failed to generate teams: %v", err)
+	}
+	stored, err := testC.GetTeamsForActiveDraft(testGuildID)
+	if err != nil {
+		t.Fatalf("failed to get teams: %v", err)
+	}
+	if len(teams) != 3 || len(stored) != 3 {
+		t.Fatalf("expected 3 teams, generated %d and stored %d", len(teams), len(stored))
+	}
+	for i, team := range stored {
+		if team.Number != i+1 || len(team.Players) != 6 {
+			t.Fatalf("expected team %d of 6 players, got team %d of %d", i+1, team.Number, len(team.Players))
+		}
+		if team.Wins != teams[i].Wins {
+			t.Fatalf("expected team %d to have %d wins, got %d", team.Number, teams[i].Wins, team.Wins)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package ci6ndex
+
+import (
+	"ci6ndex/ci6ndex/generated"
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"time"
+)
+
+// TierListOptions controls which leaders and which ratings make up a tier list.
+type TierListOptions struct {
+	// BBG builds the list from ranks submitted under the BBG ruleset and includes leaders that only exist in BBG
+	// Expanded. Otherwise only vanilla ranks are used.
+	BBG bool
+	// PlayerID builds the list from a single player's submitted ranks instead of
+	// the community tiers. 0 means community.
+	PlayerID int64
+}
+
+// TierListRow is every leader that falls into a single tier.
+type TierListRow struct {
+	Tier    Tier
+	Leaders []generated.Leader
+}
+
+// tierListOrder is the display order of a tier list, best tier first.
+var tierListOrder = []Tier{S, A, B, C, F, Unranked}
+
+// GetTierList groups the guild's leaders by tier, best tier first. Leaders keep
+// the alphabetical order of GetLeaders within a row and empty rows are kept so
+// the list always has the same shape.
+//
+// The community list uses the community tiers of the selected ruleset, leaders
+// without one under it are Unranked.
+func (c *Ci6ndex) GetTierList(guildID uint64, opts TierListOptions) ([]TierListRow, error) {
+	leaders, err := c.GetLeaders(guildID)
+	if err != nil {
+		return nil, err
+	}
+	db, err := c.getDB(guildID)
+	if err != nil {
+		return nil, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	var tiers map[int64]float64
+	if opts.PlayerID != 0 {
+		ranks, err := db.Queries.GetRanksForPlayer(ctx, opts.PlayerID)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.Join(err, errors.New("failed to query ranks"))
+		}
+		tiers = averageTiers(ranks, opts.BBG)
+	} else if tiers, err = communityTiers(ctx, db, leaders, opts.BBG); err != nil {
+		return nil, err
+	}
+
+	byTier := make(map[float64][]generated.Leader)
+	for _, l := range leaders {
+		if l.BbgExpanded && !opts.BBG {
+			continue
+		}
+		// leaders without a tier are 0, unranked
+		tier, err := GetTierByValue(tiers[l.ID])
+		if err != nil {
+			return nil, err
+		}
+		byTier[tier.Value()] = append(byTier[tier.Value()], l)
+	}
+
+	rows := make([]TierListRow, len(tierListOrder))
+	for i, t := range tierListOrder {
+		rows[i] = TierListRow{Tier: t, Leaders: byTier[t.Value()]}
+	}
+	return rows, nil
+}
+
+// DefaultsToBBG reports whether a tier list without a chosen ruleset should use BBG, which is only when the player
+// has ranked leaders under BBG and none under vanilla. The community list always has the vanilla tiers to show, so
+// a playerID of 0 is always vanilla.
+func (c *Ci6ndex) DefaultsToBBG(guildID uint64, playerID int64) (bool, error) {
+	if playerID == 0 {
+		return false, nil
+	}
+	db, err := c.getDB(guildID)
+	if err != nil {
+		return false, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+
+	ranks, err := db.Queries.GetRanksForPlayer(ctx, playerID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return false, errors.Join(err, errors.New("failed to query ranks"))
+	}
+	return len(ranks) > 0 && !slices.ContainsFunc(ranks, func(r generated.Rank) bool { return !r.Bbg }), nil
+}
+
+// communityTiers is every leader's community tier under a ruleset, leaders without one are left out. The vanilla
+// tiers are the ones CalculateTiers stores on the leaders. BBG tiers aren't stored but averaged from the BBG ranks,
+// so leaders nobody ranked under BBG have none.
+func communityTiers(ctx context.Context, db *DB, leaders []generated.Leader, bbg bool) (map[int64]float64, error) {
+	tiers := make(map[int64]float64, len(leaders))
+	if bbg {
+		ranks, err := db.Queries.GetRanksForRuleset(ctx, true)
+		if err != nil && !errors.Is(err, sql.ErrNoRows) {
+			return nil, errors.Join(err, errors.New("failed to query ranks"))
+		}
+		tiers = averageTiers(ranks, true)
+	}
+	for _, l := range leaders {
+		switch {
+		case l.Unranked:
+			delete(tiers, l.ID)
+		case !bbg:
+			tiers[l.ID] = l.Tier
+		}
+	}
+	return tiers, nil
+}
+
+// averageTiers averages the ranks of every leader that were submitted under the BBG or the vanilla ruleset.
+func averageTiers(ranks []generated.Rank, bbg bool) map[int64]float64 {
+	sums := make(map[int64]float64)
+	counts := make(map[int64]int)
+	for _, r := range ranks {
+		if r.Bbg != bbg {
+			continue
+		}
+		sums[r.LeaderID] += r.Tier
+		counts[r.LeaderID]++
+	}
+	for id, n := range counts {
+		sums[id] /= float64(n)
+	}
+	return sums
+}
@@ -0,0 +1,165 @@
+package ci6ndex
+
+import (
+	"testing"
+)
+
+// tierListTier finds which row of a tier list a leader is in, the zero Tier if it isn't listed.
+func tierListTier(rows []TierListRow, leaderID int64) Tier {
+	for _, row := range rows {
+		for _, l := range row.Leaders {
+			if l.ID == leaderID {
+				return row.Tier
+			}
+		}
+	}
+	return Tier{}
+}
+
+func TestGetTierList_Grouping(t *testing.T) {
+	leaders, err := testC.GetLeaders(testGuildID)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expanded := 0
+	for _, l := range leaders {
+		if l.BbgExpanded {
+			expanded++
+		}
+	}
+	if expanded == 0 {
+		t.Fatal("expected the seeded BBG Expanded leaders")
+	}
+
+	for _, bbg := range []bool{false, true} {
+		rows, err := testC.GetTierList(testGuildID, TierListOptions{BBG: bbg})
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(rows) != len(tierListOrder) {
+			t.Fatalf("expected a row for every tier, got %d", len(rows))
+		}
+		seen := make(map[int64]bool)
+		for i, row := range rows {
+			if row.Tier != tierListOrder[i] {
+				t.Fatalf("expected row %d to be %s, got %s", i, tierListOrder[i].Name(), row.Tier.Name())
+			}
+			for _, l := range row.Leaders {
+				if seen[l.ID] {
+					t.Fatalf("leader %d listed twice", l.ID)
+				}
+				seen[l.ID] = true
+				if l.BbgExpanded && !bbg {
+					t.Fatalf("BBG Expanded leader %d listed in the vanilla tier list", l.ID)
+				}
+			}
+		}
+		want := len(leaders)
+		if !bbg {
+			want -= expanded
+		}
+		if len(seen) != want {
+			t.Fatalf("expected %d leaders with bbg=%v, got %d", want, bbg, len(seen))
+		}
+	}
+}
+
+func TestGetTierList_Ruleset(t *testing.T) {
+	const playerID int64 = 1017
+	const bothRulesets, bbgOnly, notRankedUnderBBG int64 = 60, 62, 1
+	if err := testC.SubmitRankForPlayer(testGuildID, "S", playerID, bothRulesets, false); err != nil {
+		t.Fatalf("failed to submit rank: %v", err)
+	}
+	if err := testC.SubmitRankForPlayer(testGuildID, "F", playerID, bothRulesets, true); err != nil {
+		t.Fatalf("failed to submit rank: %v", err)
+	}
+	if err := testC.SubmitRankForPlayer(testGuildID, "A", playerID, bbgOnly, true); err != nil {
+		t.Fatalf("failed to submit rank: %v", err)
+	}
+	for _, id := range []int64{bothRulesets, bbgOnly} {
+		if err := testC.CalculateTierForLeader(testGuildID, id); err != nil {
+			t.Fatalf("failed to calculate tier: %v", err)
+		}
+	}
+	leader, err := testDB.Queries.GetLeaderById(t.Context(), bbgOnly)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	communityTier, err := GetTierForLeader(leader)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		name      string
+		opts      TierListOptions
+		both, bbg Tier
+		// wantRanked is how many leaders a personal list places in a tier
+		wantRanked int
+	}{
+		{name: "community vanilla", opts: TierListOptions{}, both: S, bbg: *communityTier},
+		{name: "community bbg", opts: TierListOptions{BBG: true}, both: F, bbg: A},
+		{name: "personal vanilla", opts: TierListOptions{PlayerID: playerID}, both: S, bbg: Unranked,
+			wantRanked: 1},
+		{name: "personal bbg", opts: TierListOptions{BBG: true, PlayerID: playerID}, both: F, bbg: A,
+			wantRanked: 2},
+	}
+	for _, tt := range tests {
+		rows, err := testC.GetTierList(testGuildID, tt.opts)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if got := tierListTier(rows, bothRulesets); got != tt.both {
+			t.Errorf("%s: expected leader %d in %s, got %s", tt.name, bothRulesets, tt.both.Name(), got.Name())
+		}
+		if got := tierListTier(rows, bbgOnly); got != tt.bbg {
+			t.Errorf("%s: expected leader %d in %s, got %s", tt.name, bbgOnly, tt.bbg.Name(), got.Name())
+		}
+		// nobody ranks Abe under BBG, so the vanilla tier mustn't fill in for it
+		if got := tierListTier(rows, notRankedUnderBBG); tt.opts.BBG && got != Unranked {
+			t.Errorf("%s: expected leader %d to be unranked, got %s", tt.name, notRankedUnderBBG, got.Name())
+		}
+		if tt.opts.PlayerID == 0 {
+			continue
+		}
+		ranked := 0
+		for _, row := range rows {
+			if row.Tier != Unranked {
+				ranked += len(row.Leaders)
+			}
+		}
+		if ranked != tt.wantRanked {
+			t.Errorf("%s: expected only the player's %d ranks to be placed, got %d", tt.name, tt.wantRanked, ranked)
+		}
+	}
+}
+
+func TestDefaultsToBBG(t *testing.T) {
+	const vanillaPlayer, bbgPlayer int64 = 1017, 1018
+	if err := testC.SubmitRankForPlayer(testGuildID, "S", vanillaPlayer, 63, false); err != nil {
+		t.Fatalf("failed to submit rank: %v", err)
+	}
+	if err := testC.SubmitRankForPlayer(testGuildID, "A", bbgPlayer, 63, true); err != nil {
+		t.Fatalf("failed to submit rank: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		playerID int64
+		want     bool
+	}{
+		{name: "community", playerID: 0, want: false},
+		{name: "vanilla ranks", playerID: vanillaPlayer, want: false},
+		{name: "only bbg ranks", playerID: bbgPlayer, want: true},
+		{name: "no ranks", playerID: 1019, want: false},
+	}
+	for _, tt := range tests {
+		got, err := testC.DefaultsToBBG(testGuildID, tt.playerID)
+		if err != nil {
+			t.Fatalf("%s: unexpected error: %v", tt.name, err)
+		}
+		if got != tt.want {
+			t.Errorf("%s: expected %v, got %v", tt.name, tt.want, got)
+		}
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"ci6ndex/ci6ndex/generated"
 	"fmt"
 	"math"
+	"strings"
 )
 
 type Tier struct {
@@ -18,6 +19,11 @@ func (t *Tier) Value() float64 {
 	return t.value
 }
 
+// Letter is the name without its emoji, e.g. S or Unranked.
+func (t *Tier) Letter() string {
+	return strings.Fields(t.name)[0]
+}
+
 var (
 	S = Tier{
 		name:  "S 🏆",
@@ -90,3 +96,20 @@ func GetTierForLeader(leader generated.Leader) (*Tier, error) {
 	}
 	return GetTierByValue(leader.Tier)
 }
+
+// TierLetter is the letter of the tier closest to value, e.g. S or Unranked, and ? for a value outside every tier.
+func TierLetter(value float64) string {
+	tier, err := GetTierByValue(value)
+	if err != nil {
+		return "?"
+	}
+	return tier.Letter()
+}
+
+// LeaderTierLetter is the letter of the leader's community tier, e.g. S or Unranked.
+func LeaderTierLetter(l generated.Leader) string {
+	if l.Unranked {
+		return Unranked.Letter()
+	}
+	return TierLetter(l.Tier)
+}
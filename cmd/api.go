@@ -0,0 +1,27 @@
+package cmd
+
+import (
+	"ci6ndex/api"
+	"ci6ndex/ci6ndex"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+type ApiServeCommand struct {
+	Addr   string   `help:"Serve the API on this address." env:"API_ADDR" default:":8081"`
+	Guilds []uint64 `help:"Guilds whose data is served, others are not found." env:"GUILD_IDS" required:""`
+	Token  string   `help:"Bearer token of the write endpoints, they are disabled when empty." env:"API_TOKEN"`
+}
+type Api struct {
+	Serve ApiServeCommand `cmd:"" help:"Serve the REST/JSON API, documented at /openapi.yaml"`
+}
+
+func (s *ApiServeCommand) Run(c *ci6ndex.Ci6ndex, timeout shutdownTimeout) error {
+	srv := api.NewHTTPServer(s.Addr, api.New(c, s.Guilds, s.Token))
+	slog.Info("Serving the API", "addr", s.Addr, "writes", s.Token != "")
+	if err := serveHTTP(srv, c, time.Duration(timeout)); err != nil {
+		return errors.Join(errors.New("failed to serve the api"), err)
+	}
+	return nil
+}
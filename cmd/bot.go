@@ -2,13 +2,18 @@ package cmd
 
 import (
 	"ci6ndex/bot"
+	"context"
 	"errors"
+	"log/slog"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 )
 
-type ServeCommand struct{}
+type ServeCommand struct {
+	HTTPAddr string `help:"Serve /healthz, /readyz and /metrics on this address, e.g. :8080. Off when empty." env:"HTTP_ADDR"`
+}
 type SyncCommand struct{}
 type Bot struct {
 	Serve ServeCommand `cmd:"" help:"Start the Discord Bot"`
@@ -25,6 +30,16 @@ func (s *ServeCommand) Run(b *bot.Bot) error {
 	if err != nil {
 		return errors.Join(errors.New("failed to start the discord bot"), err)
 	}
+	if s.HTTPAddr != "" {
+		srv := bot.NewHealthServer(s.HTTPAddr, b.Ready)
+		go func() {
+			slog.Info("Serving health checks and metrics", "addr", s.HTTPAddr)
+			if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				slog.Error("health server stopped", "error", err)
+			}
+		}()
+		defer srv.Shutdown(context.Background())
+	}
 
 	sig := make(chan os.Signal, 1)
 	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
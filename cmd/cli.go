@@ -2,25 +2,62 @@ package cmd
 
 import (
 	"ci6ndex/bot"
+	"ci6ndex/ci6ndex"
 	"errors"
+	"io"
+	"os"
+	"time"
+
 	"github.com/alecthomas/kong"
 )
 
 type CLI struct {
-	Bot Bot `cmd:"" help:"Perform bot actions."`
+	Bot     Bot        `cmd:"" help:"Perform bot actions."`
+	Api     Api        `cmd:"" help:"Serve the ci6ndex data over HTTP."`
+	Web     WebCommand `cmd:"" help:"Serve the read-only web dashboard."`
+	Leaders Leaders    `cmd:"" help:"List, show and ban leaders."`
+	Drafts  Drafts     `cmd:"" help:"List and show drafts."`
+	Roll    Roll       `cmd:"" help:"Roll offerings for the players of the active draft."`
+	Ranks   Ranks      `cmd:"" help:"Submit ranks for players."`
+	Tiers   Tiers      `cmd:"" help:"Recalculate the community tiers."`
 }
 
-func Exec(b *bot.Bot) error {
+// BotProvider connects the bot to Discord. Only the commands that need Discord call it, the others run without a
+// token.
+type BotProvider func() (*bot.Bot, error)
+
+// shutdownTimeout is how long the servers wait for in-flight requests when interrupted.
+type shutdownTimeout time.Duration
+
+func Exec(c *ci6ndex.Ci6ndex, newBot BotProvider, timeout time.Duration) error {
 	cli := CLI{}
-	ctx := kong.Parse(&cli,
-		kong.Name("ci6ndex"),
-		kong.Description("Ci6ndex Management CLI."),
-		kong.UsageOnError(),
-	)
+	parser, err := newParser(&cli, c, newBot, timeout, os.Stdout)
+	if err != nil {
+		return err
+	}
+	ctx, err := parser.Parse(os.Args[1:])
+	parser.FatalIfErrorf(err)
 
-	err := ctx.Run(b)
+	err = ctx.Run()
 	if err != nil {
-		ctx.FatalIfErrorf(errors.Join(err, errors.New("failed to run bot")))
+		ctx.FatalIfErrorf(errors.Join(err, errors.New("failed to run command")))
 	}
 	return nil
 }
+
+// newParser binds what the commands run with: the domain layer, the lazily connected bot, the shutdown timeout and
+// where to print to.
+func newParser(cli *CLI, c *ci6ndex.Ci6ndex, newBot BotProvider, timeout time.Duration,
+	out io.Writer) (*kong.Kong, error) {
+	return kong.New(cli,
+		kong.Name("ci6ndex"),
+		kong.Description("Ci6ndex Management CLI."),
+		kong.UsageOnError(),
+		kong.Writers(out, os.Stderr),
+		kong.Bind(c, shutdownTimeout(timeout)),
+		kong.BindTo(out, (*io.Writer)(nil)),
+		kong.BindSingletonProvider(func() (*bot.Bot, error) {
+			return newBot()
+		}),
+	)
+}
@@ -0,0 +1,270 @@
+package cmd
+
+import (
+	"bytes"
+	"ci6ndex/bot"
+	"ci6ndex/ci6ndex"
+	"ci6ndex/ci6ndex/generated"
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	goose "github.com/pressly/goose/v3"
+)
+
+const testGuild = "999999"
+
+var errNoDiscord = errors.New("no discord in tests")
+
+func newTestCi6ndex(t *testing.T) *ci6ndex.Ci6ndex {
+	t.Helper()
+	goose.SetBaseFS(os.DirFS(".."))
+	goose.SetLogger(goose.NopLogger())
+	if err := goose.SetDialect("sqlite3"); err != nil {
+		t.Fatal(err)
+	}
+	c := &ci6ndex.Ci6ndex{Connections: make(map[uint64]*ci6ndex.DB), Path: t.TempDir() + "/"}
+	t.Cleanup(c.Close)
+	return c
+}
+
+// run runs args like the binary would, with a bot that can't connect to Discord.
+func run(t *testing.T, c *ci6ndex.Ci6ndex, args ...string) (string, error) {
+	t.Helper()
+	var out bytes.Buffer
+	parser, err := newParser(&CLI{}, c, func() (*bot.Bot, error) {
+		return nil, errNoDiscord
+	}, time.Second, &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx, err := parser.Parse(args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = ctx.Run()
+	return out.String(), err
+}
+
+func mustRun(t *testing.T, c *ci6ndex.Ci6ndex, args ...string) string {
+	t.Helper()
+	out, err := run(t, c, args...)
+	if err != nil {
+		t.Fatalf("%v: %v", args, err)
+	}
+	return out
+}
+
+func TestBotCommandsNeedDiscord(t *testing.T) {
+	c := newTestCi6ndex(t)
+	if _, err := run(t, c, "bot", "sync"); !errors.Is(err, errNoDiscord) {
+		t.Errorf("expected bot sync to connect to discord, got %v", err)
+	}
+}
+
+func TestLeaders(t *testing.T) {
+	c := newTestCi6ndex(t)
+	if _, err := run(t, c, "leaders", "list", "--guild", testGuild); err == nil {
+		t.Fatal("expected a guild without a database to fail")
+	}
+	if guilds, err := c.Guilds(); err != nil || len(guilds) != 0 {
+		t.Fatalf("expected the command to not create a database, got %v %v", guilds, err)
+	}
+	if _, err := c.GetLeaders(999999); err != nil {
+		t.Fatal(err)
+	}
+
+	out := mustRun(t, c, "leaders", "list", "--guild", testGuild, "--json")
+	var leaders []leaderOutput
+	if err := json.Unmarshal([]byte(out), &leaders); err != nil {
+		t.Fatal(err)
+	}
+	if len(leaders) == 0 {
+		t.Fatal("expected the seeded leaders")
+	}
+	id := strconv.FormatInt(leaders[0].ID, 10)
+
+	mustRun(t, c, "leaders", "ban", id, "--guild", testGuild)
+	out = mustRun(t, c, "leaders", "list", "--guild", testGuild, "--banned")
+	if lines := strings.Split(strings.TrimSpace(out), "\n"); len(lines) != 2 ||
+		!strings.Contains(lines[1], leaders[0].LeaderName) {
+		t.Errorf("expected only the banned leader to be listed, got\n%s", out)
+	}
+	mustRun(t, c, "leaders", "ban", id, "--unban", "--guild", testGuild)
+	if out := mustRun(t, c, "leaders", "list", "--guild", testGuild, "--banned", "--json"); out != "[]\n" {
+		t.Errorf("expected no banned leaders, got %s", out)
+	}
+
+	_, err := run(t, c, "leaders", "show", "100000", "--guild", testGuild)
+	if !errors.Is(err, ci6ndex.ErrLeaderNotFound) {
+		t.Errorf("expected an unknown leader to not be found, got %v", err)
+	}
+}
+
+func TestRanksAndTiers(t *testing.T) {
+	c := newTestCi6ndex(t)
+	err := c.AddPlayer(context.Background(), 999999, generated.AddPlayerParams{ID: 1, Username: "host"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaders, err := c.GetLeaders(999999)
+	if err != nil {
+		t.Fatal(err)
+	}
+	id := strconv.FormatInt(leaders[0].ID, 10)
+
+	out := mustRun(t, c, "ranks", "submit", "--guild", testGuild, "--player", "1", "--leader", id, "--tier", "S",
+		"--json")
+	var leader leaderOutput
+	if err := json.Unmarshal([]byte(out), &leader); err != nil {
+		t.Fatal(err)
+	}
+	if leader.Tier != "S" {
+		t.Errorf("expected the leader to be S tier after the only rank, got %s", leader.Tier)
+	}
+	if _, err := run(t, c, "ranks", "submit", "--guild", testGuild, "--player", "2", "--leader", id,
+		"--tier", "S"); err == nil {
+		t.Error("expected unregistered players to not be able to rank")
+	}
+
+	out = mustRun(t, c, "leaders", "show", id, "--guild", testGuild)
+	if !strings.Contains(out, "S by host") {
+		t.Errorf("expected the rank to be shown, got\n%s", out)
+	}
+	out = mustRun(t, c, "tiers", "recalc", "--guild", testGuild)
+	if lines := strings.Split(out, "\n"); !strings.HasPrefix(lines[1], "S ") ||
+		!strings.Contains(lines[1], ci6ndex.LeaderName(leaders[0])) {
+		t.Errorf("expected the leader in the S row, got\n%s", out)
+	}
+}
+
+func TestRollAndDrafts(t *testing.T) {
+	c := newTestCi6ndex(t)
+	draft, err := c.GetOrCreateActiveDraft(999999)
+	if err != nil {
+		t.Fatal(err)
+	}
+	players := []generated.AddPlayerParams{{ID: 1, Username: "host"}, {ID: 2, Username: "player"}}
+	for _, p := range players {
+		if err := c.AddPlayer(context.Background(), 999999, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if errs := c.SetPlayersForDraft(999999, draft.ID, players); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+
+	out := mustRun(t, c, "roll", "--guild", testGuild, "--dry-run", "--json")
+	var offerings []offeringOutput
+	if err := json.Unmarshal([]byte(out), &offerings); err != nil {
+		t.Fatal(err)
+	}
+	if len(offerings) != 2 || len(offerings[0].LeaderIDs) == 0 {
+		t.Fatalf("expected an offering per player, got %+v", offerings)
+	}
+	record, err := c.GetDraft(999999, draft.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(record.Offerings) != 0 {
+		t.Errorf("expected a dry run to not record offerings, got %d", len(record.Offerings))
+	}
+
+	mustRun(t, c, "roll", "--guild", testGuild, "--players", "1")
+	if record, err = c.GetDraft(999999, draft.ID); err != nil {
+		t.Fatal(err)
+	}
+	if len(record.Offerings) == 0 || record.Offerings[0].PlayerID != 1 {
+		t.Errorf("expected the offerings of player 1 to be recorded, got %+v", record.Offerings)
+	}
+	if _, err := run(t, c, "roll", "--guild", testGuild, "--players", "3", "--dry-run"); err == nil {
+		t.Error("expected rolling for unregistered players to fail")
+	}
+	if err := c.AddPlayer(context.Background(), 999999, generated.AddPlayerParams{ID: 3, Username: "late"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := run(t, c, "roll", "--guild", testGuild, "--players", "3"); err == nil {
+		t.Error("expected recording offerings for players outside the active draft to fail")
+	}
+	mustRun(t, c, "roll", "--guild", testGuild, "--players", "3", "--dry-run")
+
+	out = mustRun(t, c, "drafts", "list", "--guild", testGuild)
+	if lines := strings.Split(out, "\n"); !slices.Equal(strings.Fields(lines[1]),
+		[]string{strconv.FormatInt(draft.ID, 10), "yes", "random"}) {
+		t.Errorf("expected the active draft to be listed, got\n%s", out)
+	}
+	out = mustRun(t, c, "drafts", "show", strconv.FormatInt(draft.ID, 10), "--guild", testGuild)
+	if !strings.Contains(out, "random") || !strings.Contains(out, "host") {
+		t.Errorf("expected the rolled draft to be shown, got\n%s", out)
+	}
+
+	if _, err := c.GenerateTeams(999999, 2, ci6ndex.TeamsRandom); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := run(t, c, "roll", "--guild", testGuild); err == nil {
+		t.Error("expected recording offerings without the draft's teams to fail")
+	}
+	mustRun(t, c, "roll", "--guild", testGuild, "--dry-run")
+}
+
+func TestRollSimulate(t *testing.T) {
+	c := newTestCi6ndex(t)
+	if _, err := run(t, c, "roll", "simulate", "--guild", testGuild, "--players", "2"); err == nil {
+		t.Fatal("expected simulating for a guild without a database to fail")
+	}
+	if guilds, err := c.Guilds(); err != nil || len(guilds) != 0 {
+		t.Fatalf("expected the simulation to not create a database, got %v %v", guilds, err)
+	}
+	if _, err := c.GetOrCreateActiveDraft(999999); err != nil {
+		t.Fatal(err)
+	}
+
+	out := mustRun(t, c, "roll", "simulate", "--guild", testGuild, "--players", "4", "--rolls", "100",
+		"--pool-size", "3", "--min-tier", "off", "--json")
+	var sim simulationOutput
+	if err := json.Unmarshal([]byte(out), &sim); err != nil {
+		t.Fatal(err)
+	}
+	if sim.Rolls != 100 || sim.Failures != 0 || sim.Settings.PoolSize != 3 || sim.Settings.MinTier != "off" {
+		t.Errorf("expected 100 successful rolls with pools of 3 and no min tier, got %+v", sim)
+	}
+	perPool := 0.0
+	for _, tier := range sim.Tiers {
+		perPool += tier.PerPool
+	}
+	if math.Abs(perPool-3) > 1e-9 || sim.MaxPlayers == 0 {
+		t.Errorf("expected pools of 3 leaders and a max player count, got %+v %d", sim.Tiers, sim.MaxPlayers)
+	}
+	record, err := c.GetDraft(999999, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(record.Offerings) != 0 {
+		t.Errorf("expected the simulation to not record offerings, got %+v", record.Offerings)
+	}
+
+	out = mustRun(t, c, "roll", "simulate", "--guild", testGuild, "--players", "2", "--rolls", "10", "--top", "2")
+	if !strings.Contains(out, "Max players") || !strings.Contains(out, "...") {
+		t.Errorf("expected a summary and the top and bottom leaders, got\n%s", out)
+	}
+	out = mustRun(t, c, "roll", "simulate", "--guild", testGuild, "--players", "2", "--rolls", "10",
+		"--player-tag", "naval")
+	if !strings.Contains(out, "play style naval") {
+		t.Errorf("expected the play-style guarantee in the settings, got\n%s", out)
+	}
+	if _, err := run(t, c, "roll", "simulate", "--guild", testGuild, "--players", "2",
+		"--player-tag", "vibes"); err == nil {
+		t.Error("expected an unknown play-style tag to fail")
+	}
+	if _, err := run(t, c, "roll", "simulate", "--guild", testGuild, "--players", "2",
+		"--pool-size", "20"); err == nil {
+		t.Error("expected invalid settings to fail")
+	}
+}
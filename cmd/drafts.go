@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"ci6ndex/ci6ndex"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type DraftsListCommand struct {
+	GuildFlag   `embed:""`
+	OutputFlags `embed:""`
+}
+
+type DraftsShowCommand struct {
+	GuildFlag   `embed:""`
+	OutputFlags `embed:""`
+	Draft       int64 `arg:"" help:"ID of the draft."`
+}
+
+type Drafts struct {
+	List DraftsListCommand `cmd:"" help:"List the drafts, newest first."`
+	Show DraftsShowCommand `cmd:"" help:"Show the players, offerings, picks and winner of a draft."`
+}
+
+// draftOutput is a draft as printed with --json, the winner is the player ID or 0 while undecided.
+type draftOutput struct {
+	ID         int64      `json:"id"`
+	Active     bool       `json:"active"`
+	Mode       string     `json:"mode"`
+	WinnerID   int64      `json:"winner_id,omitempty"`
+	RecordedAt *time.Time `json:"recorded_at,omitempty"`
+}
+
+type draftPlayerOutput struct {
+	ID       int64   `json:"id"`
+	Username string  `json:"username"`
+	Offered  []int64 `json:"offered"`
+	Picked   int64   `json:"picked,omitempty"`
+}
+
+func newDraftOutput(s ci6ndex.DraftSummary) draftOutput {
+	o := draftOutput{ID: s.Draft.ID, Active: s.Draft.Active, Mode: s.Draft.Mode}
+	if s.Result != nil {
+		// winner is the player ID as text, see 015_draft_results.sql
+		o.WinnerID, _ = strconv.ParseInt(s.Result.Winner, 10, 64)
+		o.RecordedAt = &s.Result.RecordedAt
+	}
+	return o
+}
+
+func (d *DraftsListCommand) Run(c *ci6ndex.Ci6ndex, out io.Writer) error {
+	if err := d.check(c); err != nil {
+		return err
+	}
+	drafts, err := c.GetDrafts(d.Guild)
+	if err != nil {
+		return err
+	}
+	list := make([]draftOutput, len(drafts))
+	t := table{header: []string{"ID", "ACTIVE", "MODE", "WINNER", "RECORDED"}}
+	for i, s := range drafts {
+		list[i] = newDraftOutput(s)
+		winner, recorded := "", ""
+		if s.Result != nil {
+			winner, recorded = s.Result.Winner, s.Result.RecordedAt.Format(time.DateOnly)
+		}
+		t.add(s.Draft.ID, yesNo(s.Draft.Active), s.Draft.Mode, winner, recorded)
+	}
+	return d.print(out, list, t)
+}
+
+func (d *DraftsShowCommand) Run(c *ci6ndex.Ci6ndex, out io.Writer) error {
+	if err := d.check(c); err != nil {
+		return err
+	}
+	record, err := c.GetDraft(d.Guild, d.Draft)
+	if err != nil {
+		return err
+	}
+	leaders, err := c.GetLeaders(d.Guild)
+	if err != nil {
+		return err
+	}
+	names := make(map[int64]string, len(leaders))
+	for _, l := range leaders {
+		names[l.ID] = ci6ndex.LeaderName(l)
+	}
+
+	details := struct {
+		draftOutput
+		Players []draftPlayerOutput `json:"players"`
+	}{draftOutput: newDraftOutput(record.DraftSummary), Players: make([]draftPlayerOutput, len(record.Players))}
+	t := table{header: []string{"PLAYER", "OFFERED", "PICKED"}}
+	for i, p := range record.Players {
+		player := draftPlayerOutput{ID: p.ID, Username: ci6ndex.PlayerName(p), Offered: make([]int64, 0)}
+		offered := make([]string, 0)
+		for _, o := range record.Offerings {
+			if o.PlayerID == p.ID {
+				player.Offered = append(player.Offered, o.Leader)
+				offered = append(offered, names[o.Leader])
+			}
+		}
+		for _, pick := range record.Picks {
+			// player is the player ID as text, like the winner of a draft
+			if pick.Player == strconv.FormatInt(p.ID, 10) {
+				player.Picked = pick.Pick
+			}
+		}
+		details.Players[i] = player
+		name := player.Username
+		if p.ID == details.WinnerID {
+			name += " (won)"
+		}
+		t.add(name, strings.Join(offered, ", "), names[player.Picked])
+	}
+	if !d.JSON {
+		if _, err := fmt.Fprintf(out, "Draft %d (%s)\n", record.Draft.ID, record.Draft.Mode); err != nil {
+			return err
+		}
+	}
+	return d.print(out, details, t)
+}
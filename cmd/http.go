@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"ci6ndex/ci6ndex"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// serveHTTP serves srv until it fails or the process is interrupted, then waits up to timeout for the requests in
+// flight and flushes and closes the databases they opened.
+func serveHTTP(srv *http.Server, c *ci6ndex.Ci6ndex, timeout time.Duration) error {
+	errs := make(chan error, 1)
+	go func() {
+		errs <- srv.ListenAndServe()
+	}()
+
+	sig := make(chan os.Signal, 1)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
+	select {
+	case err := <-errs:
+		return err
+	case <-sig:
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	if err := srv.Shutdown(ctx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		slog.Error("abandoned in-flight requests", "error", err)
+	}
+	flushCtx, cancelFlush := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelFlush()
+	for _, err := range c.Flush(flushCtx) {
+		slog.Error("failed to flush database", "error", err)
+	}
+	c.Close()
+	return nil
+}
@@ -0,0 +1,174 @@
+package cmd
+
+import (
+	"ci6ndex/ci6ndex"
+	"errors"
+	"fmt"
+	"io"
+)
+
+type LeadersListCommand struct {
+	GuildFlag   `embed:""`
+	OutputFlags `embed:""`
+	Sort        string `help:"Order of the leaders." enum:"name,tier,ratings,winrate" default:"name"`
+	Banned      bool   `help:"Only list banned leaders."`
+}
+
+type LeadersShowCommand struct {
+	GuildFlag   `embed:""`
+	OutputFlags `embed:""`
+	Leader      uint64 `arg:"" help:"ID of the leader."`
+}
+
+type LeadersBanCommand struct {
+	GuildFlag `embed:""`
+	Leader    uint64 `arg:"" help:"ID of the leader."`
+	Unban     bool   `help:"Lift the ban instead."`
+}
+
+type Leaders struct {
+	List LeadersListCommand `cmd:"" help:"List the leaders with their tier and how they've been played."`
+	Show LeadersShowCommand `cmd:"" help:"Show a leader and the ranks players gave it."`
+	Ban  LeadersBanCommand  `cmd:"" help:"Ban a leader from being rolled."`
+}
+
+var leaderSorts = map[string]ci6ndex.LeaderSort{
+	"name":    ci6ndex.SortByName,
+	"tier":    ci6ndex.SortByTier,
+	"ratings": ci6ndex.SortByRatings,
+	"winrate": ci6ndex.SortByWinRate,
+}
+
+// leaderOutput is a leader as printed with --json.
+type leaderOutput struct {
+	ID         int64   `json:"id"`
+	CivName    string  `json:"civ_name"`
+	LeaderName string  `json:"leader_name"`
+	Tier       string  `json:"tier"`
+	TierValue  float64 `json:"tier_value"`
+	Banned     bool    `json:"banned"`
+	Ratings    int64   `json:"ratings"`
+	Picks      int64   `json:"picks"`
+	Wins       int64   `json:"wins"`
+	WinRate    float64 `json:"win_rate"`
+}
+
+type rankOutput struct {
+	PlayerID int64  `json:"player_id"`
+	Username string `json:"username"`
+	Tier     string `json:"tier"`
+	BBG      bool   `json:"bbg"`
+}
+
+func newLeaderOutput(s ci6ndex.LeaderStats) leaderOutput {
+	return leaderOutput{
+		ID:         s.Leader.ID,
+		CivName:    s.Leader.CivName,
+		LeaderName: ci6ndex.LeaderName(s.Leader),
+		Tier:       ci6ndex.LeaderTierLetter(s.Leader),
+		TierValue:  s.Leader.Tier,
+		Banned:     s.Leader.Banned,
+		Ratings:    s.Ratings,
+		Picks:      s.Picks,
+		Wins:       s.Wins,
+		WinRate:    s.WinRate(),
+	}
+}
+
+func (l *LeadersListCommand) Run(c *ci6ndex.Ci6ndex, out io.Writer) error {
+	if err := l.check(c); err != nil {
+		return err
+	}
+	q := ci6ndex.LeaderQuery{Sort: leaderSorts[l.Sort]}
+	if l.Banned {
+		q.Banned.Valid, q.Banned.Bool = true, true
+	}
+	stats, _, err := c.QueryLeaders(l.Guild, q)
+	if err != nil {
+		return errors.Join(err, errors.New("failed to list leaders"))
+	}
+	leaders := make([]leaderOutput, len(stats))
+	t := table{header: []string{"ID", "LEADER", "CIV", "TIER", "BANNED", "PICKS", "WINS", "WIN RATE"}}
+	for i, s := range stats {
+		leaders[i] = newLeaderOutput(s)
+		o := leaders[i]
+		t.add(o.ID, o.LeaderName, o.CivName, o.Tier, yesNo(o.Banned), o.Picks, o.Wins, percent(o.WinRate))
+	}
+	return l.print(out, leaders, t)
+}
+
+func (l *LeadersShowCommand) Run(c *ci6ndex.Ci6ndex, out io.Writer) error {
+	if err := l.check(c); err != nil {
+		return err
+	}
+	leader, err := c.GetLeaderById(l.Guild, l.Leader)
+	if err != nil {
+		return err
+	}
+	stats, _, err := c.QueryLeaders(l.Guild, ci6ndex.LeaderQuery{})
+	if err != nil {
+		return errors.Join(err, errors.New("failed to get leader stats"))
+	}
+	o := newLeaderOutput(ci6ndex.LeaderStats{Leader: leader})
+	for _, s := range stats {
+		if s.Leader.ID == leader.ID {
+			o = newLeaderOutput(s)
+		}
+	}
+	ranks, err := c.GetRanksForLeader(l.Guild, leader.ID)
+	if err != nil {
+		return err
+	}
+
+	details := struct {
+		leaderOutput
+		Ranks []rankOutput `json:"ranks"`
+	}{leaderOutput: o, Ranks: make([]rankOutput, len(ranks))}
+	t := table{rows: [][]string{
+		{"Leader", fmt.Sprintf("%s (%s)", o.LeaderName, o.CivName)},
+		{"Tier", o.Tier},
+		{"Banned", yesNo(o.Banned)},
+		{"Ratings", fmt.Sprint(o.Ratings)},
+		{"Picks", fmt.Sprintf("%d, %d wins (%s)", o.Picks, o.Wins, percent(o.WinRate))},
+	}}
+	for i, r := range ranks {
+		details.Ranks[i] = rankOutput{PlayerID: r.PlayerID, Username: r.Username, Tier: ci6ndex.TierLetter(r.Tier),
+			BBG: r.BBG}
+		if r.BBG {
+			t.add("BBG rank", fmt.Sprintf("%s by %s", details.Ranks[i].Tier, r.Username))
+		} else {
+			t.add("Rank", fmt.Sprintf("%s by %s", details.Ranks[i].Tier, r.Username))
+		}
+	}
+	return l.print(out, details, t)
+}
+
+func (l *LeadersBanCommand) Run(c *ci6ndex.Ci6ndex, out io.Writer) error {
+	if err := l.check(c); err != nil {
+		return err
+	}
+	leader, err := c.GetLeaderById(l.Guild, l.Leader)
+	if err != nil {
+		return err
+	}
+	if err := c.SetLeaderBanned(l.Guild, leader.ID, !l.Unban); err != nil {
+		return err
+	}
+	if l.Unban {
+		_, err = fmt.Fprintf(out, "Unbanned %s\n", ci6ndex.LeaderName(leader))
+	} else {
+		_, err = fmt.Fprintf(out, "Banned %s\n", ci6ndex.LeaderName(leader))
+	}
+	return err
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+func percent(v float64) string {
+	return fmt.Sprintf("%.0f%%", v*100)
+}
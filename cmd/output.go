@@ -0,0 +1,73 @@
+package cmd
+
+import (
+	"ci6ndex/ci6ndex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"text/tabwriter"
+)
+
+// GuildFlag selects the guild a domain command acts on.
+type GuildFlag struct {
+	Guild uint64 `help:"Guild to act on." env:"LISTEN_TO_GUILD_ID" required:""`
+}
+
+// check fails for a guild the bot has no database for. Opening a guild's database creates it, so a mistyped --guild
+// would otherwise leave an empty database behind.
+func (g GuildFlag) check(c *ci6ndex.Ci6ndex) error {
+	guilds, err := c.Guilds()
+	if err != nil {
+		return err
+	}
+	if !slices.Contains(guilds, g.Guild) {
+		return fmt.Errorf("guild %d has no database", g.Guild)
+	}
+	return nil
+}
+
+// OutputFlags lets a command print JSON for scripting instead of a table.
+type OutputFlags struct {
+	JSON bool `help:"Print JSON instead of a table."`
+}
+
+// table is the human readable form of a command's output.
+type table struct {
+	header []string
+	rows   [][]string
+}
+
+func (t *table) add(cells ...any) {
+	row := make([]string, len(cells))
+	for i, c := range cells {
+		row[i] = fmt.Sprint(c)
+	}
+	t.rows = append(t.rows, row)
+}
+
+// print writes v as indented JSON when --json is set, and t as aligned columns otherwise.
+func (o OutputFlags) print(out io.Writer, v any, t table) error {
+	if o.JSON {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	}
+	return t.write(out)
+}
+
+func (t table) write(out io.Writer) error {
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	if len(t.header) > 0 {
+		if _, err := fmt.Fprintln(w, strings.Join(t.header, "\t")); err != nil {
+			return err
+		}
+	}
+	for _, row := range t.rows {
+		if _, err := fmt.Fprintln(w, strings.Join(row, "\t")); err != nil {
+			return err
+		}
+	}
+	return w.Flush()
+}
@@ -0,0 +1,59 @@
+package cmd
+
+import (
+	"ci6ndex/ci6ndex"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+)
+
+type RanksSubmitCommand struct {
+	GuildFlag   `embed:""`
+	OutputFlags `embed:""`
+	Player      int64  `help:"ID of the player ranking the leader." required:""`
+	Leader      uint64 `help:"ID of the leader being ranked." required:""`
+	Tier        string `help:"Tier the player puts the leader in." enum:"S,A,B,C,F" required:""`
+	BBG         bool   `name:"bbg" help:"Rank the leader under the BBG ruleset instead of vanilla."`
+}
+
+type Ranks struct {
+	Submit RanksSubmitCommand `cmd:"" help:"Submit a player's rank of a leader and recalculate the leader's tier."`
+}
+
+func (r *RanksSubmitCommand) Run(c *ci6ndex.Ci6ndex, out io.Writer) error {
+	if err := r.check(c); err != nil {
+		return err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	player, err := c.GetPlayer(ctx, r.Guild, r.Player)
+	if errors.Is(err, sql.ErrNoRows) {
+		return fmt.Errorf("player %d isn't registered in guild %d", r.Player, r.Guild)
+	}
+	if err != nil {
+		return err
+	}
+	leader, err := c.GetLeaderById(r.Guild, r.Leader)
+	if err != nil {
+		return err
+	}
+	if err := c.SubmitRankForPlayer(r.Guild, r.Tier, player.ID, leader.ID, r.BBG); err != nil {
+		return err
+	}
+	if err := c.CalculateTierForLeader(r.Guild, leader.ID); err != nil {
+		return err
+	}
+	// print the leader with its recalculated tier
+	leader, err = c.GetLeaderById(r.Guild, r.Leader)
+	if err != nil {
+		return err
+	}
+	o := newLeaderOutput(ci6ndex.LeaderStats{Leader: leader})
+	t := table{}
+	t.add(fmt.Sprintf("%s ranked %s as %s, the community tier is now %s", ci6ndex.PlayerName(*player), o.LeaderName,
+		r.Tier, o.Tier))
+	return r.print(out, o, t)
+}
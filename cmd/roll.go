@@ -0,0 +1,130 @@
+package cmd
+
+import (
+	"ci6ndex/ci6ndex"
+	"ci6ndex/ci6ndex/generated"
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+	"time"
+)
+
+type RollDraftCommand struct {
+	GuildFlag   `embed:""`
+	OutputFlags `embed:""`
+	Players     []int64 `help:"Players to roll for, the active draft's by default. Players not in it need --dry-run."`
+	DryRun      bool    `help:"Print the offerings without recording them on the active draft."`
+}
+
+type Roll struct {
+	Draft    RollDraftCommand    `cmd:"" default:"withargs" help:"Roll offerings with the guild's roll settings."`
+	Simulate RollSimulateCommand `cmd:"" help:"Roll many times without recording anything to try out rules."`
+}
+
+type offeringOutput struct {
+	PlayerID  int64   `json:"player_id"`
+	Username  string  `json:"username"`
+	LeaderIDs []int64 `json:"leader_ids"`
+	Strength  float64 `json:"strength"`
+}
+
+// Run rolls like the bot's roll button and records the offerings and game settings on the active draft. Players who
+// aren't in the active draft can only be rolled for with --dry-run, and so can drafts with teams, since the offerings
+// are rolled without them.
+func (r *RollDraftCommand) Run(c *ci6ndex.Ci6ndex, out io.Writer) error {
+	if err := r.check(c); err != nil {
+		return err
+	}
+	playerIds, err := r.playerIds(c)
+	if err != nil {
+		return err
+	}
+	settings, err := c.GetRollSettings(r.Guild)
+	if err != nil {
+		return err
+	}
+	roll, err := c.RollBalanced(r.Guild, playerIds, settings.Rules(), settings.Balance())
+	if errors.Is(err, sql.ErrNoRows) {
+		return errors.New("there is no active draft to roll for, start one first")
+	}
+	if err != nil {
+		return errors.Join(err, errors.New("failed to roll for players"))
+	}
+
+	if !r.DryRun {
+		teams, err := c.GetTeamsForActiveDraft(r.Guild)
+		if err != nil {
+			return err
+		}
+		if len(teams) > 0 {
+			return errors.New("the active draft has teams, roll for them from discord or pass --dry-run")
+		}
+		if err := c.SetDraftMode(r.Guild, ci6ndex.DraftModeRandom); err != nil {
+			return err
+		}
+		if _, err := c.RollGameSettings(r.Guild); err != nil {
+			return errors.Join(err, errors.New("failed to roll game settings"))
+		}
+		if err := c.SaveOfferings(r.Guild, roll.Offerings); err != nil {
+			return err
+		}
+	}
+
+	offerings := make([]offeringOutput, len(roll.Offerings))
+	t := table{header: []string{"PLAYER", "LEADERS", "STRENGTH"}}
+	for i, o := range roll.Offerings {
+		offering := offeringOutput{PlayerID: o.Player.ID, Username: ci6ndex.PlayerName(o.Player),
+			LeaderIDs: make([]int64, len(o.Leaders)), Strength: o.Strength}
+		names := make([]string, len(o.Leaders))
+		for j, l := range o.Leaders {
+			offering.LeaderIDs[j] = l.ID
+			names[j] = fmt.Sprintf("%s (%s)", ci6ndex.LeaderName(l), ci6ndex.LeaderTierLetter(l))
+		}
+		offerings[i] = offering
+		t.add(offering.Username, strings.Join(names, ", "), fmt.Sprintf("%g", o.Strength))
+	}
+	return r.print(out, offerings, t)
+}
+
+// playerIds checks every --players is registered, since a roll silently skips players it doesn't know, and that
+// they're in the active draft unless the offerings won't be recorded.
+func (r *RollDraftCommand) playerIds(c *ci6ndex.Ci6ndex) ([]int64, error) {
+	var inDraft []generated.Player
+	if len(r.Players) == 0 || !r.DryRun {
+		var err error
+		if inDraft, err = c.GetPlayersFromActiveDraft(r.Guild); err != nil {
+			return nil, err
+		}
+	}
+	if len(r.Players) == 0 {
+		if len(inDraft) == 0 {
+			return nil, errors.New("the active draft has no players, pass them with --players")
+		}
+		ids := make([]int64, len(inDraft))
+		for i, p := range inDraft {
+			ids[i] = p.ID
+		}
+		return ids, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 3*time.Second)
+	defer cancel()
+	registered, err := c.GetPlayers(ctx, r.Guild)
+	if err != nil {
+		return nil, err
+	}
+	for _, id := range r.Players {
+		hasID := func(p generated.Player) bool { return p.ID == id }
+		if !slices.ContainsFunc(registered, hasID) {
+			return nil, fmt.Errorf("player %d isn't registered in guild %d", id, r.Guild)
+		}
+		if !r.DryRun && !slices.ContainsFunc(inDraft, hasID) {
+			return nil, fmt.Errorf("player %d isn't in the active draft, add them or pass --dry-run", id)
+		}
+	}
+	return r.Players, nil
+}
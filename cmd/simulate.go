@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"ci6ndex/ci6ndex"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+)
+
+// RollSimulateCommand rolls with the guild's roll settings without recording anything. The rule flags that are set
+// override the guild's settings, so changes can be tried out before saving them.
+type RollSimulateCommand struct {
+	GuildFlag     `embed:""`
+	OutputFlags   `embed:""`
+	Players       int      `help:"How many players every roll is for." required:""`
+	Rolls         int      `help:"How many times to roll." default:"10000"`
+	Top           int      `help:"Most and least offered leaders to print, --json prints all." default:"10"`
+	PoolSize      *int     `help:"Leaders offered to every player."`
+	MinTier       *string  `help:"Tier every offering has a leader at or above: S, A, B, C, F or off."`
+	PlayerTag     *string  `help:"Play-style tag every offering has a leader with, e.g. naval, or off."`
+	Diversity     *int     `help:"Categories every offering has to span, 0 is off."`
+	DiversityMode *string  `help:"What the diversity counts, victory or tag."`
+	Balance       *string  `help:"How offerings are balanced, off, sum or best."`
+	Tolerance     *float64 `help:"Widest spread in strength a balanced roll accepts."`
+}
+
+type simulationOutput struct {
+	Players        int             `json:"players"`
+	Rolls          int             `json:"rolls"`
+	Settings       settingsOutput  `json:"settings"`
+	Failures       int             `json:"failures"`
+	FailureRate    float64         `json:"failure_rate"`
+	FailureReasons map[string]int  `json:"failure_reasons"`
+	OutOfTolerance int             `json:"out_of_tolerance"`
+	MaxPlayers     int             `json:"max_players"`
+	Tiers          []tierShare     `json:"tiers"`
+	Leaders        []leaderOffered `json:"leaders"`
+}
+
+type settingsOutput struct {
+	PoolSize         int     `json:"pool_size"`
+	MinTier          string  `json:"min_tier"`
+	PlayerTag        string  `json:"player_tag"`
+	Diversity        int     `json:"diversity"`
+	DiversityMode    string  `json:"diversity_mode"`
+	Balance          string  `json:"balance"`
+	BalanceTolerance float64 `json:"balance_tolerance"`
+}
+
+type tierShare struct {
+	Tier    string  `json:"tier"`
+	PerPool float64 `json:"per_pool"`
+}
+
+type leaderOffered struct {
+	ID         int64   `json:"id"`
+	LeaderName string  `json:"leader_name"`
+	CivName    string  `json:"civ_name"`
+	Tier       string  `json:"tier"`
+	Offers     int     `json:"offers"`
+	Rate       float64 `json:"rate"`
+}
+
+func (r *RollSimulateCommand) Run(c *ci6ndex.Ci6ndex, out io.Writer) error {
+	if err := r.check(c); err != nil {
+		return err
+	}
+	settings, err := c.GetRollSettings(r.Guild)
+	if err != nil {
+		return err
+	}
+	if settings, err = r.override(settings); err != nil {
+		return err
+	}
+	sim, err := c.SimulateRolls(r.Guild, ci6ndex.SimulationOptions{Players: r.Players, Rolls: r.Rolls,
+		Settings: settings})
+	if err != nil {
+		return errors.Join(err, errors.New("failed to simulate rolls"))
+	}
+	o := newSimulationOutput(sim)
+	if r.JSON {
+		return r.print(out, o, table{})
+	}
+	return r.write(out, o)
+}
+
+// override applies the flags that were set on top of the guild's roll settings.
+func (r *RollSimulateCommand) override(s ci6ndex.RollSettings) (ci6ndex.RollSettings, error) {
+	if r.PoolSize != nil {
+		s.PoolSize = *r.PoolSize
+	}
+	if r.MinTier != nil {
+		if strings.EqualFold(*r.MinTier, "off") {
+			s.MinTier = 0
+		} else {
+			tier, err := ci6ndex.GetTierByName(strings.ToUpper(*r.MinTier))
+			if err != nil {
+				return s, err
+			}
+			s.MinTier = tier.Value()
+		}
+	}
+	if r.PlayerTag != nil {
+		s.PlayerTag = strings.ToLower(*r.PlayerTag)
+		if s.PlayerTag == "off" {
+			s.PlayerTag = ""
+		}
+	}
+	if r.Diversity != nil {
+		s.DiversityMin = *r.Diversity
+	}
+	if r.DiversityMode != nil {
+		s.DiversityMode = ci6ndex.DiversityMode(*r.DiversityMode)
+	}
+	if r.Balance != nil {
+		s.BalanceMode = ci6ndex.BalanceMode(*r.Balance)
+	}
+	if r.Tolerance != nil {
+		s.BalanceTolerance = *r.Tolerance
+	}
+	return s, s.Validate()
+}
+
+func newSimulationOutput(sim ci6ndex.Simulation) simulationOutput {
+	s := sim.Options.Settings
+	o := simulationOutput{
+		Players: sim.Options.Players,
+		Rolls:   sim.Options.Rolls,
+		Settings: settingsOutput{
+			PoolSize:         s.PoolSize,
+			MinTier:          "off",
+			PlayerTag:        "off",
+			Diversity:        s.DiversityMin,
+			DiversityMode:    string(s.DiversityMode),
+			Balance:          string(s.BalanceMode),
+			BalanceTolerance: s.BalanceTolerance,
+		},
+		Failures:       sim.Failures,
+		FailureRate:    sim.FailureRate(),
+		FailureReasons: sim.FailureReasons,
+		OutOfTolerance: sim.OutOfTolerance,
+		MaxPlayers:     sim.MaxPlayers,
+		Tiers:          make([]tierShare, len(sim.Tiers)),
+		Leaders:        make([]leaderOffered, len(sim.Leaders)),
+	}
+	if s.MinTier > 0 {
+		o.Settings.MinTier = ci6ndex.TierLetter(s.MinTier)
+	}
+	if s.PlayerTag != "" {
+		o.Settings.PlayerTag = s.PlayerTag
+	}
+	for i, t := range sim.Tiers {
+		o.Tiers[i] = tierShare{Tier: t.Tier.Letter(), PerPool: t.PerPool}
+	}
+	for i, l := range sim.Leaders {
+		o.Leaders[i] = leaderOffered{ID: l.Leader.ID, LeaderName: ci6ndex.LeaderName(l.Leader), CivName: l.Leader.CivName,
+			Tier: ci6ndex.LeaderTierLetter(l.Leader), Offers: l.Offers, Rate: l.Rate}
+	}
+	return o
+}
+
+// write prints the summary, the make up of an average pool and the most and least offered leaders.
+func (r *RollSimulateCommand) write(out io.Writer, o simulationOutput) error {
+	summary := table{}
+	summary.add("Rolls", fmt.Sprintf("%d for %d players", o.Rolls, o.Players))
+	summary.add("Settings", fmt.Sprintf("pool of %d, min tier %s, play style %s, diversity %d by %s, "+
+		"balance %s within %g", o.Settings.PoolSize, o.Settings.MinTier, o.Settings.PlayerTag, o.Settings.Diversity,
+		o.Settings.DiversityMode, o.Settings.Balance, o.Settings.BalanceTolerance))
+	summary.add("Failures", fmt.Sprintf("%d (%.1f%%)", o.Failures, o.FailureRate*100))
+	reasons := make([]string, 0, len(o.FailureReasons))
+	for reason := range o.FailureReasons {
+		reasons = append(reasons, reason)
+	}
+	slices.Sort(reasons)
+	for _, reason := range reasons {
+		summary.add("", fmt.Sprintf("%s: %d", reason, o.FailureReasons[reason]))
+	}
+	if o.Settings.Balance != string(ci6ndex.BalanceOff) {
+		summary.add("Unbalanced", fmt.Sprintf("%d rolls out of tolerance", o.OutOfTolerance))
+	}
+	summary.add("Max players", o.MaxPlayers)
+
+	tiers := table{header: []string{"TIER", "PER POOL"}}
+	for _, t := range o.Tiers {
+		tiers.add(t.Tier, fmt.Sprintf("%.2f", t.PerPool))
+	}
+
+	leaders := table{header: []string{"ID", "LEADER", "CIV", "TIER", "OFFERS", "RATE"}}
+	shown := o.Leaders
+	if r.Top > 0 && len(shown) > 2*r.Top {
+		// the most offered, then the least offered
+		shown = append(slices.Clone(shown[:r.Top]), shown[len(shown)-r.Top:]...)
+	}
+	for i, l := range shown {
+		if r.Top > 0 && len(o.Leaders) > 2*r.Top && i == r.Top {
+			leaders.add("...", "", "", "", "", "")
+		}
+		leaders.add(l.ID, l.LeaderName, l.CivName, l.Tier, l.Offers, percent(l.Rate))
+	}
+
+	for i, t := range []table{summary, tiers, leaders} {
+		if i > 0 {
+			if _, err := fmt.Fprintln(out); err != nil {
+				return err
+			}
+		}
+		if err := t.write(out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,58 @@
+package cmd
+
+import (
+	"ci6ndex/ci6ndex"
+	"errors"
+	"io"
+	"strings"
+)
+
+type TiersRecalcCommand struct {
+	GuildFlag   `embed:""`
+	OutputFlags `embed:""`
+	Leader      uint64 `help:"Only recalculate this leader's tier."`
+	BBG         bool   `name:"bbg" help:"Print the BBG tier list, with the leaders added by BBG Expanded."`
+}
+
+type Tiers struct {
+	Recalc TiersRecalcCommand `cmd:"" help:"Recalculate the community tiers from the ranks and print the tier list."`
+}
+
+type tierOutput struct {
+	Tier    string         `json:"tier"`
+	Leaders []leaderOutput `json:"leaders"`
+}
+
+func (t *TiersRecalcCommand) Run(c *ci6ndex.Ci6ndex, out io.Writer) error {
+	if err := t.check(c); err != nil {
+		return err
+	}
+	if t.Leader != 0 {
+		leader, err := c.GetLeaderById(t.Guild, t.Leader)
+		if err != nil {
+			return err
+		}
+		if err := c.CalculateTierForLeader(t.Guild, leader.ID); err != nil {
+			return errors.Join(err, errors.New("failed to recalculate tier"))
+		}
+	} else if err := c.CalculateTiers(t.Guild); err != nil {
+		return errors.Join(err, errors.New("failed to recalculate tiers"))
+	}
+
+	rows, err := c.GetTierList(t.Guild, ci6ndex.TierListOptions{BBG: t.BBG})
+	if err != nil {
+		return err
+	}
+	tiers := make([]tierOutput, len(rows))
+	tbl := table{header: []string{"TIER", "LEADERS"}}
+	for i, row := range rows {
+		tiers[i] = tierOutput{Tier: row.Tier.Letter(), Leaders: make([]leaderOutput, len(row.Leaders))}
+		names := make([]string, len(row.Leaders))
+		for j, l := range row.Leaders {
+			tiers[i].Leaders[j] = newLeaderOutput(ci6ndex.LeaderStats{Leader: l})
+			names[j] = ci6ndex.LeaderName(l)
+		}
+		tbl.add(tiers[i].Tier, strings.Join(names, ", "))
+	}
+	return t.print(out, tiers, tbl)
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"ci6ndex/ci6ndex"
+	"ci6ndex/web"
+	"errors"
+	"log/slog"
+	"time"
+)
+
+type WebCommand struct {
+	Addr string `help:"Serve the dashboard on this address." env:"WEB_ADDR" default:":8082"`
+}
+
+func (w *WebCommand) Run(c *ci6ndex.Ci6ndex, timeout shutdownTimeout) error {
+	srv := web.NewHTTPServer(w.Addr, web.New(c))
+	slog.Info("Serving the web dashboard", "addr", w.Addr)
+	if err := serveHTTP(srv, c, time.Duration(timeout)); err != nil {
+		return errors.Join(errors.New("failed to serve the web dashboard"), err)
+	}
+	return nil
+}
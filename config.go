@@ -1,12 +1,18 @@
 package main
 
-import "github.com/caarlos0/env/v11"
+import (
+	"time"
+
+	"github.com/caarlos0/env/v11"
+)
 
 type Config struct {
 	DiscordToken     string `env:"DISCORD_API_TOKEN"`
 	BotApplicationID string `env:"DISCORD_BOT_APPLICATION_ID"`
 	GuildIDs         string `env:"GUILD_IDS"`
 	ListenToGuildID  string `env:"LISTEN_TO_GUILD_ID"`
+	// ShutdownTimeout is how long shutting down waits for in-flight work, e.g. 30s
+	ShutdownTimeout time.Duration `env:"SHUTDOWN_TIMEOUT" envDefault:"10s"`
 }
 
 func loadConfig() (*Config, error) {
@@ -5,9 +5,12 @@ import (
 	"ci6ndex/ci6ndex"
 	"ci6ndex/cmd"
 	"embed"
+	"errors"
 	"log/slog"
 	"os"
 	"time"
+	// game night times are entered in the host's timezone, and the alpine image ships no zoneinfo to load it from
+	_ "time/tzdata"
 
 	"github.com/charmbracelet/log"
 )
@@ -27,19 +30,25 @@ func main() {
 		slog.Error("failed to load ci6ndex", slog.Any("err", err))
 		os.Exit(1)
 	}
-	b := bot.New(
-		c,
-		config.DiscordToken,
-		config.GuildIDs,
-		config.ListenToGuildID,
-	)
-	err = b.Configure()
-	if err != nil {
-		slog.Error("Failed to configure bot", slog.Any("err", err))
-		os.Exit(1)
+	// only the bot commands connect to Discord, the others run without a token
+	newBot := func() (*bot.Bot, error) {
+		if config.DiscordToken == "" || config.ListenToGuildID == "" {
+			return nil, errors.New("DISCORD_API_TOKEN and LISTEN_TO_GUILD_ID are required to run the bot")
+		}
+		b := bot.New(
+			c,
+			config.DiscordToken,
+			config.GuildIDs,
+			config.ListenToGuildID,
+		)
+		b.ShutdownTimeout = config.ShutdownTimeout
+		if err := b.Configure(); err != nil {
+			return nil, errors.Join(errors.New("failed to configure bot"), err)
+		}
+		return b, nil
 	}
 
-	if err := cmd.Exec(b); err != nil {
+	if err := cmd.Exec(c, newBot, config.ShutdownTimeout); err != nil {
 		slog.Error("Failed to execute command", slog.Any("err", err))
 		os.Exit(1)
 	}
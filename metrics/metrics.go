@@ -0,0 +1,256 @@
+// Package metrics keeps the bot's counters and histograms and writes them in the Prometheus text format.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+var (
+	Interactions = Default.NewCounterVec("ci6ndex_interactions_total",
+		"Interactions handled, by type and route.", "type", "route")
+	HandlerErrors = Default.NewCounterVec("ci6ndex_handler_errors_total",
+		"Interactions whose handler returned an error, by type and route.", "type", "route")
+	RollFailures = Default.NewCounterVec("ci6ndex_roll_failures_total",
+		"Draft rolls that failed, by error type.", "error")
+	DBQueryDuration = Default.NewHistogramVec("ci6ndex_db_query_duration_seconds",
+		"Latency of database queries, by query.", DefaultBuckets, "query")
+)
+
+// DefaultBuckets are the histogram buckets in seconds, sized for SQLite queries.
+var DefaultBuckets = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1}
+
+// Default is the registry the bot's metrics are kept in.
+var Default = &Registry{}
+
+// Registry is a set of metrics written out together.
+type Registry struct {
+	mu      sync.Mutex
+	metrics []metric
+}
+
+type metric interface {
+	write(w io.Writer) error
+}
+
+func (r *Registry) register(m metric) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.metrics = append(r.metrics, m)
+}
+
+// NewCounterVec registers a counter partitioned by the given labels.
+func (r *Registry) NewCounterVec(name, help string, labels ...string) *CounterVec {
+	c := &CounterVec{name: name, help: help, labels: labels, values: make(map[string]*counter)}
+	r.register(c)
+	return c
+}
+
+// NewHistogramVec registers a histogram with the given upper bounds, partitioned by the given labels.
+func (r *Registry) NewHistogramVec(name, help string, buckets []float64, labels ...string) *HistogramVec {
+	h := &HistogramVec{
+		name:    name,
+		help:    help,
+		labels:  labels,
+		buckets: slices.Sorted(slices.Values(buckets)),
+		values:  make(map[string]*histogram),
+	}
+	r.register(h)
+	return h
+}
+
+// Write writes every metric in the Prometheus text exposition format.
+func (r *Registry) Write(w io.Writer) error {
+	r.mu.Lock()
+	metrics := slices.Clone(r.metrics)
+	r.mu.Unlock()
+	for _, m := range metrics {
+		if err := m.write(w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// CounterVec counts events, one count per combination of label values.
+type CounterVec struct {
+	name   string
+	help   string
+	labels []string
+	mu     sync.Mutex
+	values map[string]*counter
+}
+
+type counter struct {
+	labelValues []string
+	value       float64
+}
+
+// Inc adds one to the count of the given label values, passed in the order the labels were registered in.
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.Add(1, labelValues...)
+}
+
+func (c *CounterVec) Add(v float64, labelValues ...string) {
+	key := labelKey(c.labels, labelValues)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	ct, ok := c.values[key]
+	if !ok {
+		ct = &counter{labelValues: slices.Clone(labelValues)}
+		c.values[key] = ct
+	}
+	ct.value += v
+}
+
+// Value is the count of the given label values.
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if ct, ok := c.values[labelKey(c.labels, labelValues)]; ok {
+		return ct.value
+	}
+	return 0
+}
+
+func (c *CounterVec) write(w io.Writer) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s counter\n", c.name, c.help, c.name); err != nil {
+		return err
+	}
+	for _, key := range sortedKeys(c.values) {
+		ct := c.values[key]
+		_, err := fmt.Fprintf(w, "%s%s %s\n", c.name, formatLabels(c.labels, ct.labelValues), formatValue(ct.value))
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// HistogramVec tracks the distribution of observed values, one distribution per combination of label values.
+type HistogramVec struct {
+	name    string
+	help    string
+	labels  []string
+	buckets []float64
+	mu      sync.Mutex
+	values  map[string]*histogram
+}
+
+type histogram struct {
+	labelValues []string
+	// counts holds the observations per bucket, not cumulative, the last one counts those above every bucket
+	counts []uint64
+	count  uint64
+	sum    float64
+}
+
+func (h *HistogramVec) Observe(v float64, labelValues ...string) {
+	key := labelKey(h.labels, labelValues)
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	hist, ok := h.values[key]
+	if !ok {
+		hist = &histogram{labelValues: slices.Clone(labelValues), counts: make([]uint64, len(h.buckets)+1)}
+		h.values[key] = hist
+	}
+	i, _ := slices.BinarySearch(h.buckets, v)
+	hist.counts[i]++
+	hist.count++
+	hist.sum += v
+}
+
+// ObserveDuration observes the time since start in seconds.
+func (h *HistogramVec) ObserveDuration(start time.Time, labelValues ...string) {
+	h.Observe(time.Since(start).Seconds(), labelValues...)
+}
+
+// Count is how many values were observed with the given label values.
+func (h *HistogramVec) Count(labelValues ...string) uint64 {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if hist, ok := h.values[labelKey(h.labels, labelValues)]; ok {
+		return hist.count
+	}
+	return 0
+}
+
+func (h *HistogramVec) write(w io.Writer) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if _, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s histogram\n", h.name, h.help, h.name); err != nil {
+		return err
+	}
+	labels := append(slices.Clone(h.labels), "le")
+	for _, key := range sortedKeys(h.values) {
+		hist := h.values[key]
+		var cumulative uint64
+		for i, count := range hist.counts {
+			cumulative += count
+			le := math.Inf(1)
+			if i < len(h.buckets) {
+				le = h.buckets[i]
+			}
+			values := append(slices.Clone(hist.labelValues), formatValue(le))
+			_, err := fmt.Fprintf(w, "%s_bucket%s %d\n", h.name, formatLabels(labels, values), cumulative)
+			if err != nil {
+				return err
+			}
+		}
+		set := formatLabels(h.labels, hist.labelValues)
+		_, err := fmt.Fprintf(w, "%s_sum%s %s\n%s_count%s %d\n", h.name, set, formatValue(hist.sum),
+			h.name, set, hist.count)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// labelKey identifies a combination of label values. Missing values are empty, extra ones are dropped.
+func labelKey(labels, values []string) string {
+	values = slices.Clone(values)
+	for len(values) < len(labels) {
+		values = append(values, "")
+	}
+	return strings.Join(values[:len(labels)], "\xff")
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+func formatLabels(labels, values []string) string {
+	if len(labels) == 0 {
+		return ""
+	}
+	pairs := make([]string, len(labels))
+	for i, label := range labels {
+		var v string
+		if i < len(values) {
+			v = values[i]
+		}
+		pairs[i] = fmt.Sprintf("%s=%q", label, v)
+	}
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatValue(v float64) string {
+	if math.IsInf(v, 1) {
+		return "+Inf"
+	}
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
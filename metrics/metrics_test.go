@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestRegistry_Write(t *testing.T) {
+	r := &Registry{}
+	counter := r.NewCounterVec("test_total", "Test counter.", "route")
+	counter.Inc("/b")
+	counter.Inc("/a")
+	counter.Add(2, "/a")
+	histogram := r.NewHistogramVec("test_seconds", "Test histogram.", []float64{1, 0.1}, "query")
+	histogram.Observe(0.05, "GetPlayers")
+	histogram.Observe(0.5, "GetPlayers")
+	histogram.Observe(5, "GetPlayers")
+
+	var out bytes.Buffer
+	if err := r.Write(&out); err != nil {
+		t.Fatal(err)
+	}
+	want := `# HELP test_total Test counter.
+# TYPE test_total counter
+test_total{route="/a"} 3
+test_total{route="/b"} 1
+# HELP test_seconds Test histogram.
+# TYPE test_seconds histogram
+test_seconds_bucket{query="GetPlayers",le="0.1"} 1
+test_seconds_bucket{query="GetPlayers",le="1"} 2
+test_seconds_bucket{query="GetPlayers",le="+Inf"} 3
+test_seconds_sum{query="GetPlayers"} 5.55
+test_seconds_count{query="GetPlayers"} 3
+`
+	if out.String() != want {
+		t.Errorf("expected\n%s\ngot\n%s", want, out.String())
+	}
+}
+
+func TestCounterVec_Labels(t *testing.T) {
+	r := &Registry{}
+	counter := r.NewCounterVec("test_total", "Test counter.", "type", "route")
+	counter.Inc("command", `/say "hi"`)
+	if v := counter.Value("command", `/say "hi"`); v != 1 {
+		t.Errorf("expected 1, got %g", v)
+	}
+
+	var out bytes.Buffer
+	if err := r.Write(&out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `test_total{type="command",route="/say \"hi\""} 1`) {
+		t.Errorf("expected quotes in label values to be escaped, got\n%s", out.String())
+	}
+}
@@ -0,0 +1,43 @@
+-- +goose Up
+-- Append-only log of every rank a player has submitted. The ranks table only
+-- keeps the latest submission per player/leader.
+CREATE TABLE rank_history
+(
+    id INTEGER PRIMARY KEY,
+    leader_id INTEGER NOT NULL,
+    player_id INTEGER NOT NULL,
+    tier FLOAT NOT NULL,
+    bbg BOOLEAN NOT NULL DEFAULT FALSE,
+    submitted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (leader_id) REFERENCES leaders (id),
+    FOREIGN KEY (player_id) REFERENCES players (id)
+);
+
+-- Snapshot of a leader's computed tier every time it changes. player_id is the
+-- player whose submission caused the change, NULL for bulk recalculations.
+CREATE TABLE tier_history
+(
+    id INTEGER PRIMARY KEY,
+    leader_id INTEGER NOT NULL,
+    tier FLOAT NOT NULL,
+    player_id INTEGER,
+    calculated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (leader_id) REFERENCES leaders (id),
+    FOREIGN KEY (player_id) REFERENCES players (id)
+);
+
+CREATE INDEX idx_rank_history_leader_id ON rank_history (leader_id);
+CREATE INDEX idx_tier_history_leader_id ON tier_history (leader_id);
+
+-- Seed history with the current state so every leader has a starting point.
+INSERT INTO rank_history (leader_id, player_id, tier, bbg, submitted_at)
+SELECT leader_id, player_id, tier, bbg, updated_at FROM ranks;
+
+INSERT INTO tier_history (leader_id, tier)
+SELECT id, tier FROM leaders WHERE unranked = false;
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_tier_history_leader_id;
+DROP INDEX IF EXISTS idx_rank_history_leader_id;
+DROP TABLE IF EXISTS tier_history;
+DROP TABLE IF EXISTS rank_history;
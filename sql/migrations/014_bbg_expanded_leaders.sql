@@ -0,0 +1,8 @@
+-- +goose Up
+-- Leaders that only exist with the BBG Expanded mod installed.
+ALTER TABLE leaders ADD COLUMN bbg_expanded BOOLEAN NOT NULL DEFAULT FALSE;
+
+UPDATE leaders SET bbg_expanded = TRUE WHERE id BETWEEN 78 AND 89;
+
+-- +goose Down
+ALTER TABLE leaders DROP COLUMN bbg_expanded;
@@ -0,0 +1,18 @@
+-- +goose Up
+-- The winner of a finished draft. picks.player holds the same player id so a
+-- leader's win rate is the share of its picks made by the draft winner.
+CREATE TABLE draft_results
+(
+    draft_id INTEGER PRIMARY KEY,
+    winner TEXT NOT NULL,
+    recorded_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (draft_id) REFERENCES drafts (id)
+);
+
+CREATE INDEX idx_picks_pick ON picks (pick);
+CREATE INDEX idx_documents_leader_id ON documents (leader_id);
+
+-- +goose Down
+DROP INDEX IF EXISTS idx_documents_leader_id;
+DROP INDEX IF EXISTS idx_picks_pick;
+DROP TABLE IF EXISTS draft_results;
@@ -0,0 +1,25 @@
+-- +goose Up
+-- Player submitted guides. A document is either a link or a short markdown
+-- note, in which case link is empty. Documents added by migrations have no
+-- submitter and are approved.
+ALTER TABLE documents ADD COLUMN note TEXT;
+ALTER TABLE documents ADD COLUMN submitted_by INTEGER REFERENCES players (id);
+ALTER TABLE documents ADD COLUMN approved BOOLEAN NOT NULL DEFAULT TRUE;
+ALTER TABLE documents ADD COLUMN created_at TIMESTAMP;
+
+CREATE TABLE document_votes
+(
+    document_id INTEGER NOT NULL,
+    player_id INTEGER NOT NULL,
+    voted_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (document_id, player_id),
+    FOREIGN KEY (document_id) REFERENCES documents (id),
+    FOREIGN KEY (player_id) REFERENCES players (id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS document_votes;
+ALTER TABLE documents DROP COLUMN created_at;
+ALTER TABLE documents DROP COLUMN approved;
+ALTER TABLE documents DROP COLUMN submitted_by;
+ALTER TABLE documents DROP COLUMN note;
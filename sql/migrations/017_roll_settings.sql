@@ -0,0 +1,17 @@
+-- +goose Up
+-- The rules used when rolling leaders for a draft. Every guild has its own
+-- database, so the table only ever holds a single row.
+CREATE TABLE roll_settings
+(
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    pool_size INTEGER NOT NULL DEFAULT 5,
+    min_tier REAL NOT NULL DEFAULT 3,
+    diversity_min INTEGER NOT NULL DEFAULT 0,
+    diversity_mode TEXT NOT NULL DEFAULT 'victory',
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO roll_settings (id) VALUES (1);
+
+-- +goose Down
+DROP TABLE IF EXISTS roll_settings;
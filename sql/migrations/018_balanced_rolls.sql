@@ -0,0 +1,8 @@
+-- +goose Up
+-- Balanced rolls even out the strength of every player's pool, see ci6ndex.BalanceMode.
+ALTER TABLE roll_settings ADD COLUMN balance_mode TEXT NOT NULL DEFAULT 'off';
+ALTER TABLE roll_settings ADD COLUMN balance_tolerance REAL NOT NULL DEFAULT 2;
+
+-- +goose Down
+ALTER TABLE roll_settings DROP COLUMN balance_tolerance;
+ALTER TABLE roll_settings DROP COLUMN balance_mode;
@@ -0,0 +1,26 @@
+-- +goose Up
+-- Drafts are either rolled as random pools per player or picked in turns from
+-- one shared list of leaders (a snake draft).
+ALTER TABLE drafts ADD COLUMN mode TEXT NOT NULL DEFAULT 'random';
+-- When the current snake draft turn began, used to auto-pick on timeout
+ALTER TABLE drafts ADD COLUMN turn_started_at TIMESTAMP;
+
+-- The order players pick in during a snake draft, turn 0 picks first
+CREATE TABLE draft_turns
+(
+    draft_id INTEGER NOT NULL,
+    turn INTEGER NOT NULL,
+    player_id INTEGER NOT NULL,
+    PRIMARY KEY (draft_id, turn),
+    FOREIGN KEY (draft_id) REFERENCES drafts (id),
+    FOREIGN KEY (player_id) REFERENCES players (id)
+);
+
+-- Picks made for a player when their turn timed out
+ALTER TABLE picks ADD COLUMN auto BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE picks DROP COLUMN auto;
+DROP TABLE IF EXISTS draft_turns;
+ALTER TABLE drafts DROP COLUMN turn_started_at;
+ALTER TABLE drafts DROP COLUMN mode;
@@ -0,0 +1,24 @@
+-- +goose Up
+-- How a blind pick draft settles two players secretly picking the same leader
+ALTER TABLE drafts ADD COLUMN blind_conflict TEXT NOT NULL DEFAULT 'repick';
+-- Players whose pick clashed pick again in the next round
+ALTER TABLE drafts ADD COLUMN blind_round INTEGER NOT NULL DEFAULT 0;
+
+-- Secret picks of a blind pick draft, hidden until the round is revealed
+CREATE TABLE blind_picks
+(
+    draft_id INTEGER NOT NULL,
+    round INTEGER NOT NULL,
+    player_id INTEGER NOT NULL,
+    leader_id INTEGER NOT NULL,
+    picked_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (draft_id, round, player_id),
+    FOREIGN KEY (draft_id) REFERENCES drafts (id),
+    FOREIGN KEY (player_id) REFERENCES players (id),
+    FOREIGN KEY (leader_id) REFERENCES leaders (id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS blind_picks;
+ALTER TABLE drafts DROP COLUMN blind_round;
+ALTER TABLE drafts DROP COLUMN blind_conflict;
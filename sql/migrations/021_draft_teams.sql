@@ -0,0 +1,14 @@
+-- +goose Up
+-- Teams the players of a draft were split into for a team game, numbered from 0
+CREATE TABLE draft_teams
+(
+    draft_id INTEGER NOT NULL,
+    player_id INTEGER NOT NULL,
+    team INTEGER NOT NULL,
+    PRIMARY KEY (draft_id, player_id),
+    FOREIGN KEY (draft_id) REFERENCES drafts (id),
+    FOREIGN KEY (player_id) REFERENCES players (id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS draft_teams;
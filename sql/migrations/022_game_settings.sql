@@ -0,0 +1,76 @@
+-- +goose Up
+-- The catalogue of game settings rolled alongside the leaders, e.g. the map type
+-- or game speed. Every category gets one value per draft, chosen at random with
+-- the given weight. An allowed value restricts its category to the allowed
+-- values, a blocked value is never rolled. See ci6ndex.GameSettingStatus.
+CREATE TABLE game_setting_options
+(
+    category TEXT NOT NULL,
+    value TEXT NOT NULL,
+    weight INTEGER NOT NULL DEFAULT 1,
+    status TEXT NOT NULL DEFAULT 'default',
+    PRIMARY KEY (category, value)
+);
+
+INSERT INTO game_setting_options (category, value, weight)
+VALUES ('map_type', 'Pangaea', 3),
+       ('map_type', 'Continents', 3),
+       ('map_type', 'Continents and Islands', 2),
+       ('map_type', 'Fractal', 2),
+       ('map_type', 'Lakes', 2),
+       ('map_type', 'Seven Seas', 2),
+       ('map_type', 'Small Continents', 2),
+       ('map_type', 'Archipelago', 1),
+       ('map_type', 'Inland Sea', 1),
+       ('map_type', 'Island Plates', 1),
+       ('map_type', 'Primordial', 1),
+       ('map_type', 'Tilted Axis', 1),
+       ('map_type', 'Terra', 1),
+       ('map_size', 'Duel', 0),
+       ('map_size', 'Tiny', 1),
+       ('map_size', 'Small', 3),
+       ('map_size', 'Standard', 3),
+       ('map_size', 'Large', 1),
+       ('map_size', 'Huge', 0),
+       ('game_speed', 'Online', 4),
+       ('game_speed', 'Quick', 2),
+       ('game_speed', 'Standard', 2),
+       ('game_speed', 'Epic', 1),
+       ('game_speed', 'Marathon', 0),
+       ('disasters', '0', 1),
+       ('disasters', '1', 2),
+       ('disasters', '2', 3),
+       ('disasters', '3', 2),
+       ('disasters', '4', 1),
+       ('starting_era', 'Ancient', 6),
+       ('starting_era', 'Classical', 2),
+       ('starting_era', 'Medieval', 1),
+       ('starting_era', 'Renaissance', 1),
+       ('starting_era', 'Industrial', 0),
+       ('starting_era', 'Modern', 0),
+       ('starting_era', 'Atomic', 0),
+       ('starting_era', 'Information', 0),
+       ('victory', 'All victories', 4),
+       ('victory', 'No religious victory', 2),
+       ('victory', 'No diplomatic victory', 1),
+       ('victory', 'Science and culture only', 1),
+       ('victory', 'Domination only', 1),
+       ('victory', 'Score only', 0),
+       ('city_states', 'None', 1),
+       ('city_states', 'Half', 2),
+       ('city_states', 'Default', 4),
+       ('city_states', 'Double', 1);
+
+-- The game settings rolled for a draft, one value per category
+CREATE TABLE draft_game_settings
+(
+    draft_id INTEGER NOT NULL,
+    category TEXT NOT NULL,
+    value TEXT NOT NULL,
+    PRIMARY KEY (draft_id, category),
+    FOREIGN KEY (draft_id) REFERENCES drafts (id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS draft_game_settings;
+DROP TABLE IF EXISTS game_setting_options;
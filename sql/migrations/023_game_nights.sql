@@ -0,0 +1,30 @@
+-- +goose Up
+-- Game nights scheduled with /schedule. starts_at is stored in UTC, timezone is
+-- the IANA zone the host scheduled it in and is only used for display.
+CREATE TABLE game_nights
+(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    starts_at TIMESTAMP NOT NULL,
+    timezone TEXT NOT NULL,
+    channel_id INTEGER NOT NULL,
+    message_id INTEGER NOT NULL DEFAULT 0,
+    created_by INTEGER NOT NULL,
+    reminded BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+-- A player's answer to a game night, one of yes, maybe or no
+CREATE TABLE game_night_rsvps
+(
+    game_night_id INTEGER NOT NULL,
+    player_id INTEGER NOT NULL,
+    response TEXT NOT NULL,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (game_night_id, player_id),
+    FOREIGN KEY (game_night_id) REFERENCES game_nights (id),
+    FOREIGN KEY (player_id) REFERENCES players (id)
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS game_night_rsvps;
+DROP TABLE IF EXISTS game_nights;
@@ -0,0 +1,25 @@
+-- +goose Up
+-- Timed bot actions, e.g. auto-picking when a snake draft turn times out. Jobs
+-- outlive restarts, jobs that came due while the bot was down run on startup.
+-- due_at is always written in UTC and truncated to seconds, so it compares
+-- correctly as text. status is one of pending, running, done, failed or
+-- cancelled, tag groups the jobs of one feature instance so they can be
+-- cancelled together, e.g. snake:12.
+CREATE TABLE jobs
+(
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    kind TEXT NOT NULL,
+    tag TEXT NOT NULL DEFAULT '',
+    payload TEXT NOT NULL DEFAULT '{}',
+    due_at TIMESTAMP NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts INTEGER NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX idx_jobs_status_due_at ON jobs (status, due_at);
+CREATE INDEX idx_jobs_tag ON jobs (tag);
+
+-- +goose Down
+DROP TABLE IF EXISTS jobs;
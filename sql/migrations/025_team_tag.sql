@@ -0,0 +1,6 @@
+-- +goose Up
+-- Guarantees every team of a team roll a leader with this play-style tag, see ci6ndex.TeamTagRule. Empty is off.
+ALTER TABLE roll_settings ADD COLUMN team_tag TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE roll_settings DROP COLUMN team_tag;
@@ -0,0 +1,6 @@
+-- +goose Up
+-- Guarantees every offering a leader with this play-style tag, see ci6ndex.TagRule. Empty is off.
+ALTER TABLE roll_settings ADD COLUMN player_tag TEXT NOT NULL DEFAULT '';
+
+-- +goose Down
+ALTER TABLE roll_settings DROP COLUMN player_tag;
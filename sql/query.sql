@@ -43,9 +43,31 @@ FROM leaders l
 ORDER BY l.civ_name, l.leader_name
 LIMIT ? OFFSET ?;
 
--- name: GetAllRanks :many
+-- name: QueryLeaderStats :many
+SELECT
+    sqlc.embed(l),
+    (SELECT COUNT(*) FROM ranks r WHERE r.leader_id = l.id) AS ratings,
+    (SELECT COUNT(*) FROM picks p WHERE p.pick = l.id) AS picks,
+    (SELECT COUNT(*)
+     FROM picks p
+     JOIN draft_results dr ON dr.draft_id = p.draft_id AND dr.winner = p.player
+     WHERE p.pick = l.id) AS wins,
+    (SELECT COUNT(*) FROM documents d WHERE d.leader_id = l.id AND d.approved = TRUE) AS documents
+FROM leaders l
+WHERE (sqlc.narg(min_tier) IS NULL OR (l.unranked = FALSE AND l.tier >= sqlc.narg(min_tier)))
+  AND (sqlc.narg(max_tier) IS NULL OR (l.unranked = FALSE AND l.tier < sqlc.narg(max_tier)))
+  AND (sqlc.narg(banned) IS NULL OR l.banned = sqlc.narg(banned))
+  AND (sqlc.narg(unranked) IS NULL OR l.unranked = sqlc.narg(unranked))
+  AND (sqlc.narg(bbg_expanded) IS NULL OR l.bbg_expanded = sqlc.narg(bbg_expanded))
+  AND (sqlc.narg(has_guide) IS NULL OR EXISTS (
+      SELECT 1 FROM documents d WHERE d.leader_id = l.id AND d.approved = TRUE
+  ) = sqlc.narg(has_guide))
+ORDER BY l.civ_name, l.leader_name;
+
+-- name: GetRanksForRuleset :many
 SELECT *
-FROM ranks r;
+FROM ranks r
+WHERE r.bbg = ?;
 
 -- name: GetAllRanksForLeader :many
 SELECT
@@ -53,21 +75,164 @@ SELECT
     r.tier,
     r.leader_id  
 FROM ranks r
-WHERE r.leader_id = ?;
+WHERE r.leader_id = ? AND r.bbg = ?;
 
 -- name: GetDocumentsForLeader :many
 SELECT
-    d.*
+    d.*,
+    (SELECT COUNT(*) FROM document_votes v WHERE v.document_id = d.id) AS votes
+FROM documents d
+WHERE d.leader_id = ?
+ORDER BY votes DESC, d.id;
+
+-- name: GetDocument :one
+SELECT *
 FROM documents d
-WHERE d.leader_id = ?;
+WHERE d.id = ?;
+
+-- name: GetPendingDocuments :many
+SELECT *
+FROM documents d
+WHERE d.approved = FALSE
+ORDER BY d.created_at, d.id;
 
 -- name: GetRanksForLeaderWithPlayers :many
 SELECT
     r.tier,
+    r.bbg,
     p.id AS player_id,
     p.username,
     p.global_name
 FROM ranks r
 JOIN players p ON r.player_id = p.id
 WHERE r.leader_id = ?
-ORDER BY r.tier, p.username;
+ORDER BY r.bbg, r.tier, p.username;
+
+-- name: GetLatestRankHistoryForLeader :one
+SELECT *
+FROM rank_history rh
+WHERE rh.leader_id = ? AND rh.bbg = ?
+ORDER BY rh.submitted_at DESC, rh.id DESC
+LIMIT 1;
+
+-- name: GetRankHistoryForLeader :many
+SELECT
+    rh.tier,
+    rh.bbg,
+    rh.submitted_at,
+    p.id AS player_id,
+    p.username,
+    p.global_name
+FROM rank_history rh
+JOIN players p ON rh.player_id = p.id
+WHERE rh.leader_id = ?
+ORDER BY rh.submitted_at, rh.id;
+
+-- name: GetTierHistoryForLeader :many
+SELECT
+    th.tier,
+    th.calculated_at,
+    th.player_id,
+    p.username,
+    p.global_name
+FROM tier_history th
+LEFT JOIN players p ON th.player_id = p.id
+WHERE th.leader_id = ?
+ORDER BY th.calculated_at, th.id;
+
+-- name: GetRanksForPlayer :many
+SELECT *
+FROM ranks r
+WHERE r.player_id = ?;
+
+-- name: GetRollSettings :one
+SELECT *
+FROM roll_settings
+WHERE id = 1;
+
+-- name: GetDraftTurns :many
+SELECT t.turn, p.*
+FROM draft_turns t
+JOIN players p ON t.player_id = p.id
+WHERE t.draft_id = ?
+ORDER BY t.turn;
+
+-- name: GetPicksForDraft :many
+SELECT *
+FROM picks
+WHERE draft_id = ?;
+
+-- name: GetDraftWinCounts :many
+SELECT dr.player_id, COUNT(r.draft_id) AS wins
+FROM draft_registry dr
+LEFT JOIN draft_results r ON r.winner = CAST(dr.player_id AS TEXT)
+WHERE dr.draft_id = ?
+GROUP BY dr.player_id;
+
+-- name: GetDraft :one
+SELECT * FROM drafts WHERE id = ?;
+
+-- name: GetDrafts :many
+SELECT d.*, r.winner, r.recorded_at
+FROM drafts d
+LEFT JOIN draft_results r ON r.draft_id = d.id
+ORDER BY d.id DESC;
+
+-- name: GetDraftResult :one
+SELECT * FROM draft_results WHERE draft_id = ?;
+
+-- name: GetDraftResults :many
+SELECT *
+FROM draft_results
+ORDER BY recorded_at DESC, draft_id DESC;
+
+-- name: GetPlayerDrafts :many
+SELECT d.id, d.mode, pk.pick, r.winner
+FROM draft_registry dr
+JOIN drafts d ON d.id = dr.draft_id
+LEFT JOIN picks pk ON pk.draft_id = d.id AND pk.player = CAST(dr.player_id AS TEXT)
+LEFT JOIN draft_results r ON r.draft_id = d.id
+WHERE dr.player_id = ?
+ORDER BY d.id DESC;
+
+-- name: GetBlindPicks :many
+SELECT *
+FROM blind_picks
+WHERE draft_id = ? AND round = ?;
+
+-- name: GetTeamsForDraft :many
+SELECT t.team, p.*
+FROM draft_teams t
+JOIN players p ON t.player_id = p.id
+WHERE t.draft_id = ?
+ORDER BY t.team, p.id;
+
+-- name: GetPreviousDraftTeams :many
+SELECT player_id, team
+FROM draft_teams
+WHERE draft_id = (
+    SELECT MAX(draft_id) FROM draft_teams WHERE draft_id < ?
+);
+
+-- name: GetGameSettingOptions :many
+SELECT *
+FROM game_setting_options
+ORDER BY rowid;
+
+-- name: GetDraftGameSettings :many
+SELECT *
+FROM draft_game_settings
+WHERE draft_id = ?
+ORDER BY rowid;
+
+-- name: GetGameNight :one
+SELECT *
+FROM game_nights
+WHERE id = ?;
+
+-- name: GetGameNightRSVPs :many
+SELECT r.response, p.*
+FROM game_night_rsvps r
+JOIN players p ON r.player_id = p.id
+WHERE r.game_night_id = ?
+ORDER BY r.updated_at, p.id;
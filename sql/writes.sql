@@ -50,8 +50,8 @@ DELETE FROM pool
     AND draft_id = ?;
 
 -- name: SubmitRankForPlayer :exec
-INSERT INTO ranks (player_id, leader_id, tier)
-VALUES (?, ?, ?)
+INSERT INTO ranks (player_id, leader_id, tier, bbg)
+VALUES (?, ?, ?, ?)
 ON CONFLICT (leader_id, player_id, bbg)
 DO UPDATE SET
     tier = excluded.tier,
@@ -62,3 +62,188 @@ DO UPDATE SET
 UPDATE leaders 
 SET tier = ?
 WHERE id = ?;
+
+-- name: SetLeaderBanned :exec
+UPDATE leaders
+SET banned = ?
+WHERE id = ?;
+
+-- name: AddRankHistory :exec
+INSERT INTO rank_history (player_id, leader_id, tier, bbg)
+VALUES (?, ?, ?, ?);
+
+-- name: AddTierHistory :exec
+INSERT INTO tier_history (leader_id, tier, player_id)
+VALUES (?, ?, ?);
+
+-- name: SetDraftWinner :exec
+INSERT INTO draft_results (draft_id, winner)
+VALUES (?, ?)
+ON CONFLICT (draft_id)
+DO UPDATE SET
+    winner = excluded.winner,
+    recorded_at = CURRENT_TIMESTAMP;
+
+-- name: AddDocument :one
+INSERT INTO documents (leader_id, doc_name, link, note, submitted_by, approved, created_at)
+VALUES (?, ?, ?, ?, ?, ?, CURRENT_TIMESTAMP)
+RETURNING *;
+
+-- name: ApproveDocument :exec
+UPDATE documents
+SET approved = TRUE
+WHERE id = ?;
+
+-- name: DeleteDocument :exec
+DELETE FROM documents
+WHERE id = ?;
+
+-- name: DeleteDocumentVotes :exec
+DELETE FROM document_votes
+WHERE document_id = ?;
+
+-- name: AddDocumentVote :execrows
+INSERT INTO document_votes (document_id, player_id)
+VALUES (?, ?)
+ON CONFLICT (document_id, player_id) DO NOTHING;
+
+-- name: RemoveDocumentVote :exec
+DELETE FROM document_votes
+WHERE document_id = ? AND player_id = ?;
+
+-- name: UpdateRollSettings :exec
+UPDATE roll_settings
+SET pool_size = ?,
+    min_tier = ?,
+    diversity_min = ?,
+    diversity_mode = ?,
+    balance_mode = ?,
+    balance_tolerance = ?,
+    team_tag = ?,
+    player_tag = ?,
+    updated_at = CURRENT_TIMESTAMP
+WHERE id = 1;
+
+-- name: SetDraftMode :exec
+UPDATE drafts
+SET mode = ?
+WHERE id = ?;
+
+-- name: SetTurnStartedAt :exec
+UPDATE drafts
+SET turn_started_at = ?
+WHERE id = ?;
+
+-- name: DeleteDraftTurns :exec
+DELETE FROM draft_turns
+WHERE draft_id = ?;
+
+-- name: AddDraftTurn :exec
+INSERT INTO draft_turns (draft_id, turn, player_id)
+VALUES (?, ?, ?);
+
+-- name: DeletePicksForDraft :exec
+DELETE FROM picks
+WHERE draft_id = ?;
+
+-- name: AddPick :exec
+INSERT INTO picks (player, draft_id, pick, auto)
+VALUES (?, ?, ?, ?);
+
+-- name: SetBlindConflict :exec
+UPDATE drafts
+SET blind_conflict = ?
+WHERE id = ?;
+
+-- name: StartBlindRound :exec
+UPDATE drafts
+SET blind_round = ?, turn_started_at = ?
+WHERE id = ?;
+
+-- name: SetBlindPick :exec
+INSERT INTO blind_picks (draft_id, round, player_id, leader_id)
+VALUES (?, ?, ?, ?)
+ON CONFLICT (draft_id, round, player_id) DO UPDATE
+SET leader_id = excluded.leader_id, picked_at = CURRENT_TIMESTAMP;
+
+-- name: DeleteBlindPicks :exec
+DELETE FROM blind_picks
+WHERE draft_id = ?;
+
+-- name: DeleteTeamsForDraft :exec
+DELETE FROM draft_teams
+WHERE draft_id = ?;
+
+-- name: AddTeamMember :exec
+INSERT INTO draft_teams (draft_id, player_id, team)
+VALUES (?, ?, ?);
+
+-- name: SetGameSettingStatus :exec
+UPDATE game_setting_options
+SET status = ?
+WHERE category = ? AND value = ?;
+
+-- name: DeleteDraftGameSettings :exec
+DELETE FROM draft_game_settings
+WHERE draft_id = ?;
+
+-- name: AddDraftGameSetting :exec
+INSERT INTO draft_game_settings (draft_id, category, value)
+VALUES (?, ?, ?);
+
+-- name: CreateGameNight :one
+INSERT INTO game_nights (starts_at, timezone, channel_id, created_by)
+VALUES (?, ?, ?, ?)
+RETURNING *;
+
+-- name: SetGameNightMessage :exec
+UPDATE game_nights
+SET message_id = ?
+WHERE id = ?;
+
+-- name: SetRSVP :exec
+INSERT INTO game_night_rsvps (game_night_id, player_id, response)
+VALUES (?, ?, ?)
+ON CONFLICT (game_night_id, player_id) DO UPDATE
+SET response = excluded.response, updated_at = CURRENT_TIMESTAMP;
+
+-- name: MarkGameNightReminded :execrows
+UPDATE game_nights
+SET reminded = TRUE
+WHERE id = ? AND reminded = FALSE;
+
+-- name: CreateJob :one
+INSERT INTO jobs (kind, tag, payload, due_at)
+VALUES (?, ?, ?, ?)
+RETURNING *;
+
+-- name: CancelJobs :execrows
+UPDATE jobs
+SET status = 'cancelled'
+WHERE tag = ? AND status = 'pending';
+
+-- name: ClaimDueJobs :many
+UPDATE jobs
+SET status = 'running', attempts = attempts + 1
+WHERE id IN (
+    SELECT id FROM jobs
+    WHERE status = 'pending' AND due_at <= ?
+    ORDER BY due_at, id
+    LIMIT ?
+)
+RETURNING *;
+
+-- name: CompleteJob :exec
+UPDATE jobs
+SET status = 'done'
+WHERE id = ?;
+
+-- name: RetryJob :exec
+UPDATE jobs
+SET status = ?, due_at = ?, last_error = ?
+WHERE id = ?;
+
+-- name: ResetRunningJobs :execrows
+UPDATE jobs
+SET status = 'pending'
+WHERE status = 'running';
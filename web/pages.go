@@ -0,0 +1,232 @@
+package web
+
+import (
+	"ci6ndex/ci6ndex"
+	"ci6ndex/ci6ndex/generated"
+	"net/http"
+	"strconv"
+)
+
+type tiersPage struct {
+	Rows []ci6ndex.TierListRow
+	BBG  bool
+}
+
+func (s *Server) tiers(w http.ResponseWriter, r *http.Request, guildID uint64) error {
+	bbg, _ := strconv.ParseBool(r.URL.Query().Get("bbg"))
+	rows, err := s.c.GetTierList(guildID, ci6ndex.TierListOptions{BBG: bbg})
+	if err != nil {
+		return err
+	}
+	return render(w, "tiers", page{Title: "Tier list", Guild: guildID, Data: tiersPage{Rows: rows, BBG: bbg}})
+}
+
+type leaderPage struct {
+	Stats       ci6ndex.LeaderStats
+	Metadata    ci6ndex.LeaderMetadata
+	HasMetadata bool
+	Ranks       []ci6ndex.LeaderRankWithPlayer
+	Documents   []ci6ndex.LeaderDocument
+	History     []ci6ndex.TierChange
+}
+
+func (s *Server) leader(w http.ResponseWriter, r *http.Request, guildID uint64) error {
+	leaderID, err := pathID(r, "leaderId")
+	if err != nil {
+		return err
+	}
+	leader, err := s.c.GetLeaderById(guildID, uint64(leaderID))
+	if err != nil {
+		return err
+	}
+	data := leaderPage{Stats: ci6ndex.LeaderStats{Leader: leader}}
+	data.Metadata, data.HasMetadata = ci6ndex.MetadataForLeader(leader)
+
+	stats, _, err := s.c.QueryLeaders(guildID, ci6ndex.LeaderQuery{})
+	if err != nil {
+		return err
+	}
+	for _, st := range stats {
+		if st.Leader.ID == leader.ID {
+			data.Stats = st
+		}
+	}
+	if data.Ranks, err = s.c.GetRanksForLeader(guildID, leader.ID); err != nil {
+		return err
+	}
+	docs, err := s.c.GetDocumentsForLeader(guildID, leader.ID)
+	if err != nil {
+		return err
+	}
+	// pending guides are only shown to admins in discord until they are approved
+	data.Documents = make([]ci6ndex.LeaderDocument, 0, len(docs))
+	for _, d := range docs {
+		if d.Document.Approved {
+			data.Documents = append(data.Documents, d)
+		}
+	}
+	if data.History, err = s.c.GetTierHistory(guildID, leader.ID); err != nil {
+		return err
+	}
+	return render(w, "leader", page{Title: ci6ndex.LeaderName(leader), Guild: guildID, Data: data})
+}
+
+type draftRow struct {
+	ci6ndex.DraftSummary
+	Winner *generated.Player
+}
+
+func (s *Server) drafts(w http.ResponseWriter, r *http.Request, guildID uint64) error {
+	drafts, err := s.c.GetDrafts(guildID)
+	if err != nil {
+		return err
+	}
+	players, err := s.playersByID(r, guildID)
+	if err != nil {
+		return err
+	}
+	rows := make([]draftRow, len(drafts))
+	for i, d := range drafts {
+		rows[i] = draftRow{DraftSummary: d}
+		if d.Result != nil {
+			rows[i].Winner = players[d.Result.Winner]
+		}
+	}
+	return render(w, "drafts", page{Title: "Drafts", Guild: guildID, Data: rows})
+}
+
+type draftPage struct {
+	Record    ci6ndex.DraftRecord
+	Winner    *generated.Player
+	Offerings []offering
+	Picks     []pick
+}
+
+type offering struct {
+	Player  *generated.Player
+	Leaders []generated.Leader
+}
+
+type pick struct {
+	Player *generated.Player
+	Leader generated.Leader
+	Auto   bool
+}
+
+func (s *Server) draft(w http.ResponseWriter, r *http.Request, guildID uint64) error {
+	draftID, err := pathID(r, "draftId")
+	if err != nil {
+		return err
+	}
+	record, err := s.c.GetDraft(guildID, draftID)
+	if err != nil {
+		return err
+	}
+	leaders, err := s.leadersByID(guildID)
+	if err != nil {
+		return err
+	}
+	players, err := s.playersByID(r, guildID)
+	if err != nil {
+		return err
+	}
+
+	data := draftPage{Record: record}
+	if record.Result != nil {
+		data.Winner = players[record.Result.Winner]
+	}
+	// the pool lists a player's offerings together, in the order they were rolled
+	for _, p := range record.Offerings {
+		player := players[strconv.FormatInt(p.PlayerID, 10)]
+		if len(data.Offerings) == 0 || data.Offerings[len(data.Offerings)-1].Player != player {
+			data.Offerings = append(data.Offerings, offering{Player: player})
+		}
+		last := &data.Offerings[len(data.Offerings)-1]
+		last.Leaders = append(last.Leaders, leaders[p.Leader])
+	}
+	for _, p := range record.Picks {
+		data.Picks = append(data.Picks, pick{Player: players[p.Player], Leader: leaders[p.Pick], Auto: p.Auto})
+	}
+	return render(w, "draft", page{Title: "Draft " + strconv.FormatInt(draftID, 10), Guild: guildID, Data: data})
+}
+
+func (s *Server) players(w http.ResponseWriter, r *http.Request, guildID uint64) error {
+	players, err := s.c.GetPlayers(r.Context(), guildID)
+	if err != nil {
+		return err
+	}
+	return render(w, "players", page{Title: "Players", Guild: guildID, Data: players})
+}
+
+type playerPage struct {
+	Player generated.Player
+	Ranks  []ci6ndex.PlayerRank
+	BBG    bool
+	Drafts []playerDraft
+	Wins   int
+}
+
+type playerDraft struct {
+	ci6ndex.PlayerDraft
+	Pick *generated.Leader
+}
+
+func (s *Server) player(w http.ResponseWriter, r *http.Request, guildID uint64) error {
+	playerID, err := pathID(r, "playerId")
+	if err != nil {
+		return err
+	}
+	player, err := s.c.GetPlayer(r.Context(), guildID, playerID)
+	if err != nil {
+		return err
+	}
+	data := playerPage{Player: *player}
+	data.BBG, _ = strconv.ParseBool(r.URL.Query().Get("bbg"))
+	if data.Ranks, err = s.c.GetPlayerRanks(guildID, playerID, data.BBG); err != nil {
+		return err
+	}
+	drafts, err := s.c.GetPlayerDrafts(guildID, playerID)
+	if err != nil {
+		return err
+	}
+	leaders, err := s.leadersByID(guildID)
+	if err != nil {
+		return err
+	}
+	for _, d := range drafts {
+		row := playerDraft{PlayerDraft: d}
+		if l, ok := leaders[d.PickID]; ok {
+			row.Pick = &l
+		}
+		if d.Won {
+			data.Wins++
+		}
+		data.Drafts = append(data.Drafts, row)
+	}
+	return render(w, "player", page{Title: ci6ndex.PlayerName(*player), Guild: guildID, Data: data})
+}
+
+func (s *Server) leadersByID(guildID uint64) (map[int64]generated.Leader, error) {
+	leaders, err := s.c.GetLeaders(guildID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[int64]generated.Leader, len(leaders))
+	for _, l := range leaders {
+		byID[l.ID] = l
+	}
+	return byID, nil
+}
+
+// playersByID keys the players by their ID as text, the way picks and results refer to them.
+func (s *Server) playersByID(r *http.Request, guildID uint64) (map[string]*generated.Player, error) {
+	players, err := s.c.GetPlayers(r.Context(), guildID)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*generated.Player, len(players))
+	for i := range players {
+		byID[strconv.FormatInt(players[i].ID, 10)] = &players[i]
+	}
+	return byID, nil
+}
@@ -0,0 +1,147 @@
+// Package web serves a read-only dashboard of the guilds' tier lists, leaders, drafts and players, rendered on the
+// server from the templates in templates/.
+package web
+
+import (
+	"bytes"
+	"ci6ndex/ci6ndex"
+	"database/sql"
+	"embed"
+	"errors"
+	"fmt"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"slices"
+	"strconv"
+	"time"
+)
+
+//go:embed templates/*.html
+var templateFS embed.FS
+
+var funcs = template.FuncMap{
+	"leaderName": ci6ndex.LeaderName,
+	"playerName": ci6ndex.PlayerName,
+	"tier":       ci6ndex.TierLetter,
+	"leaderTier": ci6ndex.LeaderTierLetter,
+	"percent": func(v float64) string {
+		return fmt.Sprintf("%.0f%%", v*100)
+	},
+	"date": func(t time.Time) string {
+		return t.Format("2 Jan 2006")
+	},
+}
+
+// pages are parsed once, each along with the layout it's rendered in.
+var pages = parsePages("index", "tiers", "leader", "drafts", "draft", "players", "player")
+
+func parsePages(names ...string) map[string]*template.Template {
+	parsed := make(map[string]*template.Template, len(names))
+	for _, name := range names {
+		parsed[name] = template.Must(template.New("layout.html").Funcs(funcs).
+			ParseFS(templateFS, "templates/layout.html", "templates/"+name+".html"))
+	}
+	return parsed
+}
+
+// Server renders the dashboard from the guild databases in the data directory. It never writes to them.
+type Server struct {
+	c *ci6ndex.Ci6ndex
+}
+
+func New(c *ci6ndex.Ci6ndex) *Server {
+	return &Server{c: c}
+}
+
+// NewHTTPServer serves the dashboard on addr.
+func NewHTTPServer(addr string, s *Server) *http.Server {
+	return &http.Server{
+		Addr:              addr,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+}
+
+// Handler routes the dashboard pages.
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /{$}", s.index)
+	mux.HandleFunc("GET /guilds/{guildId}", s.guild(s.tiers))
+	mux.HandleFunc("GET /guilds/{guildId}/leaders/{leaderId}", s.guild(s.leader))
+	mux.HandleFunc("GET /guilds/{guildId}/drafts", s.guild(s.drafts))
+	mux.HandleFunc("GET /guilds/{guildId}/drafts/{draftId}", s.guild(s.draft))
+	mux.HandleFunc("GET /guilds/{guildId}/players", s.guild(s.players))
+	mux.HandleFunc("GET /guilds/{guildId}/players/{playerId}", s.guild(s.player))
+	return mux
+}
+
+// page is what every template gets, Guild is 0 outside of a guild's pages.
+type page struct {
+	Title string
+	Guild uint64
+	Data  any
+}
+
+// errNotFound is rendered as a 404 page.
+var errNotFound = errors.New("not found")
+
+type guildHandler func(w http.ResponseWriter, r *http.Request, guildID uint64) error
+
+// guild only serves the guilds that have a database, so looking one up never creates it.
+func (s *Server) guild(fn guildHandler) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		guildID, err := strconv.ParseUint(r.PathValue("guildId"), 10, 64)
+		if err == nil {
+			var guilds []uint64
+			guilds, err = s.c.Guilds()
+			if err == nil && !slices.Contains(guilds, guildID) {
+				err = errNotFound
+			}
+		}
+		if err == nil {
+			err = fn(w, r, guildID)
+		}
+		if err != nil {
+			writeError(w, r, err)
+		}
+	}
+}
+
+func (s *Server) index(w http.ResponseWriter, r *http.Request) {
+	guilds, err := s.c.Guilds()
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+	if err := render(w, "index", page{Title: "Guilds", Data: guilds}); err != nil {
+		writeError(w, r, err)
+	}
+}
+
+// render executes the page into a buffer first, so a failing template doesn't leave half a page behind.
+func render(w http.ResponseWriter, name string, p page) error {
+	var buf bytes.Buffer
+	if err := pages[name].Execute(&buf, p); err != nil {
+		return err
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, err := buf.WriteTo(w)
+	return err
+}
+
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	var numErr *strconv.NumError
+	switch {
+	case errors.Is(err, errNotFound), errors.Is(err, ci6ndex.ErrDraftNotFound),
+		errors.Is(err, ci6ndex.ErrLeaderNotFound), errors.Is(err, sql.ErrNoRows), errors.As(err, &numErr):
+		http.Error(w, "Not found", http.StatusNotFound)
+	default:
+		slog.Error("failed to render page", "path", r.URL.Path, "error", err)
+		http.Error(w, "Something went wrong", http.StatusInternalServerError)
+	}
+}
+
+func pathID(r *http.Request, name string) (int64, error) {
+	return strconv.ParseInt(r.PathValue(name), 10, 64)
+}
@@ -0,0 +1,164 @@
+package web
+
+import (
+	"ci6ndex/ci6ndex"
+	"ci6ndex/ci6ndex/generated"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+
+	goose "github.com/pressly/goose/v3"
+)
+
+const testGuildID = 999999
+
+// fixture is a guild with one finished draft: host and player were offered two leaders each, both picked their first
+// one and player won.
+type fixture struct {
+	srv     *httptest.Server
+	c       *ci6ndex.Ci6ndex
+	draft   generated.Draft
+	leaders []generated.Leader
+}
+
+func newFixture(t *testing.T) fixture {
+	t.Helper()
+	goose.SetBaseFS(os.DirFS(".."))
+	goose.SetLogger(goose.NopLogger())
+	if err := goose.SetDialect("sqlite3"); err != nil {
+		t.Fatal(err)
+	}
+	c := &ci6ndex.Ci6ndex{Connections: make(map[uint64]*ci6ndex.DB), Path: t.TempDir() + "/"}
+	t.Cleanup(c.Close)
+
+	draft, err := c.GetOrCreateActiveDraft(testGuildID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	players := []generated.AddPlayerParams{{ID: 1, Username: "host"}, {ID: 2, Username: "player"}}
+	for _, p := range players {
+		if err := c.AddPlayer(context.Background(), testGuildID, p); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if errs := c.SetPlayersForDraft(testGuildID, draft.ID, players); len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	leaders, err := c.GetLeaders(testGuildID)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db := c.Connections[testGuildID]
+	ctx := context.Background()
+	for i, p := range players {
+		for _, l := range leaders[i*2 : i*2+2] {
+			err := db.Writes.AddPool(ctx, generated.AddPoolParams{PlayerID: p.ID, DraftID: draft.ID, Leader: l.ID})
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		err := db.Writes.AddPick(ctx, generated.AddPickParams{Player: strconv.FormatInt(p.ID, 10),
+			DraftID: draft.ID, Pick: leaders[i*2].ID})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := c.SetDraftWinner(testGuildID, draft.ID, 2); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.SubmitRankForPlayer(testGuildID, "S", 1, leaders[0].ID, false); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := httptest.NewServer(New(c).Handler())
+	t.Cleanup(srv.Close)
+	return fixture{srv: srv, c: c, draft: draft, leaders: leaders}
+}
+
+func get(t *testing.T, srv *httptest.Server, path string) (int, string) {
+	t.Helper()
+	res, err := http.Get(srv.URL + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return res.StatusCode, string(body)
+}
+
+func assertPage(t *testing.T, srv *httptest.Server, path string, substrings ...string) {
+	t.Helper()
+	status, body := get(t, srv, path)
+	if status != http.StatusOK {
+		t.Fatalf("expected %s to be ok, got %d\n%s", path, status, body)
+	}
+	for _, s := range substrings {
+		if !strings.Contains(body, s) {
+			t.Errorf("expected %q on %s", s, path)
+		}
+	}
+}
+
+func TestPages(t *testing.T) {
+	f := newFixture(t)
+	srv, draft, leaders := f.srv, f.draft, f.leaders
+	draftPath := "/guilds/999999/drafts/" + strconv.FormatInt(draft.ID, 10)
+	leaderPath := "/guilds/999999/leaders/" + strconv.FormatInt(leaders[0].ID, 10)
+
+	assertPage(t, srv, "/", `href="/guilds/999999"`)
+	assertPage(t, srv, "/guilds/999999", "Tier list", leaderPath, leaders[0].CivName)
+	assertPage(t, srv, "/guilds/999999/drafts", draftPath, "player")
+	assertPage(t, srv, draftPath, "Offerings", ci6ndex.LeaderName(leaders[3]), "Won by", "/guilds/999999/players/2")
+	assertPage(t, srv, leaderPath, leaders[0].CivName, "Picks", "/guilds/999999/players/1")
+	assertPage(t, srv, "/guilds/999999/players", "/guilds/999999/players/1", "/guilds/999999/players/2")
+	assertPage(t, srv, "/guilds/999999/players/2", "1 wins in 1 drafts", ci6ndex.LeaderName(leaders[2]), "Won")
+	assertPage(t, srv, "/guilds/999999/players/1", "Lost", ci6ndex.LeaderName(leaders[0]))
+}
+
+func TestLeaderPage_PendingGuides(t *testing.T) {
+	f := newFixture(t)
+	leaderID := f.leaders[0].ID
+	for _, d := range []ci6ndex.DocumentSubmission{
+		{LeaderID: leaderID, Name: "Approved Guide", Link: "https://example.com/approved", Approved: true},
+		{LeaderID: leaderID, Name: "Pending Guide", Link: "https://example.com/pending"},
+	} {
+		if _, err := f.c.SubmitDocument(testGuildID, d); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	path := "/guilds/999999/leaders/" + strconv.FormatInt(leaderID, 10)
+	assertPage(t, f.srv, path, "Approved Guide")
+	if _, body := get(t, f.srv, path); strings.Contains(body, "Pending Guide") {
+		t.Errorf("expected pending guides to be hidden on %s", path)
+	}
+}
+
+func TestNotFound(t *testing.T) {
+	f := newFixture(t)
+	srv := f.srv
+	for _, path := range []string{
+		"/guilds/1",
+		"/guilds/abc",
+		"/guilds/999999/drafts/12345",
+		"/guilds/999999/leaders/100000",
+		"/guilds/999999/players/12345",
+		"/guilds/999999/players/abc",
+	} {
+		if status, _ := get(t, srv, path); status != http.StatusNotFound {
+			t.Errorf("expected %s to be not found, got %d", path, status)
+		}
+	}
+	if guilds, err := f.c.Guilds(); err != nil || len(guilds) != 1 {
+		t.Errorf("expected looking up unknown guilds to not create their databases, got %v %v", guilds, err)
+	}
+}